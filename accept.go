@@ -0,0 +1,53 @@
+package httpc
+
+import (
+	"io"
+	"reflect"
+)
+
+const (
+	acceptAny  = "*/*"
+	acceptJSON = "application/json, application/xml;q=0.9, */*;q=0.8"
+	acceptText = "text/plain, application/json;q=0.9, */*;q=0.8"
+)
+
+// applyDefaultAccept sets the Accept header of the request based on the type of dst, if not already set.
+//
+// Streams and byte slices accept any content type, strings prefer plain text and structs, maps and slices prefer JSON.
+// For other types no header is set.
+func (ctx *fetchContext) applyDefaultAccept(dst any) {
+	if ctx.Request.Header.Get("Accept") != "" {
+		return
+	}
+
+	if accept := defaultAccept(dst); accept != "" {
+		ctx.Request.Header.Set("Accept", accept)
+	}
+}
+
+func defaultAccept(dst any) string {
+	switch dst.(type) {
+	case *io.ReadCloser, *[]byte:
+		return acceptAny
+	case *string:
+		return acceptText
+	}
+
+	t := reflect.TypeOf(dst)
+	if t == nil || t.Kind() != reflect.Pointer {
+		return ""
+	}
+
+	t = t.Elem()
+
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		return acceptJSON
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,111 @@
+package httpc_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/nussjustin/httpc"
+)
+
+func TestDefaultAccept(t *testing.T) {
+	type value struct{}
+
+	const (
+		acceptAny  = "*/*"
+		acceptJSON = "application/json, application/xml;q=0.9, */*;q=0.8"
+		acceptText = "text/plain, application/json;q=0.9, */*;q=0.8"
+	)
+
+	testCases := []struct {
+		Name     string
+		Fetch    func(client *http.Client, opts ...httpc.FetchOption) error
+		Opts     []httpc.FetchOption
+		Expected string
+	}{
+		{
+			Name:     "Bytes",
+			Fetch:    fetchAs[[]byte],
+			Expected: acceptAny,
+		},
+		{
+			Name:     "ReadCloser",
+			Fetch:    fetchAs[io.ReadCloser],
+			Expected: acceptAny,
+		},
+		{
+			Name:     "String",
+			Fetch:    fetchAs[string],
+			Expected: acceptText,
+		},
+		{
+			Name:     "Struct",
+			Fetch:    fetchAs[value],
+			Expected: acceptJSON,
+		},
+		{
+			Name:     "Pointer to struct",
+			Fetch:    fetchAs[*value],
+			Expected: acceptJSON,
+		},
+		{
+			Name:     "Map",
+			Fetch:    fetchAs[map[string]any],
+			Expected: acceptJSON,
+		},
+		{
+			Name:     "Slice",
+			Fetch:    fetchAs[[]value],
+			Expected: acceptJSON,
+		},
+		{
+			Name:  "Int",
+			Fetch: fetchAs[int],
+		},
+		{
+			Name:     "Explicit header",
+			Fetch:    fetchAs[value],
+			Opts:     []httpc.FetchOption{httpc.WithHeader("Accept", "application/xml")},
+			Expected: "application/xml",
+		},
+		{
+			Name:  "Custom handler",
+			Fetch: fetchAs[value],
+			Opts:  []httpc.FetchOption{httpc.WithHandler(httpc.DiscardBodyHandler())},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			var got string
+
+			client := &http.Client{
+				Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+					got = r.Header.Get("Accept")
+
+					return &http.Response{
+						StatusCode: http.StatusNoContent,
+						Header:     make(http.Header),
+						Body:       http.NoBody,
+						Request:    r,
+					}, nil
+				}),
+			}
+
+			if err := testCase.Fetch(client, testCase.Opts...); err != nil {
+				t.Fatalf("got error %v", err)
+			}
+
+			if got != testCase.Expected {
+				t.Errorf("got Accept %q, want %q", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func fetchAs[T any](client *http.Client, opts ...httpc.FetchOption) error {
+	_, err := httpc.Fetch[T](context.Background(), http.MethodGet, "http://example.com/",
+		append([]httpc.FetchOption{httpc.WithClient(client)}, opts...)...)
+	return err
+}
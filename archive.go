@@ -0,0 +1,247 @@
+package httpc
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ErrUnsupportedArchive is returned by [ExtractArchiveHandler] for responses that are not a supported archive.
+var ErrUnsupportedArchive = errors.New("github.com/nussjustin/httpc: unsupported archive format")
+
+// ErrUnsafeArchivePath is returned by [ExtractArchiveHandler] for archives containing entries with absolute paths or
+// paths that would be extracted outside the target directory.
+var ErrUnsafeArchivePath = errors.New("github.com/nussjustin/httpc: unsafe path in archive")
+
+const (
+	// tarHeaderSize is the size of a tar header.
+	tarHeaderSize = 512
+
+	// tarMagicOffset is the offset of tarMagic in a tar header.
+	tarMagicOffset = 257
+
+	// tarMagic identifies tar headers in the POSIX and GNU formats.
+	tarMagic = "ustar"
+)
+
+// DownloadAndExtract downloads the archive at the given URL and extracts it into dir.
+//
+// The archive is extracted using [ExtractArchiveHandler]. If the response has a non-2xx status code an error
+// wrapping [ErrUnexpectedStatus] is returned.
+//
+// Any [Handler] configured via the given options is ignored.
+func DownloadAndExtract(ctx context.Context, url string, dir string, opts ...FetchOption) error {
+	_, err := Fetch[struct{}](ctx, http.MethodGet, url, slices.Concat(opts, []FetchOption{
+		WithHandlerFunc(func(dst any, resp *http.Response) (err error) {
+			if resp.StatusCode < 200 || resp.StatusCode > 299 {
+				defer discardBody(resp, &err)
+				return fmt.Errorf("%w %q", ErrUnexpectedStatus, resp.Status)
+			}
+
+			return ExtractArchiveHandler(dir)(dst, resp)
+		}),
+	})...)
+	return err
+}
+
+// ExtractArchiveHandler returns a [Handler] that extracts the response body into dir.
+//
+// The format of the archive is detected from its content. Supported formats are tar archives, gzip compressed tar
+// archives and zip archives. Responses with other content fail with [ErrUnsupportedArchive]. Tar archives are
+// extracted while they are read, while zip archives are first written to a temporary file, since the format requires
+// random access.
+//
+// dir is created if it does not exist. Existing files are overwritten. Entries with absolute paths or paths that
+// would be extracted outside dir cause the extraction to fail with [ErrUnsafeArchivePath]. Symbolic links, hard links
+// and other special files are skipped.
+//
+// Entries that were extracted before an error occurred are not removed.
+//
+// The response body will automatically be closed.
+func ExtractArchiveHandler(dir string) HandlerFunc {
+	return func(_ any, resp *http.Response) (err error) {
+		defer discardBody(resp, &err)
+
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return err
+		}
+
+		root, err := os.OpenRoot(dir)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = root.Close() }()
+
+		r := bufio.NewReader(resp.Body)
+
+		magic, _ := r.Peek(tarHeaderSize)
+
+		switch {
+		case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+			gz, err := gzip.NewReader(r)
+			if err != nil {
+				return err
+			}
+
+			return extractTar(root, tar.NewReader(gz))
+		case bytes.HasPrefix(magic, []byte("PK\x03\x04")):
+			return extractZip(root, r)
+		case bytes.HasPrefix(magic[min(len(magic), tarMagicOffset):], []byte(tarMagic)):
+			return extractTar(root, tar.NewReader(r))
+		default:
+			return ErrUnsupportedArchive
+		}
+	}
+}
+
+// extractTar extracts all regular files and directories from r into root.
+func extractTar(root *os.Root, r *tar.Reader) error {
+	for {
+		hdr, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		// Insecure paths are rejected by extractFile and mkdirAllRoot using ErrUnsafeArchivePath.
+		if err != nil && !errors.Is(err, tar.ErrInsecurePath) {
+			return err
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := mkdirAllRoot(root, hdr.Name); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := extractFile(root, hdr.Name, hdr.FileInfo().Mode(), r); err != nil {
+				return err
+			}
+		default:
+			// Links and special files are skipped.
+		}
+	}
+}
+
+// extractZip extracts all regular files and directories from the zip archive in r into root.
+func extractZip(root *os.Root, r io.Reader) error {
+	f, err := os.CreateTemp("", "httpc-archive-*")
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+
+	size, err := io.Copy(f, r)
+	if err != nil {
+		return err
+	}
+
+	// Insecure paths are rejected by extractFile and mkdirAllRoot using ErrUnsafeArchivePath.
+	zr, err := zip.NewReader(f, size)
+	if err != nil && !errors.Is(err, zip.ErrInsecurePath) {
+		return err
+	}
+
+	for _, zf := range zr.File {
+		mode := zf.Mode()
+
+		switch {
+		case mode.IsDir():
+			if err := mkdirAllRoot(root, zf.Name); err != nil {
+				return err
+			}
+		case mode.IsRegular():
+			if err := extractZipFile(root, zf); err != nil {
+				return err
+			}
+		default:
+			// Links and special files are skipped.
+		}
+	}
+
+	return nil
+}
+
+func extractZipFile(root *os.Root, zf *zip.File) error {
+	rc, err := zf.Open()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rc.Close() }()
+
+	return extractFile(root, zf.Name, zf.Mode(), rc)
+}
+
+// extractFile writes the content of r to the file with the given archive path, creating parent directories as
+// needed.
+func extractFile(root *os.Root, name string, mode fs.FileMode, r io.Reader) error {
+	name, err := archivePath(name)
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(name); dir != "." {
+		if err := mkdirAllRoot(root, dir); err != nil {
+			return err
+		}
+	}
+
+	f, err := root.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode.Perm()|0o600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(f, r); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// mkdirAllRoot creates the directory with the given archive path and all its parents inside root.
+func mkdirAllRoot(root *os.Root, name string) error {
+	name, err := archivePath(name)
+	if err != nil {
+		return err
+	}
+
+	var dir string
+
+	for part := range strings.SplitSeq(name, string(filepath.Separator)) {
+		dir = filepath.Join(dir, part)
+
+		if err := root.Mkdir(dir, 0o750); err != nil && !errors.Is(err, fs.ErrExist) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// archivePath validates the given path of an archive entry and converts it into a local file path.
+func archivePath(name string) (string, error) {
+	cleaned := path.Clean(strings.ReplaceAll(name, `\`, "/"))
+
+	if !filepath.IsLocal(filepath.FromSlash(cleaned)) {
+		return "", fmt.Errorf("%w: %q", ErrUnsafeArchivePath, name)
+	}
+
+	return filepath.FromSlash(cleaned), nil
+}
@@ -0,0 +1,239 @@
+package httpc_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nussjustin/httpc"
+)
+
+type archiveEntry struct {
+	Name    string
+	Content string
+	Dir     bool
+	Link    bool
+}
+
+func tarArchive(tb testing.TB, entries []archiveEntry) []byte {
+	tb.Helper()
+
+	var buf bytes.Buffer
+
+	w := tar.NewWriter(&buf)
+
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.Name, Mode: 0o644, Size: int64(len(e.Content)), Typeflag: tar.TypeReg}
+
+		switch {
+		case e.Dir:
+			hdr.Typeflag, hdr.Mode = tar.TypeDir, 0o755
+		case e.Link:
+			hdr.Typeflag, hdr.Linkname, hdr.Size = tar.TypeSymlink, e.Content, 0
+		}
+
+		if err := w.WriteHeader(hdr); err != nil {
+			tb.Fatal(err)
+		}
+
+		if hdr.Typeflag == tar.TypeReg {
+			if _, err := w.Write([]byte(e.Content)); err != nil {
+				tb.Fatal(err)
+			}
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		tb.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func gzipArchive(tb testing.TB, data []byte) []byte {
+	tb.Helper()
+
+	var buf bytes.Buffer
+
+	w := gzip.NewWriter(&buf)
+
+	if _, err := w.Write(data); err != nil {
+		tb.Fatal(err)
+	}
+
+	if err := w.Close(); err != nil {
+		tb.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func zipArchive(tb testing.TB, entries []archiveEntry) []byte {
+	tb.Helper()
+
+	var buf bytes.Buffer
+
+	w := zip.NewWriter(&buf)
+
+	for _, e := range entries {
+		name := e.Name
+		if e.Dir {
+			name += "/"
+		}
+
+		f, err := w.Create(name)
+		if err != nil {
+			tb.Fatal(err)
+		}
+
+		if _, err := f.Write([]byte(e.Content)); err != nil {
+			tb.Fatal(err)
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		tb.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+// readTree returns the content of all files below dir by their slash separated relative path.
+func readTree(tb testing.TB, dir string) map[string]string {
+	tb.Helper()
+
+	files := make(map[string]string)
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		rel, _ := filepath.Rel(dir, path)
+		files[filepath.ToSlash(rel)] = string(b)
+		return nil
+	})
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return files
+}
+
+func TestDownloadAndExtract(t *testing.T) {
+	entries := []archiveEntry{
+		{Name: "release", Dir: true},
+		{Name: "release/bin/tool", Content: "binary"},
+		{Name: "./README.md", Content: "readme"},
+		{Name: "release/link", Content: "../../etc/passwd", Link: true},
+	}
+
+	zipEntries := []archiveEntry{
+		{Name: "release", Dir: true},
+		{Name: "release/bin/tool", Content: "binary"},
+		{Name: "README.md", Content: "readme"},
+	}
+
+	testCases := []struct {
+		Name string
+		Body []byte
+	}{
+		{Name: "Tar", Body: tarArchive(t, entries)},
+		{Name: "Tar gzip", Body: gzipArchive(t, tarArchive(t, entries))},
+		{Name: "Zip", Body: zipArchive(t, zipEntries)},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/octet-stream")
+				_, _ = w.Write(testCase.Body)
+			}))
+			t.Cleanup(srv.Close)
+
+			dir := filepath.Join(t.TempDir(), "out")
+
+			if err := httpc.DownloadAndExtract(t.Context(), srv.URL, dir); err != nil {
+				t.Fatalf("got error %v", err)
+			}
+
+			want := map[string]string{
+				"README.md":        "readme",
+				"release/bin/tool": "binary",
+			}
+
+			if diff := cmp.Diff(want, readTree(t, dir)); diff != "" {
+				t.Errorf("files mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDownloadAndExtract_Errors(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Status   int
+		Body     []byte
+		Expected error
+	}{
+		{
+			Name:     "Path traversal",
+			Status:   http.StatusOK,
+			Body:     tarArchive(t, []archiveEntry{{Name: "../evil", Content: "evil"}}),
+			Expected: httpc.ErrUnsafeArchivePath,
+		},
+		{
+			Name:     "Absolute path",
+			Status:   http.StatusOK,
+			Body:     zipArchive(t, []archiveEntry{{Name: "/evil", Content: "evil"}}),
+			Expected: httpc.ErrUnsafeArchivePath,
+		},
+		{
+			Name:     "Unsupported",
+			Status:   http.StatusOK,
+			Body:     []byte("not an archive"),
+			Expected: httpc.ErrUnsupportedArchive,
+		},
+		{
+			Name:     "Status",
+			Status:   http.StatusNotFound,
+			Expected: httpc.ErrUnexpectedStatus,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(testCase.Status)
+				_, _ = w.Write(testCase.Body)
+			}))
+			t.Cleanup(srv.Close)
+
+			parent := t.TempDir()
+
+			err := httpc.DownloadAndExtract(t.Context(), srv.URL, filepath.Join(parent, "out"))
+			if !errors.Is(err, testCase.Expected) {
+				t.Errorf("got error %v, want %v", err, testCase.Expected)
+			}
+
+			if _, err := os.Stat(filepath.Join(parent, "evil")); err == nil {
+				t.Error("file extracted outside of target directory")
+			}
+		})
+	}
+}
@@ -0,0 +1,316 @@
+package httpc
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// KeyLocation specifies where an API key is placed in a request.
+type KeyLocation int
+
+const (
+	// KeyInHeader places the API key in a request header.
+	KeyInHeader KeyLocation = iota + 1
+
+	// KeyInQuery places the API key in a query parameter.
+	KeyInQuery
+
+	// KeyInCookie places the API key in a cookie.
+	KeyInCookie
+)
+
+// WithAPIKey adds the given API key to the request, using the given location and name.
+//
+// Existing headers and query parameters with the same name are replaced.
+func WithAPIKey(key string, in KeyLocation, name string) FetchOption {
+	switch in {
+	case KeyInHeader, KeyInQuery, KeyInCookie:
+	default:
+		panic(fmt.Errorf("unknown key location %d", in))
+	}
+
+	return func(ctx *fetchContext) error {
+		switch in {
+		case KeyInHeader:
+			ctx.Request.Header.Set(name, key)
+		case KeyInQuery:
+			addQueryParam(ctx.Request.URL, name, key, true)
+		case KeyInCookie:
+			ctx.Request.AddCookie(&http.Cookie{Name: name, Value: key})
+		}
+
+		return nil
+	}
+}
+
+// Challenge is a single authentication challenge from a WWW-Authenticate or Proxy-Authenticate header.
+type Challenge struct {
+	// Scheme is the authentication scheme, like "Basic" or "Digest".
+	Scheme string
+
+	// Token68 contains the token68 value of the challenge, if any.
+	Token68 string
+
+	// Params contains the parameters of the challenge, keyed by lower case name.
+	Params map[string]string
+}
+
+// parseChallenges parses all challenges in the given header values as defined by RFC 9110, Section 11.6.1.
+//
+// Malformed parts are skipped.
+func parseChallenges(values []string) []Challenge {
+	var challenges []Challenge
+
+	for _, s := range values {
+		p := challengeParser{s: s}
+
+		for {
+			p.skip(", \t")
+
+			scheme := p.token()
+			if scheme == "" {
+				if p.i >= len(p.s) {
+					break
+				}
+
+				// Skip invalid characters
+				p.i++
+				continue
+			}
+
+			c := Challenge{Scheme: scheme, Params: make(map[string]string)}
+			p.params(&c)
+
+			challenges = append(challenges, c)
+		}
+	}
+
+	return challenges
+}
+
+type challengeParser struct {
+	s string
+	i int
+}
+
+func (p *challengeParser) skip(chars string) {
+	for p.i < len(p.s) && strings.IndexByte(chars, p.s[p.i]) >= 0 {
+		p.i++
+	}
+}
+
+func (p *challengeParser) peek() byte {
+	if p.i >= len(p.s) {
+		return 0
+	}
+	return p.s[p.i]
+}
+
+func (p *challengeParser) token() string {
+	start := p.i
+	for p.i < len(p.s) && isTokenChar(p.s[p.i]) {
+		p.i++
+	}
+	return p.s[start:p.i]
+}
+
+func (p *challengeParser) quoted() string {
+	var b strings.Builder
+
+	// Skip opening quote
+	p.i++
+
+	for p.i < len(p.s) {
+		c := p.s[p.i]
+		p.i++
+
+		switch {
+		case c == '"':
+			return b.String()
+		case c == '\\' && p.i < len(p.s):
+			b.WriteByte(p.s[p.i])
+			p.i++
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String()
+}
+
+// params parses the token68 or parameters following the scheme of a challenge.
+func (p *challengeParser) params(c *Challenge) {
+	p.skip(" \t")
+
+	start := p.i
+
+	for p.i < len(p.s) && isToken68Char(p.s[p.i]) {
+		p.i++
+	}
+
+	if p.i > start {
+		p.skip("=")
+		end := p.i
+		p.skip(" \t")
+
+		// A token68 value is the only value of a challenge.
+		if next := p.peek(); next == ',' || next == 0 {
+			c.Token68 = p.s[start:end]
+			return
+		}
+	}
+
+	p.i = start
+
+	for {
+		p.skip(", \t")
+
+		start := p.i
+
+		name := p.token()
+		if name == "" {
+			return
+		}
+
+		p.skip(" \t")
+
+		if p.peek() != '=' {
+			// Start of the next challenge
+			p.i = start
+			return
+		}
+
+		p.i++
+		p.skip(" \t")
+
+		var value string
+		if p.peek() == '"' {
+			value = p.quoted()
+		} else {
+			value = p.token()
+		}
+
+		c.Params[strings.ToLower(name)] = value
+	}
+}
+
+// isTokenChar reports whether c is a valid character in a token as defined by RFC 9110, Section 5.6.2.
+func isTokenChar(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	default:
+		return strings.IndexByte("!#$%&'*+-.^_`|~", c) >= 0
+	}
+}
+
+// isToken68Char reports whether c is a valid character in a token68 as defined by RFC 9110, Section 11.2, excluding
+// the trailing "=" padding.
+func isToken68Char(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	default:
+		return strings.IndexByte("-._~+/", c) >= 0
+	}
+}
+
+// Challenger answers authentication challenges sent by servers.
+type Challenger interface {
+	// Challenge is called when a request was answered with 401 Unauthorized.
+	//
+	// The given challenges are parsed from the WWW-Authenticate headers of the response. If the challenges can be
+	// answered, the returned value is used as Authorization header for sending the request again. If the returned
+	// value is empty, the 401 response is returned as is.
+	Challenge(req *http.Request, challenges []Challenge) (authorization string, err error)
+}
+
+// ChallengerFunc implements the [Challenger] interface using a function.
+type ChallengerFunc func(req *http.Request, challenges []Challenge) (authorization string, err error)
+
+// Challenge implements the [Challenger] interface.
+func (f ChallengerFunc) Challenge(req *http.Request, challenges []Challenge) (string, error) {
+	return f(req, challenges)
+}
+
+// WithChallenger causes requests answered with 401 Unauthorized to be sent again once, using credentials obtained from
+// the given [Challenger].
+//
+// This can be used to implement authentication schemes like Negotiate or flows that need to refresh credentials.
+//
+// Requests with a body can only be sent again if [http.Request.GetBody] is set. Otherwise, the 401 response is
+// returned as is.
+func WithChallenger(c Challenger) FetchOption {
+	return func(ctx *fetchContext) error {
+		ctx.Challenger = c
+		return nil
+	}
+}
+
+// applyChallenger replaces the client with one that answers challenges using the configured [Challenger], if any.
+func (ctx *fetchContext) applyChallenger() {
+	if ctx.Challenger == nil || ctx.PresignedURL {
+		return
+	}
+
+	ctx.wrapTransport(func(rt http.RoundTripper) http.RoundTripper {
+		return &challengeTransport{next: rt, challenger: ctx.Challenger}
+	})
+}
+
+type challengeTransport struct {
+	next       http.RoundTripper
+	challenger Challenger
+}
+
+func (t *challengeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	authorization, err := t.challenger.Challenge(req, parseChallenges(resp.Header.Values("WWW-Authenticate")))
+	if err != nil {
+		discardBody(resp, nil)
+		return nil, err
+	}
+
+	if authorization == "" {
+		return resp, nil
+	}
+
+	retry, ok := rewindRequest(req)
+	if !ok {
+		return resp, nil
+	}
+
+	discardBody(resp, nil)
+
+	retry.Header.Set("Authorization", authorization)
+
+	return t.next.RoundTrip(retry)
+}
+
+// rewindRequest returns a copy of req that can be sent again, using [http.Request.GetBody] to get a new body.
+//
+// If req has a body, but no GetBody function, false is returned.
+func rewindRequest(req *http.Request) (*http.Request, bool) {
+	retry := req.Clone(req.Context())
+
+	if req.Body == nil || req.Body == http.NoBody {
+		return retry, true
+	}
+
+	if req.GetBody == nil {
+		return nil, false
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, false
+	}
+
+	retry.Body = body
+	return retry, true
+}
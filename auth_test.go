@@ -0,0 +1,151 @@
+package httpc_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nussjustin/httpc"
+)
+
+func TestWithAPIKey(t *testing.T) {
+	t.Run("Header", func(t *testing.T) {
+		req := captureRequest(t, "/", httpc.WithAPIKey("secret", httpc.KeyInHeader, "X-API-Key"))
+
+		if got, want := req.Header.Get("X-API-Key"), "secret"; got != want {
+			t.Errorf("got header %q, want %q", got, want)
+		}
+	})
+
+	t.Run("Query", func(t *testing.T) {
+		req := captureRequest(t, "/?a=1&api_key=old", httpc.WithAPIKey("secret", httpc.KeyInQuery, "api_key"))
+
+		if got, want := req.URL.RawQuery, "a=1&api_key=secret"; got != want {
+			t.Errorf("got query %q, want %q", got, want)
+		}
+	})
+
+	t.Run("Cookie", func(t *testing.T) {
+		req := captureRequest(t, "/", httpc.WithAPIKey("secret", httpc.KeyInCookie, "session"))
+
+		cookie, err := req.Cookie("session")
+		if err != nil {
+			t.Fatalf("failed to get cookie: %v", err)
+		}
+
+		if got, want := cookie.Value, "secret"; got != want {
+			t.Errorf("got cookie %q, want %q", got, want)
+		}
+	})
+
+	t.Run("Unknown location", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected panic")
+			}
+		}()
+
+		httpc.WithAPIKey("secret", 0, "key")
+	})
+}
+
+func TestWithChallenger(t *testing.T) {
+	var requests int
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		if r.Header.Get("Authorization") != "Bearer valid" {
+			w.Header().Add("WWW-Authenticate", `Negotiate abc==, Basic realm="basic"`)
+			w.Header().Add("WWW-Authenticate", `Bearer realm="api", error="invalid_token", `+
+				`error_description="The \"token\" expired"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+
+	errChallenge := errors.New("challenge error")
+
+	testCases := []struct {
+		Name             string
+		Authorization    string
+		Error            error
+		ExpectedError    error
+		ExpectedRequests int
+	}{
+		{
+			Name:             "Answered",
+			Authorization:    "Bearer valid",
+			ExpectedRequests: 2,
+		},
+		{
+			Name:             "Rejected",
+			Authorization:    "Bearer invalid",
+			ExpectedError:    httpc.ErrUnhandledResponse,
+			ExpectedRequests: 2,
+		},
+		{
+			Name:             "Not answered",
+			ExpectedError:    httpc.ErrUnhandledResponse,
+			ExpectedRequests: 1,
+		},
+		{
+			Name:             "Error",
+			Error:            errChallenge,
+			ExpectedError:    errChallenge,
+			ExpectedRequests: 1,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			requests = 0
+
+			var gotChallenges []httpc.Challenge
+
+			challenger := httpc.ChallengerFunc(func(_ *http.Request, challenges []httpc.Challenge) (string, error) {
+				gotChallenges = challenges
+				return testCase.Authorization, testCase.Error
+			})
+
+			got, err := httpc.Fetch[string](t.Context(), http.MethodPost, srv.URL,
+				httpc.WithBody(strings.NewReader("body")),
+				httpc.WithHandler(httpc.StatusHandler(http.StatusOK, httpc.UnmarshalTextHandler())),
+				httpc.WithChallenger(challenger))
+			if !errors.Is(err, testCase.ExpectedError) {
+				t.Fatalf("got error %v, want %v", err, testCase.ExpectedError)
+			}
+
+			if err == nil && got != "body" {
+				t.Errorf("got %q, want %q", got, "body")
+			}
+
+			if requests != testCase.ExpectedRequests {
+				t.Errorf("got %d requests, want %d", requests, testCase.ExpectedRequests)
+			}
+
+			wantChallenges := []httpc.Challenge{
+				{Scheme: "Negotiate", Token68: "abc==", Params: map[string]string{}},
+				{Scheme: "Basic", Params: map[string]string{"realm": "basic"}},
+				{Scheme: "Bearer", Params: map[string]string{
+					"realm":             "api",
+					"error":             "invalid_token",
+					"error_description": `The "token" expired`,
+				}},
+			}
+
+			if diff := cmp.Diff(wantChallenges, gotChallenges); diff != "" {
+				t.Errorf("challenges mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package httpc
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// WithBandwidthLimit limits the bandwidth used for request and response bodies to bytesPerSec bytes per second in
+// each direction.
+//
+// The limit is shared by all requests using the returned option. The option should be created once and reused, for
+// example by passing it to [New], so that the limit applies to all requests made using a [Client].
+//
+// See [WithBandwidthLimits] for using separate limits for uploads and downloads.
+func WithBandwidthLimit(bytesPerSec int64) FetchOption {
+	return WithBandwidthLimits(bytesPerSec, bytesPerSec)
+}
+
+// WithBandwidthLimits limits the bandwidth used for request bodies to up bytes per second and the bandwidth used for
+// response bodies to down bytes per second. A limit of 0 disables the limit for the direction.
+//
+// Limits are enforced using a token bucket that allows bursts of up to one second worth of data. Reading or writing
+// a body blocks until enough bandwidth is available or the request context is canceled.
+//
+// The limits are shared by all requests using the returned option. The option should be created once and reused, for
+// example by passing it to [New], so that the limits apply to all requests made using a [Client].
+func WithBandwidthLimits(up, down int64) FetchOption {
+	if up < 0 || down < 0 {
+		panic(errors.New("limits must not be negative"))
+	}
+
+	upload, download := newBandwidthLimiter(up), newBandwidthLimiter(down)
+
+	return func(ctx *fetchContext) error {
+		ctx.UploadLimiter, ctx.DownloadLimiter = upload, download
+		return nil
+	}
+}
+
+// limitRequestBandwidth wraps the request body so that reads are limited by UploadLimiter.
+func (ctx *fetchContext) limitRequestBandwidth() {
+	if ctx.Request.Body == nil || ctx.Request.Body == http.NoBody {
+		return
+	}
+
+	req, limiter := ctx.Request, ctx.UploadLimiter
+
+	ctx.Request.Body = &limitedBody{ReadCloser: ctx.Request.Body, req: req, limiter: limiter}
+
+	if getBody := ctx.Request.GetBody; getBody != nil {
+		ctx.Request.GetBody = func() (io.ReadCloser, error) {
+			body, err := getBody()
+			if err != nil {
+				return nil, err
+			}
+			return &limitedBody{ReadCloser: body, req: req, limiter: limiter}, nil
+		}
+	}
+}
+
+// limitResponseBandwidth wraps the response body so that reads are limited by DownloadLimiter.
+func (ctx *fetchContext) limitResponseBandwidth(resp *http.Response) {
+	resp.Body = &limitedBody{ReadCloser: resp.Body, req: ctx.Request, limiter: ctx.DownloadLimiter}
+}
+
+// bandwidthLimiter implements a token bucket where each token allows transferring a single byte.
+type bandwidthLimiter struct {
+	rate float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// newBandwidthLimiter returns a limiter for the given number of bytes per second or nil, if bytesPerSec is 0.
+func newBandwidthLimiter(bytesPerSec int64) *bandwidthLimiter {
+	if bytesPerSec == 0 {
+		return nil
+	}
+
+	return &bandwidthLimiter{rate: float64(bytesPerSec), tokens: float64(bytesPerSec), last: time.Now()}
+}
+
+// burst returns the maximum number of bytes that can be transferred at once.
+func (l *bandwidthLimiter) burst() int {
+	return max(int(l.rate), 1)
+}
+
+// reserve takes n tokens from the bucket and returns how long the caller must wait before using them.
+func (l *bandwidthLimiter) reserve(n int) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+
+	l.tokens = min(l.rate, l.tokens+now.Sub(l.last).Seconds()*l.rate) - float64(n)
+	l.last = now
+
+	if l.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// limitedBody is a request or response body whose reads are limited by a [bandwidthLimiter].
+type limitedBody struct {
+	io.ReadCloser
+
+	// req is used to stop waiting once the request context is canceled.
+	req     *http.Request
+	limiter *bandwidthLimiter
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if len(p) > b.limiter.burst() {
+		p = p[:b.limiter.burst()]
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	if n == 0 {
+		return n, err
+	}
+
+	if d := b.limiter.reserve(n); d > 0 {
+		if sErr := sleep(b.req, d); sErr != nil {
+			return n, sErr
+		}
+	}
+
+	return n, err
+}
@@ -0,0 +1,81 @@
+package httpc_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/nussjustin/httpc"
+)
+
+func TestWithBandwidthLimit(t *testing.T) {
+	const size = 150_000
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := io.Copy(io.Discard, r.Body)
+
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("X-Received", strconv.FormatInt(n, 10))
+		_, _ = w.Write(bytes.Repeat([]byte("x"), size))
+	}))
+	t.Cleanup(srv.Close)
+
+	testCases := []struct {
+		Name   string
+		Option httpc.FetchOption
+		Body   []byte
+	}{
+		{
+			Name:   "Download",
+			Option: httpc.WithBandwidthLimits(0, 100_000),
+		},
+		{
+			Name:   "Upload",
+			Option: httpc.WithBandwidthLimits(100_000, 0),
+			Body:   bytes.Repeat([]byte("x"), size),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			start := time.Now()
+
+			got, resp, err := httpc.FetchWithResponse[[]byte](t.Context(), http.MethodPost, srv.URL,
+				httpc.WithBody(bytes.NewReader(testCase.Body)),
+				testCase.Option)
+			if err != nil {
+				t.Fatalf("got error %v", err)
+			}
+
+			if got, want := len(got), size; got != want {
+				t.Errorf("got %d bytes, want %d", got, want)
+			}
+
+			if got, want := resp.Header.Get("X-Received"), strconv.Itoa(len(testCase.Body)); got != want {
+				t.Errorf("server received %s bytes, want %s", got, want)
+			}
+
+			// The first 100000 bytes are allowed as burst, the rest takes about half a second.
+			if took := time.Since(start); took < 400*time.Millisecond {
+				t.Errorf("request took %s, want at least 400ms", took)
+			}
+		})
+	}
+
+	t.Run("Canceled", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := httpc.Fetch[[]byte](ctx, http.MethodGet, srv.URL,
+			httpc.WithBandwidthLimit(10_000))
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("got error %v, want %v", err, context.DeadlineExceeded)
+		}
+	})
+}
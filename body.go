@@ -0,0 +1,101 @@
+package httpc
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+)
+
+// ErrBodyNotClosed is reported by [WithBodyOwnershipCheck] when a [Handler] returned without closing the response body
+// and without passing it on to the destination.
+var ErrBodyNotClosed = errors.New("github.com/nussjustin/httpc: response body not closed by handler")
+
+// ErrBodyClosedTwice is reported by [WithBodyOwnershipCheck] when the response body was closed more than once.
+var ErrBodyClosedTwice = errors.New("github.com/nussjustin/httpc: response body closed twice")
+
+// WithBodyOwnershipCheck enables checks that verify that handlers follow the body ownership contract.
+//
+// A [Handler] that successfully handles a response owns the body and must either close it or pass it on to the
+// destination, as done by [ReadCloserHandler]. Handlers that return without doing either leak the body, which is
+// reported using [ErrBodyNotClosed]. Closing the body more than once is reported using [ErrBodyClosedTwice].
+//
+// The report function is called with an error wrapping one of these errors. It may be called after the request
+// completed, for example when the caller closes the body twice.
+//
+// This is intended for debugging and tests, for example by passing a function that calls [testing.T.Error].
+func WithBodyOwnershipCheck(report func(err error)) FetchOption {
+	return func(ctx *fetchContext) error {
+		ctx.BodyOwnershipCheck = report
+		return nil
+	}
+}
+
+// ownBody wraps the response body, so that closing it can be tracked.
+func (ctx *fetchContext) ownBody(resp *http.Response) *ownedBody {
+	b := &ownedBody{ReadCloser: resp.Body, resp: resp}
+
+	if ctx.BodyOwnershipCheck != nil {
+		b.report, b.redactor = ctx.BodyOwnershipCheck, ctx.redactor()
+	}
+
+	resp.Body = b
+
+	if s, ok := b.ReadCloser.(*spooledBody); ok {
+		resp.Body = &ownedSpooledBody{ownedBody: b, spooled: s}
+	}
+
+	return b
+}
+
+// checkBodyOwnership reports an error if the handler neither closed the body nor passed it on to dst.
+func (ctx *fetchContext) checkBodyOwnership(b *ownedBody, dst any) {
+	if ctx.BodyOwnershipCheck == nil || b.isClosed() {
+		return
+	}
+
+	if rc, ok := dst.(*io.ReadCloser); ok && *rc != nil {
+		return
+	}
+
+	ctx.BodyOwnershipCheck(b.error(ErrBodyNotClosed))
+}
+
+// ownedBody tracks whether a response body was closed.
+//
+// Only the first call to Close closes the underlying body. Later calls return nil.
+type ownedBody struct {
+	io.ReadCloser
+
+	resp     *http.Response
+	report   func(err error)
+	redactor *Redactor
+	closed   atomic.Int32
+}
+
+func (b *ownedBody) Close() error {
+	if b.closed.Add(1) > 1 {
+		if b.report != nil {
+			b.report(b.error(ErrBodyClosedTwice))
+		}
+
+		return nil
+	}
+
+	return b.ReadCloser.Close()
+}
+
+// isClosed reports whether the body was already closed.
+func (b *ownedBody) isClosed() bool {
+	return b.closed.Load() > 0
+}
+
+// error returns an error wrapping err that includes the request method and redacted URL.
+func (b *ownedBody) error(err error) error {
+	if req := b.resp.Request; req != nil && req.URL != nil {
+		return fmt.Errorf("%w: %s %s", err, req.Method, b.redactor.URL(req.URL))
+	}
+
+	return err
+}
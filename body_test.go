@@ -0,0 +1,145 @@
+package httpc_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/nussjustin/httpc"
+)
+
+type trackingBody struct {
+	io.Reader
+
+	closes          int
+	readsAfterClose int
+	closed          bool
+}
+
+func (b *trackingBody) Read(p []byte) (int, error) {
+	if b.closed {
+		b.readsAfterClose++
+		return 0, errors.New("read on closed body")
+	}
+	return b.Reader.Read(p)
+}
+
+func (b *trackingBody) Close() error {
+	b.closes++
+	b.closed = true
+	return nil
+}
+
+func TestWithBodyOwnershipCheck(t *testing.T) {
+	testCases := []struct {
+		Name    string
+		Handler httpc.Handler
+		Want    error
+	}{
+		{
+			Name: "Closed",
+			Handler: httpc.HandlerFunc(func(_ any, resp *http.Response) error {
+				return resp.Body.Close()
+			}),
+		},
+		{
+			Name: "Not closed",
+			Handler: httpc.HandlerFunc(func(any, *http.Response) error {
+				return nil
+			}),
+			Want: httpc.ErrBodyNotClosed,
+		},
+		{
+			Name: "Closed twice",
+			Handler: httpc.HandlerFunc(func(_ any, resp *http.Response) error {
+				_ = resp.Body.Close()
+				return resp.Body.Close()
+			}),
+			Want: httpc.ErrBodyClosedTwice,
+		},
+		{
+			Name:    "Sniffed and limited",
+			Handler: httpc.LimitBody(1024)(httpc.SniffHandler()),
+		},
+		{
+			Name:    "Default handlers",
+			Handler: httpc.DefaultHandlers,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			body := &trackingBody{Reader: strings.NewReader(`{"name":"value"}`)}
+
+			client := &http.Client{
+				Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       body,
+						Request:    req,
+					}, nil
+				}),
+			}
+
+			var got []error
+
+			_, err := httpc.Fetch[map[string]string](t.Context(), http.MethodGet, "https://example.com/",
+				httpc.WithClient(client),
+				httpc.WithHandler(testCase.Handler),
+				httpc.WithBodyOwnershipCheck(func(err error) {
+					got = append(got, err)
+				}))
+			if err != nil {
+				t.Fatalf("got error %v", err)
+			}
+
+			switch {
+			case testCase.Want == nil && len(got) > 0:
+				t.Errorf("got reports %v, want none", got)
+			case testCase.Want != nil && (len(got) != 1 || !errors.Is(got[0], testCase.Want)):
+				t.Errorf("got reports %v, want %v", got, testCase.Want)
+			}
+
+			if body.closes != 1 {
+				t.Errorf("got %d closes of underlying body, want 1", body.closes)
+			}
+
+			if body.readsAfterClose != 0 {
+				t.Errorf("got %d reads after close, want 0", body.readsAfterClose)
+			}
+		})
+	}
+}
+
+func TestWithBodyOwnershipCheck_ReadCloser(t *testing.T) {
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader("body")),
+				Request:    req,
+			}, nil
+		}),
+	}
+
+	var got []error
+
+	check := httpc.WithBodyOwnershipCheck(func(err error) {
+		got = append(got, err)
+	})
+
+	body, err := httpc.Fetch[io.ReadCloser](t.Context(), http.MethodGet, "https://example.com/",
+		httpc.WithClient(client), check)
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	_ = body.Close()
+	_ = body.Close()
+
+	if len(got) != 1 || !errors.Is(got[0], httpc.ErrBodyClosedTwice) {
+		t.Errorf("got reports %v, want %v", got, httpc.ErrBodyClosedTwice)
+	}
+}
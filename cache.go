@@ -0,0 +1,884 @@
+package httpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/textproto"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrOffline is returned when a request can not be answered from the cache in offline mode.
+//
+// See [WithOfflineMode].
+var ErrOffline = errors.New("github.com/nussjustin/httpc: offline and no cached response")
+
+// Cache stores serialized HTTP responses for use with [WithCache].
+//
+// Caches are best effort. Entries may be evicted at any time and errors are not reported.
+//
+// Implementations must be safe for concurrent use by multiple goroutines.
+type Cache interface {
+	// Get returns the entry for the given key, if any.
+	Get(key string) ([]byte, bool)
+
+	// Set stores an entry for the given key, replacing any existing entry.
+	Set(key string, value []byte)
+
+	// Delete removes the entry for the given key, if any.
+	Delete(key string)
+}
+
+// CacheWriter is implemented by caches that can store entries while they are written, without keeping the whole entry
+// in memory.
+//
+// If the [Cache] passed to [WithCache] implements CacheWriter, response bodies are written to the cache while they are
+// read, for example by a [Handler], so that large responses are not buffered in memory before they are stored.
+type CacheWriter interface {
+	// NewWriter returns a writer for a new entry for the given key.
+	//
+	// Once the entry was written completely, Commit is called to store the entry, replacing any existing entry for
+	// the key. Otherwise, Abort is called to discard the entry.
+	//
+	// If NewWriter returns an error, the entry is buffered and stored using Set instead.
+	NewWriter(key string) (BodyWriter, error)
+}
+
+// MemoryCache is a [Cache] that stores entries in memory.
+//
+// MemoryCache has no size limit.
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[string][]byte
+}
+
+var _ Cache = (*MemoryCache)(nil)
+
+// NewMemoryCache returns a new, empty [MemoryCache].
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: make(map[string][]byte)}
+}
+
+// Get implements the [Cache] interface.
+func (m *MemoryCache) Get(key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	value, ok := m.entries[key]
+	return value, ok
+}
+
+// Set implements the [Cache] interface.
+func (m *MemoryCache) Set(key string, value []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries[key] = value
+}
+
+// Delete implements the [Cache] interface.
+func (m *MemoryCache) Delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.entries, key)
+}
+
+// WithCache caches responses to GET requests in the given [Cache], following the rules for private caches from
+// RFC 9111.
+//
+// Fresh responses are returned from the cache without sending a request. Stale responses with an ETag or Last-Modified
+// header are revalidated using a conditional request and returned from the cache, if the server responds with
+// 304 Not Modified. Requests that already contain conditional headers are never answered from the cache.
+//
+// Responses are stored per URL. URLs that only differ in the order of their query parameters share a response.
+//
+// Responses are stored once their body was read completely. Responses whose body is closed early are not stored. If
+// the cache implements [CacheWriter], the body is written to the cache while it is read instead of being buffered.
+//
+// Successful requests using other methods, like POST, PUT, PATCH or DELETE, remove the cached response for their URL
+// as well as for the URLs in the Location and Content-Location headers of the response, if these have the same origin.
+//
+// The cache is used for each request, including redirects.
+func WithCache(c Cache) FetchOption {
+	return func(ctx *fetchContext) error {
+		ctx.Cache = c
+		return nil
+	}
+}
+
+// WithOfflineMode causes all requests to be answered from the cache configured via [WithCache], without sending any
+// request.
+//
+// Cached responses are returned even if they are stale. If there is no cached response, for example because the
+// request does not use the GET method or no cache is configured, the request fails with [ErrOffline].
+//
+// This can be used for example by command line tools to work with previously fetched data while disconnected.
+func WithOfflineMode() FetchOption {
+	return func(ctx *fetchContext) error {
+		ctx.Offline = true
+		return nil
+	}
+}
+
+// applyCache replaces the client with one that uses the configured cache, if any.
+func (ctx *fetchContext) applyCache() {
+	if ctx.Cache == nil && !ctx.Offline {
+		return
+	}
+
+	ctx.wrapTransport(func(rt http.RoundTripper) http.RoundTripper {
+		return &cacheTransport{
+			next:    rt,
+			cache:   ctx.Cache,
+			offline: ctx.Offline,
+			misses:  ctx.CacheMisses,
+			hook:    ctx.CacheHook,
+		}
+	})
+}
+
+// CacheStatus describes how a request was handled by the cache.
+type CacheStatus int
+
+const (
+	// CacheHit means that a fresh cached response was returned without sending a request.
+	CacheHit CacheStatus = iota + 1
+
+	// CacheRevalidated means that a stale cached response was returned after the server responded to a conditional
+	// request with 304 Not Modified.
+	CacheRevalidated
+
+	// CacheStale means that a stale cached response was returned without revalidation, for example in offline mode.
+	CacheStale
+
+	// CacheMiss means that the response was fetched from the server and will be stored once its body was read.
+	CacheMiss
+
+	// CacheUncacheable means that the response was fetched from the server, but can not be stored.
+	CacheUncacheable
+
+	// CacheBypass means that the cache was not used for the request, for example because the request was already
+	// conditional.
+	CacheBypass
+)
+
+// CacheEvent describes how a single request was handled by the cache.
+//
+// See [WithCacheHook].
+type CacheEvent struct {
+	// Request is the request handled by the cache.
+	Request *http.Request
+
+	// Status describes how the request was handled.
+	Status CacheStatus
+
+	// Reason is a short, human-readable explanation for the status, for example "no-store" or "modified".
+	Reason string
+}
+
+// WithCacheHook calls the given function each time a GET request is handled by the cache configured via [WithCache].
+//
+// The function is called synchronously with the request and must not block. It can be used to track the effectiveness
+// of the cache, for example by counting hits and revalidations, or to detect servers whose responses can never be
+// cached, by looking for events with status [CacheUncacheable].
+func WithCacheHook(hook func(CacheEvent)) FetchOption {
+	return func(ctx *fetchContext) error {
+		ctx.CacheHook = hook
+		return nil
+	}
+}
+
+// cacheKey returns the key used to store responses for requests with the given method and URL.
+//
+// Query parameters are sorted by name, so that URLs that only differ in the order of their query parameters use the
+// same key.
+func cacheKey(method string, u *url.URL) string {
+	c := *u
+	c.Fragment, c.RawFragment = "", ""
+	c.RawQuery = sortQuery(c.RawQuery)
+
+	return method + " " + c.String()
+}
+
+// sortQuery sorts the parameters in the given raw query by name.
+//
+// The order of parameters with the same name is kept, since it may be significant.
+func sortQuery(rawQuery string) string {
+	if !strings.Contains(rawQuery, "&") {
+		return rawQuery
+	}
+
+	params := strings.Split(rawQuery, "&")
+
+	slices.SortStableFunc(params, func(a, b string) int {
+		nameA, _, _ := strings.Cut(a, "=")
+		nameB, _, _ := strings.Cut(b, "=")
+
+		return strings.Compare(nameA, nameB)
+	})
+
+	return strings.Join(params, "&")
+}
+
+type cacheTransport struct {
+	next    http.RoundTripper
+	cache   Cache
+	offline bool
+	misses  *missGroup
+	hook    func(CacheEvent)
+}
+
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.offline {
+		closeRequestBody(req)
+
+		if entry, ok := t.lookup(req); ok {
+			now := time.Now()
+
+			if entry.fresh(now) {
+				t.report(req, CacheHit, "fresh")
+			} else {
+				t.report(req, CacheStale, "offline")
+			}
+
+			return entry.response(req, now), nil
+		}
+
+		return nil, ErrOffline
+	}
+
+	switch req.Method {
+	case http.MethodGet:
+	case http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return t.next.RoundTrip(req)
+	default:
+		resp, err := t.next.RoundTrip(req)
+		if err == nil {
+			t.invalidate(req, resp)
+		}
+		return resp, err
+	}
+
+	if hasCacheDirective(req.Header, "no-store") {
+		t.report(req, CacheBypass, "no-store")
+		return t.next.RoundTrip(req)
+	}
+
+	// Requests that are already conditional are handled by the caller.
+	if req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != "" {
+		t.report(req, CacheBypass, "conditional request")
+		return t.next.RoundTrip(req)
+	}
+
+	entry, ok := t.lookup(req)
+
+	var release func()
+
+	if !ok && t.misses != nil {
+		var waited bool
+
+		if release, waited = t.misses.join(req.Context(), cacheKey(req.Method, req.URL)); waited {
+			entry, ok = t.lookup(req)
+		}
+	}
+
+	// Other requests waiting for the same key are released once the response was stored or can not be stored.
+	defer func() {
+		if release != nil {
+			release()
+		}
+	}()
+
+	now := time.Now()
+
+	if ok && !hasCacheDirective(req.Header, "no-cache") && entry.fresh(now) {
+		t.report(req, CacheHit, "fresh")
+		return entry.response(req, now), nil
+	}
+
+	outReq := req
+
+	if ok && entry.hasValidators() {
+		outReq = req.Clone(req.Context())
+
+		if etag := entry.resp.Header.Get("ETag"); etag != "" {
+			outReq.Header.Set("If-None-Match", etag)
+		}
+
+		if lastModified := entry.resp.Header.Get("Last-Modified"); lastModified != "" {
+			outReq.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+
+	requestTime := time.Now()
+
+	resp, err := t.next.RoundTrip(outReq)
+	if err != nil {
+		return nil, err
+	}
+
+	responseTime := time.Now()
+
+	if ok && resp.StatusCode == http.StatusNotModified {
+		discardBody(resp, nil)
+
+		entry.update(resp, requestTime, responseTime)
+		t.store(req, entry)
+
+		t.report(req, CacheRevalidated, "not modified")
+
+		return entry.response(req, responseTime), nil
+	}
+
+	if reason := uncacheableReason(resp); reason != "" {
+		t.report(req, CacheUncacheable, reason)
+		return resp, nil
+	}
+
+	switch {
+	case !ok:
+		t.report(req, CacheMiss, "not cached")
+	case outReq != req:
+		t.report(req, CacheMiss, "modified")
+	default:
+		t.report(req, CacheMiss, "stale")
+	}
+
+	resp.Body = t.body(req, resp.Body, &cacheEntry{
+		resp:         resp,
+		vary:         varyHeader(req, resp),
+		requestTime:  requestTime,
+		responseTime: responseTime,
+	}, release)
+
+	release = nil
+
+	return resp, nil
+}
+
+// body returns a body that stores the entry once the given body was read completely.
+//
+// If the cache implements [CacheWriter], the body is written to the cache while it is read. Otherwise, it is buffered.
+// If set, release is called once the entry was stored or can not be stored.
+func (t *cacheTransport) body(req *http.Request, body io.ReadCloser, entry *cacheEntry, release func()) *teeBody {
+	b := t.storeBody(req, body, entry)
+
+	if release != nil {
+		done, abort := b.done, b.abort
+
+		b.done = func() {
+			done()
+			release()
+		}
+
+		b.abort = func() {
+			if abort != nil {
+				abort()
+			}
+			release()
+		}
+	}
+
+	return b
+}
+
+// storeBody returns a body that writes the entry to the cache while it is read, if the cache implements [CacheWriter],
+// or buffers the body and stores the entry once the body was read completely.
+func (t *cacheTransport) storeBody(req *http.Request, body io.ReadCloser, entry *cacheEntry) *teeBody {
+	if cw, ok := t.cache.(CacheWriter); ok {
+		if w, err := cw.NewWriter(cacheKey(req.Method, req.URL)); err == nil {
+			if err := entry.encodeHeader(w); err == nil {
+				chunked := httputil.NewChunkedWriter(w)
+
+				return &teeBody{
+					ReadCloser: body,
+					w:          chunked,
+					done: func() {
+						// The chunked writer does not write the final CRLF after the last chunk.
+						if chunked.Close() != nil {
+							w.Abort()
+							return
+						}
+
+						if _, err := io.WriteString(w, "\r\n"); err != nil {
+							w.Abort()
+							return
+						}
+
+						w.Commit()
+					},
+					abort: w.Abort,
+				}
+			}
+
+			w.Abort()
+		}
+	}
+
+	var buf bytes.Buffer
+
+	return &teeBody{
+		ReadCloser: body,
+		w:          &buf,
+		done: func() {
+			entry.body = buf.Bytes()
+			t.store(req, entry)
+		},
+	}
+}
+
+// lookup returns the cached entry for the request, if any.
+func (t *cacheTransport) lookup(req *http.Request) (*cacheEntry, bool) {
+	if t.cache == nil || req.Method != http.MethodGet {
+		return nil, false
+	}
+
+	data, ok := t.cache.Get(cacheKey(req.Method, req.URL))
+	if !ok {
+		return nil, false
+	}
+
+	entry, err := decodeCacheEntry(data)
+	if err != nil || !entry.matches(req) {
+		return nil, false
+	}
+
+	return entry, true
+}
+
+// invalidate removes the cached responses for the target URL of an unsafe request as well as the URLs in the Location
+// and Content-Location headers of the response, if the request was successful.
+//
+// See RFC 9111, Section 4.4.
+func (t *cacheTransport) invalidate(req *http.Request, resp *http.Response) {
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		return
+	}
+
+	t.cache.Delete(cacheKey(http.MethodGet, req.URL))
+
+	for _, name := range []string{"Location", "Content-Location"} {
+		value := resp.Header.Get(name)
+		if value == "" {
+			continue
+		}
+
+		u, err := req.URL.Parse(value)
+
+		// Only URLs with the same origin are invalidated, to prevent denial-of-service attacks.
+		if err != nil || !sameOrigin(u, req.URL) {
+			continue
+		}
+
+		t.cache.Delete(cacheKey(http.MethodGet, u))
+	}
+}
+
+// report calls the hook, if any.
+func (t *cacheTransport) report(req *http.Request, status CacheStatus, reason string) {
+	if t.hook != nil {
+		t.hook(CacheEvent{Request: req, Status: status, Reason: reason})
+	}
+}
+
+// store stores the entry for the request.
+func (t *cacheTransport) store(req *http.Request, entry *cacheEntry) {
+	data, err := entry.encode()
+	if err != nil {
+		return
+	}
+
+	t.cache.Set(cacheKey(req.Method, req.URL), data)
+}
+
+// uncacheableReason returns the reason why the response can not be stored or an empty string, if it can be stored.
+func uncacheableReason(resp *http.Response) string {
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNonAuthoritativeInfo, http.StatusNoContent, http.StatusMultipleChoices,
+		http.StatusMovedPermanently, http.StatusPermanentRedirect, http.StatusNotFound, http.StatusMethodNotAllowed,
+		http.StatusGone, http.StatusRequestURITooLong, http.StatusNotImplemented:
+	default:
+		return "status " + strconv.Itoa(resp.StatusCode)
+	}
+
+	if hasCacheDirective(resp.Header, "no-store") {
+		return "no-store"
+	}
+
+	if resp.Header.Get("Vary") == "*" {
+		return "Vary: *"
+	}
+
+	if _, hasMaxAge := cacheDirective(resp.Header, "max-age"); hasMaxAge ||
+		resp.Header.Get("Expires") != "" ||
+		resp.Header.Get("ETag") != "" ||
+		resp.Header.Get("Last-Modified") != "" {
+		return ""
+	}
+
+	return "no freshness information or validators"
+}
+
+// cacheDirective returns the value of the given Cache-Control directive.
+func cacheDirective(h http.Header, name string) (string, bool) {
+	for _, value := range h.Values("Cache-Control") {
+		for directive := range strings.SplitSeq(value, ",") {
+			key, arg, _ := strings.Cut(strings.TrimSpace(directive), "=")
+
+			if strings.EqualFold(key, name) {
+				return strings.Trim(arg, `"`), true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// hasCacheDirective reports whether the given Cache-Control directive is set.
+func hasCacheDirective(h http.Header, name string) bool {
+	_, ok := cacheDirective(h, name)
+	return ok
+}
+
+// varyHeader returns the request header values for the header names in the Vary header of the response.
+func varyHeader(req *http.Request, resp *http.Response) http.Header {
+	vary := make(http.Header)
+
+	for _, value := range resp.Header.Values("Vary") {
+		for name := range strings.SplitSeq(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				vary[http.CanonicalHeaderKey(name)] = req.Header.Values(name)
+			}
+		}
+	}
+
+	return vary
+}
+
+// WithCacheCoalescing coalesces concurrent requests that miss the cache for the same URL, so that only a single
+// request is sent to the origin. This protects origins from bursts of requests for popular URLs, for example when a
+// cached response expires or after a restart.
+//
+// The first request is sent as usual, while other requests wait until its response was stored in the cache and are
+// then answered from the cache. If the response can not be cached or wait is exceeded, waiting requests are sent
+// to the origin. If wait is not positive, requests wait until the first request is done or their context is canceled.
+//
+// This only has an effect together with [WithCache]. Since requests are only coalesced with other requests using the
+// same option, the returned option should be created once and reused, for example by passing it to [New].
+func WithCacheCoalescing(wait time.Duration) FetchOption {
+	g := &missGroup{wait: wait, inflight: make(map[string]chan struct{})}
+
+	return func(ctx *fetchContext) error {
+		ctx.CacheMisses = g
+		return nil
+	}
+}
+
+// missGroup tracks in-flight requests for keys that missed the cache.
+type missGroup struct {
+	wait time.Duration
+
+	mu       sync.Mutex
+	inflight map[string]chan struct{}
+}
+
+// join registers a request for the given key.
+//
+// If there is no other request for the key, join returns a function that must be called once the response was stored
+// or can not be stored. Otherwise, join waits for the other request and returns waited=true.
+func (g *missGroup) join(ctx context.Context, key string) (release func(), waited bool) {
+	g.mu.Lock()
+
+	if ch, ok := g.inflight[key]; ok {
+		g.mu.Unlock()
+
+		var timeout <-chan time.Time
+
+		if g.wait > 0 {
+			timer := time.NewTimer(g.wait)
+			defer timer.Stop()
+
+			timeout = timer.C
+		}
+
+		select {
+		case <-ch:
+		case <-timeout:
+		case <-ctx.Done():
+		}
+
+		return nil, true
+	}
+
+	ch := make(chan struct{})
+	g.inflight[key] = ch
+	g.mu.Unlock()
+
+	return func() {
+		g.mu.Lock()
+		delete(g.inflight, key)
+		g.mu.Unlock()
+
+		close(ch)
+	}, false
+}
+
+// cacheEntryMagic is written at the start of each encoded entry, so that the format can be changed later.
+const cacheEntryMagic = "httpc-cache-v1"
+
+// cacheEntry is a response stored in the cache.
+type cacheEntry struct {
+	resp *http.Response
+	body []byte
+
+	// vary contains the request header values for the header names listed in the Vary header.
+	vary http.Header
+
+	requestTime  time.Time
+	responseTime time.Time
+}
+
+// encode serializes the entry.
+func (e *cacheEntry) encode() ([]byte, error) {
+	var buf bytes.Buffer
+
+	if err := e.encodePrefix(&buf); err != nil {
+		return nil, err
+	}
+
+	resp := *e.resp
+	resp.Body = io.NopCloser(bytes.NewReader(e.body))
+	resp.ContentLength = int64(len(e.body))
+	resp.TransferEncoding = nil
+	resp.Header = resp.Header.Clone()
+	resp.Header.Del("Content-Length")
+
+	dump, err := httputil.DumpResponse(&resp, true)
+	if err != nil {
+		return nil, err
+	}
+
+	buf.Write(dump)
+	return buf.Bytes(), nil
+}
+
+// encodeHeader writes the entry without body to w. The body must be written afterwards using chunked encoding.
+func (e *cacheEntry) encodeHeader(w io.Writer) error {
+	var buf bytes.Buffer
+
+	if err := e.encodePrefix(&buf); err != nil {
+		return err
+	}
+
+	resp := *e.resp
+	resp.ContentLength = -1
+	resp.TransferEncoding = []string{"chunked"}
+	resp.Header = resp.Header.Clone()
+	resp.Header.Del("Content-Length")
+
+	// Chunked encoding is only supported starting with HTTP/1.1.
+	if !resp.ProtoAtLeast(1, 1) {
+		resp.ProtoMajor, resp.ProtoMinor = 1, 1
+	}
+
+	dump, err := httputil.DumpResponse(&resp, false)
+	if err != nil {
+		return err
+	}
+
+	buf.Write(dump)
+
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// encodePrefix writes the times and the Vary header values of the entry, which precede the response.
+func (e *cacheEntry) encodePrefix(buf *bytes.Buffer) error {
+	_, _ = fmt.Fprintf(buf, "%s\n%d\n%d\n", cacheEntryMagic, e.requestTime.UnixNano(), e.responseTime.UnixNano())
+
+	if err := e.vary.Write(buf); err != nil {
+		return err
+	}
+	buf.WriteString("\r\n")
+
+	return nil
+}
+
+// decodeCacheEntry parses an entry serialized using [cacheEntry.encode].
+func decodeCacheEntry(data []byte) (*cacheEntry, error) {
+	r := bufio.NewReader(bytes.NewReader(data))
+
+	readLine := func() (string, error) {
+		line, err := r.ReadString('\n')
+		return strings.TrimSuffix(line, "\n"), err
+	}
+
+	readTime := func() (time.Time, error) {
+		line, err := readLine()
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		nanos, err := strconv.ParseInt(line, 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		return time.Unix(0, nanos), nil
+	}
+
+	if magic, err := readLine(); err != nil || magic != cacheEntryMagic {
+		return nil, errors.New("invalid cache entry")
+	}
+
+	requestTime, err := readTime()
+	if err != nil {
+		return nil, err
+	}
+
+	responseTime, err := readTime()
+	if err != nil {
+		return nil, err
+	}
+
+	vary, err := textproto.NewReader(r).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.ReadResponse(r, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	// Entries written using a CacheWriter use chunked encoding, which does not apply to the decoded body.
+	resp.TransferEncoding = nil
+
+	return &cacheEntry{
+		resp:         resp,
+		body:         body,
+		vary:         http.Header(vary),
+		requestTime:  requestTime,
+		responseTime: responseTime,
+	}, nil
+}
+
+// matches reports whether the entry can be used for the request, based on the Vary header.
+func (e *cacheEntry) matches(req *http.Request) bool {
+	for name, values := range e.vary {
+		if strings.Join(values, ", ") != strings.Join(req.Header.Values(name), ", ") {
+			return false
+		}
+	}
+
+	return true
+}
+
+// hasValidators reports whether the entry can be revalidated using a conditional request.
+func (e *cacheEntry) hasValidators() bool {
+	return e.resp.Header.Get("ETag") != "" || e.resp.Header.Get("Last-Modified") != ""
+}
+
+// date returns the value of the Date header or the response time, if the header is missing or invalid.
+func (e *cacheEntry) date() time.Time {
+	if date, err := http.ParseTime(e.resp.Header.Get("Date")); err == nil {
+		return date
+	}
+
+	return e.responseTime
+}
+
+// age returns the current age of the entry as defined in RFC 9111, Section 4.2.3.
+func (e *cacheEntry) age(now time.Time) time.Duration {
+	apparentAge := max(0, e.responseTime.Sub(e.date()))
+
+	var ageValue time.Duration
+	if seconds, err := strconv.ParseInt(e.resp.Header.Get("Age"), 10, 64); err == nil && seconds > 0 {
+		ageValue = time.Duration(seconds) * time.Second
+	}
+
+	correctedAgeValue := ageValue + e.responseTime.Sub(e.requestTime)
+
+	return max(apparentAge, correctedAgeValue) + now.Sub(e.responseTime)
+}
+
+// freshnessLifetime returns the freshness lifetime of the entry as defined in RFC 9111, Section 4.2.1.
+func (e *cacheEntry) freshnessLifetime() time.Duration {
+	h := e.resp.Header
+
+	if hasCacheDirective(h, "no-cache") {
+		return 0
+	}
+
+	if maxAge, ok := cacheDirective(h, "max-age"); ok {
+		seconds, err := strconv.ParseInt(maxAge, 10, 64)
+		if err != nil {
+			return 0
+		}
+
+		return time.Duration(seconds) * time.Second
+	}
+
+	if expires := h.Get("Expires"); expires != "" {
+		t, err := http.ParseTime(expires)
+		if err != nil {
+			return 0
+		}
+
+		return t.Sub(e.date())
+	}
+
+	// Heuristic freshness as suggested by RFC 9111, Section 4.2.2.
+	if lastModified, err := http.ParseTime(h.Get("Last-Modified")); err == nil && e.resp.StatusCode == http.StatusOK {
+		return e.date().Sub(lastModified) / 10
+	}
+
+	return 0
+}
+
+// fresh reports whether the entry is still fresh.
+func (e *cacheEntry) fresh(now time.Time) bool {
+	return e.freshnessLifetime() > e.age(now)
+}
+
+// update updates the entry using the headers of a 304 Not Modified response as described in RFC 9111, Section 3.2.
+func (e *cacheEntry) update(resp *http.Response, requestTime, responseTime time.Time) {
+	for name, values := range resp.Header {
+		switch name {
+		case "Content-Length", "Content-Encoding", "Transfer-Encoding":
+			continue
+		}
+
+		e.resp.Header[name] = values
+	}
+
+	e.requestTime, e.responseTime = requestTime, responseTime
+}
+
+// response returns a new response for the request using the cached response.
+func (e *cacheEntry) response(req *http.Request, now time.Time) *http.Response {
+	resp := *e.resp
+	resp.Header = e.resp.Header.Clone()
+	resp.Header.Set("Age", strconv.FormatInt(int64(e.age(now)/time.Second), 10))
+	resp.Body = io.NopCloser(bytes.NewReader(e.body))
+	resp.ContentLength = int64(len(e.body))
+	resp.Request = req
+
+	return &resp
+}
@@ -0,0 +1,559 @@
+package httpc_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nussjustin/httpc"
+)
+
+func cacheServer(t *testing.T) (*httptest.Server, *atomic.Int64) {
+	t.Helper()
+
+	var calls atomic.Int64
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/fresh", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		_, _ = w.Write([]byte("fresh-" + strconv.FormatInt(calls.Add(1), 10)))
+	})
+	mux.HandleFunc("/etag", func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("ETag", `"v1"`)
+
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		_, _ = w.Write([]byte("etag"))
+	})
+	mux.HandleFunc("/no-store", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store, max-age=60")
+		_, _ = w.Write([]byte("no-store-" + strconv.FormatInt(calls.Add(1), 10)))
+	})
+	mux.HandleFunc("/vary", func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("Vary", "Accept-Language")
+		_, _ = w.Write([]byte("vary-" + r.Header.Get("Accept-Language")))
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return srv, &calls
+}
+
+func TestWithCache(t *testing.T) {
+	testCases := []struct {
+		Name    string
+		Path    string
+		Options [][]httpc.FetchOption
+		Want    []string
+		Calls   int64
+	}{
+		{
+			Name:  "Fresh",
+			Path:  "/fresh",
+			Want:  []string{"fresh-1", "fresh-1", "fresh-1"},
+			Calls: 1,
+		},
+		{
+			Name:  "Revalidated",
+			Path:  "/etag",
+			Want:  []string{"etag", "etag", "etag"},
+			Calls: 3,
+		},
+		{
+			Name:  "No store",
+			Path:  "/no-store",
+			Want:  []string{"no-store-1", "no-store-2"},
+			Calls: 2,
+		},
+		{
+			Name: "Request no-cache",
+			Path: "/fresh",
+			Options: [][]httpc.FetchOption{
+				nil,
+				{httpc.WithHeader("Cache-Control", "no-cache")},
+			},
+			Want:  []string{"fresh-1", "fresh-2"},
+			Calls: 2,
+		},
+		{
+			Name: "Query order",
+			Path: "/fresh",
+			Options: [][]httpc.FetchOption{
+				{httpc.WithQueryParam("a", "1"), httpc.WithQueryParam("b", "2")},
+				{httpc.WithQueryParam("b", "2"), httpc.WithQueryParam("a", "1")},
+				{httpc.WithQueryParam("b", "1"), httpc.WithQueryParam("a", "2")},
+			},
+			Want:  []string{"fresh-1", "fresh-1", "fresh-2"},
+			Calls: 2,
+		},
+		{
+			Name: "Repeated query parameters",
+			Path: "/fresh",
+			Options: [][]httpc.FetchOption{
+				{httpc.WithAddedQueryParam("a", "1"), httpc.WithAddedQueryParam("a", "2")},
+				{httpc.WithAddedQueryParam("a", "2"), httpc.WithAddedQueryParam("a", "1")},
+			},
+			Want:  []string{"fresh-1", "fresh-2"},
+			Calls: 2,
+		},
+		{
+			Name: "Vary",
+			Path: "/vary",
+			Options: [][]httpc.FetchOption{
+				{httpc.WithAcceptLanguage("de")},
+				{httpc.WithAcceptLanguage("en")},
+				{httpc.WithAcceptLanguage("en")},
+			},
+			Want:  []string{"vary-de", "vary-en", "vary-en"},
+			Calls: 2,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			srv, calls := cacheServer(t)
+
+			c := httpc.New(httpc.WithCache(httpc.NewMemoryCache()))
+
+			for i, want := range testCase.Want {
+				var opts []httpc.FetchOption
+				if i < len(testCase.Options) {
+					opts = testCase.Options[i]
+				}
+
+				var got string
+
+				if err := c.Fetch(t.Context(), http.MethodGet, srv.URL+testCase.Path, &got, opts...); err != nil {
+					t.Fatalf("got error %v", err)
+				}
+
+				if got != want {
+					t.Errorf("request %d: got %q, want %q", i, got, want)
+				}
+			}
+
+			if got := calls.Load(); got != testCase.Calls {
+				t.Errorf("got %d calls, want %d", got, testCase.Calls)
+			}
+		})
+	}
+}
+
+func TestWithCache_PartialBody(t *testing.T) {
+	srv, calls := cacheServer(t)
+
+	c := httpc.New(httpc.WithCache(httpc.NewMemoryCache()))
+
+	var body io.ReadCloser
+	if err := c.Fetch(t.Context(), http.MethodGet, srv.URL+"/fresh", &body); err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	_ = body.Close()
+
+	var got string
+
+	if err := c.Fetch(t.Context(), http.MethodGet, srv.URL+"/fresh", &got); err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	if got != "fresh-2" || calls.Load() != 2 {
+		t.Errorf("got %q after %d calls, want response that was not cached", got, calls.Load())
+	}
+}
+
+// writerCache is a [httpc.CacheWriter] that stores entries in a [httpc.MemoryCache] and records all writes.
+type writerCache struct {
+	*httpc.MemoryCache
+
+	mu      sync.Mutex
+	written bytes.Buffer
+	aborted int
+}
+
+func (c *writerCache) NewWriter(key string) (httpc.BodyWriter, error) {
+	return &writerCacheEntry{cache: c, key: key}, nil
+}
+
+func (c *writerCache) writtenLen() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.written.Len()
+}
+
+type writerCacheEntry struct {
+	cache *writerCache
+	key   string
+	buf   bytes.Buffer
+}
+
+func (w *writerCacheEntry) Write(p []byte) (int, error) {
+	w.cache.mu.Lock()
+	w.cache.written.Write(p)
+	w.cache.mu.Unlock()
+
+	return w.buf.Write(p)
+}
+
+func (w *writerCacheEntry) Commit() {
+	w.cache.Set(w.key, w.buf.Bytes())
+}
+
+func (w *writerCacheEntry) Abort() {
+	w.cache.mu.Lock()
+	w.cache.aborted++
+	w.cache.mu.Unlock()
+}
+
+func TestWithCache_Writer(t *testing.T) {
+	srv, calls := cacheServer(t)
+
+	cache := &writerCache{MemoryCache: httpc.NewMemoryCache()}
+
+	c := httpc.New(httpc.WithCache(cache))
+
+	fetchBody := func() io.ReadCloser {
+		t.Helper()
+
+		var body io.ReadCloser
+		if err := c.Fetch(t.Context(), http.MethodGet, srv.URL+"/fresh", &body); err != nil {
+			t.Fatalf("got error %v", err)
+		}
+		return body
+	}
+
+	// Closing the body early discards the entry.
+	body := fetchBody()
+
+	if _, err := body.Read(make([]byte, 1)); err != nil {
+		t.Fatalf("failed to read body: %v", err)
+	}
+
+	_ = body.Close()
+
+	if got, want := cache.aborted, 1; got != want {
+		t.Errorf("got %d aborted entries, want %d", got, want)
+	}
+
+	body = fetchBody()
+	defer func() { _ = body.Close() }()
+
+	// The body is written to the cache while it is read.
+	before := cache.writtenLen()
+
+	if _, err := body.Read(make([]byte, 3)); err != nil {
+		t.Fatalf("failed to read body: %v", err)
+	}
+
+	if cache.writtenLen() <= before {
+		t.Error("body was not written to the cache while reading")
+	}
+
+	if b, err := io.ReadAll(body); err != nil || string(b) != "sh-2" {
+		t.Fatalf("got rest of body %q, %v, want %q", b, err, "sh-2")
+	}
+
+	var got string
+
+	if err := c.Fetch(t.Context(), http.MethodGet, srv.URL+"/fresh", &got); err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	if got != "fresh-2" || calls.Load() != 2 {
+		t.Errorf("got %q after %d calls, want cached response", got, calls.Load())
+	}
+}
+
+func TestWithOfflineMode(t *testing.T) {
+	srv, _ := cacheServer(t)
+
+	cache := httpc.NewMemoryCache()
+
+	online := httpc.New(httpc.WithCache(cache))
+	offline := httpc.New(httpc.WithCache(cache), httpc.WithOfflineMode())
+
+	if err := offline.Fetch(t.Context(), http.MethodGet, srv.URL+"/etag", nil); !errors.Is(err, httpc.ErrOffline) {
+		t.Errorf("got error %v, want %v", err, httpc.ErrOffline)
+	}
+
+	var got string
+
+	if err := online.Fetch(t.Context(), http.MethodGet, srv.URL+"/etag", &got); err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	srv.Close()
+
+	got = ""
+
+	// Stale entries are returned as well
+	if err := offline.Fetch(t.Context(), http.MethodGet, srv.URL+"/etag", &got); err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	if got != "etag" {
+		t.Errorf("got %q, want %q", got, "etag")
+	}
+
+	if err := offline.Fetch(t.Context(), http.MethodPost, srv.URL+"/etag", nil); !errors.Is(err, httpc.ErrOffline) {
+		t.Errorf("got error %v for POST, want %v", err, httpc.ErrOffline)
+	}
+
+	noCache := httpc.New(httpc.WithOfflineMode())
+
+	if err := noCache.Fetch(t.Context(), http.MethodGet, srv.URL+"/etag", nil); !errors.Is(err, httpc.ErrOffline) {
+		t.Errorf("got error %v without cache, want %v", err, httpc.ErrOffline)
+	}
+}
+
+func TestWithCache_Age(t *testing.T) {
+	srv, _ := cacheServer(t)
+
+	cache := httpc.WithCache(httpc.NewMemoryCache())
+
+	for i, want := range []string{"", "0"} {
+		_, resp, err := httpc.FetchWithResponse[string](t.Context(), http.MethodGet, srv.URL+"/fresh", cache)
+		if err != nil {
+			t.Fatalf("got error %v", err)
+		}
+
+		if got := resp.Header.Get("Age"); got != want {
+			t.Errorf("request %d: got Age %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestWithCache_Invalidation(t *testing.T) {
+	var version atomic.Int64
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		_, _ = w.Write([]byte(r.PathValue("id") + "-" + strconv.FormatInt(version.Load(), 10)))
+	})
+	mux.HandleFunc("POST /items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		version.Add(1)
+
+		if r.Header.Get("X-Fail") != "" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Location", "/items/other")
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	c := httpc.New(httpc.WithCache(httpc.NewMemoryCache()))
+
+	get := func(path string, want string) {
+		t.Helper()
+
+		var got string
+
+		if err := c.Fetch(t.Context(), http.MethodGet, srv.URL+path, &got); err != nil {
+			t.Fatalf("got error %v", err)
+		}
+
+		if got != want {
+			t.Errorf("GET %s: got %q, want %q", path, got, want)
+		}
+	}
+
+	get("/items/1", "1-0")
+	get("/items/other", "other-0")
+
+	// Failed requests do not invalidate entries
+	_ = c.Fetch(t.Context(), http.MethodPost, srv.URL+"/items/1", nil, httpc.WithHeader("X-Fail", "1"))
+
+	get("/items/1", "1-0")
+	get("/items/other", "other-0")
+
+	if err := c.Fetch(t.Context(), http.MethodPost, srv.URL+"/items/1", nil); err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	get("/items/1", "1-2")
+	get("/items/other", "other-2")
+}
+
+func TestWithCacheCoalescing(t *testing.T) {
+	testCases := []struct {
+		Name  string
+		Wait  time.Duration
+		Calls int64
+	}{
+		{Name: "Coalesced", Wait: time.Minute, Calls: 1},
+		{Name: "Timeout", Wait: time.Millisecond, Calls: 5},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			var calls atomic.Int64
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+
+				time.Sleep(100 * time.Millisecond)
+
+				w.Header().Set("Cache-Control", "max-age=60")
+				_, _ = w.Write([]byte("value"))
+			}))
+			t.Cleanup(srv.Close)
+
+			c := httpc.New(httpc.WithCache(httpc.NewMemoryCache()), httpc.WithCacheCoalescing(testCase.Wait))
+
+			var wg sync.WaitGroup
+
+			for range 5 {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+
+					var got string
+
+					if err := c.Fetch(t.Context(), http.MethodGet, srv.URL, &got); err != nil {
+						t.Errorf("got error %v", err)
+					}
+
+					if got != "value" {
+						t.Errorf("got %q, want %q", got, "value")
+					}
+				}()
+			}
+
+			wg.Wait()
+
+			if got := calls.Load(); got != testCase.Calls {
+				t.Errorf("got %d calls, want %d", got, testCase.Calls)
+			}
+		})
+	}
+}
+
+func TestWithCacheHook(t *testing.T) {
+	type event struct {
+		Status httpc.CacheStatus
+		Reason string
+	}
+
+	testCases := []struct {
+		Name    string
+		Path    string
+		Options []httpc.FetchOption
+		Want    []event
+	}{
+		{
+			Name: "Fresh",
+			Path: "/fresh",
+			Want: []event{
+				{httpc.CacheMiss, "not cached"},
+				{httpc.CacheHit, "fresh"},
+			},
+		},
+		{
+			Name: "Revalidated",
+			Path: "/etag",
+			Want: []event{
+				{httpc.CacheMiss, "not cached"},
+				{httpc.CacheRevalidated, "not modified"},
+			},
+		},
+		{
+			Name: "No store",
+			Path: "/no-store",
+			Want: []event{
+				{httpc.CacheUncacheable, "no-store"},
+				{httpc.CacheUncacheable, "no-store"},
+			},
+		},
+		{
+			Name:    "Conditional request",
+			Path:    "/fresh",
+			Options: []httpc.FetchOption{httpc.WithHeader("If-None-Match", `"v0"`)},
+			Want: []event{
+				{httpc.CacheBypass, "conditional request"},
+				{httpc.CacheBypass, "conditional request"},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			srv, _ := cacheServer(t)
+
+			var got []event
+
+			c := httpc.New(
+				httpc.WithCache(httpc.NewMemoryCache()),
+				httpc.WithCacheHook(func(e httpc.CacheEvent) {
+					if e.Request == nil {
+						t.Error("got event without request")
+					}
+
+					got = append(got, event{e.Status, e.Reason})
+				}),
+			)
+
+			for range testCase.Want {
+				var body string
+
+				err := c.Fetch(t.Context(), http.MethodGet, srv.URL+testCase.Path, &body, testCase.Options...)
+				if err != nil {
+					t.Fatalf("got error %v", err)
+				}
+			}
+
+			if diff := cmp.Diff(testCase.Want, got); diff != "" {
+				t.Errorf("events mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWithCacheHook_Offline(t *testing.T) {
+	srv, _ := cacheServer(t)
+
+	cache := httpc.WithCache(httpc.NewMemoryCache())
+
+	if _, err := httpc.Fetch[string](t.Context(), http.MethodGet, srv.URL+"/etag", cache); err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	var got httpc.CacheEvent
+
+	_, err := httpc.Fetch[string](t.Context(), http.MethodGet, srv.URL+"/etag",
+		cache,
+		httpc.WithOfflineMode(),
+		httpc.WithCacheHook(func(e httpc.CacheEvent) { got = e }))
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	if got.Status != httpc.CacheStale || got.Reason != "offline" {
+		t.Errorf("got status %d with reason %q, want %d with reason %q",
+			got.Status, got.Reason, httpc.CacheStale, "offline")
+	}
+}
@@ -0,0 +1,145 @@
+package httpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+)
+
+// ErrChecksumMismatch is returned by [DownloadVerified] when the checksum of a downloaded artifact does not match the
+// expected checksum.
+var ErrChecksumMismatch = errors.New("github.com/nussjustin/httpc: checksum mismatch")
+
+// ErrNoChecksum is returned by [DownloadVerified] when the checksum manifest contains no checksum for an artifact.
+var ErrNoChecksum = errors.New("github.com/nussjustin/httpc: no checksum found")
+
+// ChecksumManifest maps file names to their SHA-256 checksums.
+type ChecksumManifest map[string][]byte
+
+// ParseChecksumManifest parses a checksum manifest in the format used by sha256sum and SHA256SUMS files.
+//
+// Each line consists of the hex encoded SHA-256 checksum, a space, a space or asterisk and the file name. A leading
+// "./" is removed from file names. Empty lines and lines starting with "#" are ignored.
+func ParseChecksumManifest(data []byte) (ChecksumManifest, error) {
+	m := make(ChecksumManifest)
+
+	s := bufio.NewScanner(bytes.NewReader(data))
+
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		sum, name, ok := strings.Cut(line, " ")
+		if !ok || len(sum) != hex.EncodedLen(sha256.Size) {
+			return nil, fmt.Errorf("github.com/nussjustin/httpc: invalid checksum manifest line %d", n)
+		}
+
+		checksum, err := hex.DecodeString(sum)
+		if err != nil {
+			return nil, fmt.Errorf("github.com/nussjustin/httpc: invalid checksum manifest line %d: %w", n, err)
+		}
+
+		name = strings.TrimPrefix(strings.TrimPrefix(name, " "), "*")
+		name = strings.TrimPrefix(name, "./")
+
+		m[name] = checksum
+	}
+
+	return m, s.Err()
+}
+
+// FetchChecksumManifest fetches the checksum manifest at the given URL and parses it using [ParseChecksumManifest].
+//
+// If the response has a non-2xx status code an error wrapping [ErrUnexpectedStatus] is returned.
+//
+// Any [Handler] configured via the given options is ignored.
+func FetchChecksumManifest(ctx context.Context, url string, opts ...FetchOption) (ChecksumManifest, error) {
+	return Fetch[ChecksumManifest](ctx, http.MethodGet, url, slices.Concat(opts, []FetchOption{
+		WithHandlerFunc(func(dst any, resp *http.Response) (err error) {
+			defer discardBody(resp, &err)
+
+			if !IsSuccess(resp) {
+				return fmt.Errorf("%w %q", ErrUnexpectedStatus, resp.Status)
+			}
+
+			data, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return err
+			}
+
+			m, err := ParseChecksumManifest(data)
+			if err != nil {
+				return err
+			}
+
+			*dst.(*ChecksumManifest) = m
+			return nil
+		}),
+	})...)
+}
+
+// DownloadVerified downloads the artifact at artifactURL into w and verifies it using the checksum manifest at
+// manifestURL.
+//
+// The manifest is fetched first using [FetchChecksumManifest]. The checksum of the artifact is looked up using the
+// last element of the path of artifactURL as file name. If the manifest contains no checksum for the artifact,
+// [ErrNoChecksum] is returned without downloading the artifact.
+//
+// The artifact is hashed while it is written to w. If the checksums do not match, an error wrapping
+// [ErrChecksumMismatch] is returned. Since the artifact is streamed, w has already received the data at this point,
+// so callers should write to a temporary location and only use the artifact if no error is returned.
+//
+// The given options are used for both requests. Any [Handler] configured via the options is ignored.
+func DownloadVerified(ctx context.Context, manifestURL, artifactURL string, w io.Writer, opts ...FetchOption) error {
+	u, err := url.Parse(artifactURL)
+	if err != nil {
+		return err
+	}
+
+	name := path.Base(u.Path)
+
+	manifest, err := FetchChecksumManifest(ctx, manifestURL, opts...)
+	if err != nil {
+		return err
+	}
+
+	want, ok := manifest[name]
+	if !ok {
+		return fmt.Errorf("%w for %q", ErrNoChecksum, name)
+	}
+
+	_, err = Fetch[struct{}](ctx, http.MethodGet, artifactURL, slices.Concat(opts, []FetchOption{
+		WithHandlerFunc(func(_ any, resp *http.Response) (err error) {
+			defer discardBody(resp, &err)
+
+			if !IsSuccess(resp) {
+				return fmt.Errorf("%w %q", ErrUnexpectedStatus, resp.Status)
+			}
+
+			h := sha256.New()
+
+			if _, err := io.Copy(io.MultiWriter(w, h), resp.Body); err != nil {
+				return err
+			}
+
+			if got := h.Sum(nil); !bytes.Equal(got, want) {
+				return fmt.Errorf("%w for %q: got %x, want %x", ErrChecksumMismatch, name, got, want)
+			}
+
+			return nil
+		}),
+	})...)
+	return err
+}
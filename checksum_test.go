@@ -0,0 +1,91 @@
+package httpc_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nussjustin/httpc"
+)
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestParseChecksumManifest(t *testing.T) {
+	data := "# checksums\n" +
+		sha256Hex("a") + "  tool-linux.tar.gz\n" +
+		"\n" +
+		sha256Hex("b") + " *./tool-windows.zip\n"
+
+	got, err := httpc.ParseChecksumManifest([]byte(data))
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	sumA, sumB := sha256.Sum256([]byte("a")), sha256.Sum256([]byte("b"))
+
+	want := httpc.ChecksumManifest{
+		"tool-linux.tar.gz": sumA[:],
+		"tool-windows.zip":  sumB[:],
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("manifest mismatch (-want +got):\n%s", diff)
+	}
+
+	for _, invalid := range []string{"abc  file", sha256Hex("a"), "zz" + sha256Hex("a")[2:] + "  file"} {
+		if _, err := httpc.ParseChecksumManifest([]byte(invalid)); err == nil {
+			t.Errorf("got no error for %q", invalid)
+		}
+	}
+}
+
+func TestDownloadVerified(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/SHA256SUMS", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		_, _ = w.Write([]byte(sha256Hex("good") + "  good.bin\n" + sha256Hex("other") + "  bad.bin\n"))
+	})
+	mux.HandleFunc("/good.bin", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("good"))
+	})
+	mux.HandleFunc("/bad.bin", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("bad"))
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	testCases := []struct {
+		Name     string
+		Path     string
+		Expected error
+	}{
+		{Name: "Match", Path: "/good.bin"},
+		{Name: "Mismatch", Path: "/bad.bin", Expected: httpc.ErrChecksumMismatch},
+		{Name: "Missing checksum", Path: "/missing.bin", Expected: httpc.ErrNoChecksum},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			err := httpc.DownloadVerified(t.Context(), srv.URL+"/SHA256SUMS", srv.URL+testCase.Path, &buf)
+			if !errors.Is(err, testCase.Expected) {
+				t.Fatalf("got error %v, want %v", err, testCase.Expected)
+			}
+
+			if testCase.Expected == nil && buf.String() != "good" {
+				t.Errorf("got %q, want %q", buf.String(), "good")
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package httpc
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Client can be used to make multiple requests using the same set of default options.
+//
+// A Client is safe for concurrent use by multiple goroutines.
+type Client struct {
+	opts atomic.Pointer[[]FetchOption]
+
+	tracker requestTracker
+}
+
+// New returns a new [Client] that applies the given options to each request, before any per-request options.
+func New(opts ...FetchOption) *Client {
+	c := &Client{}
+	c.Update(opts...)
+	return c
+}
+
+// Update atomically replaces the default options of the client with the given options.
+//
+// Requests started after Update returns use the new options, while requests that are already in progress keep using
+// the previous options. This can be used to react to configuration changes at runtime, for example to change the base
+// URL or to rotate credentials, without having to recreate the client.
+//
+// Options that keep state, like [WithCache] or [WithTokenSource], are replaced as well. To keep their state, pass the
+// same option values again.
+func (c *Client) Update(opts ...FetchOption) {
+	opts = slices.Clone(opts)
+	c.opts.Store(&opts)
+}
+
+// options returns the default options of the client followed by the given per-request options.
+func (c *Client) options(opts []FetchOption) []FetchOption {
+	var defaults []FetchOption
+	if p := c.opts.Load(); p != nil {
+		defaults = *p
+	}
+
+	return slices.Concat(defaults, opts, []FetchOption{func(ctx *fetchContext) error {
+		ctx.Tracker = &c.tracker
+		return nil
+	}})
+}
+
+// Fetch requests the given endpoint and decodes the response into dst.
+//
+// The default options of the client are applied before the given options. See [Fetch] for more information.
+//
+// The response body is always closed before Fetch returns.
+func (c *Client) Fetch(ctx context.Context, method string, url string, dst any, opts ...FetchOption) error {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := fetch(req, url, dst, c.options(opts))
+	if resp != nil {
+		defer discardBody(resp, nil)
+	}
+	return err
+}
+
+// FetchURL requests the given URL using a GET request and decodes the response into dst.
+//
+// This can be used to follow links returned by an API, for example via the Location or Link header, while still
+// applying the defaults of the client like authentication or custom headers. Relative references can be resolved
+// against the URL of the response that contained them using [ResolveReference].
+//
+// Unlike paths passed to [Fetch], the URL is not modified after all options were applied. This means that wildcards
+// in the URL path are not replaced and trailing slashes are kept as is. Relative URLs are still resolved against the
+// configured base URL, if any.
+//
+// If a base URL is configured and the URL points to a different origin, that is a different scheme, host or port, the
+// headers set using [WithSensitiveHeaders] are removed from the request, including headers added by options like
+// [WithTokenSource]. This ensures that links returned by an API can not be used to leak credentials to other hosts.
+// Other headers, like the header used by [WithAPIKey], are only removed if given to [WithSensitiveHeaders].
+//
+// The response body is always closed before FetchURL returns.
+func (c *Client) FetchURL(ctx context.Context, u *url.URL, dst any, opts ...FetchOption) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "", nil)
+	if err != nil {
+		return err
+	}
+
+	req.URL = new(url.URL)
+	*req.URL = *u
+
+	opts = append(c.options(opts), func(ctx *fetchContext) error {
+		ctx.PreserveURL = true
+		ctx.Origin = ctx.BaseURL
+		return nil
+	})
+
+	resp, err := fetch(req, u.String(), dst, opts)
+	if resp != nil {
+		defer discardBody(resp, nil)
+	}
+	return err
+}
+
+// ResolveReference resolves the given URI reference, for example from a Location or Link header, against the URL of
+// the request that resulted in the given response.
+//
+// If the response has no associated request, the reference is returned as is.
+func ResolveReference(resp *http.Response, ref string) (*url.URL, error) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Request != nil && resp.Request.URL != nil {
+		u = resp.Request.URL.ResolveReference(u)
+	}
+
+	return u, nil
+}
+
+// RequestInfo contains information about a request that is currently executed by a [Client].
+type RequestInfo struct {
+	// Method is the HTTP method of the request.
+	Method string
+
+	// OperationName is the name set using [WithOperationName], if any.
+	OperationName string
+
+	// URLTemplate is the URL of the request before any wildcards were replaced.
+	//
+	// For example "https://example.com/product/{id}".
+	URLTemplate string
+
+	// Start is the time at which the request was started.
+	Start time.Time
+
+	// Attempt is the number of the current attempt, starting at 1.
+	Attempt int
+
+	// Labels contains the labels added using [WithLabel]. It must not be modified.
+	Labels map[string]string
+}
+
+// InFlight returns information about all requests that are currently executed by the client, ordered by start time.
+//
+// A request is considered in-flight from the time it is sent until its response was handled.
+func (c *Client) InFlight() []RequestInfo {
+	return c.tracker.list()
+}
+
+type trackedRequest struct {
+	info    RequestInfo
+	attempt atomic.Int64
+}
+
+// requestTracker keeps track of requests that are currently executed.
+type requestTracker struct {
+	mu       sync.Mutex
+	requests map[*trackedRequest]struct{}
+}
+
+func (t *requestTracker) add(ctx *fetchContext) *trackedRequest {
+	r := &trackedRequest{
+		info: RequestInfo{
+			Method:        ctx.Request.Method,
+			OperationName: ctx.OperationName,
+			URLTemplate:   ctx.URLTemplate,
+			Start:         time.Now(),
+			Labels:        ctx.Labels,
+		},
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.requests == nil {
+		t.requests = make(map[*trackedRequest]struct{})
+	}
+
+	t.requests[r] = struct{}{}
+	return r
+}
+
+func (t *requestTracker) remove(r *trackedRequest) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.requests, r)
+}
+
+func (t *requestTracker) list() []RequestInfo {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	infos := make([]RequestInfo, 0, len(t.requests))
+
+	for r := range t.requests {
+		info := r.info
+		info.Attempt = int(r.attempt.Load())
+
+		infos = append(infos, info)
+	}
+
+	slices.SortFunc(infos, func(a, b RequestInfo) int {
+		return a.Start.Compare(b.Start)
+	})
+
+	return infos
+}
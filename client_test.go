@@ -0,0 +1,310 @@
+package httpc_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nussjustin/httpc"
+)
+
+func TestClient_Fetch(t *testing.T) {
+	client, baseURL := testEndpoint(t)
+
+	c := httpc.New(
+		httpc.WithClient(client),
+		httpc.WithBaseURL(baseURL),
+		httpc.WithHeader("Authorization", "Bearer token"),
+	)
+
+	var got infoResponse
+
+	if err := c.Fetch(t.Context(), http.MethodPut, "/product/{id}", &got,
+		httpc.WithPathValue("id", "1"),
+		httpc.WithHeader("X-Custom", "value")); err != nil {
+		t.Fatalf("failed to fetch: %v", err)
+	}
+
+	want := infoResponse{
+		Method: http.MethodPut,
+		Host:   baseURL.Host,
+		Path:   "/product/1",
+		Query:  url.Values{},
+		Header: http.Header{
+			"Authorization": []string{"Bearer token"},
+			"X-Custom":      []string{"value"},
+		},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Response mismatch (-want +got):\n%s", diff)
+	}
+
+	var handled int
+
+	c = httpc.New(
+		httpc.WithClient(client),
+		httpc.WithBaseURL(baseURL),
+		httpc.WithHandlerFunc(func(_ any, resp *http.Response) error {
+			handled++
+			return resp.Body.Close()
+		}),
+	)
+
+	for range 2 {
+		if err := c.Fetch(t.Context(), http.MethodGet, "/", nil); err != nil {
+			t.Fatalf("failed to fetch: %v", err)
+		}
+	}
+
+	if handled != 2 {
+		t.Errorf("default handler called %d times, want 2", handled)
+	}
+}
+
+func TestClient_FetchURL(t *testing.T) {
+	client, baseURL := testEndpoint(t)
+
+	c := httpc.New(
+		httpc.WithClient(client),
+		httpc.WithBaseURL(baseURL),
+		httpc.WithHeader("Authorization", "Bearer token"),
+		httpc.WithPathValue("id", "1"),
+		httpc.WithTrailingSlash(httpc.TrailingSlashStrip),
+	)
+
+	u := baseURL.JoinPath("/product/{id}/")
+
+	var got infoResponse
+
+	if err := c.FetchURL(t.Context(), u, &got, httpc.WithHeader("X-Custom", "value")); err != nil {
+		t.Fatalf("failed to fetch: %v", err)
+	}
+
+	want := infoResponse{
+		Method: http.MethodGet,
+		Host:   baseURL.Host,
+		Path:   "/product/{id}/",
+		Query:  url.Values{},
+		Header: http.Header{
+			"Authorization": []string{"Bearer token"},
+			"X-Custom":      []string{"value"},
+		},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Response mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestClient_FetchURL_CrossOrigin(t *testing.T) {
+	client, baseURL := testEndpoint(t)
+	_, otherURL := testEndpoint(t)
+
+	c := httpc.New(
+		httpc.WithClient(client),
+		httpc.WithBaseURL(baseURL),
+		httpc.WithHeader("Authorization", "Bearer token"),
+		httpc.WithHeader("Cookie", "session=secret"),
+		httpc.WithAPIKey("key", httpc.KeyInHeader, "X-Api-Key"),
+		httpc.WithHeader("X-Custom", "value"),
+	)
+
+	var got infoResponse
+
+	if err := c.FetchURL(t.Context(), otherURL.JoinPath("/next"), &got); err != nil {
+		t.Fatalf("failed to fetch: %v", err)
+	}
+
+	want := http.Header{
+		"X-Api-Key": []string{"key"},
+		"X-Custom":  []string{"value"},
+	}
+
+	if diff := cmp.Diff(want, got.Header); diff != "" {
+		t.Errorf("Header mismatch (-want +got):\n%s", diff)
+	}
+
+	got = infoResponse{}
+
+	if err := c.FetchURL(t.Context(), otherURL.JoinPath("/next"), &got,
+		httpc.WithSensitiveHeaders("Authorization", "X-Api-Key")); err != nil {
+		t.Fatalf("failed to fetch: %v", err)
+	}
+
+	want = http.Header{
+		"Cookie":   []string{"session=secret"},
+		"X-Custom": []string{"value"},
+	}
+
+	if diff := cmp.Diff(want, got.Header); diff != "" {
+		t.Errorf("Header mismatch (-want +got):\n%s", diff)
+	}
+
+	got = infoResponse{}
+
+	if err := c.FetchURL(t.Context(), baseURL.JoinPath("/next"), &got); err != nil {
+		t.Fatalf("failed to fetch: %v", err)
+	}
+
+	if got, want := got.Header.Get("Authorization"), "Bearer token"; got != want {
+		t.Errorf("got Authorization %q, want %q", got, want)
+	}
+}
+
+func TestClient_Update(t *testing.T) {
+	client, baseURL := testEndpoint(t)
+
+	c := httpc.New(
+		httpc.WithClient(client),
+		httpc.WithBaseURL(baseURL),
+		httpc.WithHeader("Authorization", "Bearer old"),
+	)
+
+	fetchAuthorization := func() string {
+		t.Helper()
+
+		var got infoResponse
+
+		if err := c.Fetch(t.Context(), http.MethodGet, "/", &got); err != nil {
+			t.Fatalf("failed to fetch: %v", err)
+		}
+
+		return got.Header.Get("Authorization")
+	}
+
+	if got, want := fetchAuthorization(), "Bearer old"; got != want {
+		t.Errorf("got Authorization %q, want %q", got, want)
+	}
+
+	c.Update(
+		httpc.WithClient(client),
+		httpc.WithBaseURL(baseURL),
+		httpc.WithHeader("Authorization", "Bearer new"),
+	)
+
+	if got, want := fetchAuthorization(), "Bearer new"; got != want {
+		t.Errorf("got Authorization %q, want %q", got, want)
+	}
+}
+
+func TestResolveReference(t *testing.T) {
+	reqURL, _ := url.Parse("https://example.com/api/v1/users?page=1")
+
+	testCases := []struct {
+		Name     string
+		Response *http.Response
+		Ref      string
+		Expected string
+	}{
+		{
+			Name:     "Absolute",
+			Response: &http.Response{Request: &http.Request{URL: reqURL}},
+			Ref:      "https://example.org/other",
+			Expected: "https://example.org/other",
+		},
+		{
+			Name:     "Absolute path",
+			Response: &http.Response{Request: &http.Request{URL: reqURL}},
+			Ref:      "/api/v1/users?page=2",
+			Expected: "https://example.com/api/v1/users?page=2",
+		},
+		{
+			Name:     "Relative path",
+			Response: &http.Response{Request: &http.Request{URL: reqURL}},
+			Ref:      "../v2/users",
+			Expected: "https://example.com/api/v2/users",
+		},
+		{
+			Name:     "No request",
+			Response: &http.Response{},
+			Ref:      "/api/v1/users?page=2",
+			Expected: "/api/v1/users?page=2",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			got, err := httpc.ResolveReference(testCase.Response, testCase.Ref)
+			if err != nil {
+				t.Fatalf("got error %v", err)
+			}
+
+			if got.String() != testCase.Expected {
+				t.Errorf("got %q, want %q", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestClient_InFlight(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	c := httpc.New(
+		httpc.WithClient(&http.Client{
+			Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				started <- struct{}{}
+				<-release
+
+				return &http.Response{
+					StatusCode: http.StatusNoContent,
+					Header:     make(http.Header),
+					Body:       http.NoBody,
+					Request:    req,
+				}, nil
+			}),
+		}),
+		httpc.WithBaseURL(&url.URL{Scheme: "https", Host: "example.com"}),
+	)
+
+	if got := c.InFlight(); len(got) != 0 {
+		t.Fatalf("got %d in-flight requests before fetch, want 0", len(got))
+	}
+
+	before := time.Now()
+
+	errs := make(chan error, 1)
+
+	go func() {
+		errs <- c.Fetch(t.Context(), http.MethodPut, "/product/{id}", nil,
+			httpc.WithPathValue("id", "1"),
+			httpc.WithLabel("feature", "test"))
+	}()
+
+	<-started
+
+	got := c.InFlight()
+	if len(got) != 1 {
+		t.Fatalf("got %d in-flight requests, want 1", len(got))
+	}
+
+	if got[0].Start.Before(before) || got[0].Start.After(time.Now()) {
+		t.Errorf("got start time %s, want time between %s and now", got[0].Start, before)
+	}
+
+	want := httpc.RequestInfo{
+		Method:      http.MethodPut,
+		URLTemplate: "https://example.com/product/{id}",
+		Start:       got[0].Start,
+		Attempt:     1,
+		Labels:      map[string]string{"feature": "test"},
+	}
+
+	if diff := cmp.Diff(want, got[0]); diff != "" {
+		t.Errorf("RequestInfo mismatch (-want +got):\n%s", diff)
+	}
+
+	close(release)
+
+	if err := <-errs; err != nil {
+		t.Fatalf("failed to fetch: %v", err)
+	}
+
+	if got := c.InFlight(); len(got) != 0 {
+		t.Fatalf("got %d in-flight requests after fetch, want 0", len(got))
+	}
+}
@@ -0,0 +1,165 @@
+package httpc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"sync"
+)
+
+// CompressedCache is a [Cache] that transparently compresses entries before passing them to another Cache.
+//
+// Entries are compressed using gzip. This trades CPU time for a smaller memory or disk footprint, which is especially
+// useful for large, repetitive payloads like JSON. gzip is used instead of a faster format like zstd, since it is
+// available in the standard library and does not require an additional dependency.
+//
+// If the underlying Cache implements [CacheWriter], CompressedCache compresses entries while they are written, so that
+// they are not buffered in memory before they are stored.
+//
+// Entries that can not be decompressed, for example because they were stored before the cache was wrapped, are
+// treated as missing.
+type CompressedCache struct {
+	cache Cache
+	level int
+}
+
+var (
+	_ Cache       = (*CompressedCache)(nil)
+	_ CacheWriter = (*CompressedCache)(nil)
+)
+
+// NewCompressedCache returns a new [CompressedCache] that stores compressed entries in the given [Cache].
+//
+// The level must be one of the compression levels accepted by [gzip.NewWriterLevel]. If level is 0,
+// [gzip.DefaultCompression] is used.
+func NewCompressedCache(c Cache, level int) *CompressedCache {
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+
+	if _, err := gzip.NewWriterLevel(io.Discard, level); err != nil {
+		panic(err)
+	}
+
+	return &CompressedCache{cache: c, level: level}
+}
+
+// gzipWriters pools writers by compression level, since creating a writer is expensive.
+var gzipWriters sync.Map
+
+// Get implements the [Cache] interface.
+func (c *CompressedCache) Get(key string) ([]byte, bool) {
+	data, ok := c.cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, false
+	}
+
+	value, err := io.ReadAll(r)
+	if err != nil {
+		return nil, false
+	}
+
+	return value, true
+}
+
+// Set implements the [Cache] interface.
+func (c *CompressedCache) Set(key string, value []byte) {
+	var buf bytes.Buffer
+
+	w, pool := c.gzipWriter(&buf)
+	defer func() {
+		w.Reset(io.Discard)
+		pool.Put(w)
+	}()
+
+	if _, err := w.Write(value); err != nil {
+		return
+	}
+
+	if err := w.Close(); err != nil {
+		return
+	}
+
+	c.cache.Set(key, buf.Bytes())
+}
+
+// NewWriter implements the [CacheWriter] interface.
+//
+// If the underlying cache does not implement [CacheWriter], an error is returned, so that entries are stored using
+// [CompressedCache.Set] instead.
+func (c *CompressedCache) NewWriter(key string) (BodyWriter, error) {
+	cw, ok := c.cache.(CacheWriter)
+	if !ok {
+		return nil, errors.New("github.com/nussjustin/httpc: underlying cache does not implement CacheWriter")
+	}
+
+	w, err := cw.NewWriter(key)
+	if err != nil {
+		return nil, err
+	}
+
+	gw, pool := c.gzipWriter(w)
+
+	return &compressedCacheWriter{w: w, gw: gw, pool: pool}, nil
+}
+
+// gzipWriter returns a pooled writer that writes to w and the pool it must be returned to.
+func (c *CompressedCache) gzipWriter(w io.Writer) (*gzip.Writer, *sync.Pool) {
+	v, _ := gzipWriters.LoadOrStore(c.level, &sync.Pool{})
+	pool := v.(*sync.Pool)
+
+	gw, _ := pool.Get().(*gzip.Writer)
+	if gw == nil {
+		// The level was already validated by NewCompressedCache.
+		gw, _ = gzip.NewWriterLevel(nil, c.level)
+	}
+
+	gw.Reset(w)
+
+	return gw, pool
+}
+
+// Delete implements the [Cache] interface.
+func (c *CompressedCache) Delete(key string) {
+	c.cache.Delete(key)
+}
+
+// compressedCacheWriter compresses an entry while it is written to the writer of the underlying cache.
+type compressedCacheWriter struct {
+	w    BodyWriter
+	gw   *gzip.Writer
+	pool *sync.Pool
+}
+
+func (w *compressedCacheWriter) Write(p []byte) (int, error) {
+	return w.gw.Write(p)
+}
+
+func (w *compressedCacheWriter) Commit() {
+	defer w.release()
+
+	if err := w.gw.Close(); err != nil {
+		w.w.Abort()
+		return
+	}
+
+	w.w.Commit()
+}
+
+func (w *compressedCacheWriter) Abort() {
+	defer w.release()
+
+	w.w.Abort()
+}
+
+// release returns the gzip writer to the pool.
+func (w *compressedCacheWriter) release() {
+	w.gw.Reset(io.Discard)
+	w.pool.Put(w.gw)
+}
@@ -0,0 +1,121 @@
+package httpc_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/nussjustin/httpc"
+)
+
+func TestCompressedCache(t *testing.T) {
+	inner := httpc.NewMemoryCache()
+
+	c := httpc.NewCompressedCache(inner, 0)
+
+	value := []byte(strings.Repeat(`{"name":"value"},`, 1000))
+
+	c.Set("key", value)
+
+	stored, ok := inner.Get("key")
+	if !ok {
+		t.Fatal("entry not stored in underlying cache")
+	}
+
+	if len(stored) >= len(value) {
+		t.Errorf("got stored size %d, want less than %d", len(stored), len(value))
+	}
+
+	got, ok := c.Get("key")
+	if !ok {
+		t.Fatal("entry not found")
+	}
+
+	if !bytes.Equal(got, value) {
+		t.Errorf("got %q, want %q", got, value)
+	}
+
+	c.Delete("key")
+
+	if _, ok := inner.Get("key"); ok {
+		t.Error("entry not deleted from underlying cache")
+	}
+
+	// Entries that were not compressed are treated as missing.
+	inner.Set("plain", value)
+
+	if _, ok := c.Get("plain"); ok {
+		t.Error("got uncompressed entry, want miss")
+	}
+}
+
+func TestCompressedCache_InvalidLevel(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic")
+		}
+	}()
+
+	httpc.NewCompressedCache(httpc.NewMemoryCache(), gzip.BestCompression+1)
+}
+
+func TestCompressedCache_WithCache(t *testing.T) {
+	srv, calls := cacheServer(t)
+
+	c := httpc.New(httpc.WithCache(httpc.NewCompressedCache(httpc.NewMemoryCache(), gzip.BestSpeed)))
+
+	for range 2 {
+		var got string
+
+		if err := c.Fetch(t.Context(), http.MethodGet, srv.URL+"/fresh", &got); err != nil {
+			t.Fatalf("got error %v", err)
+		}
+
+		if want := "fresh-1"; got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("got %d calls, want 1", got)
+	}
+}
+
+func TestCompressedCache_Writer(t *testing.T) {
+	srv, calls := cacheServer(t)
+
+	inner := &writerCache{MemoryCache: httpc.NewMemoryCache()}
+
+	c := httpc.New(httpc.WithCache(httpc.NewCompressedCache(inner, 0)))
+
+	for range 2 {
+		var got string
+
+		if err := c.Fetch(t.Context(), http.MethodGet, srv.URL+"/fresh", &got); err != nil {
+			t.Fatalf("got error %v", err)
+		}
+
+		if want := "fresh-1"; got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("got %d calls, want 1", got)
+	}
+
+	inner.mu.Lock()
+	written := inner.written.Bytes()
+	inner.mu.Unlock()
+
+	// Entries are written using the CacheWriter of the underlying cache and start with the gzip magic number.
+	if !bytes.HasPrefix(written, []byte{0x1f, 0x8b}) {
+		t.Errorf("got written data %q, want gzip compressed data", written)
+	}
+
+	if _, err := httpc.NewCompressedCache(httpc.NewMemoryCache(), 0).NewWriter("key"); err == nil {
+		t.Error("got no error for underlying cache without CacheWriter")
+	}
+}
@@ -0,0 +1,71 @@
+package httpc
+
+import (
+	"context"
+	"net/http"
+	"slices"
+)
+
+// Conditional holds a value together with the validators needed to conditionally request it again.
+//
+// See [FetchIfChanged] for details.
+type Conditional[T any] struct {
+	// Value is the last received value.
+	Value T
+
+	// ETag is the value of the ETag header of the last response, if any.
+	ETag string
+
+	// LastModified is the value of the Last-Modified header of the last response, if any.
+	LastModified string
+}
+
+// FetchIfChanged requests the given endpoint using a conditional GET request and returns the current value as well as
+// a boolean indicating whether the value changed.
+//
+// If prev contains an ETag or Last-Modified value from a previous response, the request will contain matching
+// If-None-Match and If-Modified-Since headers. If the server responds with 304 Not Modified, the value in prev is
+// returned together with false.
+//
+// Otherwise, the response is handled as with [Fetch], prev is updated with the new value and validators and the new
+// value is returned together with true.
+//
+// prev must not be nil. A zero Conditional can be used for the first request.
+func FetchIfChanged[T any](
+	ctx context.Context,
+	url string,
+	prev *Conditional[T],
+	opts ...FetchOption,
+) (T, bool, error) {
+	opts = slices.Concat(opts, []FetchOption{func(ctx *fetchContext) error {
+		if prev.ETag != "" {
+			ctx.Request.Header.Set("If-None-Match", prev.ETag)
+		}
+
+		if prev.LastModified != "" {
+			ctx.Request.Header.Set("If-Modified-Since", prev.LastModified)
+		}
+
+		return nil
+	}})
+
+	t, resp, err := FetchWithResponse[T](ctx, http.MethodGet, url, opts...)
+	if resp != nil {
+		defer discardBody(resp, nil)
+	}
+
+	if err != nil {
+		var zeroT T
+		return zeroT, false, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified {
+		return prev.Value, false, nil
+	}
+
+	prev.Value = t
+	prev.ETag = resp.Header.Get("ETag")
+	prev.LastModified = resp.Header.Get("Last-Modified")
+
+	return t, true, nil
+}
@@ -0,0 +1,69 @@
+package httpc_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/nussjustin/httpc"
+)
+
+func TestFetchIfChanged(t *testing.T) {
+	version := 1
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		etag := `"v` + strconv.Itoa(version) + `"`
+
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+
+		_, _ = w.Write([]byte(strconv.Itoa(version)))
+	}))
+	t.Cleanup(srv.Close)
+
+	var cond httpc.Conditional[int]
+
+	assert := func(wantValue int, wantChanged bool) {
+		t.Helper()
+
+		got, changed, err := httpc.FetchIfChanged(t.Context(), srv.URL, &cond)
+		if err != nil {
+			t.Fatalf("failed to fetch: %v", err)
+		}
+
+		if got != wantValue {
+			t.Errorf("got value %d, want %d", got, wantValue)
+		}
+
+		if changed != wantChanged {
+			t.Errorf("got changed %t, want %t", changed, wantChanged)
+		}
+
+		if cond.Value != wantValue {
+			t.Errorf("got stored value %d, want %d", cond.Value, wantValue)
+		}
+
+		if want := `"v` + strconv.Itoa(version) + `"`; cond.ETag != want {
+			t.Errorf("got stored ETag %q, want %q", cond.ETag, want)
+		}
+
+		if want := "Mon, 02 Jan 2006 15:04:05 GMT"; cond.LastModified != want {
+			t.Errorf("got stored Last-Modified %q, want %q", cond.LastModified, want)
+		}
+	}
+
+	assert(1, true)
+	assert(1, false)
+
+	version = 2
+
+	assert(2, true)
+	assert(2, false)
+}
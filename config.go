@@ -0,0 +1,329 @@
+package httpc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config declares the configuration of a [Client] created using [FromConfig].
+//
+// Config can be decoded from JSON and YAML using the names in the struct tags or loaded from environment variables
+// using [ConfigFromEnv]. Zero values keep the defaults of the Go standard library.
+type Config struct {
+	// BaseURL is the base URL used for all requests. See [WithBaseURL].
+	BaseURL string `json:"baseUrl" yaml:"baseUrl" env:"BASE_URL"`
+
+	// Headers contains headers that are set on all requests.
+	Headers map[string]string `json:"headers" yaml:"headers" env:"HEADERS"`
+
+	// Timeout limits the total time of each request, including reading the response body. See [http.Client.Timeout].
+	Timeout Duration `json:"timeout" yaml:"timeout" env:"TIMEOUT"`
+
+	// DialTimeout limits the time spent establishing new connections. See [net.Dialer.Timeout].
+	DialTimeout Duration `json:"dialTimeout" yaml:"dialTimeout" env:"DIAL_TIMEOUT"`
+
+	// TLSHandshakeTimeout limits the time spent on TLS handshakes. See [http.Transport.TLSHandshakeTimeout].
+	TLSHandshakeTimeout Duration `json:"tlsHandshakeTimeout" yaml:"tlsHandshakeTimeout" env:"TLS_HANDSHAKE_TIMEOUT"`
+
+	// HeaderTimeout limits the time spent waiting for the response headers after the request was sent. See
+	// [http.Transport.ResponseHeaderTimeout].
+	HeaderTimeout Duration `json:"headerTimeout" yaml:"headerTimeout" env:"HEADER_TIMEOUT"`
+
+	// MaxConnsPerHost limits the total number of connections per host. See [http.Transport.MaxConnsPerHost].
+	MaxConnsPerHost int `json:"maxConnsPerHost" yaml:"maxConnsPerHost" env:"MAX_CONNS_PER_HOST"`
+
+	// MaxIdleConnsPerHost limits the number of idle connections per host. See [http.Transport.MaxIdleConnsPerHost].
+	MaxIdleConnsPerHost int `json:"maxIdleConnsPerHost" yaml:"maxIdleConnsPerHost" env:"MAX_IDLE_CONNS_PER_HOST"`
+
+	// Proxy is the URL of the proxy used for all requests.
+	//
+	// If empty, the proxy is determined using the environment. See [http.ProxyFromEnvironment].
+	Proxy string `json:"proxy" yaml:"proxy" env:"PROXY"`
+
+	// Retry configures how failed requests are retried.
+	Retry RetryConfig `json:"retry" yaml:"retry" env:"RETRY"`
+
+	// TLS configures TLS connections.
+	TLS TLSConfig `json:"tls" yaml:"tls" env:"TLS"`
+}
+
+// RetryConfig configures retries for clients created using [FromConfig]. See [RetryPolicy] for details.
+type RetryConfig struct {
+	// MaxAttempts is the maximum number of times a request is sent. Requests are not retried if this is zero.
+	MaxAttempts int `json:"maxAttempts" yaml:"maxAttempts" env:"MAX_ATTEMPTS"`
+
+	// MaxRetryAfter is the maximum delay requested by a Retry-After header that is honored.
+	MaxRetryAfter Duration `json:"maxRetryAfter" yaml:"maxRetryAfter" env:"MAX_RETRY_AFTER"`
+}
+
+// TLSConfig configures TLS for clients created using [FromConfig].
+type TLSConfig struct {
+	// CAFile is the path to a PEM encoded file with certificate authorities used to verify servers.
+	//
+	// If empty, the certificate authorities of the system are used.
+	CAFile string `json:"caFile" yaml:"caFile" env:"CA_FILE"`
+
+	// CertFile and KeyFile are the paths to a PEM encoded client certificate and its private key.
+	//
+	// The files are reloaded when they change. See [CertificateFiles].
+	CertFile string `json:"certFile" yaml:"certFile" env:"CERT_FILE"`
+
+	// KeyFile is the path to the private key of the certificate in CertFile.
+	KeyFile string `json:"keyFile" yaml:"keyFile" env:"KEY_FILE"`
+
+	// ServerName overrides the name used to verify server certificates.
+	ServerName string `json:"serverName" yaml:"serverName" env:"SERVER_NAME"`
+
+	// MinVersion is the minimum TLS version, either "1.2" or "1.3".
+	MinVersion string `json:"minVersion" yaml:"minVersion" env:"MIN_VERSION"`
+
+	// InsecureSkipVerify disables the verification of server certificates. This should only be used for testing.
+	InsecureSkipVerify bool `json:"insecureSkipVerify" yaml:"insecureSkipVerify" env:"INSECURE_SKIP_VERIFY"`
+}
+
+// Duration is a [time.Duration] that is encoded as string, for example "1m30s", in configuration files.
+type Duration time.Duration
+
+// MarshalText implements the [encoding.TextMarshaler] interface.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// UnmarshalText implements the [encoding.TextUnmarshaler] interface.
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(v)
+	return nil
+}
+
+// FromConfig returns a new [Client] configured using the given [Config].
+//
+// The client uses a new [http.Client] with its own transport, which is based on a copy of [http.DefaultTransport]. Any
+// given options are applied after the options created from the configuration.
+func FromConfig(cfg Config, opts ...FetchOption) (*Client, error) {
+	transport, err := cfg.transport()
+	if err != nil {
+		return nil, fmt.Errorf("github.com/nussjustin/httpc: invalid config: %w", err)
+	}
+
+	configOpts := []FetchOption{
+		WithClient(&http.Client{Transport: transport, Timeout: time.Duration(cfg.Timeout)}),
+	}
+
+	if cfg.BaseURL != "" {
+		baseURL, err := url.Parse(cfg.BaseURL)
+		if err != nil {
+			return nil, fmt.Errorf("github.com/nussjustin/httpc: invalid config: base URL: %w", err)
+		}
+
+		configOpts = append(configOpts, WithBaseURL(baseURL))
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(cfg.Headers)) {
+		configOpts = append(configOpts, WithHeader(name, cfg.Headers[name]))
+	}
+
+	if cfg.Retry.MaxAttempts < 0 {
+		return nil, errors.New("github.com/nussjustin/httpc: invalid config: negative retry attempts")
+	}
+
+	if cfg.Retry.MaxAttempts > 0 {
+		configOpts = append(configOpts, WithRetry(RetryPolicy{
+			MaxAttempts:   cfg.Retry.MaxAttempts,
+			MaxRetryAfter: time.Duration(cfg.Retry.MaxRetryAfter),
+		}))
+	}
+
+	return New(append(configOpts, opts...)...), nil
+}
+
+// transport returns a new [*http.Transport] configured according to the config.
+func (cfg *Config) transport() (*http.Transport, error) {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+
+	if cfg.DialTimeout > 0 {
+		t.DialContext = (&net.Dialer{Timeout: time.Duration(cfg.DialTimeout), KeepAlive: 30 * time.Second}).DialContext
+	}
+
+	if cfg.TLSHandshakeTimeout > 0 {
+		t.TLSHandshakeTimeout = time.Duration(cfg.TLSHandshakeTimeout)
+	}
+
+	if cfg.HeaderTimeout > 0 {
+		t.ResponseHeaderTimeout = time.Duration(cfg.HeaderTimeout)
+	}
+
+	if cfg.MaxConnsPerHost > 0 {
+		t.MaxConnsPerHost = cfg.MaxConnsPerHost
+	}
+
+	if cfg.MaxIdleConnsPerHost > 0 {
+		t.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	}
+
+	if cfg.Proxy != "" {
+		proxyURL, err := url.Parse(cfg.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("proxy: %w", err)
+		}
+
+		t.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	tlsConfig, err := cfg.TLS.config()
+	if err != nil {
+		return nil, fmt.Errorf("tls: %w", err)
+	}
+
+	if tlsConfig != nil {
+		t.TLSClientConfig = tlsConfig
+	}
+
+	return t, nil
+}
+
+// config returns the [tls.Config] for the configuration or nil, if the defaults should be used.
+func (cfg *TLSConfig) config() (*tls.Config, error) {
+	if *cfg == (TLSConfig{}) {
+		return nil, nil
+	}
+
+	c := &tls.Config{
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+
+	switch cfg.MinVersion {
+	case "":
+	case "1.2":
+		c.MinVersion = tls.VersionTLS12
+	case "1.3":
+		c.MinVersion = tls.VersionTLS13
+	default:
+		return nil, fmt.Errorf("unsupported minimum version %q", cfg.MinVersion)
+	}
+
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, err
+		}
+
+		c.RootCAs = x509.NewCertPool()
+
+		if !c.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", cfg.CAFile)
+		}
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		files, err := NewCertificateFiles(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		c.GetClientCertificate = files.GetClientCertificate
+	}
+
+	return c, nil
+}
+
+// ConfigFromEnv returns a [Config] loaded from environment variables with the given prefix.
+//
+// The name of each variable is the prefix followed by the name in the env struct tag of the field, with the names of
+// nested fields separated by underscores. For example, with the prefix "API_", the base URL is read from API_BASE_URL
+// and the maximum number of attempts from API_RETRY_MAX_ATTEMPTS. Headers are read from a comma-separated list of
+// Name=Value pairs. Unset variables keep their zero value.
+func ConfigFromEnv(prefix string) (Config, error) {
+	var cfg Config
+
+	if err := configFromEnv(reflect.ValueOf(&cfg).Elem(), prefix); err != nil {
+		return Config{}, fmt.Errorf("github.com/nussjustin/httpc: invalid config: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// configFromEnv sets the fields of the struct v from environment variables with the given prefix.
+func configFromEnv(v reflect.Value, prefix string) error {
+	for i := range v.NumField() {
+		name := prefix + v.Type().Field(i).Tag.Get("env")
+
+		field := v.Field(i)
+
+		if field.Kind() == reflect.Struct {
+			if err := configFromEnv(field, name+"_"); err != nil {
+				return err
+			}
+			continue
+		}
+
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+
+		if err := setConfigField(field, value); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// setConfigField parses value and stores it in the field.
+func setConfigField(field reflect.Value, value string) error {
+	if u, ok := field.Addr().Interface().(*Duration); ok {
+		return u.UnmarshalText([]byte(value))
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(n))
+	case reflect.Map:
+		headers := make(map[string]string)
+
+		for pair := range strings.SplitSeq(value, ",") {
+			name, value, ok := strings.Cut(pair, "=")
+			if !ok {
+				return fmt.Errorf("invalid header %q", pair)
+			}
+
+			headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+		}
+
+		field.Set(reflect.ValueOf(headers))
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package httpc_test
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-json-experiment/json"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nussjustin/httpc"
+)
+
+func TestConfig_JSON(t *testing.T) {
+	var got httpc.Config
+
+	err := json.Unmarshal([]byte(`{
+		"baseUrl": "https://example.com/api/",
+		"headers": {"X-Custom": "value"},
+		"timeout": "30s",
+		"headerTimeout": "5s",
+		"retry": {"maxAttempts": 3, "maxRetryAfter": "1m"},
+		"tls": {"minVersion": "1.3"}
+	}`), &got)
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	want := httpc.Config{
+		BaseURL:       "https://example.com/api/",
+		Headers:       map[string]string{"X-Custom": "value"},
+		Timeout:       httpc.Duration(30 * time.Second),
+		HeaderTimeout: httpc.Duration(5 * time.Second),
+		Retry:         httpc.RetryConfig{MaxAttempts: 3, MaxRetryAfter: httpc.Duration(time.Minute)},
+		TLS:           httpc.TLSConfig{MinVersion: "1.3"},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("config mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("TEST_BASE_URL", "https://example.com/")
+	t.Setenv("TEST_HEADERS", "X-A=a, X-B=b")
+	t.Setenv("TEST_DIAL_TIMEOUT", "2s")
+	t.Setenv("TEST_MAX_CONNS_PER_HOST", "10")
+	t.Setenv("TEST_RETRY_MAX_ATTEMPTS", "2")
+	t.Setenv("TEST_TLS_INSECURE_SKIP_VERIFY", "true")
+	t.Setenv("OTHER_PROXY", "http://proxy.example.com/")
+
+	got, err := httpc.ConfigFromEnv("TEST_")
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	want := httpc.Config{
+		BaseURL:         "https://example.com/",
+		Headers:         map[string]string{"X-A": "a", "X-B": "b"},
+		DialTimeout:     httpc.Duration(2 * time.Second),
+		MaxConnsPerHost: 10,
+		Retry:           httpc.RetryConfig{MaxAttempts: 2},
+		TLS:             httpc.TLSConfig{InsecureSkipVerify: true},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("config mismatch (-want +got):\n%s", diff)
+	}
+
+	t.Setenv("TEST_TIMEOUT", "soon")
+
+	if _, err := httpc.ConfigFromEnv("TEST_"); err == nil {
+		t.Error("got no error for invalid duration")
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	var calls atomic.Int64
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(r.URL.Path + " " + r.Header.Get("X-Custom")))
+	}))
+	t.Cleanup(srv.Close)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(caFile, caPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := httpc.FromConfig(httpc.Config{
+		BaseURL: srv.URL + "/api/",
+		Headers: map[string]string{"X-Custom": "value"},
+		Timeout: httpc.Duration(5 * time.Second),
+		Retry:   httpc.RetryConfig{MaxAttempts: 2},
+		TLS:     httpc.TLSConfig{CAFile: caFile, MinVersion: "1.2"},
+	})
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	var got string
+
+	if err := c.Fetch(t.Context(), http.MethodGet, "users", &got); err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	if want := "/api/users value"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if got, want := calls.Load(), int64(2); got != want {
+		t.Errorf("got %d calls, want %d", got, want)
+	}
+
+	for name, cfg := range map[string]httpc.Config{
+		"Invalid proxy":       {Proxy: "http://[::1"},
+		"Invalid TLS version": {TLS: httpc.TLSConfig{MinVersion: "1.0"}},
+		"Missing CA file":     {TLS: httpc.TLSConfig{CAFile: filepath.Join(t.TempDir(), "missing.pem")}},
+		"Negative attempts":   {Retry: httpc.RetryConfig{MaxAttempts: -1}},
+	} {
+		if _, err := httpc.FromConfig(cfg); err == nil {
+			t.Errorf("%s: got no error", name)
+		}
+	}
+}
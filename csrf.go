@@ -0,0 +1,252 @@
+package httpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// CSRFConfig configures how [WithCSRF] obtains and sends CSRF tokens.
+type CSRFConfig struct {
+	// URL is requested using GET to obtain a token.
+	//
+	// Relative URLs are resolved against the URL of the request that needs the token.
+	URL string
+
+	// Cookie is the name of the cookie that contains the token.
+	//
+	// The cookie is looked for in the response to the priming request and, if the client has a cookie jar, in the jar.
+	Cookie string
+
+	// Extract extracts the token from the response to the priming request, for example from a meta tag or a JSON
+	// field in the body.
+	//
+	// Extract is only used if Cookie is empty.
+	Extract func(resp *http.Response) (string, error)
+
+	// Header is the name of the header used to send the token.
+	//
+	// If both Header and FormField are empty, the token is sent using the X-CSRF-Token header.
+	Header string
+
+	// FormField is the name of the form field used to send the token.
+	//
+	// The field is only added to requests with a body of type application/x-www-form-urlencoded.
+	FormField string
+}
+
+// ErrNoCSRFToken is returned when no CSRF token could be obtained from the response to the priming request.
+var ErrNoCSRFToken = errors.New("github.com/nussjustin/httpc: no CSRF token found")
+
+// WithCSRF adds a CSRF token to mutating requests, that is requests using methods other than GET, HEAD, OPTIONS and
+// TRACE.
+//
+// The token is obtained by a priming GET request as configured by cfg and cached for later requests to the same
+// origin, that is the same scheme, host and port, as the priming request. Cookies set by the
+// priming request are stored in the cookie jar of the client, if any, as is needed for double-submit cookies. If a
+// request is answered with 403 Forbidden or 419, a new token is obtained and the request is sent again once.
+//
+// Requests with a body can only be sent again if [http.Request.GetBody] is set. Otherwise, the response is returned
+// as is.
+//
+// Since tokens are shared only by requests using the same option, the returned option should be created once and
+// reused, for example by passing it to [New].
+//
+// WithCSRF panics if neither cfg.Cookie nor cfg.Extract is set.
+func WithCSRF(cfg CSRFConfig) FetchOption {
+	if cfg.Cookie == "" && cfg.Extract == nil {
+		panic(errors.New("CSRF config needs either Cookie or Extract"))
+	}
+
+	if cfg.Header == "" && cfg.FormField == "" {
+		cfg.Header = "X-CSRF-Token"
+	}
+
+	c := &csrf{cfg: cfg}
+
+	return func(ctx *fetchContext) error {
+		ctx.CSRF = c
+		return nil
+	}
+}
+
+// applyCSRF replaces the client with one that adds CSRF tokens to requests, if configured.
+func (ctx *fetchContext) applyCSRF() {
+	if ctx.CSRF == nil {
+		return
+	}
+
+	client := ctx.Client
+
+	ctx.wrapTransport(func(rt http.RoundTripper) http.RoundTripper {
+		return &csrfTransport{next: rt, csrf: ctx.CSRF, client: client}
+	})
+}
+
+type csrf struct {
+	cfg CSRFConfig
+
+	mu     sync.Mutex
+	tokens map[string]*tokenCache
+}
+
+// tokensFor returns the cache for tokens obtained using the given priming URL.
+//
+// Caches are keyed by the origin of the priming URL, so that tokens are never sent to other origins.
+func (c *csrf) tokensFor(u *url.URL) *tokenCache {
+	key := strings.ToLower(u.Scheme) + "://" + strings.ToLower(u.Hostname()) + ":" + urlPort(u)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if tokens, ok := c.tokens[key]; ok {
+		return tokens
+	}
+
+	if c.tokens == nil {
+		c.tokens = make(map[string]*tokenCache)
+	}
+
+	tokens := &tokenCache{source: TokenSourceFunc(func(ctx context.Context) (string, error) {
+		return c.prime(ctx, u)
+	})}
+
+	c.tokens[key] = tokens
+	return tokens
+}
+
+// csrfClientKey is used to pass the client used for priming requests to [csrf.prime].
+type csrfClientKey struct{}
+
+// prime performs a priming request to the given URL and returns the token.
+func (c *csrf) prime(ctx context.Context, u *url.URL) (string, error) {
+	client := ctx.Value(csrfClientKey{}).(*http.Client)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer discardBody(resp, nil)
+
+	if c.cfg.Cookie == "" {
+		return c.cfg.Extract(resp)
+	}
+
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == c.cfg.Cookie && cookie.Value != "" {
+			return cookie.Value, nil
+		}
+	}
+
+	if client.Jar != nil {
+		for _, cookie := range client.Jar.Cookies(u) {
+			if cookie.Name == c.cfg.Cookie && cookie.Value != "" {
+				return cookie.Value, nil
+			}
+		}
+	}
+
+	return "", ErrNoCSRFToken
+}
+
+type csrfTransport struct {
+	next   http.RoundTripper
+	csrf   *csrf
+	client *http.Client
+}
+
+func (t *csrfTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return t.next.RoundTrip(req)
+	}
+
+	primeURL, err := req.URL.Parse(t.csrf.cfg.URL)
+	if err != nil {
+		closeRequestBody(req)
+		return nil, err
+	}
+
+	tokens := t.csrf.tokensFor(primeURL)
+	ctx := context.WithValue(req.Context(), csrfClientKey{}, t.client)
+
+	token, err := tokens.get(ctx)
+	if err != nil {
+		closeRequestBody(req)
+		return nil, err
+	}
+
+	// Keep the original request, so that it can be rewound for a retry.
+	first, err := t.withToken(req.Clone(req.Context()), token)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(first)
+	if err != nil || (resp.StatusCode != http.StatusForbidden && resp.StatusCode != 419) {
+		return resp, err
+	}
+
+	retry, ok := rewindRequest(req)
+	if !ok {
+		return resp, nil
+	}
+
+	discardBody(resp, nil)
+
+	if token, err = tokens.refresh(ctx, token); err != nil {
+		closeRequestBody(retry)
+		return nil, err
+	}
+
+	if retry, err = t.withToken(retry, token); err != nil {
+		return nil, err
+	}
+
+	return t.next.RoundTrip(retry)
+}
+
+// withToken adds the token to req, which must be a copy of the original request.
+func (t *csrfTransport) withToken(req *http.Request, token string) (*http.Request, error) {
+	if t.csrf.cfg.Header != "" {
+		req.Header.Set(t.csrf.cfg.Header, token)
+	}
+
+	if t.csrf.cfg.FormField == "" || req.Body == nil || req.Body == http.NoBody ||
+		!strings.HasPrefix(req.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		return req, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	closeRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil, err
+	}
+
+	form.Set(t.csrf.cfg.FormField, token)
+
+	encoded := []byte(form.Encode())
+
+	req.Body = io.NopCloser(bytes.NewReader(encoded))
+	req.ContentLength = int64(len(encoded))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(encoded)), nil
+	}
+
+	return req, nil
+}
@@ -0,0 +1,159 @@
+package httpc_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/nussjustin/httpc"
+)
+
+func csrfServer(t *testing.T) (srv *httptest.Server, primes *atomic.Int64, rotate func()) {
+	t.Helper()
+
+	primes = new(atomic.Int64)
+
+	var token atomic.Value
+	token.Store("token-1")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /login", func(w http.ResponseWriter, r *http.Request) {
+		primes.Add(1)
+
+		http.SetCookie(w, &http.Cookie{Name: "csrf", Value: token.Load().(string), Path: "/"})
+		_, _ = w.Write([]byte(`<meta name="csrf-token" content="` + token.Load().(string) + `">`))
+	})
+	mux.HandleFunc("/api", func(w http.ResponseWriter, r *http.Request) {
+		got := r.Header.Get("X-CSRF-Token")
+		if got == "" {
+			got = r.PostFormValue("_csrf")
+		}
+
+		if r.Method != http.MethodGet && got != token.Load().(string) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	srv = httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return srv, primes, func() { token.Store("token-2") }
+}
+
+func TestWithCSRF(t *testing.T) {
+	srv, primes, rotate := csrfServer(t)
+
+	jar, _ := cookiejar.New(nil)
+
+	c := httpc.New(
+		httpc.WithClient(&http.Client{Jar: jar}),
+		httpc.WithCSRF(httpc.CSRFConfig{URL: "/login", Cookie: "csrf"}),
+	)
+
+	if err := c.Fetch(t.Context(), http.MethodGet, srv.URL+"/api", nil); err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	if got := primes.Load(); got != 0 {
+		t.Errorf("got %d priming requests for GET, want 0", got)
+	}
+
+	for range 2 {
+		if err := c.Fetch(t.Context(), http.MethodPost, srv.URL+"/api", nil); err != nil {
+			t.Fatalf("got error %v", err)
+		}
+	}
+
+	if got := primes.Load(); got != 1 {
+		t.Errorf("got %d priming requests, want 1", got)
+	}
+
+	srvURL, _ := url.Parse(srv.URL)
+
+	if cookies := jar.Cookies(srvURL); len(cookies) != 1 || cookies[0].Value != "token-1" {
+		t.Errorf("got cookies %v, want csrf cookie in jar", cookies)
+	}
+
+	rotate()
+
+	if err := c.Fetch(t.Context(), http.MethodDelete, srv.URL+"/api", nil); err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	if got := primes.Load(); got != 2 {
+		t.Errorf("got %d priming requests, want 2", got)
+	}
+}
+
+func TestWithCSRF_MultipleOrigins(t *testing.T) {
+	srvA, primesA, _ := csrfServer(t)
+	srvB, primesB, rotateB := csrfServer(t)
+
+	// Use different tokens for both servers.
+	rotateB()
+
+	c := httpc.New(httpc.WithCSRF(httpc.CSRFConfig{URL: "/login", Cookie: "csrf"}))
+
+	for range 2 {
+		for _, srv := range []*httptest.Server{srvA, srvB} {
+			if err := c.Fetch(t.Context(), http.MethodPost, srv.URL+"/api", nil); err != nil {
+				t.Fatalf("got error %v", err)
+			}
+		}
+	}
+
+	if got := primesA.Load(); got != 1 {
+		t.Errorf("got %d priming requests for first server, want 1", got)
+	}
+
+	if got := primesB.Load(); got != 1 {
+		t.Errorf("got %d priming requests for second server, want 1", got)
+	}
+}
+
+func TestWithCSRF_FormField(t *testing.T) {
+	srv, _, _ := csrfServer(t)
+
+	extract := func(resp *http.Response) (string, error) {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return "", err
+		}
+
+		_, token, ok := strings.Cut(string(body), `content="`)
+		if !ok {
+			return "", errors.New("no token")
+		}
+
+		token, _, _ = strings.Cut(token, `"`)
+		return token, nil
+	}
+
+	c := httpc.New(httpc.WithCSRF(httpc.CSRFConfig{URL: "/login", Extract: extract, FormField: "_csrf"}))
+
+	err := c.Fetch(t.Context(), http.MethodPost, srv.URL+"/api", nil,
+		httpc.WithHeader("Content-Type", "application/x-www-form-urlencoded"),
+		httpc.WithBody(strings.NewReader(url.Values{"name": {"value"}}.Encode())))
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+}
+
+func TestWithCSRF_NoToken(t *testing.T) {
+	srv, _, _ := csrfServer(t)
+
+	c := httpc.New(httpc.WithCSRF(httpc.CSRFConfig{URL: "/login", Cookie: "missing"}))
+
+	if err := c.Fetch(t.Context(), http.MethodPost, srv.URL+"/api", nil); !errors.Is(err, httpc.ErrNoCSRFToken) {
+		t.Errorf("got error %v, want %v", err, httpc.ErrNoCSRFToken)
+	}
+}
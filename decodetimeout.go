@@ -0,0 +1,84 @@
+package httpc
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrDecodeTimeout is returned when reading the response body took longer than allowed by [WithDecodeTimeout].
+var ErrDecodeTimeout = errors.New("github.com/nussjustin/httpc: decode timeout")
+
+// WithDecodeTimeout limits the time spent reading and decoding the response body to d.
+//
+// The timeout starts once the response headers were received and is independent of the request context. This can be
+// used to protect against servers that send the body very slowly, without limiting the time needed to connect to the
+// server and wait for the response.
+//
+// Once the timeout expires, the body is closed and reads from the body fail with [ErrDecodeTimeout]. This also applies
+// to bodies passed on to the caller, for example when using [ReadCloserHandler] or [WithKeepBodyOpen].
+func WithDecodeTimeout(d time.Duration) FetchOption {
+	if d <= 0 {
+		panic(errors.New("d must be positive"))
+	}
+
+	return func(ctx *fetchContext) error {
+		ctx.DecodeTimeout = d
+		return nil
+	}
+}
+
+// limitDecodeTime wraps the response body so that it is closed once DecodeTimeout has passed.
+func (ctx *fetchContext) limitDecodeTime(resp *http.Response) {
+	b := &deadlineBody{ReadCloser: resp.Body}
+	b.timer = time.AfterFunc(ctx.DecodeTimeout, b.expire)
+	resp.Body = b
+}
+
+// deadlineBody is a response body that fails with [ErrDecodeTimeout] once its deadline has passed.
+type deadlineBody struct {
+	io.ReadCloser
+
+	timer     *time.Timer
+	expired   atomic.Bool
+	closeOnce sync.Once
+	closeErr  error
+}
+
+func (b *deadlineBody) Read(p []byte) (int, error) {
+	if b.expired.Load() {
+		return 0, ErrDecodeTimeout
+	}
+
+	n, err := b.ReadCloser.Read(p)
+
+	// Closing the body interrupts pending reads, which then fail with an error specific to the underlying body.
+	if err != nil && b.expired.Load() {
+		err = ErrDecodeTimeout
+	}
+
+	return n, err
+}
+
+func (b *deadlineBody) Close() error {
+	b.timer.Stop()
+	return b.close()
+}
+
+// expire is called once the deadline has passed.
+func (b *deadlineBody) expire() {
+	b.expired.Store(true)
+	_ = b.close()
+}
+
+// close closes the underlying body once.
+func (b *deadlineBody) close() error {
+	b.closeOnce.Do(func() {
+		b.closeErr = b.ReadCloser.Close()
+	})
+
+	return b.closeErr
+}
@@ -0,0 +1,70 @@
+package httpc_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nussjustin/httpc"
+)
+
+func TestWithDecodeTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		_, _ = w.Write([]byte(`{"key":`))
+
+		if r.URL.Path == "/slow" {
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+			return
+		}
+
+		_, _ = w.Write([]byte(`1}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	t.Run("Success", func(t *testing.T) {
+		got, err := httpc.Fetch[map[string]int](t.Context(), http.MethodGet, srv.URL+"/fast",
+			httpc.WithDecodeTimeout(time.Second))
+		if err != nil {
+			t.Fatalf("got error %v", err)
+		}
+
+		if got["key"] != 1 {
+			t.Errorf("got %v, want key 1", got)
+		}
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		start := time.Now()
+
+		_, err := httpc.Fetch[map[string]int](t.Context(), http.MethodGet, srv.URL+"/slow",
+			httpc.WithDecodeTimeout(50*time.Millisecond))
+		if !errors.Is(err, httpc.ErrDecodeTimeout) {
+			t.Errorf("got error %v, want %v", err, httpc.ErrDecodeTimeout)
+		}
+
+		if took := time.Since(start); took > 5*time.Second {
+			t.Errorf("request took %s", took)
+		}
+	})
+
+	t.Run("Keep body open", func(t *testing.T) {
+		_, resp, err := httpc.FetchWithResponse[any](t.Context(), http.MethodGet, srv.URL+"/slow",
+			httpc.WithDecodeTimeout(50*time.Millisecond),
+			httpc.WithKeepBodyOpen())
+		if err != nil {
+			t.Fatalf("got error %v", err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		time.Sleep(100 * time.Millisecond)
+
+		if _, err := resp.Body.Read(make([]byte, 1)); !errors.Is(err, httpc.ErrDecodeTimeout) {
+			t.Errorf("got error %v, want %v", err, httpc.ErrDecodeTimeout)
+		}
+	})
+}
@@ -0,0 +1,244 @@
+package httpc
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// WithDigestAuth enables HTTP Digest access authentication as defined by RFC 7616.
+//
+// If a request is answered with 401 Unauthorized and a Digest challenge, the request is sent again once using the
+// given credentials. The challenge is remembered, so that later requests using the same option can send credentials
+// right away, until the server sends a new challenge.
+//
+// The algorithms MD5, SHA-256 and SHA-512-256 as well as their session variants are supported. Only the "auth"
+// quality of protection is supported.
+//
+// Requests with a body can only be sent again if [http.Request.GetBody] is set. Otherwise, the 401 response is
+// returned as is.
+//
+// Since the challenge is shared only by requests using the same option, the returned option should be created once
+// and reused, for example by passing it to [New].
+func WithDigestAuth(username, password string) FetchOption {
+	d := &digestAuth{username: username, password: password}
+
+	return func(ctx *fetchContext) error {
+		ctx.DigestAuth = d
+		return nil
+	}
+}
+
+// applyDigestAuth replaces the client with one that handles Digest challenges, if configured.
+func (ctx *fetchContext) applyDigestAuth() {
+	if ctx.DigestAuth == nil || ctx.PresignedURL {
+		return
+	}
+
+	ctx.wrapTransport(func(rt http.RoundTripper) http.RoundTripper {
+		return &digestTransport{next: rt, auth: ctx.DigestAuth}
+	})
+}
+
+type digestChallenge struct {
+	realm     string
+	nonce     string
+	opaque    string
+	algorithm string
+	qop       bool
+	stale     bool
+	userhash  bool
+}
+
+// digestAlgorithms contains the supported algorithms, ordered from least to most preferred.
+var digestAlgorithms = []string{"MD5", "SHA-256", "SHA-512-256"}
+
+// parseDigestChallenge returns the most preferred supported Digest challenge in the given header values.
+func parseDigestChallenge(values []string) (digestChallenge, bool) {
+	var (
+		best     digestChallenge
+		bestRank = -1
+	)
+
+	for _, c := range parseChallenges(values) {
+		if !strings.EqualFold(c.Scheme, "Digest") || c.Params["nonce"] == "" {
+			continue
+		}
+
+		algorithm := c.Params["algorithm"]
+		if algorithm == "" {
+			algorithm = "MD5"
+		}
+
+		rank := slices.IndexFunc(digestAlgorithms, func(s string) bool {
+			return strings.EqualFold(s, strings.TrimSuffix(strings.ToUpper(algorithm), "-SESS"))
+		})
+		if rank <= bestRank {
+			continue
+		}
+
+		qop, hasQop := c.Params["qop"]
+		if hasQop && !slices.ContainsFunc(strings.Split(qop, ","), func(s string) bool {
+			return strings.TrimSpace(s) == "auth"
+		}) {
+			continue
+		}
+
+		best = digestChallenge{
+			realm:     c.Params["realm"],
+			nonce:     c.Params["nonce"],
+			opaque:    c.Params["opaque"],
+			algorithm: algorithm,
+			qop:       hasQop,
+			stale:     strings.EqualFold(c.Params["stale"], "true"),
+			userhash:  strings.EqualFold(c.Params["userhash"], "true"),
+		}
+		bestRank = rank
+	}
+
+	return best, bestRank >= 0
+}
+
+type digestAuth struct {
+	username string
+	password string
+
+	mu        sync.Mutex
+	challenge digestChallenge
+	nc        uint32
+}
+
+// setChallenge replaces the current challenge and resets the nonce count.
+func (d *digestAuth) setChallenge(c digestChallenge) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.challenge = c
+	d.nc = 0
+}
+
+// authorization returns the Authorization header for the given request based on the current challenge as well as the
+// used nonce.
+func (d *digestAuth) authorization(req *http.Request) (header string, nonce string, ok bool) {
+	d.mu.Lock()
+	c := d.challenge
+	d.nc++
+	nc := d.nc
+	d.mu.Unlock()
+
+	if c.nonce == "" {
+		return "", "", false
+	}
+
+	newHash := digestHash(c.algorithm)
+
+	h := func(parts ...string) string {
+		hh := newHash()
+		_, _ = io.WriteString(hh, strings.Join(parts, ":"))
+		return hex.EncodeToString(hh.Sum(nil))
+	}
+
+	cnonce := rand.Text()
+	uri := req.URL.RequestURI()
+
+	ha1 := h(d.username, c.realm, d.password)
+	if strings.HasSuffix(strings.ToUpper(c.algorithm), "-SESS") {
+		ha1 = h(ha1, c.nonce, cnonce)
+	}
+
+	ha2 := h(req.Method, uri)
+
+	username := d.username
+	if c.userhash {
+		username = h(d.username, c.realm)
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Digest username=%s, realm=%s, uri=%s, algorithm=%s, nonce=%s",
+		quoteDigest(username), quoteDigest(c.realm), quoteDigest(uri), c.algorithm, quoteDigest(c.nonce))
+
+	if c.qop {
+		ncValue := fmt.Sprintf("%08x", nc)
+
+		fmt.Fprintf(&b, ", nc=%s, cnonce=%s, qop=auth, response=%s",
+			ncValue, quoteDigest(cnonce), quoteDigest(h(ha1, c.nonce, ncValue, cnonce, "auth", ha2)))
+	} else {
+		fmt.Fprintf(&b, ", response=%s", quoteDigest(h(ha1, c.nonce, ha2)))
+	}
+
+	if c.opaque != "" {
+		fmt.Fprintf(&b, ", opaque=%s", quoteDigest(c.opaque))
+	}
+
+	if c.userhash {
+		b.WriteString(", userhash=true")
+	}
+
+	return b.String(), c.nonce, true
+}
+
+func digestHash(algorithm string) func() hash.Hash {
+	switch strings.TrimSuffix(strings.ToUpper(algorithm), "-SESS") {
+	case "SHA-256":
+		return sha256.New
+	case "SHA-512-256":
+		return sha512.New512_256
+	default:
+		return md5.New
+	}
+}
+
+func quoteDigest(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+type digestTransport struct {
+	next http.RoundTripper
+	auth *digestAuth
+}
+
+func (t *digestTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	first := req
+
+	header, usedNonce, authorized := t.auth.authorization(req)
+	if authorized {
+		first = req.Clone(req.Context())
+		first.Header.Set("Authorization", header)
+	}
+
+	resp, err := t.next.RoundTrip(first)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	c, ok := parseDigestChallenge(resp.Header.Values("WWW-Authenticate"))
+
+	// If the credentials were rejected for the current nonce, sending them again will not help.
+	if !ok || (authorized && c.nonce == usedNonce && !c.stale) {
+		return resp, nil
+	}
+
+	retry, ok := rewindRequest(req)
+	if !ok {
+		return resp, nil
+	}
+
+	discardBody(resp, nil)
+
+	t.auth.setChallenge(c)
+
+	header, _, _ = t.auth.authorization(retry)
+	retry.Header.Set("Authorization", header)
+
+	return t.next.RoundTrip(retry)
+}
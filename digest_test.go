@@ -0,0 +1,171 @@
+package httpc_test
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/nussjustin/httpc"
+)
+
+var digestParamRegexp = regexp.MustCompile(`(\w+)=(?:"((?:[^"\\]|\\.)*)"|([^,\s]*))`)
+
+type digestServer struct {
+	algorithm string
+	newHash   func() hash.Hash
+	qop       bool
+
+	mu       sync.Mutex
+	nonce    string
+	requests int
+	rejected int
+}
+
+func (s *digestServer) h(parts ...string) string {
+	hh := s.newHash()
+	hh.Write([]byte(strings.Join(parts, ":")))
+	return hex.EncodeToString(hh.Sum(nil))
+}
+
+func (s *digestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests++
+
+	params := make(map[string]string)
+	if auth, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Digest "); ok {
+		for _, m := range digestParamRegexp.FindAllStringSubmatch(auth, -1) {
+			params[m[1]] = m[2] + m[3]
+		}
+	}
+
+	ha1 := s.h("user", "test", "pass")
+	ha2 := s.h(r.Method, r.URL.RequestURI())
+
+	var want string
+	if s.qop {
+		want = s.h(ha1, s.nonce, params["nc"], params["cnonce"], "auth", ha2)
+	} else {
+		want = s.h(ha1, s.nonce, ha2)
+	}
+
+	if params["response"] == "" || params["response"] != want || params["nonce"] != s.nonce ||
+		params["uri"] != r.URL.RequestURI() || params["opaque"] != "opaque" {
+		s.rejected++
+
+		challenge := `Digest realm="test", nonce="` + s.nonce + `", opaque="opaque", algorithm=` + s.algorithm
+		if s.qop {
+			challenge += `, qop="auth,auth-int"`
+		}
+
+		w.Header().Add("WWW-Authenticate", `Negotiate abc==, Basic realm="test"`)
+		w.Header().Add("WWW-Authenticate", challenge)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	_, _ = w.Write([]byte("OK"))
+}
+
+func TestWithDigestAuth(t *testing.T) {
+	testCases := []struct {
+		Name      string
+		Algorithm string
+		NewHash   func() hash.Hash
+		Qop       bool
+	}{
+		{Name: "MD5", Algorithm: "MD5", NewHash: md5.New, Qop: true},
+		{Name: "SHA-256", Algorithm: "SHA-256", NewHash: sha256.New, Qop: true},
+		{Name: "Without qop", Algorithm: "MD5", NewHash: md5.New},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			handler := &digestServer{
+				algorithm: testCase.Algorithm,
+				newHash:   testCase.NewHash,
+				qop:       testCase.Qop,
+				nonce:     "nonce1",
+			}
+
+			srv := httptest.NewServer(handler)
+			t.Cleanup(srv.Close)
+
+			c := httpc.New(httpc.WithDigestAuth("user", "pass"))
+
+			fetch := func() {
+				t.Helper()
+
+				var got string
+				if err := c.Fetch(t.Context(), http.MethodPost, srv.URL+"/path?query=1", &got,
+					httpc.WithBody(strings.NewReader("body"))); err != nil {
+					t.Fatalf("got error %v", err)
+				}
+
+				if got != "OK" {
+					t.Errorf("got %q, want %q", got, "OK")
+				}
+			}
+
+			// First request is challenged
+			fetch()
+
+			if got, want := handler.rejected, 1; got != want {
+				t.Errorf("got %d rejected requests, want %d", got, want)
+			}
+
+			// Second request reuses the challenge
+			fetch()
+
+			if got, want := handler.rejected, 1; got != want {
+				t.Errorf("got %d rejected requests, want %d", got, want)
+			}
+
+			// Nonce changed
+			handler.mu.Lock()
+			handler.nonce = "nonce2"
+			handler.mu.Unlock()
+
+			fetch()
+
+			if got, want := handler.rejected, 2; got != want {
+				t.Errorf("got %d rejected requests, want %d", got, want)
+			}
+
+			if got, want := handler.requests, 5; got != want {
+				t.Errorf("got %d requests, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestWithDigestAuth_WrongCredentials(t *testing.T) {
+	handler := &digestServer{algorithm: "MD5", newHash: md5.New, qop: true, nonce: "nonce"}
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	c := httpc.New(httpc.WithDigestAuth("user", "wrong"))
+
+	var got string
+	if err := c.Fetch(t.Context(), http.MethodGet, srv.URL, &got); err == nil {
+		t.Fatal("got no error")
+	}
+
+	if err := c.Fetch(t.Context(), http.MethodGet, srv.URL, &got); err == nil {
+		t.Fatal("got no error")
+	}
+
+	// The first request is sent twice, the second is rejected right away.
+	if got, want := handler.requests, 3; got != want {
+		t.Errorf("got %d requests, want %d", got, want)
+	}
+}
@@ -0,0 +1,305 @@
+package httpc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/go-json-experiment/json"
+)
+
+// DiskCache is a [Cache] that stores entries as files in a directory, so that they can be reused across runs.
+//
+// Entries are stored content-addressed, so that identical entries are only stored once, together with an index that
+// maps keys to entries. If the total size of all entries exceeds the configured maximum, the least recently used
+// entries are removed.
+//
+// DiskCache implements [CacheWriter], so that responses stored via [WithCache] are written to disk while they are read.
+//
+// The index is written when entries are added or removed. Access times updated by [DiskCache.Get] are only persisted
+// with the next write.
+//
+// A directory must not be used by multiple DiskCache instances at the same time, including instances in other
+// processes.
+type DiskCache struct {
+	dir     string
+	maxSize int64
+
+	mu    sync.Mutex
+	index map[string]diskCacheEntry
+	refs  map[string]int
+	size  int64
+}
+
+var (
+	_ Cache       = (*DiskCache)(nil)
+	_ CacheWriter = (*DiskCache)(nil)
+)
+
+type diskCacheEntry struct {
+	Hash     string    `json:"hash"`
+	Size     int64     `json:"size"`
+	Accessed time.Time `json:"accessed"`
+}
+
+// diskCacheIndex is the name of the index file.
+const diskCacheIndex = "index.json"
+
+// NewDiskCache returns a new [DiskCache] using the given directory, which is created if it does not exist.
+//
+// Existing entries in the directory are reused. If maxSize is greater than zero, the total size of all entries is
+// limited to maxSize bytes.
+func NewDiskCache(dir string, maxSize int64) (*DiskCache, error) {
+	if err := os.MkdirAll(filepath.Join(dir, "blobs"), 0o700); err != nil {
+		return nil, err
+	}
+
+	d := &DiskCache{
+		dir:     dir,
+		maxSize: maxSize,
+		index:   make(map[string]diskCacheEntry),
+		refs:    make(map[string]int),
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, diskCacheIndex))
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return nil, err
+	default:
+		// A broken index is treated as empty, the cache is rebuilt over time.
+		_ = json.Unmarshal(data, &d.index)
+	}
+
+	for _, entry := range d.index {
+		if d.refs[entry.Hash]++; d.refs[entry.Hash] == 1 {
+			d.size += entry.Size
+		}
+	}
+
+	return d, nil
+}
+
+// Get implements the [Cache] interface.
+func (d *DiskCache) Get(key string) ([]byte, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	entry, ok := d.index[key]
+	if !ok {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(d.blobPath(entry.Hash))
+	if err != nil {
+		d.remove(key)
+		_ = d.writeIndex()
+		return nil, false
+	}
+
+	entry.Accessed = time.Now()
+	d.index[key] = entry
+
+	return data, true
+}
+
+// Set implements the [Cache] interface.
+func (d *DiskCache) Set(key string, value []byte) {
+	size := int64(len(value))
+
+	if d.maxSize > 0 && size > d.maxSize {
+		d.Delete(key)
+		return
+	}
+
+	sum := sha256.Sum256(value)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.insert(key, hex.EncodeToString(sum[:]), size, func(name string) error {
+		return writeFileAtomic(name, value)
+	})
+}
+
+// NewWriter implements the [CacheWriter] interface.
+//
+// The entry is written to a temporary file in the cache directory, which is moved into place on commit.
+func (d *DiskCache) NewWriter(key string) (BodyWriter, error) {
+	f, err := os.CreateTemp(filepath.Join(d.dir, "blobs"), ".tmp-*")
+	if err != nil {
+		return nil, err
+	}
+
+	return &diskCacheWriter{cache: d, key: key, f: f, hash: sha256.New()}, nil
+}
+
+// insert replaces the entry for the given key with the blob with the given hash sum, calling write to create the blob
+// if it does not exist yet.
+//
+// insert must be called with d.mu held.
+func (d *DiskCache) insert(key string, sum string, size int64, write func(name string) error) {
+	d.remove(key)
+
+	if d.refs[sum] == 0 {
+		if err := write(d.blobPath(sum)); err != nil {
+			_ = d.writeIndex()
+			return
+		}
+	}
+
+	d.index[key] = diskCacheEntry{Hash: sum, Size: size, Accessed: time.Now()}
+
+	if d.refs[sum]++; d.refs[sum] == 1 {
+		d.size += size
+	}
+
+	d.evict()
+
+	_ = d.writeIndex()
+}
+
+// Delete implements the [Cache] interface.
+func (d *DiskCache) Delete(key string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.index[key]; !ok {
+		return
+	}
+
+	d.remove(key)
+
+	_ = d.writeIndex()
+}
+
+// Size returns the total size of all entries in bytes.
+func (d *DiskCache) Size() int64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.size
+}
+
+// remove removes the key from the index and deletes its file, if it is not used by another key.
+func (d *DiskCache) remove(key string) {
+	entry, ok := d.index[key]
+	if !ok {
+		return
+	}
+
+	delete(d.index, key)
+
+	if d.refs[entry.Hash]--; d.refs[entry.Hash] > 0 {
+		return
+	}
+
+	delete(d.refs, entry.Hash)
+	d.size -= entry.Size
+
+	_ = os.Remove(d.blobPath(entry.Hash))
+}
+
+// evict removes the least recently used entries until the total size is below the limit.
+func (d *DiskCache) evict() {
+	for d.maxSize > 0 && d.size > d.maxSize {
+		var (
+			oldestKey string
+			oldest    time.Time
+		)
+
+		for key, entry := range d.index {
+			if oldestKey == "" || entry.Accessed.Before(oldest) {
+				oldestKey, oldest = key, entry.Accessed
+			}
+		}
+
+		d.remove(oldestKey)
+	}
+}
+
+func (d *DiskCache) blobPath(hash string) string {
+	return filepath.Join(d.dir, "blobs", hash)
+}
+
+func (d *DiskCache) writeIndex() error {
+	data, err := json.Marshal(d.index, json.Deterministic(true))
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(filepath.Join(d.dir, diskCacheIndex), data)
+}
+
+// diskCacheWriter writes an entry to a temporary file while hashing it.
+type diskCacheWriter struct {
+	cache *DiskCache
+	key   string
+	f     *os.File
+	hash  hash.Hash
+	size  int64
+}
+
+// Write implements the [io.Writer] interface.
+func (w *diskCacheWriter) Write(p []byte) (int, error) {
+	n, err := w.f.Write(p)
+	w.hash.Write(p[:n])
+	w.size += int64(n)
+	return n, err
+}
+
+// Commit implements the [BodyWriter] interface.
+func (w *diskCacheWriter) Commit() {
+	defer func() { _ = os.Remove(w.f.Name()) }()
+
+	if err := w.f.Close(); err != nil {
+		return
+	}
+
+	d := w.cache
+
+	if d.maxSize > 0 && w.size > d.maxSize {
+		d.Delete(w.key)
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.insert(w.key, hex.EncodeToString(w.hash.Sum(nil)), w.size, func(name string) error {
+		return os.Rename(w.f.Name(), name)
+	})
+}
+
+// Abort implements the [BodyWriter] interface.
+func (w *diskCacheWriter) Abort() {
+	_ = w.f.Close()
+	_ = os.Remove(w.f.Name())
+}
+
+// writeFileAtomic writes the data to a temporary file and renames it to the given name.
+func writeFileAtomic(name string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(name), ".tmp-*")
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+
+	return os.Rename(f.Name(), name)
+}
@@ -0,0 +1,173 @@
+package httpc_test
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nussjustin/httpc"
+)
+
+func TestDiskCache(t *testing.T) {
+	dir := t.TempDir()
+
+	c, err := httpc.NewDiskCache(dir, 0)
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+
+	if _, ok := c.Get("a"); ok {
+		t.Error("got entry from empty cache")
+	}
+
+	c.Set("a", []byte("value a"))
+	c.Set("b", []byte("value a"))
+	c.Set("c", []byte("value c"))
+	c.Set("c", []byte("value c"))
+
+	if got, ok := c.Get("c"); !ok || string(got) != "value c" {
+		t.Errorf("got %q, %v after replacing entry with same value", got, ok)
+	}
+
+	if got, want := c.Size(), int64(14); got != want {
+		t.Errorf("got size %d, want %d for deduplicated entries", got, want)
+	}
+
+	c.Delete("c")
+
+	// Reopen to check persistence
+	c, err = httpc.NewDiskCache(dir, 0)
+	if err != nil {
+		t.Fatalf("failed to reopen cache: %v", err)
+	}
+
+	for _, key := range []string{"a", "b"} {
+		if got, ok := c.Get(key); !ok || string(got) != "value a" {
+			t.Errorf("got %q, %v for key %q, want %q", got, ok, key, "value a")
+		}
+	}
+
+	if _, ok := c.Get("c"); ok {
+		t.Error("got deleted entry")
+	}
+
+	c.Delete("a")
+
+	if got, ok := c.Get("b"); !ok || string(got) != "value a" {
+		t.Errorf("got %q, %v after deleting other key with same value", got, ok)
+	}
+
+	blobs, _ := os.ReadDir(filepath.Join(dir, "blobs"))
+	if len(blobs) != 1 {
+		t.Errorf("got %d blobs, want 1", len(blobs))
+	}
+}
+
+func TestDiskCache_Eviction(t *testing.T) {
+	c, err := httpc.NewDiskCache(t.TempDir(), 10)
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+
+	c.Set("a", []byte("aaaa"))
+	c.Set("b", []byte("bbbb"))
+
+	// Make b the least recently used entry
+	c.Get("a")
+
+	c.Set("c", []byte("cccc"))
+
+	if _, ok := c.Get("b"); ok {
+		t.Error("least recently used entry was not evicted")
+	}
+
+	for _, key := range []string{"a", "c"} {
+		if _, ok := c.Get(key); !ok {
+			t.Errorf("entry %q was evicted", key)
+		}
+	}
+
+	c.Set("d", []byte("too large to be cached"))
+
+	if _, ok := c.Get("d"); ok {
+		t.Error("got entry larger than the cache")
+	}
+}
+
+func TestDiskCache_WithCache(t *testing.T) {
+	srv, calls := cacheServer(t)
+
+	dir := t.TempDir()
+
+	for range 2 {
+		cache, err := httpc.NewDiskCache(dir, 1<<20)
+		if err != nil {
+			t.Fatalf("failed to create cache: %v", err)
+		}
+
+		var got string
+
+		c := httpc.New(httpc.WithCache(cache))
+
+		if err := c.Fetch(t.Context(), http.MethodGet, srv.URL+"/fresh", &got); err != nil {
+			t.Fatalf("got error %v", err)
+		}
+
+		if got != "fresh-1" {
+			t.Errorf("got %q, want %q", got, "fresh-1")
+		}
+	}
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("got %d calls, want 1", got)
+	}
+}
+
+func TestDiskCache_NewWriter(t *testing.T) {
+	dir := t.TempDir()
+
+	c, err := httpc.NewDiskCache(dir, 0)
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+
+	write := func(key, value string) httpc.BodyWriter {
+		t.Helper()
+
+		w, err := c.NewWriter(key)
+		if err != nil {
+			t.Fatalf("failed to create writer: %v", err)
+		}
+
+		if _, err := io.WriteString(w, value); err != nil {
+			t.Fatalf("failed to write entry: %v", err)
+		}
+
+		return w
+	}
+
+	write("a", "value a").Commit()
+	write("b", "value a").Commit()
+	write("c", "value c").Abort()
+
+	for _, key := range []string{"a", "b"} {
+		if got, ok := c.Get(key); !ok || string(got) != "value a" {
+			t.Errorf("got %q, %v for key %q, want %q", got, ok, key, "value a")
+		}
+	}
+
+	if _, ok := c.Get("c"); ok {
+		t.Error("got aborted entry")
+	}
+
+	if got, want := c.Size(), int64(7); got != want {
+		t.Errorf("got size %d, want %d for deduplicated entries", got, want)
+	}
+
+	blobs, _ := os.ReadDir(filepath.Join(dir, "blobs"))
+	if len(blobs) != 1 {
+		t.Errorf("got %d blobs, want 1", len(blobs))
+	}
+}
@@ -0,0 +1,127 @@
+package httpc
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// ErrInjectedFault is returned for connection resets injected via [WithFaultInjection].
+//
+// Errors for injected resets also match [syscall.ECONNRESET] when using [errors.Is].
+var ErrInjectedFault = errors.New("github.com/nussjustin/httpc: injected fault")
+
+// FaultConfig configures the faults injected by [WithFaultInjection].
+//
+// Probabilities are given as values between 0 and 1. A probability of 0 disables the fault.
+type FaultConfig struct {
+	// LatencyProbability is the probability of delaying a request.
+	LatencyProbability float64
+
+	// Latency is the maximum delay added to a request. The actual delay is chosen randomly between 0 and Latency.
+	Latency time.Duration
+
+	// ErrorProbability is the probability of responding to a request with ErrorStatus instead of sending it.
+	ErrorProbability float64
+
+	// ErrorStatus is the status code of injected error responses.
+	//
+	// Defaults to [http.StatusServiceUnavailable].
+	ErrorStatus int
+
+	// ResetProbability is the probability of failing a request with a simulated connection reset instead of sending
+	// it.
+	ResetProbability float64
+}
+
+// WithFaultInjection randomly injects latency, error responses and connection resets at the transport layer.
+//
+// Faults are chosen using the source of randomness configured via [WithRand]. They are injected for each attempt
+// separately, before the request is passed to the transport of the underlying client. Latency is applied first and
+// can be combined with the other faults.
+//
+// This can be used to validate retry and fallback settings and should only be enabled in tests or staging
+// environments.
+func WithFaultInjection(cfg FaultConfig) FetchOption {
+	if cfg.ErrorStatus == 0 {
+		cfg.ErrorStatus = http.StatusServiceUnavailable
+	}
+
+	return func(ctx *fetchContext) error {
+		ctx.Faults = &cfg
+		return nil
+	}
+}
+
+// applyFaultInjection replaces the client with one that injects the configured faults.
+func (ctx *fetchContext) applyFaultInjection() {
+	if ctx.Faults == nil {
+		return
+	}
+
+	ctx.wrapTransport(func(rt http.RoundTripper) http.RoundTripper {
+		return &faultTransport{next: rt, cfg: ctx.Faults, rand: ctx.Rand}
+	})
+}
+
+type faultTransport struct {
+	next http.RoundTripper
+	cfg  *FaultConfig
+	rand *randSource
+}
+
+func (t *faultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.cfg.Latency > 0 && t.hit(t.cfg.LatencyProbability) {
+		if err := sleep(req, t.rand.Duration(t.cfg.Latency)); err != nil {
+			closeRequestBody(req)
+			return nil, err
+		}
+	}
+
+	switch {
+	case t.hit(t.cfg.ResetProbability):
+		closeRequestBody(req)
+		return nil, fmt.Errorf("%w: %w", ErrInjectedFault, syscall.ECONNRESET)
+	case t.hit(t.cfg.ErrorProbability):
+		closeRequestBody(req)
+		return &http.Response{
+			Status:     fmt.Sprintf("%d %s", t.cfg.ErrorStatus, http.StatusText(t.cfg.ErrorStatus)),
+			StatusCode: t.cfg.ErrorStatus,
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     make(http.Header),
+			Body:       http.NoBody,
+			Request:    req,
+		}, nil
+	}
+
+	return t.next.RoundTrip(req)
+}
+
+// hit reports whether an event with the given probability should happen.
+func (t *faultTransport) hit(probability float64) bool {
+	return probability > 0 && t.rand.Float64() < probability
+}
+
+// sleep waits for the given duration or until the context of the request is done.
+func sleep(req *http.Request, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-req.Context().Done():
+		return req.Context().Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// closeRequestBody closes the body of a request that is not passed to a transport.
+func closeRequestBody(req *http.Request) {
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+}
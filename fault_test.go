@@ -0,0 +1,91 @@
+package httpc_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/nussjustin/httpc"
+)
+
+func TestWithFaultInjection(t *testing.T) {
+	var sent int
+
+	client := &http.Client{
+		Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			sent++
+
+			return &http.Response{
+				StatusCode: http.StatusNoContent,
+				Header:     make(http.Header),
+				Body:       http.NoBody,
+				Request:    r,
+			}, nil
+		}),
+	}
+
+	testCases := []struct {
+		Name          string
+		Config        httpc.FaultConfig
+		Timeout       time.Duration
+		ExpectedError []error
+		ExpectedSent  int
+	}{
+		{
+			Name:         "No faults",
+			Config:       httpc.FaultConfig{},
+			ExpectedSent: 1,
+		},
+		{
+			Name:          "Error",
+			Config:        httpc.FaultConfig{ErrorProbability: 1},
+			ExpectedError: []error{httpc.ErrUnexpectedStatus},
+		},
+		{
+			Name:          "Reset",
+			Config:        httpc.FaultConfig{ResetProbability: 1, ErrorProbability: 1},
+			ExpectedError: []error{httpc.ErrInjectedFault, syscall.ECONNRESET},
+		},
+		{
+			Name:          "Latency",
+			Config:        httpc.FaultConfig{LatencyProbability: 1, Latency: time.Hour},
+			Timeout:       10 * time.Millisecond,
+			ExpectedError: []error{context.DeadlineExceeded},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			sent = 0
+
+			ctx := t.Context()
+
+			if testCase.Timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, testCase.Timeout)
+				defer cancel()
+			}
+
+			_, err := httpc.Fetch[any](ctx, http.MethodGet, "http://example.com/",
+				httpc.WithClient(client),
+				httpc.WithFaultInjection(testCase.Config))
+
+			if len(testCase.ExpectedError) == 0 && err != nil {
+				t.Fatalf("got error %v", err)
+			}
+
+			for _, want := range testCase.ExpectedError {
+				if !errors.Is(err, want) {
+					t.Errorf("got error %v, want %v", err, want)
+				}
+			}
+
+			if sent != testCase.ExpectedSent {
+				t.Errorf("got %d requests sent, want %d", sent, testCase.ExpectedSent)
+			}
+		})
+	}
+}
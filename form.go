@@ -0,0 +1,144 @@
+package httpc
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WithBodyForm encodes the given values as application/x-www-form-urlencoded form and uses the result as the request
+// body.
+//
+// If the Content-Type header is not set or empty, it will be set to "application/x-www-form-urlencoded".
+//
+// The content length is set and the body can be sent again, for example on redirects or when retrying the request.
+func WithBodyForm(values url.Values) FetchOption {
+	return func(ctx *fetchContext) error {
+		ctx.setBody("application/x-www-form-urlencoded", []byte(values.Encode()))
+		return nil
+	}
+}
+
+// WithBodyFormStruct is like [WithBodyForm], but encodes the struct pointed to by v using [MarshalForm].
+func WithBodyFormStruct(v any) FetchOption {
+	return func(ctx *fetchContext) error {
+		values, err := MarshalForm(v)
+		if err != nil {
+			return err
+		}
+
+		ctx.setBody("application/x-www-form-urlencoded", []byte(values.Encode()))
+		return nil
+	}
+}
+
+var textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+
+// MarshalForm encodes the given struct, or pointer to a struct, into form values.
+//
+// Only fields with a "form" tag are encoded. The tag value is the name of the form field, optionally followed by
+// ",omitempty" to skip fields with a zero value.
+//
+// The following field types are supported:
+//
+//   - string
+//   - bool, integer and float types, which are formatted using the strconv package
+//   - [time.Time], which is formatted using [time.RFC3339Nano]
+//   - types implementing [encoding.TextMarshaler]
+//   - slices of any of the above, which are encoded as multiple values with the same name
+//   - pointers to any of the above, which are skipped if nil
+func MarshalForm(v any) (url.Values, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("github.com/nussjustin/httpc: can not marshal %T as form", v)
+	}
+
+	rt := rv.Type()
+
+	values := make(url.Values)
+
+	for i := range rt.NumField() {
+		field := rt.Field(i)
+
+		tag, ok := field.Tag.Lookup("form")
+		if !ok || tag == "" || tag == "-" || !field.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+
+		fv := rv.Field(i)
+
+		if opts == "omitempty" && fv.IsZero() {
+			continue
+		}
+
+		if err := marshalFormValue(values, name, fv); err != nil {
+			return nil, fmt.Errorf("github.com/nussjustin/httpc: invalid value for form field %q: %w", name, err)
+		}
+	}
+
+	return values, nil
+}
+
+func marshalFormValue(values url.Values, name string, v reflect.Value) error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+
+		return marshalFormValue(values, name, v.Elem())
+	}
+
+	var m encoding.TextMarshaler
+
+	switch {
+	case v.Type() == timeType:
+		values.Add(name, v.Interface().(time.Time).Format(time.RFC3339Nano))
+		return nil
+	case v.Type().Implements(textMarshalerType):
+		m = v.Interface().(encoding.TextMarshaler)
+	case v.CanAddr() && reflect.PointerTo(v.Type()).Implements(textMarshalerType):
+		m = v.Addr().Interface().(encoding.TextMarshaler)
+	}
+
+	if m != nil {
+		text, err := m.MarshalText()
+		if err != nil {
+			return err
+		}
+		values.Add(name, string(text))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		values.Add(name, v.String())
+	case reflect.Slice:
+		for i := range v.Len() {
+			if err := marshalFormValue(values, name, v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Bool:
+		values.Add(name, strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		values.Add(name, strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		values.Add(name, strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		values.Add(name, strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package httpc_test
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nussjustin/httpc"
+)
+
+func TestWithBodyForm(t *testing.T) {
+	req := captureRequest(t, "/",
+		httpc.WithBodyForm(url.Values{"name": []string{"value 1", "value&2"}, "other": []string{"x"}}))
+
+	want := "name=value+1&name=value%262&other=x"
+
+	if got, want := req.Header.Get("Content-Type"), "application/x-www-form-urlencoded"; got != want {
+		t.Errorf("got Content-Type %q, want %q", got, want)
+	}
+
+	if got, want := req.ContentLength, int64(len(want)); got != want {
+		t.Errorf("got content length %d, want %d", got, want)
+	}
+
+	if req.GetBody == nil {
+		t.Fatal("GetBody not set")
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	got, _ := io.ReadAll(body)
+	if string(got) != want {
+		t.Errorf("got body %q, want %q", got, want)
+	}
+}
+
+func TestWithBodyFormStruct(t *testing.T) {
+	req := captureRequest(t, "/",
+		httpc.WithHeader("Content-Type", "application/x-www-form-urlencoded; charset=utf-8"),
+		httpc.WithBodyFormStruct(struct {
+			Name string `form:"name"`
+		}{"value"}))
+
+	if got, want := req.Header.Get("Content-Type"), "application/x-www-form-urlencoded; charset=utf-8"; got != want {
+		t.Errorf("got Content-Type %q, want %q", got, want)
+	}
+
+	got, _ := io.ReadAll(req.Body)
+	if want := "name=value"; string(got) != want {
+		t.Errorf("got body %q, want %q", got, want)
+	}
+
+	_, err := httpc.Fetch[any](t.Context(), http.MethodPost, "/", httpc.WithBodyFormStruct(1))
+	if err == nil {
+		t.Error("got no error for invalid value")
+	}
+}
+
+func TestMarshalForm(t *testing.T) {
+	type form struct {
+		String     string    `form:"string"`
+		Empty      string    `form:"empty,omitempty"`
+		Bool       bool      `form:"bool"`
+		Int        int       `form:"int"`
+		Uint       uint8     `form:"uint"`
+		Float      float64   `form:"float"`
+		Time       time.Time `form:"time"`
+		Text       net.IP    `form:"text"`
+		Slice      []int     `form:"slice"`
+		Pointer    *string   `form:"pointer"`
+		NilPtr     *string   `form:"nil"`
+		Untagged   string
+		Skipped    string     `form:"-"`
+		unexported string     `form:"unexported"`
+		Named      *time.Time `form:"named,omitempty"`
+	}
+
+	value := "pointer"
+
+	got, err := httpc.MarshalForm(&form{
+		String:     "string",
+		Bool:       true,
+		Int:        -1,
+		Uint:       2,
+		Float:      1.5,
+		Time:       time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Text:       net.IPv4(127, 0, 0, 1),
+		Slice:      []int{1, 2},
+		Pointer:    &value,
+		Untagged:   "untagged",
+		Skipped:    "skipped",
+		unexported: "unexported",
+	})
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	want := url.Values{
+		"string":  []string{"string"},
+		"bool":    []string{"true"},
+		"int":     []string{"-1"},
+		"uint":    []string{"2"},
+		"float":   []string{"1.5"},
+		"time":    []string{"2025-01-02T03:04:05Z"},
+		"text":    []string{"127.0.0.1"},
+		"slice":   []string{"1", "2"},
+		"pointer": []string{"pointer"},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("values mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := httpc.MarshalForm(struct {
+		Map map[string]string `form:"map"`
+	}{}); err == nil {
+		t.Error("got no error for unsupported type")
+	}
+}
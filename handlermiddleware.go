@@ -0,0 +1,161 @@
+package httpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// HandlerMiddleware wraps a [Handler] to add behavior around it.
+type HandlerMiddleware func(Handler) Handler
+
+// WrapHandler wraps the given handler with the given middlewares.
+//
+// The first middleware is the outermost one, so it is called first and returns last.
+func WrapHandler(h Handler, mws ...HandlerMiddleware) Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+// ErrBodyTooLarge is returned when reading a response body that exceeds the configured limit.
+var ErrBodyTooLarge = errors.New("github.com/nussjustin/httpc: response body too large")
+
+// ResponseTooLargeError is returned when reading a response body that exceeds the limit configured using [LimitBody]
+// or [WithMaxResponseBytes].
+//
+// ResponseTooLargeError wraps [ErrBodyTooLarge].
+type ResponseTooLargeError struct {
+	// Limit is the maximum number of bytes that could be read from the body.
+	Limit int64
+}
+
+// Error implements the error interface.
+func (e *ResponseTooLargeError) Error() string {
+	return fmt.Sprintf("%s (limit %d bytes)", ErrBodyTooLarge, e.Limit)
+}
+
+// Unwrap returns [ErrBodyTooLarge].
+func (e *ResponseTooLargeError) Unwrap() error {
+	return ErrBodyTooLarge
+}
+
+// LimitBody returns a [HandlerMiddleware] that limits the number of bytes that can be read from the response body.
+//
+// Reading more than n bytes fails with a [*ResponseTooLargeError].
+func LimitBody(n int64) HandlerMiddleware {
+	return func(h Handler) Handler {
+		return HandlerFunc(func(dst any, resp *http.Response) error {
+			resp.Body = &limitedReadCloser{ReadCloser: resp.Body, n: n, limit: n}
+			return h.HandleResponse(dst, resp)
+		})
+	}
+}
+
+type limitedReadCloser struct {
+	io.ReadCloser
+
+	n     int64
+	limit int64
+}
+
+func (l *limitedReadCloser) Read(p []byte) (int, error) {
+	if l.n < 0 {
+		return 0, &ResponseTooLargeError{Limit: l.limit}
+	}
+
+	// Read one byte more than allowed to detect bodies exceeding the limit.
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+
+	n, err := l.ReadCloser.Read(p)
+
+	if int64(n) > l.n {
+		n, l.n = int(l.n), -1
+		return n, &ResponseTooLargeError{Limit: l.limit}
+	}
+
+	l.n -= int64(n)
+	return n, err
+}
+
+// Recover returns a [HandlerMiddleware] that recovers from panics in the wrapped handler and returns them as error.
+func Recover() HandlerMiddleware {
+	return func(h Handler) Handler {
+		return HandlerFunc(func(dst any, resp *http.Response) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					err = fmt.Errorf("github.com/nussjustin/httpc: handler panicked: %v", r)
+				}
+			}()
+
+			return h.HandleResponse(dst, resp)
+		})
+	}
+}
+
+// Timing returns a [HandlerMiddleware] that calls f with the time spent in the wrapped handler and the returned error.
+//
+// This can be used for example to log or record metrics about slow response handling.
+func Timing(f func(d time.Duration, err error)) HandlerMiddleware {
+	return func(h Handler) Handler {
+		return HandlerFunc(func(dst any, resp *http.Response) error {
+			start := time.Now()
+			err := h.HandleResponse(dst, resp)
+			f(time.Since(start), err)
+			return err
+		})
+	}
+}
+
+// LogHandler returns a [HandlerMiddleware] that logs the result of the wrapped handler using the given logger.
+//
+// Messages include the method and URL of the request, the status code of the response, the time spent in the handler
+// and the returned error, if any. Secrets in URLs are redacted using [DefaultRedactor].
+//
+// Responses that were handled or skipped by returning [ErrUnhandledResponse] are logged at the given level. Other
+// errors are logged at [slog.LevelError].
+func LogHandler(logger *slog.Logger, level slog.Level) HandlerMiddleware {
+	redactor := DefaultRedactor()
+
+	return func(h Handler) Handler {
+		return HandlerFunc(func(dst any, resp *http.Response) error {
+			start := time.Now()
+			err := h.HandleResponse(dst, resp)
+
+			attrs := make([]slog.Attr, 0, 5)
+
+			ctx := context.Background()
+
+			if req := resp.Request; req != nil {
+				ctx = req.Context()
+
+				attrs = append(attrs,
+					slog.String("method", req.Method),
+					slog.String("url", redactor.URL(req.URL)))
+			}
+
+			attrs = append(attrs,
+				slog.Int("status", resp.StatusCode),
+				slog.Duration("duration", time.Since(start)))
+
+			switch {
+			case err == nil:
+				logger.LogAttrs(ctx, level, "response handled", attrs...)
+			case errors.Is(err, ErrUnhandledResponse):
+				logger.LogAttrs(ctx, level, "response not handled", attrs...)
+			default:
+				attrs = append(attrs, slog.Any("error", err))
+				logger.LogAttrs(ctx, slog.LevelError, "response handling failed", attrs...)
+			}
+
+			return err
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package httpc_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nussjustin/httpc"
+)
+
+func TestWrapHandler(t *testing.T) {
+	var calls []string
+
+	mw := func(name string) httpc.HandlerMiddleware {
+		return func(h httpc.Handler) httpc.Handler {
+			return httpc.HandlerFunc(func(dst any, resp *http.Response) error {
+				calls = append(calls, name+" before")
+				err := h.HandleResponse(dst, resp)
+				calls = append(calls, name+" after")
+				return err
+			})
+		}
+	}
+
+	h := httpc.WrapHandler(httpc.HandlerFunc(func(any, *http.Response) error {
+		calls = append(calls, "handler")
+		return nil
+	}), mw("a"), mw("b"))
+
+	if err := h.HandleResponse(nil, &http.Response{}); err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	want := []string{"a before", "b before", "handler", "b after", "a after"}
+
+	if !slices.Equal(calls, want) {
+		t.Errorf("got calls %q, want %q", calls, want)
+	}
+}
+
+func TestLimitBody(t *testing.T) {
+	read := httpc.HandlerFunc(func(dst any, resp *http.Response) error {
+		b, err := io.ReadAll(resp.Body)
+		*dst.(*string) = string(b)
+		return err
+	})
+
+	testCases := []struct {
+		Name          string
+		Body          string
+		Limit         int64
+		Expected      string
+		ExpectedError error
+	}{
+		{Name: "Below limit", Body: "abc", Limit: 4, Expected: "abc"},
+		{Name: "At limit", Body: "abcd", Limit: 4, Expected: "abcd"},
+		{Name: "Above limit", Body: "abcde", Limit: 4, Expected: "abcd", ExpectedError: httpc.ErrBodyTooLarge},
+		{Name: "Zero", Body: "a", Limit: 0, ExpectedError: httpc.ErrBodyTooLarge},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			resp := &http.Response{Body: io.NopCloser(strings.NewReader(testCase.Body))}
+
+			var got string
+
+			err := httpc.WrapHandler(read, httpc.LimitBody(testCase.Limit)).HandleResponse(&got, resp)
+			if !errors.Is(err, testCase.ExpectedError) {
+				t.Errorf("got error %v, want %v", err, testCase.ExpectedError)
+			}
+
+			if got != testCase.Expected {
+				t.Errorf("got %q, want %q", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestRecover(t *testing.T) {
+	h := httpc.WrapHandler(httpc.HandlerFunc(func(any, *http.Response) error {
+		panic("oops")
+	}), httpc.Recover())
+
+	err := h.HandleResponse(nil, &http.Response{})
+	if err == nil || !strings.Contains(err.Error(), "oops") {
+		t.Errorf("got error %v, want error containing %q", err, "oops")
+	}
+}
+
+func TestTiming(t *testing.T) {
+	handlerErr := errors.New("handler error")
+
+	var (
+		gotDuration time.Duration
+		gotErr      error
+	)
+
+	h := httpc.WrapHandler(httpc.HandlerFunc(func(any, *http.Response) error {
+		time.Sleep(time.Millisecond)
+		return handlerErr
+	}), httpc.Timing(func(d time.Duration, err error) {
+		gotDuration, gotErr = d, err
+	}))
+
+	if err := h.HandleResponse(nil, &http.Response{}); !errors.Is(err, handlerErr) {
+		t.Errorf("got error %v, want %v", err, handlerErr)
+	}
+
+	if gotDuration < time.Millisecond {
+		t.Errorf("got duration %s, want at least %s", gotDuration, time.Millisecond)
+	}
+
+	if !errors.Is(gotErr, handlerErr) {
+		t.Errorf("got error %v passed to callback, want %v", gotErr, handlerErr)
+	}
+}
+
+func TestLogHandler(t *testing.T) {
+	handlerErr := errors.New("handler error")
+
+	testCases := []struct {
+		Name string
+		Err  error
+		Want string
+	}{
+		{
+			Name: "Handled",
+			Want: `level=DEBUG msg="response handled" method=GET url="https://example.com/?token=REDACTED" status=200`,
+		},
+		{
+			Name: "Unhandled",
+			Err:  httpc.ErrUnhandledResponse,
+			Want: `level=DEBUG msg="response not handled" method=GET url="https://example.com/?token=REDACTED" ` +
+				`status=200`,
+		},
+		{
+			Name: "Error",
+			Err:  handlerErr,
+			Want: `level=ERROR msg="response handling failed" method=GET url="https://example.com/?token=REDACTED" ` +
+				`status=200 error="handler error"`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			h := httpc.WrapHandler(httpc.HandlerFunc(func(any, *http.Response) error {
+				return testCase.Err
+			}), httpc.LogHandler(newTestLogger(&buf), slog.LevelDebug))
+
+			req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "https://example.com/?token=secret", nil)
+
+			err := h.HandleResponse(nil, &http.Response{StatusCode: http.StatusOK, Request: req})
+			if !errors.Is(err, testCase.Err) {
+				t.Errorf("got error %v, want %v", err, testCase.Err)
+			}
+
+			if got := strings.TrimSpace(buf.String()); got != testCase.Want {
+				t.Errorf("got log %q, want %q", got, testCase.Want)
+			}
+		})
+	}
+}
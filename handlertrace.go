@@ -0,0 +1,124 @@
+package httpc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// WithHandlerTrace calls the given function for each [Handler] that was tried while handling the response.
+//
+// The function is called after a handler returned, with the name of the handler and the returned error. A nil error
+// means that the handler handled the response, [ErrUnhandledResponse] means that it was skipped and any other error
+// means that the handler failed. Handlers nested in a [HandlerChain] or passed to [AllHandlers] are reported
+// individually, before the handler containing them. The handler configured for the request is reported last.
+//
+// This is intended for debugging, for example to find out why a handler was not called for a response.
+func WithHandlerTrace(trace func(name string, err error)) FetchOption {
+	return func(ctx *fetchContext) error {
+		ctx.HandlerTrace = trace
+		return nil
+	}
+}
+
+// handlerTraceKey is the context key used to pass the trace function from a request to handlers.
+type handlerTraceKey struct{}
+
+// traceHandler reports the result of calling the handler h, if tracing is enabled for the request of the response.
+func traceHandler(resp *http.Response, h Handler, err error) {
+	if resp == nil || resp.Request == nil {
+		return
+	}
+
+	trace, _ := resp.Request.Context().Value(handlerTraceKey{}).(func(string, error))
+	if trace == nil {
+		return
+	}
+
+	trace(handlerName(h), err)
+}
+
+// withHandlerTrace returns a copy of req that passes the trace function to handlers.
+func withHandlerTrace(req *http.Request, trace func(string, error)) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), handlerTraceKey{}, trace))
+}
+
+// NamedHandler returns a [Handler] that calls h and uses the given name in errors and traces, for example when using
+// [WithHandlerTrace].
+func NamedHandler(name string, h Handler) Handler {
+	return namedHandler{name: name, handler: h}
+}
+
+type namedHandler struct {
+	name    string
+	handler Handler
+}
+
+// HandleResponse implements the [Handler] interface.
+func (n namedHandler) HandleResponse(dst any, resp *http.Response) error {
+	return n.handler.HandleResponse(dst, resp)
+}
+
+// String returns the name of the handler.
+func (n namedHandler) String() string {
+	return n.name
+}
+
+// handlerName returns a name for the given handler.
+//
+// Handlers implementing [fmt.Stringer], including handlers created using [NamedHandler] or [ContentTypeHandler], are
+// named using their String method. Other handlers are named using [funcName].
+func handlerName(h Handler) string {
+	if s, ok := h.(fmt.Stringer); ok {
+		return s.String()
+	}
+
+	return funcName(h)
+}
+
+// funcName returns the name of the given function, or of the function that created it for function literals, for
+// example "ReadCloserHandler" for handlers returned by [ReadCloserHandler] or "IsSuccess" for [IsSuccess].
+//
+// Values that are not functions are named after their type.
+func funcName(fn any) string {
+	name := fmt.Sprintf("%T", fn)
+
+	if v := reflect.ValueOf(fn); v.Kind() == reflect.Func && !v.IsNil() {
+		if f := runtime.FuncForPC(v.Pointer()); f != nil {
+			name = trimClosureSuffix(f.Name())
+		}
+	}
+
+	return trimPackagePath(name)
+}
+
+// trimPackagePath removes the package path from the given name, keeping the package name for names from other
+// packages.
+func trimPackagePath(name string) string {
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+
+	return strings.TrimPrefix(strings.TrimPrefix(name, "*"), "httpc.")
+}
+
+// trimClosureSuffix removes the suffixes added by the compiler to the names of function literals, for example
+// "ContentTypeHandler.func1" or "SniffHandler.func1.2".
+func trimClosureSuffix(name string) string {
+	for {
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			return name
+		}
+
+		suffix := strings.TrimPrefix(name[i+1:], "func")
+		if suffix == "" || strings.Trim(suffix, "0123456789") != "" {
+			return name
+		}
+
+		name = name[:i]
+	}
+}
@@ -0,0 +1,157 @@
+package httpc_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/nussjustin/httpc"
+)
+
+type namedHandler string
+
+func (n namedHandler) HandleResponse(any, *http.Response) error {
+	return httpc.ErrUnhandledResponse
+}
+
+func (n namedHandler) String() string {
+	return string(n)
+}
+
+func TestWithHandlerTrace(t *testing.T) {
+	type event struct {
+		Name string
+		Err  error
+	}
+
+	errTest := errors.New("test error")
+
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": []string{"text/plain"}},
+				Body:       io.NopCloser(strings.NewReader("body")),
+				Request:    req,
+			}, nil
+		}),
+	}
+
+	handler := httpc.HandlerChain{
+		namedHandler("first"),
+		httpc.AllHandlers(namedHandler("nested"), httpc.ErrorHandler(errTest)),
+		namedHandler("never"),
+	}
+
+	var got []event
+
+	_, err := httpc.Fetch[string](t.Context(), http.MethodGet, "https://example.com/",
+		httpc.WithClient(client),
+		httpc.WithHandler(handler),
+		httpc.WithHandlerTrace(func(name string, err error) {
+			got = append(got, event{name, err})
+		}))
+	if !errors.Is(err, errTest) {
+		t.Fatalf("got error %v, want %v", err, errTest)
+	}
+
+	want := []event{
+		{"first", httpc.ErrUnhandledResponse},
+		{"nested", httpc.ErrUnhandledResponse},
+		{"ErrorHandler", errTest},
+		{"AllHandlers", errTest},
+		{"HandlerChain", errTest},
+	}
+
+	if diff := cmp.Diff(want, got, cmpopts.EquateErrors()); diff != "" {
+		t.Errorf("trace mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestHandlerNames(t *testing.T) {
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(`"value"`)),
+				Request:    req,
+			}, nil
+		}),
+	}
+
+	testCases := []struct {
+		Name    string
+		Handler httpc.Handler
+		Want    []string
+	}{
+		{
+			Name:    "Default handlers",
+			Handler: httpc.DefaultHandlers,
+			Want: []string{
+				"ProblemHandler",
+				"ConditionalHandler(IsSuccess, ReadCloserHandler)",
+				"ConditionalHandler(IsSuccess, BytesHandler)",
+				"ConditionalHandler(IsSuccess, ContentTypeHandler(application/json))",
+				"HandlerChain",
+			},
+		},
+		{
+			Name:    "Named",
+			Handler: httpc.NamedHandler("custom", httpc.UnmarshalJSONHandler()),
+			Want:    []string{"custom"},
+		},
+		{
+			Name:    "Content type handler",
+			Handler: httpc.ContentTypeHandler("application/json", httpc.UnmarshalJSONHandler()),
+			Want:    []string{"ContentTypeHandler(application/json)"},
+		},
+		{
+			Name:    "Status handler",
+			Handler: httpc.StatusHandler(http.StatusOK, httpc.UnmarshalJSONHandler()),
+			Want:    []string{"StatusHandler(200)"},
+		},
+		{
+			Name:    "Conditional handler",
+			Handler: httpc.ConditionalHandler(httpc.IsSuccess, httpc.UnmarshalJSONHandler()),
+			Want:    []string{"ConditionalHandler(IsSuccess, UnmarshalJSONHandler)"},
+		},
+		{
+			Name:    "Not handler",
+			Handler: httpc.NotHandler(httpc.HasHeader("X-Skip"), httpc.UnmarshalJSONHandler()),
+			Want:    []string{"NotHandler(HasHeader, UnmarshalJSONHandler)"},
+		},
+		{
+			Name: "Function literal",
+			Handler: httpc.HandlerFunc(func(any, *http.Response) error {
+				return nil
+			}),
+			Want: []string{"httpc_test.TestHandlerNames"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			var got []string
+
+			_, err := httpc.Fetch[string](t.Context(), http.MethodGet, "https://example.com/",
+				httpc.WithClient(client),
+				httpc.WithHandler(testCase.Handler),
+				httpc.WithHandlerTrace(func(name string, _ error) {
+					got = append(got, name)
+				}))
+			if err != nil {
+				t.Fatalf("got error %v", err)
+			}
+
+			if diff := cmp.Diff(testCase.Want, got); diff != "" {
+				t.Errorf("names mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
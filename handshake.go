@@ -0,0 +1,132 @@
+package httpc
+
+import (
+	"net/http"
+	"sync"
+)
+
+// maxHandshakeRounds limits the number of requests sent during a single handshake.
+const maxHandshakeRounds = 8
+
+// WithHandshake answers authentication challenges using handshakes that span multiple round-trips over a single
+// connection, as required by connection-based authentication schemes like NTLM and Negotiate (SPNEGO).
+//
+// If a request is answered with 401 Unauthorized, newChallenger is called to start a new handshake. The returned
+// [Challenger] is called with the challenges of each 401 response of the handshake and the returned value is sent as
+// Authorization header with the next round, so that it can keep state between rounds. The handshake ends once the
+// server responds with anything other than 401 Unauthorized, the [Challenger] returns an empty string or after a
+// fixed number of rounds. In all cases the last response is returned.
+//
+// Since connection-based schemes authenticate the connection instead of a single request, the [*http.Transport] of
+// the underlying client is cloned and limited to a single HTTP/1.1 connection per host and handshakes are not run
+// concurrently. Requests using the option after a successful handshake reuse the authenticated connection. The
+// returned option should be created once and reused, for example by passing it to [New].
+//
+// Requests with a body can only be sent again if [http.Request.GetBody] is set. Otherwise, the 401 response is
+// returned as is.
+//
+// The transport of the underlying client must be an [*http.Transport]. If the client has no transport,
+// [http.DefaultTransport] is used.
+func WithHandshake(newChallenger func() Challenger) FetchOption {
+	h := &handshakeAuth{newChallenger: newChallenger}
+
+	return func(ctx *fetchContext) error {
+		ctx.Handshake = h
+		return nil
+	}
+}
+
+type handshakeAuth struct {
+	newChallenger func() Challenger
+
+	// handshakeMu serializes handshakes, so that the rounds of different handshakes are not mixed on a connection.
+	handshakeMu sync.Mutex
+
+	transports transportCache
+}
+
+// transport returns a copy of t that uses at most one HTTP/1.1 connection per host.
+func (h *handshakeAuth) transport(t *http.Transport) *http.Transport {
+	return h.transports.get(t, func(t *http.Transport) *http.Transport {
+		sticky := versionedTransport(t, HTTPVersion1).Clone()
+		sticky.MaxConnsPerHost = 1
+		sticky.DisableKeepAlives = false
+
+		return sticky
+	})
+}
+
+// applyHandshakeTransport replaces the client with one that uses a transport suitable for handshakes, if configured.
+func (ctx *fetchContext) applyHandshakeTransport() error {
+	if ctx.Handshake == nil {
+		return nil
+	}
+
+	t, err := ctx.transport()
+	if err != nil {
+		return err
+	}
+
+	client := *ctx.Client
+	client.Transport = ctx.Handshake.transport(t)
+
+	ctx.Client = &client
+	return nil
+}
+
+// applyHandshake replaces the client with one that performs handshakes, if configured.
+func (ctx *fetchContext) applyHandshake() {
+	if ctx.Handshake == nil || ctx.PresignedURL {
+		return
+	}
+
+	ctx.wrapTransport(func(rt http.RoundTripper) http.RoundTripper {
+		return &handshakeTransport{next: rt, auth: ctx.Handshake}
+	})
+}
+
+type handshakeTransport struct {
+	next http.RoundTripper
+	auth *handshakeAuth
+}
+
+func (t *handshakeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	t.auth.handshakeMu.Lock()
+	defer t.auth.handshakeMu.Unlock()
+
+	c := t.auth.newChallenger()
+
+	for range maxHandshakeRounds {
+		authorization, err := c.Challenge(req, parseChallenges(resp.Header.Values("WWW-Authenticate")))
+		if err != nil {
+			discardBody(resp, nil)
+			return nil, err
+		}
+
+		if authorization == "" {
+			return resp, nil
+		}
+
+		next, ok := rewindRequest(req)
+		if !ok {
+			return resp, nil
+		}
+
+		// The body must be read completely, so that the connection can be reused for the next round.
+		discardBody(resp, nil)
+
+		next.Header.Set("Authorization", authorization)
+
+		resp, err = t.next.RoundTrip(next)
+		if err != nil || resp.StatusCode != http.StatusUnauthorized {
+			return resp, err
+		}
+	}
+
+	return resp, nil
+}
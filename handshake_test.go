@@ -0,0 +1,126 @@
+package httpc_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/nussjustin/httpc"
+)
+
+// handshakeServer returns a server that authenticates connections using a two round handshake.
+func handshakeServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	var (
+		mu            sync.Mutex
+		nonces        = make(map[string]string)
+		authenticated = make(map[string]bool)
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		conn := r.RemoteAddr
+
+		switch authorization := r.Header.Get("Authorization"); {
+		case authenticated[conn]:
+			w.WriteHeader(http.StatusNoContent)
+		case authorization == "Mock negotiate":
+			nonces[conn] = "nonce-" + strconv.Itoa(len(nonces))
+			w.Header().Set("WWW-Authenticate", "Mock "+nonces[conn])
+			w.WriteHeader(http.StatusUnauthorized)
+		case nonces[conn] != "" && authorization == "Mock response-"+nonces[conn]:
+			authenticated[conn] = true
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.Header().Set("WWW-Authenticate", "Mock")
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte("unauthorized"))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestWithHandshake(t *testing.T) {
+	srv := handshakeServer(t)
+
+	var (
+		mu         sync.Mutex
+		handshakes int
+		rounds     int
+	)
+
+	newChallenger := func() httpc.Challenger {
+		mu.Lock()
+		handshakes++
+		mu.Unlock()
+
+		return httpc.ChallengerFunc(func(_ *http.Request, challenges []httpc.Challenge) (string, error) {
+			mu.Lock()
+			rounds++
+			mu.Unlock()
+
+			if len(challenges) != 1 || challenges[0].Scheme != "Mock" {
+				return "", nil
+			}
+
+			if challenges[0].Token68 == "" {
+				return "Mock negotiate", nil
+			}
+
+			return "Mock response-" + challenges[0].Token68, nil
+		})
+	}
+
+	handshake := httpc.WithHandshake(newChallenger)
+
+	for range 3 {
+		_, resp, err := httpc.FetchWithResponse[struct{}](t.Context(), http.MethodGet, srv.URL,
+			httpc.WithClient(srv.Client()), handshake)
+		if err != nil {
+			t.Fatalf("got error %v", err)
+		}
+
+		if got, want := resp.StatusCode, http.StatusNoContent; got != want {
+			t.Errorf("got status %d, want %d", got, want)
+		}
+	}
+
+	if handshakes != 1 || rounds != 2 {
+		t.Errorf("got %d handshakes with %d rounds, want 1 handshake with 2 rounds", handshakes, rounds)
+	}
+}
+
+func TestWithHandshake_Rejected(t *testing.T) {
+	srv := handshakeServer(t)
+
+	newChallenger := func() httpc.Challenger {
+		return httpc.ChallengerFunc(func(*http.Request, []httpc.Challenge) (string, error) {
+			return "Mock invalid", nil
+		})
+	}
+
+	body, resp, err := httpc.FetchWithResponse[io.ReadCloser](t.Context(), http.MethodGet, srv.URL,
+		httpc.WithClient(srv.Client()),
+		httpc.WithHandshake(newChallenger),
+		httpc.WithHandler(httpc.ReadCloserHandler()))
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+	defer func() { _ = body.Close() }()
+
+	if got, want := resp.StatusCode, http.StatusUnauthorized; got != want {
+		t.Errorf("got status %d, want %d", got, want)
+	}
+
+	if got, _ := io.ReadAll(body); string(got) != "unauthorized" {
+		t.Errorf("got body %q, want body of last response", got)
+	}
+}
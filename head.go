@@ -0,0 +1,212 @@
+package httpc
+
+import (
+	"context"
+	"encoding"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// ErrUnexpectedStatus is returned when a response has a status code that can not be handled.
+var ErrUnexpectedStatus = errors.New("github.com/nussjustin/httpc: unexpected status")
+
+// errMethodNotAllowed is used by [Exists] to signal that the request must be retried using GET.
+var errMethodNotAllowed = errors.New("method not allowed")
+
+// Exists checks if the given endpoint exists using a HEAD request.
+//
+// If the server responds to the HEAD request with 405 Method Not Allowed, a GET request is made instead and the
+// response body is discarded.
+//
+// Responses with a 2xx status code result in true and 404 Not Found results in false. For all other responses an error
+// is returned. If the response to a GET request contains problem details as defined by RFC 9457, the problem is
+// returned as error. Otherwise, the error wraps [ErrUnexpectedStatus].
+//
+// Any [Handler] configured via the given options is ignored.
+func Exists(ctx context.Context, url string, opts ...FetchOption) (bool, error) {
+	exists, err := Fetch[bool](ctx, http.MethodHead, url, slices.Concat(opts, []FetchOption{
+		WithHandler(existsHandler(true)),
+	})...)
+	if !errors.Is(err, errMethodNotAllowed) {
+		return exists, err
+	}
+
+	return Fetch[bool](ctx, http.MethodGet, url, slices.Concat(opts, []FetchOption{
+		WithHandler(existsHandler(false)),
+	})...)
+}
+
+func existsHandler(head bool) HandlerFunc {
+	return func(dst any, resp *http.Response) (err error) {
+		switch {
+		case resp.StatusCode >= 200 && resp.StatusCode <= 299:
+			*dst.(*bool) = true
+		case resp.StatusCode == http.StatusNotFound:
+			*dst.(*bool) = false
+		case resp.StatusCode == http.StatusMethodNotAllowed && head:
+			err = errMethodNotAllowed
+		default:
+			// Responses to HEAD requests have no body, so there can be no problem details
+			if !head {
+				if err := ProblemHandler().HandleResponse(dst, resp); !errors.Is(err, ErrUnhandledResponse) {
+					return err
+				}
+			}
+
+			err = fmt.Errorf("%w %q", ErrUnexpectedStatus, resp.Status)
+		}
+
+		discardBody(resp, &err)
+		return err
+	}
+}
+
+// Head makes a HEAD request to the given URL and returns the response headers.
+//
+// If the response has a non-2xx status code an error wrapping [ErrUnexpectedStatus] is returned.
+//
+// To decode the headers into a struct, use [Fetch] together with [UnmarshalHeaderHandler] instead.
+//
+// Any [Handler] configured via the given options is ignored.
+func Head(ctx context.Context, url string, opts ...FetchOption) (http.Header, error) {
+	return Fetch[http.Header](ctx, http.MethodHead, url, slices.Concat(opts, []FetchOption{
+		WithHandlerFunc(func(dst any, resp *http.Response) (err error) {
+			defer discardBody(resp, &err)
+
+			if resp.StatusCode < 200 || resp.StatusCode > 299 {
+				return fmt.Errorf("%w %q", ErrUnexpectedStatus, resp.Status)
+			}
+
+			*dst.(*http.Header) = resp.Header
+			return nil
+		}),
+	})...)
+}
+
+// UnmarshalHeaderHandler returns a handler that decodes the response headers into dst using [UnmarshalHeader].
+//
+// The response body is discarded.
+func UnmarshalHeaderHandler() HandlerFunc {
+	return func(dst any, resp *http.Response) (err error) {
+		defer discardBody(resp, &err)
+
+		return UnmarshalHeader(resp.Header, dst)
+	}
+}
+
+// UnmarshalHeader decodes the given headers into the struct pointed to by v.
+//
+// Only fields with a "header" tag are decoded. The tag value is the name of the header. Fields for headers that are
+// not set are left unchanged.
+//
+// The following field types are supported:
+//
+//   - string, which is set to the first value of the header
+//   - []string, which is set to all values of the header
+//   - bool, integer and float types, which are parsed using the strconv package
+//   - [time.Time], which is parsed using [http.ParseTime]
+//   - types implementing [encoding.TextUnmarshaler]
+//   - pointers to any of the above, which are allocated as needed
+func UnmarshalHeader(h http.Header, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("github.com/nussjustin/httpc: can not unmarshal headers into %T", v)
+	}
+
+	rv = rv.Elem()
+	rt := rv.Type()
+
+	for i := range rt.NumField() {
+		field := rt.Field(i)
+
+		name, ok := field.Tag.Lookup("header")
+		if !ok || name == "" || name == "-" || !field.IsExported() {
+			continue
+		}
+
+		values := h.Values(name)
+		if len(values) == 0 {
+			continue
+		}
+
+		if err := unmarshalHeaderValue(rv.Field(i), values); err != nil {
+			return fmt.Errorf("github.com/nussjustin/httpc: invalid value for header %q: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+var (
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+	timeType            = reflect.TypeFor[time.Time]()
+)
+
+func unmarshalHeaderValue(v reflect.Value, values []string) error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+
+		return unmarshalHeaderValue(v.Elem(), values)
+	}
+
+	switch {
+	case v.Type() == timeType:
+		t, err := http.ParseTime(values[0])
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	case reflect.PointerTo(v.Type()).Implements(textUnmarshalerType):
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(values[0]))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(values[0])
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s", v.Type())
+		}
+		s := reflect.MakeSlice(v.Type(), len(values), len(values))
+		for i, value := range values {
+			s.Index(i).SetString(value)
+		}
+		v.Set(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(values[0])
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(values[0], 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(values[0], 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(values[0], v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,239 @@
+package httpc_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nussjustin/problem"
+
+	"github.com/nussjustin/httpc"
+)
+
+func TestExists(t *testing.T) {
+	var methods []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+
+		switch r.URL.Path {
+		case "/found":
+			_, _ = w.Write([]byte("hello"))
+		case "/no-head":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			_, _ = w.Write([]byte("hello"))
+		case "/error":
+			w.WriteHeader(http.StatusInternalServerError)
+		case "/problem":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			w.Header().Set("Content-Type", problem.ContentType)
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"title":"forbidden"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	testCases := []struct {
+		Name            string
+		Path            string
+		Expected        bool
+		ExpectedError   error
+		ExpectedMethods []string
+	}{
+		{
+			Name:            "Found",
+			Path:            "/found",
+			Expected:        true,
+			ExpectedMethods: []string{http.MethodHead},
+		},
+		{
+			Name:            "Not found",
+			Path:            "/not-found",
+			Expected:        false,
+			ExpectedMethods: []string{http.MethodHead},
+		},
+		{
+			Name:            "Fallback to GET",
+			Path:            "/no-head",
+			Expected:        true,
+			ExpectedMethods: []string{http.MethodHead, http.MethodGet},
+		},
+		{
+			Name:            "Unexpected status",
+			Path:            "/error",
+			ExpectedError:   httpc.ErrUnexpectedStatus,
+			ExpectedMethods: []string{http.MethodHead},
+		},
+		{
+			Name:            "Problem",
+			Path:            "/problem",
+			ExpectedError:   &problem.Details{Title: "forbidden"},
+			ExpectedMethods: []string{http.MethodHead, http.MethodGet},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			methods = nil
+
+			got, err := httpc.Exists(t.Context(), srv.URL+testCase.Path)
+
+			switch {
+			case testCase.ExpectedError == nil && err != nil:
+				t.Fatalf("got error %v", err)
+			case testCase.ExpectedError != nil && err == nil:
+				t.Fatalf("got no error, want %v", testCase.ExpectedError)
+			case testCase.ExpectedError != nil && !errors.Is(err, testCase.ExpectedError) &&
+				err.Error() != testCase.ExpectedError.Error():
+				t.Fatalf("got error %v, want %v", err, testCase.ExpectedError)
+			}
+
+			if got != testCase.Expected {
+				t.Errorf("got %t, want %t", got, testCase.Expected)
+			}
+
+			if len(methods) != len(testCase.ExpectedMethods) {
+				t.Fatalf("got methods %v, want %v", methods, testCase.ExpectedMethods)
+			}
+
+			for i := range methods {
+				if methods[i] != testCase.ExpectedMethods[i] {
+					t.Errorf("got methods %v, want %v", methods, testCase.ExpectedMethods)
+				}
+			}
+		})
+	}
+}
+
+func TestHead(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			t.Errorf("got method %q, want %q", r.Method, http.MethodHead)
+		}
+
+		if r.URL.Path != "/found" {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("ETag", `"abc"`)
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	t.Run("Found", func(t *testing.T) {
+		h, err := httpc.Head(t.Context(), srv.URL+"/found")
+		if err != nil {
+			t.Fatalf("got error %v", err)
+		}
+
+		if got, want := h.Get("ETag"), `"abc"`; got != want {
+			t.Errorf("got ETag %q, want %q", got, want)
+		}
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		_, err := httpc.Head(t.Context(), srv.URL+"/not-found")
+		if !errors.Is(err, httpc.ErrUnexpectedStatus) {
+			t.Errorf("got error %v, want %v", err, httpc.ErrUnexpectedStatus)
+		}
+	})
+}
+
+type resourceInfo struct {
+	ContentLength int64     `header:"Content-Length"`
+	LastModified  time.Time `header:"Last-Modified"`
+	ETag          string    `header:"ETag"`
+	Vary          []string  `header:"Vary"`
+	Age           *int      `header:"Age"`
+	Ignored       string
+}
+
+func TestUnmarshalHeaderHandler(t *testing.T) {
+	lastModified := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Length", "5")
+		w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+		w.Header().Set("ETag", `"abc"`)
+		w.Header().Add("Vary", "Accept")
+		w.Header().Add("Vary", "Accept-Encoding")
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	got, err := httpc.Fetch[resourceInfo](t.Context(), http.MethodHead, srv.URL,
+		httpc.WithHandler(httpc.UnmarshalHeaderHandler()))
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	want := resourceInfo{
+		ContentLength: 5,
+		LastModified:  lastModified,
+		ETag:          `"abc"`,
+		Vary:          []string{"Accept", "Accept-Encoding"},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("UnmarshalHeaderHandler() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestUnmarshalHeader(t *testing.T) {
+	t.Run("Pointer", func(t *testing.T) {
+		var got resourceInfo
+
+		if err := httpc.UnmarshalHeader(http.Header{"Age": {"10"}}, &got); err != nil {
+			t.Fatalf("got error %v", err)
+		}
+
+		if got.Age == nil || *got.Age != 10 {
+			t.Errorf("got Age %v, want 10", got.Age)
+		}
+	})
+
+	t.Run("Named string slice", func(t *testing.T) {
+		type token string
+
+		var got struct {
+			Vary []token `header:"Vary"`
+		}
+
+		if err := httpc.UnmarshalHeader(http.Header{"Vary": {"Accept", "Accept-Encoding"}}, &got); err != nil {
+			t.Fatalf("got error %v", err)
+		}
+
+		if want := []token{"Accept", "Accept-Encoding"}; !slices.Equal(got.Vary, want) {
+			t.Errorf("got Vary %q, want %q", got.Vary, want)
+		}
+	})
+
+	t.Run("Invalid value", func(t *testing.T) {
+		var got resourceInfo
+
+		if err := httpc.UnmarshalHeader(http.Header{"Content-Length": {"abc"}}, &got); err == nil {
+			t.Error("got no error")
+		}
+	})
+
+	t.Run("Non struct", func(t *testing.T) {
+		var got string
+
+		if err := httpc.UnmarshalHeader(http.Header{}, &got); err == nil {
+			t.Error("got no error")
+		}
+	})
+}
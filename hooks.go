@@ -0,0 +1,78 @@
+package httpc
+
+import (
+	"net/http"
+	"time"
+)
+
+// Hooks contains functions that are called during the lifecycle of a request.
+//
+// Hooks can be used to implement logging, metrics or auditing without wrapping the transport of the underlying client.
+// All functions are optional and are called synchronously, so they should return quickly.
+type Hooks struct {
+	// OnRequest is called before each attempt to send the request.
+	OnRequest func(event HookEvent)
+
+	// OnResponse is called for each response received, including responses that cause the request to be retried.
+	//
+	// The response body must not be read or closed by the hook.
+	OnResponse func(event HookEvent)
+
+	// OnError is called once when [Fetch] or a similar function returns an error, for example because the request
+	// failed or the response could not be handled.
+	OnError func(event HookEvent)
+
+	// OnRetry is called before waiting for the next attempt when a request is retried, for example when using
+	// [WithRetry]. The event contains the response or error of the failed attempt as well as the delay.
+	OnRetry func(event HookEvent)
+}
+
+// HookEvent contains information passed to the functions in [Hooks].
+type HookEvent struct {
+	// Request is the request sent by the attempt.
+	Request *http.Request
+
+	// Response is the received response, if any.
+	Response *http.Response
+
+	// Attempt is the number of the attempt, starting at 1.
+	Attempt int
+
+	// Err is the error, if any.
+	Err error
+
+	// Delay is the delay before the next attempt. It is only set for OnRetry.
+	Delay time.Duration
+
+	// OperationName is the name set using [WithOperationName], if any.
+	OperationName string
+
+	// PathTemplate is the path of the request before any wildcards were replaced, for example "/product/{id}".
+	PathTemplate string
+
+	// Labels contains the labels added using [WithLabel]. It must not be modified.
+	Labels map[string]string
+}
+
+// WithHooks sets the functions that are called during the lifecycle of the request.
+//
+// Hooks set by previous options are replaced.
+func WithHooks(hooks Hooks) FetchOption {
+	return func(ctx *fetchContext) error {
+		ctx.Hooks = hooks
+		return nil
+	}
+}
+
+// hookEvent returns a new [HookEvent] for the current attempt.
+func (ctx *fetchContext) hookEvent(resp *http.Response, err error) HookEvent {
+	return HookEvent{
+		Request:       ctx.Request,
+		Response:      resp,
+		Attempt:       ctx.Attempt,
+		Err:           err,
+		OperationName: ctx.OperationName,
+		PathTemplate:  ctx.PathTemplate,
+		Labels:        ctx.Labels,
+	}
+}
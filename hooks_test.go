@@ -0,0 +1,111 @@
+package httpc_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/nussjustin/httpc"
+)
+
+func TestWithHooks(t *testing.T) {
+	type event struct {
+		Hook    string
+		Attempt int
+		Status  int
+		Err     error
+		Delay   time.Duration
+	}
+
+	errTest := errors.New("test error")
+
+	var sent int
+
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			sent++
+
+			if sent == 1 {
+				return nil, errTest
+			}
+
+			status := http.StatusOK
+			if sent == 2 {
+				status = http.StatusServiceUnavailable
+			}
+
+			return &http.Response{
+				StatusCode: status,
+				Header:     http.Header{"Content-Type": []string{"text/plain"}},
+				Body:       io.NopCloser(strings.NewReader("body")),
+				Request:    req,
+			}, nil
+		}),
+	}
+
+	var got []event
+
+	record := func(hook string) func(httpc.HookEvent) {
+		return func(e httpc.HookEvent) {
+			if e.Request == nil {
+				t.Errorf("%s: request not set", hook)
+			}
+
+			ev := event{Hook: hook, Attempt: e.Attempt, Err: e.Err, Delay: e.Delay}
+			if e.Response != nil {
+				ev.Status = e.Response.StatusCode
+			}
+
+			got = append(got, ev)
+		}
+	}
+
+	hooks := httpc.WithHooks(httpc.Hooks{
+		OnRequest:  record("request"),
+		OnResponse: record("response"),
+		OnError:    record("error"),
+		OnRetry:    record("retry"),
+	})
+
+	_, err := httpc.Fetch[string](t.Context(), http.MethodGet, "https://example.com/",
+		httpc.WithClient(client),
+		httpc.WithRetry(httpc.RetryPolicy{
+			MaxAttempts: 3,
+			Backoff:     func(int) time.Duration { return time.Millisecond },
+		}),
+		hooks)
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	_, err = httpc.Fetch[string](t.Context(), http.MethodGet, "https://example.com/",
+		httpc.WithClient(client),
+		httpc.WithHandler(httpc.ErrorHandler(errTest)),
+		hooks)
+	if !errors.Is(err, errTest) {
+		t.Fatalf("got error %v, want %v", err, errTest)
+	}
+
+	want := []event{
+		{Hook: "request", Attempt: 1},
+		{Hook: "retry", Attempt: 1, Err: errTest, Delay: time.Millisecond},
+		{Hook: "request", Attempt: 2},
+		{Hook: "response", Attempt: 2, Status: http.StatusServiceUnavailable},
+		{Hook: "retry", Attempt: 2, Status: http.StatusServiceUnavailable, Delay: time.Millisecond},
+		{Hook: "request", Attempt: 3},
+		{Hook: "response", Attempt: 3, Status: http.StatusOK},
+		{Hook: "request", Attempt: 1},
+		{Hook: "response", Attempt: 1, Status: http.StatusOK},
+		{Hook: "error", Attempt: 1, Status: http.StatusOK, Err: errTest},
+	}
+
+	if diff := cmp.Diff(want, got, cmpopts.EquateErrors()); diff != "" {
+		t.Errorf("events mismatch (-want +got):\n%s", diff)
+	}
+}
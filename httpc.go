@@ -3,13 +3,20 @@ package httpc
 import (
 	"bytes"
 	"context"
+	"encoding"
+	"encoding/hex"
 	"encoding/xml"
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 	"unicode"
 
 	"github.com/go-json-experiment/json"
@@ -28,19 +35,248 @@ type fetchContext struct {
 
 	// Handler is called to handle the response.
 	//
-	// Defaults to [DefaultHandlers].
+	// If nil, [DefaultHandlers] is used and the Accept header is set based on the destination type.
 	Handler Handler
+
+	// PathValues contains the already encoded values for wildcards in the request path, keyed by wildcard name.
+	//
+	// Wildcards are replaced after all options were applied.
+	PathValues map[string]string
+
+	// StrictPathValues causes [Fetch] to fail if the request path still contains wildcards after all options were
+	// applied.
+	StrictPathValues bool
+
+	// JSONOptions contains additional options used when decoding JSON responses using [UnmarshalJSONHandler].
+	JSONOptions []jsontext.Options
+
+	// UnknownJSONFields is called with the unknown members found by [UnmarshalJSONHandler], if set.
+	UnknownJSONFields func(resp *http.Response, fields []UnknownJSONField)
+
+	// TrailingSlash specifies how trailing slashes in the request path are handled after wildcards were replaced.
+	TrailingSlash TrailingSlash
+
+	// PreserveURL disables all modifications of the request URL that happen after all options were applied, like
+	// replacing wildcards or handling trailing slashes.
+	PreserveURL bool
+
+	// NormalizeURL causes the request URL to be normalized before sending.
+	NormalizeURL bool
+
+	// RawURL contains the URL as originally passed to [Fetch].
+	RawURL string
+
+	// OpaqueURL causes the path of RawURL to be sent exactly as given.
+	OpaqueURL bool
+
+	// PresignedURL marks RawURL as presigned URL, which must not be modified.
+	PresignedURL bool
+
+	// SensitiveHeaders contains the headers removed on cross-origin redirects. If nil, a default list is used.
+	SensitiveHeaders []string
+
+	// BaseURL is the base URL set using [WithBaseURL] or [WithBaseURLJoin], if any.
+	BaseURL *url.URL
+
+	// Origin is the origin the options of the request were configured for, if it differs from the request URL.
+	//
+	// Sensitive headers are removed from requests to other origins.
+	Origin *url.URL
+
+	// Redactor is used to remove secrets from diagnostics. If nil, [DefaultRedactor] is used.
+	Redactor *Redactor
+
+	// HTTPVersion specifies the HTTP version used for the request.
+	HTTPVersion HTTPVersion
+
+	// IsolateTransport returns the isolated copy of the given transport that should be used for the request.
+	IsolateTransport func(*http.Transport) *http.Transport
+
+	// ClientCertificate returns the copy of the given transport that is configured to use a client certificate.
+	ClientCertificate func(*http.Transport) *http.Transport
+
+	// SSRFGuard returns the copy of the given transport that only connects to allowed addresses.
+	SSRFGuard func(*http.Transport) *http.Transport
+
+	// Middlewares wrap the transport of the client, if any.
+	Middlewares []Middleware
+
+	// Mirrors contains the base URLs of mirrors used when requests fail, if any.
+	Mirrors []*url.URL
+
+	// ConfigureProxy returns a copy of the given transport that uses the configured proxy.
+	ConfigureProxy func(*http.Transport) *http.Transport
+
+	// ProxyEnabled reports whether ConfigureProxy configures the transport to send requests via a proxy.
+	ProxyEnabled bool
+
+	// ProxyAuth returns a transport based on the given transport that authenticates requests to proxies.
+	ProxyAuth func(*http.Transport) http.RoundTripper
+
+	// HTTPSOnly causes requests using plain HTTP to fail.
+	HTTPSOnly bool
+
+	// HSTS is used to upgrade requests to known HTTPS hosts, if set.
+	HSTS *HSTSCache
+
+	// Challenger answers authentication challenges for the request, if set.
+	Challenger Challenger
+
+	// Handshake performs connection-based authentication handshakes for the request, if set.
+	Handshake *handshakeAuth
+
+	// Tokens provides bearer tokens for the request, if set.
+	Tokens *tokenCache
+
+	// TokenAuthorization returns the Authorization header value for tokens from Tokens, if set.
+	TokenAuthorization func(token string) string
+
+	// Cache is used to cache responses, if set.
+	Cache Cache
+
+	// Offline causes all requests to be answered from Cache.
+	Offline bool
+
+	// CacheMisses coalesces concurrent requests missing the cache, if set.
+	CacheMisses *missGroup
+
+	// CacheHook is called for each request handled by Cache, if set.
+	CacheHook func(CacheEvent)
+
+	// CSRF adds CSRF tokens to mutating requests, if set.
+	CSRF *csrf
+
+	// Session authenticates the request using a session created by [NewSessionClient], if set.
+	Session *session
+
+	// DigestAuth handles Digest challenges for the request, if set.
+	DigestAuth *digestAuth
+
+	// Rand is the source of randomness used by the request.
+	//
+	// If nil, the global source of the math/rand/v2 package is used.
+	Rand *randSource
+
+	// Faults configures the faults injected into requests, if set.
+	Faults *FaultConfig
+
+	// URLTemplate contains the request URL before any wildcards were replaced.
+	URLTemplate string
+
+	// PathTemplate contains the request path before any wildcards were replaced.
+	PathTemplate string
+
+	// Attempt is the number of the current attempt, starting at 1.
+	Attempt int
+
+	// Retry configures how failed requests are retried, if set.
+	Retry *RetryPolicy
+
+	// Scheduler is used to admit the request, if set.
+	Scheduler *Scheduler
+
+	// Priority is the priority of the request used by Scheduler.
+	Priority Priority
+
+	// PriorityHeaders specifies whether Priority is sent to the server using the Priority header.
+	PriorityHeaders bool
+
+	// Memo is used to cache decoded responses, if set.
+	Memo *memoCache
+
+	// ErrorBodySnippet is the maximum number of body bytes included in errors returned by Handler.
+	ErrorBodySnippet int
+
+	// BodyTee returns the writer response bodies are copied into while they are read, if set.
+	BodyTee func(*http.Response) BodyWriter
+
+	// DecodeTimeout limits the time spent reading the response body, if positive.
+	DecodeTimeout time.Duration
+
+	// MinThroughput is the minimum number of bytes per second that must be received for the body, if positive.
+	MinThroughput int64
+
+	// MinThroughputWindow is the window over which MinThroughput is measured.
+	MinThroughputWindow time.Duration
+
+	// UploadLimiter limits the bandwidth used for the request body, if set.
+	UploadLimiter *bandwidthLimiter
+
+	// DownloadLimiter limits the bandwidth used for the response body, if set.
+	DownloadLimiter *bandwidthLimiter
+
+	// MaxResponseBytes is the maximum number of bytes that can be read from the response body, if positive.
+	MaxResponseBytes int64
+
+	// SpoolThreshold is the maximum size of response bodies kept in memory when spooling, if positive.
+	SpoolThreshold int64
+
+	// KeepBodyOpen causes the response to be returned without calling Handler.
+	KeepBodyOpen bool
+
+	// BodyOwnershipCheck is called for violations of the body ownership contract, if set.
+	BodyOwnershipCheck func(err error)
+
+	// HandlerTrace is called for each handler that was tried, if set.
+	HandlerTrace func(name string, err error)
+
+	// OperationName is the name of the logical operation performed by the request, if set.
+	OperationName string
+
+	// Labels contains the labels of the request, if any.
+	Labels map[string]string
+
+	// Tracer is used to create a span for the request, if set.
+	Tracer Tracer
+
+	// Metrics is used to record metrics for the request, if set.
+	Metrics Metrics
+
+	// Logger is used to log the start and end of the request, if set.
+	Logger *slog.Logger
+
+	// LoggerOptions configures how the request is logged using Logger.
+	LoggerOptions LoggerOptions
+
+	// Hooks are called during the lifecycle of the request.
+	Hooks Hooks
+
+	// Stats is used to record statistics about the request, if set.
+	Stats *Stats
+
+	// Tracker is used to track the request while it is executed.
+	Tracker *requestTracker
+
+	// Tracked is the entry of the request in Tracker.
+	Tracked *trackedRequest
 }
 
 // DefaultHandlers is the default [Handler] used by [Fetch] if no other [Handler] was specified.
 //
-// It will automatically handle RFC 9457 style errors, JSON and XML responses as well as 204 and 304 responses.
+// It will automatically handle RFC 9457 style errors, successful responses for [io.ReadCloser] and []byte
+// destinations, successful JSON and XML responses, successful plain text responses for string and
+// [encoding.TextUnmarshaler] destinations as well as 204 and 304 responses. Other successful responses are passed to
+// destinations implementing [encoding.BinaryUnmarshaler] or [encoding.TextUnmarshaler]. Successful JSON and XML
+// responses without proper content type are detected using [SniffHandler]. Remaining responses with a non-2xx status
+// code, including JSON and XML error responses that are not RFC 9457 problems, are turned into a [*StatusError] using
+// [ErrorStatusHandler].
+//
+// When DefaultHandlers is used because no other [Handler] was specified, [Fetch] also sets the Accept header based on
+// the destination type, unless the header was already set.
 var DefaultHandlers = HandlerChain{
 	ProblemHandler(),
-	ContentTypeHandler("application/json", UnmarshalJSONHandler()),
-	ContentTypeHandler("application/xml", UnmarshalXMLHandler(true)),
+	ConditionalHandler(IsSuccess, ReadCloserHandler()),
+	ConditionalHandler(IsSuccess, BytesHandler()),
+	ConditionalHandler(IsSuccess, ContentTypeHandler("application/json", UnmarshalJSONHandler())),
+	ConditionalHandler(IsSuccess,
+		ContentTypeHandler("application/xml", UnmarshalXMLHandlerWithOptions(XMLOptions{Strict: true}))),
+	// Plain text is commonly used for error pages, for example by [http.Error].
+	ConditionalHandler(IsSuccess, ContentTypeHandler("text/plain", UnmarshalTextHandler())),
 	StatusHandler(http.StatusNoContent, DiscardBodyHandler()),
 	StatusHandler(http.StatusNotModified, DiscardBodyHandler()),
+	ConditionalHandler(IsSuccess, UnmarshalerHandler()),
+	ConditionalHandler(IsSuccess, SniffHandler()),
+	ErrorStatusHandler(),
 }
 
 // FetchOption defines the signature for functions that can be used to configure the request creation and response
@@ -62,7 +298,8 @@ func Fetch[T any](ctx context.Context, method string, url string, opts ...FetchO
 
 // FetchWithResponse is the same as [Fetch], but also returns the raw response.
 //
-// Depending on the used [Handler], the response body may already be closed.
+// Depending on the used [Handler], the response body may already be closed. Use [WithKeepBodyOpen] to skip the
+// [Handler] and keep the body open.
 //
 // If the response was already received, it will be returned even on error.
 func FetchWithResponse[T any](
@@ -77,24 +314,10 @@ func FetchWithResponse[T any](
 		return zeroT, nil, err
 	}
 
-	fetchCtx := &fetchContext{Client: http.DefaultClient, Request: req, Handler: DefaultHandlers}
-
-	for _, opt := range opts {
-		if err := opt(fetchCtx); err != nil {
-			var zeroT T
-			return zeroT, nil, err
-		}
-	}
-
-	resp, err := fetchCtx.Client.Do(req)
-	if err != nil {
-		var zeroT T
-		return zeroT, resp, err
-	}
-
 	var t T
 
-	if err := fetchCtx.Handler.HandleResponse(&t, resp); err != nil {
+	resp, err := fetch(req, url, &t, opts)
+	if err != nil {
 		var zeroT T
 		return zeroT, resp, err
 	}
@@ -102,216 +325,1256 @@ func FetchWithResponse[T any](
 	return t, resp, nil
 }
 
-// WithClient sets the underlying client used by [Fetch] to make the request and receive the response.
-func WithClient(client *http.Client) FetchOption {
-	return func(fetchCtx *fetchContext) error {
-		fetchCtx.Client = client
-		return nil
+// fetch applies the given options to the request, sends it and handles the response using dst as destination.
+func fetch(req *http.Request, rawURL string, dst any, opts []FetchOption) (resp *http.Response, err error) {
+	fetchCtx := &fetchContext{Client: http.DefaultClient, Request: req, RawURL: rawURL}
+
+	for _, opt := range opts {
+		if err := opt(fetchCtx); err != nil {
+			return nil, err
+		}
 	}
-}
 
-// WithBaseURL configures a request to use the given base URL.
-//
-// This can be useful for example when the paths are always the same but the domain may differ and allows for easier
-// separation between those.
-func WithBaseURL(baseURL *url.URL) FetchOption {
-	return func(ctx *fetchContext) error {
-		ctx.Request.URL = baseURL.ResolveReference(ctx.Request.URL)
-		return nil
+	if fetchCtx.Hooks.OnError != nil {
+		defer func() {
+			if err != nil {
+				fetchCtx.Hooks.OnError(fetchCtx.hookEvent(resp, err))
+			}
+		}()
 	}
-}
 
-// Copied from https://github.com/golang/go/blob/a11643df8ff8a575abe4abc7f25d09631424ea49/src/net/http/pattern.go#L186
-func isValidWildcardName(s string) bool {
-	if s == "" {
-		return false
+	if fetchCtx.Handler == nil {
+		fetchCtx.Handler = DefaultHandlers
+		fetchCtx.applyDefaultAccept(dst)
 	}
-	// Valid Go identifier.
-	for i, c := range s {
-		if !unicode.IsLetter(c) && c != '_' && (i == 0 || !unicode.IsDigit(c)) {
-			return false
-		}
+
+	if fetchCtx.PriorityHeaders {
+		fetchCtx.applyPriorityHeader()
 	}
-	return true
-}
 
-// WithPathValue searches the URL path for wildcards with the given key and replaces them with the given value.
-//
-// Wildcards are specified using { and } around a wildcard name. The wildcard name must be a valid Go identifier. If the
-// name is empty or not a valid Go identifier, WithPathValue will panic. Identifiers are case-sensitive.
-//
-// For example given the path "/product/{id}", calling WithPathValue("id", "1234") will result in "/product/1234".
-//
-// The wildcard is not required to be a full path segment. For example, "/b_{bucket}" is a valid pattern and calling
-// WithPathValue("bucket", "test") would result in a path of "/b_test".
-//
-// The value will automatically be escaped using [url.PathEscape].
-//
-// Specifying WithPathValue multiple times with the same name will cause all but the first one to become no-ops.
-func WithPathValue(name string, value string) FetchOption {
-	if name == "" {
-		panic(errors.New("empty wildcard"))
+	if fetchCtx.PresignedURL {
+		if err := fetchCtx.checkPresignedURL(); err != nil {
+			return nil, err
+		}
 	}
 
-	if !isValidWildcardName(name) {
-		panic(fmt.Errorf("bad wildcard name %q", name))
+	fetchCtx.URLTemplate = urlTemplate(req.URL)
+	fetchCtx.PathTemplate = pathTemplate(req.URL)
+
+	if err := fetchCtx.applyIDN(); err != nil {
+		return nil, err
 	}
 
-	wildcard := "{" + name + "}"
+	switch {
+	case fetchCtx.OpaqueURL:
+		if err := fetchCtx.applyOpaqueURL(); err != nil {
+			return nil, err
+		}
+	case !fetchCtx.PreserveURL:
+		if err := fetchCtx.expandPath(); err != nil {
+			return nil, err
+		}
 
-	escaped := url.PathEscape(value)
+		if err := fetchCtx.applyTrailingSlash(); err != nil {
+			return nil, err
+		}
 
-	return func(ctx *fetchContext) error {
-		ctx.Request.URL.Path = strings.ReplaceAll(ctx.Request.URL.Path, wildcard, escaped)
-		return nil
+		if fetchCtx.NormalizeURL {
+			if err := fetchCtx.applyNormalizedURL(); err != nil {
+				return nil, err
+			}
+		}
 	}
-}
 
-// WithAddedQueryParam adds a query parameter.
-//
-// Existing values are kept and the new value is added after them.
-func WithAddedQueryParam(key, value string) FetchOption {
-	return func(ctx *fetchContext) error {
-		q := ctx.Request.URL.Query()
-		q.Add(key, value)
-		ctx.Request.URL.RawQuery = q.Encode()
-		return nil
+	if err := fetchCtx.applyIsolatedTransport(); err != nil {
+		return nil, err
 	}
-}
 
-// WithQueryParam sets a query parameter.
-//
-// Any existing values for the parameter are replaced.
-func WithQueryParam(key, value string) FetchOption {
-	return func(ctx *fetchContext) error {
-		q := ctx.Request.URL.Query()
-		q.Set(key, value)
-		ctx.Request.URL.RawQuery = q.Encode()
-		return nil
+	if err := fetchCtx.applyClientCertificate(); err != nil {
+		return nil, err
 	}
-}
 
-// WithAddedHeader adds a header parameter.
-//
-// Existing values are kept and the new value is added after them.
-func WithAddedHeader(key, value string) FetchOption {
-	return func(ctx *fetchContext) error {
-		ctx.Request.Header.Add(key, value)
-		return nil
+	if err := fetchCtx.applyProxy(); err != nil {
+		return nil, err
 	}
-}
 
-// WithHeader sets a header.
-//
-// Any existing values for the header are replaced.
-func WithHeader(key, value string) FetchOption {
-	return func(ctx *fetchContext) error {
-		ctx.Request.Header.Set(key, value)
-		return nil
+	if err := fetchCtx.applySSRFGuard(); err != nil {
+		return nil, err
 	}
-}
 
-func asReadCloser(r io.Reader) io.ReadCloser {
-	rc, ok := r.(io.ReadCloser)
-	if !ok {
-		return io.NopCloser(r)
+	if err := fetchCtx.applyHTTPVersion(); err != nil {
+		return nil, err
 	}
-	return rc
-}
 
-// WithBody sets the body for the request to the given io.Reader.
-//
-// If the given reader is either a [*bytes.Buffer], [*bytes.Reader] or [*strings.Reader] it will also set the content
-// length to number of bytes available.
-func WithBody(body io.Reader) FetchOption {
-	return func(ctx *fetchContext) error {
-		switch v := body.(type) {
-		case *bytes.Buffer:
-			ctx.Request.ContentLength = int64(v.Len())
-		case *bytes.Reader:
-			ctx.Request.ContentLength = int64(v.Len())
-		case *strings.Reader:
-			ctx.Request.ContentLength = int64(v.Len())
-		}
+	if err := fetchCtx.applyHandshakeTransport(); err != nil {
+		return nil, err
+	}
 
-		ctx.Request.Body = asReadCloser(body)
-		return nil
+	if err := fetchCtx.applyProxyAuth(); err != nil {
+		return nil, err
 	}
-}
 
-// WithBodyJSON encodes the given value as JSON and uses the result as the request body.
-//
-// If the Content-Type header is not set or empty, it will be set to "application/json".
-func WithBodyJSON(v any, opts ...jsontext.Options) FetchOption {
-	return func(ctx *fetchContext) error {
-		body, err := json.Marshal(v, opts...)
-		if err != nil {
-			return err
-		}
+	fetchCtx.applyMiddlewares()
+	fetchCtx.applyHTTPS()
+	fetchCtx.applyMirrors()
+	fetchCtx.applyRedirectPolicy()
+	fetchCtx.applyFaultInjection()
+	fetchCtx.applyDigestAuth()
+	fetchCtx.applyChallenger()
+	fetchCtx.applyHandshake()
+	fetchCtx.applyTokenSource()
+	fetchCtx.applyCSRF()
+	fetchCtx.applySession()
+	fetchCtx.applyCache()
 
-		if ctx.Request.Header.Get("Content-Type") == "" {
-			ctx.Request.Header.Set("Content-Type", "application/json")
-		}
+	if fetchCtx.Memo != nil && !fetchCtx.KeepBodyOpen {
+		if resp, ok := fetchCtx.Memo.load(dst, req, fetchCtx.redactor()); ok {
+			if fetchCtx.Stats != nil {
+				*fetchCtx.Stats = Stats{}
+			}
 
-		ctx.Request.ContentLength = int64(len(body))
-		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
-		ctx.Request.GetBody = func() (io.ReadCloser, error) {
-			return io.NopCloser(bytes.NewReader(body)), nil
+			return resp, nil
 		}
+	}
 
-		return nil
+	if fetchCtx.Tracer != nil {
+		span := fetchCtx.startSpan()
+		defer func() { span.End(fetchCtx.spanResult(resp, err)) }()
 	}
-}
 
-// Handler specifies methods for handling responses.
-type Handler interface {
-	// HandleResponse is called after receiving a response and is passed both the response and a pointer to the
-	// value that should be filled with the response.
-	HandleResponse(dst any, resp *http.Response) error
-}
+	if fetchCtx.Metrics != nil {
+		finish := fetchCtx.startMetrics()
+		defer func() { finish(fetchCtx.spanResult(resp, err)) }()
+	}
 
-// HandlerFunc implements the [Handler] interface using itself as [Handler.HandleResponse] implementation.
-type HandlerFunc func(dst any, resp *http.Response) error
+	if fetchCtx.Logger != nil {
+		finish := fetchCtx.startLogging()
+		defer func() { finish(fetchCtx.spanResult(resp, err)) }()
+	}
 
-// HandleResponse returns the result of calling h(dst, resp).
-func (h HandlerFunc) HandleResponse(dst any, resp *http.Response) error {
-	return h(dst, resp)
-}
+	if len(fetchCtx.JSONOptions) > 0 {
+		fetchCtx.Request = fetchCtx.Request.WithContext(
+			context.WithValue(fetchCtx.Request.Context(), jsonOptionsKey{}, fetchCtx.JSONOptions))
+	}
 
-// ErrUnhandledResponse can be returned by [Handler.HandleResponse] when the implementation can not handle the
-// given response.
-var ErrUnhandledResponse = errors.New("github.com/nussjustin/httpc: unhandled response")
+	if fetchCtx.UnknownJSONFields != nil {
+		fetchCtx.Request = withUnknownJSONFields(fetchCtx.Request, fetchCtx.UnknownJSONFields)
+	}
 
-// WithHandler sets the [Handler] used by [Fetch] to process the response.
-func WithHandler(h Handler) FetchOption {
-	return func(ctx *fetchContext) error {
-		ctx.Handler = h
-		return nil
+	if fetchCtx.Redactor != nil {
+		fetchCtx.Request = withRedactor(fetchCtx.Request, fetchCtx.Redactor)
 	}
-}
 
-// WithHandlerFunc is a shortcut for WithHandler(HandlerFunc(h)).
-func WithHandlerFunc(h HandlerFunc) FetchOption {
-	return WithHandler(h)
-}
+	fetchCtx.Request = withPathTemplate(fetchCtx.Request, fetchCtx.PathTemplate)
 
-// HandlerChain wraps multiple [Handler] implementations in a single [Handler] that calls each underlying [Handler] in
-// order of first to last, until one returns a nil error or any error that is not [ErrUnhandledResponse], as determined
-// by [errors.Is].
-//
-// If the chain is empty or no [Handler] can handle the response, [ErrUnhandledResponse] is returned.
-type HandlerChain []Handler
+	if fetchCtx.OperationName != "" {
+		fetchCtx.Request = withOperationName(fetchCtx.Request, fetchCtx.OperationName)
+	}
 
-// HandleResponse implements the [Handler] interface.
-func (h HandlerChain) HandleResponse(dst any, resp *http.Response) error {
-	for i := range h {
-		if err := h[i].HandleResponse(dst, resp); err == nil || !errors.Is(err, ErrUnhandledResponse) {
-			return err
-		}
+	if fetchCtx.HandlerTrace != nil {
+		fetchCtx.Request = withHandlerTrace(fetchCtx.Request, fetchCtx.HandlerTrace)
 	}
 
-	return ErrUnhandledResponse
-}
+	if fetchCtx.Stats != nil {
+		fetchCtx.countRequestBytes()
+
+		if fetchCtx.ConfigureProxy != nil {
+			fetchCtx.Request = fetchCtx.Request.WithContext(
+				context.WithValue(fetchCtx.Request.Context(), proxyStatsKey{}, fetchCtx.Stats))
+		}
+	}
+
+	if fetchCtx.UploadLimiter != nil {
+		fetchCtx.limitRequestBandwidth()
+	}
+
+	if fetchCtx.Scheduler != nil {
+		if err := fetchCtx.Scheduler.acquire(req.Context(), fetchCtx.Priority); err != nil {
+			return nil, err
+		}
+		defer fetchCtx.Scheduler.release()
+	}
+
+	if fetchCtx.Tracker != nil {
+		fetchCtx.Tracked = fetchCtx.Tracker.add(fetchCtx)
+		defer fetchCtx.Tracker.remove(fetchCtx.Tracked)
+	}
+
+	resp, err = fetchCtx.doWithRetry()
+	if err != nil {
+		return resp, fetchCtx.formatError(err)
+	}
+
+	if fetchCtx.Stats != nil {
+		fetchCtx.countResponseBytes(resp)
+	}
+
+	if fetchCtx.DecodeTimeout > 0 {
+		fetchCtx.limitDecodeTime(resp)
+	}
+
+	if fetchCtx.MinThroughput > 0 {
+		fetchCtx.watchThroughput(resp)
+	}
+
+	if fetchCtx.DownloadLimiter != nil {
+		fetchCtx.limitResponseBandwidth(resp)
+	}
+
+	if fetchCtx.MaxResponseBytes > 0 {
+		fetchCtx.limitResponseBytes(resp)
+	}
+
+	if fetchCtx.SpoolThreshold > 0 {
+		if err := fetchCtx.spoolBody(resp); err != nil {
+			return resp, fetchCtx.formatError(err)
+		}
+	}
+
+	if fetchCtx.BodyTee != nil {
+		fetchCtx.teeResponseBody(resp)
+	}
+
+	if fetchCtx.KeepBodyOpen {
+		return resp, nil
+	}
+
+	var snippet *snippetReadCloser
+
+	if fetchCtx.ErrorBodySnippet > 0 {
+		snippet = fetchCtx.recordSnippet(resp)
+	}
+
+	body := fetchCtx.ownBody(resp)
+
+	err = fetchCtx.Handler.HandleResponse(dst, resp)
+
+	if fetchCtx.HandlerTrace != nil {
+		fetchCtx.HandlerTrace(handlerName(fetchCtx.Handler), err)
+	}
+
+	// Handlers may wrap the body. Once closed, the tracked body is restored, so that it is not read or closed again.
+	if body.isClosed() {
+		resp.Body = body
+	}
+
+	if err != nil {
+		if snippet != nil {
+			err = snippet.wrap(err)
+		}
+
+		return resp, err
+	}
+
+	fetchCtx.checkBodyOwnership(body, dst)
+
+	if fetchCtx.Memo != nil {
+		fetchCtx.Memo.store(dst, req, fetchCtx.redactor(), resp)
+	}
+
+	return resp, nil
+}
+
+// do sends the request using the underlying client.
+func (ctx *fetchContext) do() (*http.Response, error) {
+	ctx.Attempt++
+
+	if ctx.Tracked != nil {
+		ctx.Tracked.attempt.Store(int64(ctx.Attempt))
+	}
+
+	if ctx.Stats != nil {
+		ctx.Stats.Attempts = ctx.Attempt
+	}
+
+	if ctx.Hooks.OnRequest != nil {
+		ctx.Hooks.OnRequest(ctx.hookEvent(nil, nil))
+	}
+
+	resp, err := ctx.Client.Do(ctx.Request)
+
+	if resp != nil && ctx.Hooks.OnResponse != nil {
+		ctx.Hooks.OnResponse(ctx.hookEvent(resp, nil))
+	}
+
+	return resp, err
+}
+
+// urlTemplate returns the given URL as string, keeping any wildcards unescaped.
+func urlTemplate(u *url.URL) string {
+	return strings.NewReplacer("%7B", "{", "%7D", "}").Replace(u.String())
+}
+
+// WithClient sets the underlying client used by [Fetch] to make the request and receive the response.
+func WithClient(client *http.Client) FetchOption {
+	return func(fetchCtx *fetchContext) error {
+		fetchCtx.Client = client
+		return nil
+	}
+}
+
+// WithBaseURL configures a request to use the given base URL.
+//
+// This can be useful for example when the paths are always the same but the domain may differ and allows for easier
+// separation between those.
+//
+// The request URL is resolved against the base URL as defined by RFC 3986, which means that an absolute request path
+// replaces the path of the base URL. To append the request path to the path of the base URL instead, use
+// [WithBaseURLJoin] with [PathJoinAppend].
+func WithBaseURL(baseURL *url.URL) FetchOption {
+	return WithBaseURLJoin(baseURL, PathJoinResolve)
+}
+
+// PathJoin specifies how [WithBaseURLJoin] combines the path of the base URL with the path of the request URL.
+type PathJoin int
+
+const (
+	// PathJoinResolve resolves the request URL against the base URL as defined by RFC 3986, section 5.2.
+	//
+	// For example a base path of "/api" and a request path of "/v1/users" will result in "/v1/users", while a base
+	// path of "/api/" and a request path of "v1/users" will result in "/api/v1/users".
+	//
+	// This is the behaviour of [WithBaseURL].
+	PathJoinResolve PathJoin = iota
+
+	// PathJoinAppend appends the request path to the base path, making sure that both are separated by a single slash.
+	//
+	// For example a base path of "/api" or "/api/" and a request path of "/v1/users" or "v1/users" will always result
+	// in "/api/v1/users".
+	//
+	// If the request URL has no query, the query of the base URL is used. Absolute request URLs are used as is.
+	PathJoinAppend
+)
+
+// WithBaseURLJoin is the same as [WithBaseURL], but uses the given [PathJoin] to combine the paths of the base URL and
+// the request URL.
+func WithBaseURLJoin(baseURL *url.URL, join PathJoin) FetchOption {
+	switch join {
+	case PathJoinResolve:
+		return func(ctx *fetchContext) error {
+			ctx.BaseURL = baseURL
+			ctx.Request.URL = baseURL.ResolveReference(ctx.Request.URL)
+			return nil
+		}
+	case PathJoinAppend:
+		return func(ctx *fetchContext) error {
+			ctx.BaseURL = baseURL
+
+			ref := ctx.Request.URL
+
+			if ref.Scheme != "" || ref.Host != "" {
+				return nil
+			}
+
+			u := *baseURL
+
+			if ref.RawQuery != "" || ref.ForceQuery {
+				u.RawQuery, u.ForceQuery = ref.RawQuery, ref.ForceQuery
+			}
+
+			u.Fragment, u.RawFragment = ref.Fragment, ref.RawFragment
+
+			if refPath := ref.EscapedPath(); refPath != "" {
+				path := strings.TrimSuffix(baseURL.EscapedPath(), "/") + "/" + strings.TrimPrefix(refPath, "/")
+
+				if err := setEscapedPath(&u, path); err != nil {
+					return err
+				}
+			}
+
+			ctx.Request.URL = &u
+			return nil
+		}
+	default:
+		panic(fmt.Errorf("unknown path join %d", join))
+	}
+}
+
+// TrailingSlash specifies how [WithTrailingSlash] handles trailing slashes in the request path.
+type TrailingSlash int
+
+const (
+	// TrailingSlashKeep keeps the request path as is.
+	//
+	// This is the default.
+	TrailingSlashKeep TrailingSlash = iota
+
+	// TrailingSlashStrip removes all trailing slashes from the request path, unless the path consists only of slashes.
+	TrailingSlashStrip
+
+	// TrailingSlashAdd adds a trailing slash to the request path if it does not already end with one.
+	TrailingSlashAdd
+)
+
+// WithTrailingSlash configures how trailing slashes in the request path are handled.
+//
+// The configured [TrailingSlash] is applied after all options were applied and all wildcards were replaced.
+func WithTrailingSlash(policy TrailingSlash) FetchOption {
+	switch policy {
+	case TrailingSlashKeep, TrailingSlashStrip, TrailingSlashAdd:
+	default:
+		panic(fmt.Errorf("unknown trailing slash policy %d", policy))
+	}
+
+	return func(ctx *fetchContext) error {
+		ctx.TrailingSlash = policy
+		return nil
+	}
+}
+
+// applyTrailingSlash adds or removes trailing slashes from the request path based on the configured policy.
+func (ctx *fetchContext) applyTrailingSlash() error {
+	u := ctx.Request.URL
+
+	path := u.EscapedPath()
+
+	switch ctx.TrailingSlash {
+	case TrailingSlashKeep:
+		return nil
+	case TrailingSlashStrip:
+		if trimmed := strings.TrimRight(path, "/"); trimmed != "" {
+			path = trimmed
+		}
+	case TrailingSlashAdd:
+		if !strings.HasSuffix(path, "/") {
+			path += "/"
+		}
+	}
+
+	return setEscapedPath(u, path)
+}
+
+// setEscapedPath updates both the Path and RawPath of the given URL using the given escaped path.
+func setEscapedPath(u *url.URL, escaped string) error {
+	path, err := url.PathUnescape(escaped)
+	if err != nil {
+		return err
+	}
+
+	u.Path, u.RawPath = path, ""
+
+	if escapePath(path) != escaped {
+		u.RawPath = escaped
+	}
+
+	return nil
+}
+
+// Copied from https://github.com/golang/go/blob/a11643df8ff8a575abe4abc7f25d09631424ea49/src/net/http/pattern.go#L186
+func isValidWildcardName(s string) bool {
+	if s == "" {
+		return false
+	}
+	// Valid Go identifier.
+	for i, c := range s {
+		if !unicode.IsLetter(c) && c != '_' && (i == 0 || !unicode.IsDigit(c)) {
+			return false
+		}
+	}
+	return true
+}
+
+// PathEncoding specifies how a value passed to [WithPathValueEncoding] is encoded before it is inserted into the path.
+type PathEncoding int
+
+const (
+	// PathEncodingEscape escapes the value using [url.PathEscape].
+	//
+	// This is the encoding used by [WithPathValue].
+	PathEncodingEscape PathEncoding = iota
+
+	// PathEncodingNone inserts the value as is.
+	//
+	// This can be used for values that are already percent-encoded. The value must be a valid percent-encoded string.
+	PathEncodingNone
+
+	// PathEncodingSegments splits the value at each "/" and escapes each part using [url.PathEscape], so that a value
+	// like "a/b/c" expands to multiple path segments.
+	PathEncodingSegments
+)
+
+// WithPathValue searches the URL path for wildcards with the given key and replaces them with the given value.
+//
+// Wildcards are specified using { and } around a wildcard name. The wildcard name must be a valid Go identifier. If the
+// name is empty or not a valid Go identifier, WithPathValue will panic. Identifiers are case-sensitive.
+//
+// For example given the path "/product/{id}", calling WithPathValue("id", "1234") will result in "/product/1234".
+//
+// The wildcard is not required to be a full path segment. For example, "/b_{bucket}" is a valid pattern and calling
+// WithPathValue("bucket", "test") would result in a path of "/b_test".
+//
+// The value will automatically be escaped using [url.PathEscape]. To use a different encoding, use
+// [WithPathValueEncoding].
+//
+// Wildcards are replaced after all options were applied, so the order of WithPathValue relative to other options,
+// like [WithBaseURL], does not matter.
+//
+// Matrix-style parameters as defined by RFC 6570 are supported by prefixing the wildcard name with a semicolon. For
+// example given the path "/map{;x,y}", calling WithPathValue("x", "1024") and WithPathValue("y", "768") will result
+// in "/map;x=1024;y=768". Wildcards in matrix-style expressions are optional and are omitted if no value was given.
+//
+// Specifying WithPathValue multiple times with the same name will cause all but the first one to become no-ops.
+func WithPathValue(name string, value string) FetchOption {
+	return WithPathValueEncoding(name, value, PathEncodingEscape)
+}
+
+// WithPathValueEncoding is the same as [WithPathValue], but encodes the value using the given [PathEncoding].
+func WithPathValueEncoding(name string, value string, encoding PathEncoding) FetchOption {
+	if name == "" {
+		panic(errors.New("empty wildcard"))
+	}
+
+	if !isValidWildcardName(name) {
+		panic(fmt.Errorf("bad wildcard name %q", name))
+	}
+
+	var encoded string
+
+	switch encoding {
+	case PathEncodingEscape:
+		encoded = url.PathEscape(value)
+	case PathEncodingNone:
+		encoded = value
+	case PathEncodingSegments:
+		segments := strings.Split(value, "/")
+		for i := range segments {
+			segments[i] = url.PathEscape(segments[i])
+		}
+		encoded = strings.Join(segments, "/")
+	default:
+		panic(fmt.Errorf("unknown path encoding %d", encoding))
+	}
+
+	return func(ctx *fetchContext) error {
+		if _, ok := ctx.PathValues[name]; ok {
+			return nil
+		}
+
+		if encoding == PathEncodingNone {
+			if _, err := url.PathUnescape(value); err != nil {
+				return fmt.Errorf("invalid value for wildcard %q: %w", name, err)
+			}
+		}
+
+		if ctx.PathValues == nil {
+			ctx.PathValues = make(map[string]string)
+		}
+
+		ctx.PathValues[name] = encoded
+		return nil
+	}
+}
+
+type integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// WithPathValueInt is the same as [WithPathValue], but takes an integer that is formatted as decimal number.
+func WithPathValueInt[T integer](name string, value T) FetchOption {
+	if value < 0 {
+		return WithPathValue(name, strconv.FormatInt(int64(value), 10))
+	}
+
+	return WithPathValue(name, strconv.FormatUint(uint64(value), 10))
+}
+
+// WithPathValueUUID is the same as [WithPathValue], but takes a UUID that is formatted using the canonical
+// representation as defined by RFC 9562, for example "f81d4fae-7dec-11d0-a765-00a0c91e6bf6".
+//
+// The value can be of any type based on [16]byte, which includes UUID types provided by most third party packages.
+func WithPathValueUUID[T ~[16]byte](name string, value T) FetchOption {
+	var b [36]byte
+
+	hex.Encode(b[0:8], value[0:4])
+	b[8] = '-'
+	hex.Encode(b[9:13], value[4:6])
+	b[13] = '-'
+	hex.Encode(b[14:18], value[6:8])
+	b[18] = '-'
+	hex.Encode(b[19:23], value[8:10])
+	b[23] = '-'
+	hex.Encode(b[24:], value[10:])
+
+	return WithPathValue(name, string(b[:]))
+}
+
+// WithPathValueStringer is the same as [WithPathValue], but uses the result of calling the String method on the given
+// value.
+func WithPathValueStringer[T fmt.Stringer](name string, value T) FetchOption {
+	return WithPathValue(name, value.String())
+}
+
+// ErrMissingPathValue is returned by [Fetch] when strict path values are enabled and the request path still contains
+// a wildcard after all options were applied.
+var ErrMissingPathValue = errors.New("github.com/nussjustin/httpc: missing path value")
+
+// wildcard describes a single wildcard expression in a path.
+type wildcard struct {
+	// Matrix is true for matrix-style expressions like "{;name}".
+	Matrix bool
+
+	// Names contains the names of all wildcards in the expression.
+	Names []string
+}
+
+func parseWildcard(s string) (wildcard, bool) {
+	var w wildcard
+
+	s, w.Matrix = strings.CutPrefix(s, ";")
+
+	if w.Matrix {
+		w.Names = strings.Split(s, ",")
+	} else {
+		w.Names = []string{s}
+	}
+
+	for _, name := range w.Names {
+		if !isValidWildcardName(name) {
+			return wildcard{}, false
+		}
+	}
+
+	return w, true
+}
+
+// indexEscaped returns the index of the first occurrence of the percent-encoded form of c in s, or -1.
+func indexEscaped(s string, c byte) int {
+	encoded := fmt.Sprintf("%%%02X", c)
+
+	for offset := 0; ; {
+		i := strings.IndexByte(s[offset:], '%')
+		if i == -1 || offset+i+len(encoded) > len(s) {
+			return -1
+		}
+
+		if strings.EqualFold(s[offset+i:offset+i+len(encoded)], encoded) {
+			return offset + i
+		}
+
+		offset += i + 1
+	}
+}
+
+// nextWildcard returns the first valid wildcard expression in the given escaped path as well as its start and end
+// offsets.
+//
+// Since { and } are always escaped in escaped paths, the wildcard is searched using the escaped form of both.
+//
+// If no wildcard is found, start and end are -1.
+func nextWildcard(path string) (w wildcard, start int, end int) {
+	for offset := 0; ; offset = start + 1 {
+		i := indexEscaped(path[offset:], '{')
+		if i == -1 {
+			return wildcard{}, -1, -1
+		}
+
+		start = offset + i
+
+		j := indexEscaped(path[start:], '}')
+		if j == -1 {
+			return wildcard{}, -1, -1
+		}
+
+		end = start + j + len("%7D")
+
+		expr, err := url.PathUnescape(path[start+len("%7B") : start+j])
+		if err != nil {
+			continue
+		}
+
+		if w, ok := parseWildcard(expr); ok {
+			return w, start, end
+		}
+	}
+}
+
+func escapePath(path string) string {
+	return (&url.URL{Path: path}).EscapedPath()
+}
+
+// expandPath replaces all wildcards in the request path with their configured values.
+func (ctx *fetchContext) expandPath() error {
+	u := ctx.Request.URL
+
+	path := u.EscapedPath()
+
+	var b strings.Builder
+
+	var expanded bool
+
+	for {
+		w, start, end := nextWildcard(path)
+		if start == -1 {
+			break
+		}
+
+		if w.Matrix {
+			b.WriteString(path[:start])
+			path = path[end:]
+
+			for _, name := range w.Names {
+				value, ok := ctx.PathValues[name]
+				if !ok {
+					continue
+				}
+
+				b.WriteByte(';')
+				b.WriteString(url.PathEscape(name))
+
+				if value != "" {
+					b.WriteByte('=')
+					b.WriteString(value)
+				}
+			}
+
+			expanded = true
+			continue
+		}
+
+		value, ok := ctx.PathValues[w.Names[0]]
+		if !ok {
+			if ctx.StrictPathValues {
+				return fmt.Errorf("%w %q", ErrMissingPathValue, w.Names[0])
+			}
+
+			b.WriteString(path[:end])
+			path = path[end:]
+			continue
+		}
+
+		b.WriteString(path[:start])
+		b.WriteString(value)
+		path = path[end:]
+
+		expanded = true
+	}
+
+	if !expanded {
+		return nil
+	}
+
+	b.WriteString(path)
+
+	return setEscapedPath(u, b.String())
+}
+
+// WithStrictPathValues configures whether [Fetch] should fail with [ErrMissingPathValue] if the request path still
+// contains wildcards after all options were applied.
+//
+// This can be used to catch missing calls to [WithPathValue] before a request with a literal wildcard in its path is
+// sent to a server.
+//
+// Strict path values are disabled by default, but are enabled by [WithStrictMode].
+func WithStrictPathValues(strict bool) FetchOption {
+	return func(ctx *fetchContext) error {
+		ctx.StrictPathValues = strict
+		return nil
+	}
+}
+
+// WithStrictMode enables all strict checks.
+//
+// Currently this is the same as WithStrictPathValues(true).
+//
+// Strict JSON decoding is not enabled by WithStrictMode, since it causes requests to fail when an API adds new fields.
+// Use [WithStrictJSON] to enable it.
+//
+// Individual checks can be disabled again by passing the corresponding option after WithStrictMode.
+func WithStrictMode() FetchOption {
+	return func(ctx *fetchContext) error {
+		ctx.StrictPathValues = true
+		return nil
+	}
+}
+
+// addQueryParam adds the given key and value to the raw query of u.
+//
+// If replace is true, all existing values for key are removed first.
+//
+// All other parameters are kept as is, including their order and encoding.
+func addQueryParam(u *url.URL, key, value string, replace bool) {
+	var b strings.Builder
+
+	for pair := range strings.SplitSeq(u.RawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+
+		if replace {
+			name, _, _ := strings.Cut(pair, "=")
+
+			if name, err := url.QueryUnescape(name); err == nil && name == key {
+				continue
+			}
+		}
+
+		b.WriteString(pair)
+		b.WriteByte('&')
+	}
+
+	b.WriteString(url.QueryEscape(key))
+	b.WriteByte('=')
+	b.WriteString(url.QueryEscape(value))
+
+	u.RawQuery = b.String()
+}
+
+// WithAddedQueryParam adds a query parameter.
+//
+// Existing values are kept and the new value is added after them.
+//
+// Other query parameters are kept as is, including their order and encoding.
+func WithAddedQueryParam(key, value string) FetchOption {
+	return func(ctx *fetchContext) error {
+		addQueryParam(ctx.Request.URL, key, value, false)
+		return nil
+	}
+}
+
+// WithQueryParam sets a query parameter.
+//
+// Any existing values for the parameter are replaced.
+//
+// Other query parameters are kept as is, including their order and encoding.
+func WithQueryParam(key, value string) FetchOption {
+	return func(ctx *fetchContext) error {
+		addQueryParam(ctx.Request.URL, key, value, true)
+		return nil
+	}
+}
+
+// WithFragment sets the fragment of the request URL.
+//
+// Fragments are not sent to the server, but are part of the URL of the request as seen by handlers.
+func WithFragment(fragment string) FetchOption {
+	return func(ctx *fetchContext) error {
+		ctx.Request.URL.Fragment, ctx.Request.URL.RawFragment = fragment, ""
+		return nil
+	}
+}
+
+// WithOpaqueURL causes the path of the URL passed to [Fetch] to be sent exactly as given, without being decoded and
+// re-encoded.
+//
+// This can be useful when a server requires a specific percent-encoding, for example for presigned URLs.
+//
+// If the URL passed to [Fetch] is not absolute, only the scheme and host are taken from the base URL. In this case the
+// path must start with a slash.
+//
+// Since the path is sent as is, wildcards in the path are not replaced and trailing slashes are kept as is.
+//
+// The query is always kept as given. Options that add query parameters only append new parameters.
+func WithOpaqueURL() FetchOption {
+	return func(ctx *fetchContext) error {
+		ctx.OpaqueURL = true
+		return nil
+	}
+}
+
+// ErrPresignedURLModified is returned by [Fetch] when [WithPresignedURL] is used together with options that modify
+// the query of the URL.
+var ErrPresignedURLModified = errors.New("github.com/nussjustin/httpc: presigned URL modified")
+
+// WithPresignedURL marks the URL passed to [Fetch] as presigned URL, for example as used by S3 or GCS.
+//
+// Since any modification of the URL would invalidate the signature, this implies [WithOpaqueURL] and causes [Fetch]
+// to fail with [ErrPresignedURLModified] if any option modified the query of the URL.
+//
+// Any Authorization header, for example as set by the default options of a [Client], is removed from the request as
+// the request is already authorized via the URL. For the same reason options that add credentials to requests, like
+// [WithTokenSource], [WithOAuth2], [WithDigestAuth], [WithChallenger], [WithHandshake] and sessions created using
+// [NewSessionClient], are ignored.
+//
+// Other headers are kept as is, since servers may require headers that are part of the signature to be set.
+func WithPresignedURL() FetchOption {
+	return func(ctx *fetchContext) error {
+		ctx.OpaqueURL = true
+		ctx.PresignedURL = true
+		return nil
+	}
+}
+
+// checkPresignedURL verifies that the query of the presigned request URL was not modified and removes any
+// Authorization header.
+func (ctx *fetchContext) checkPresignedURL() error {
+	parsed, err := url.Parse(ctx.RawURL)
+	if err != nil {
+		return err
+	}
+
+	if parsed.RawQuery != ctx.Request.URL.RawQuery {
+		return fmt.Errorf("%w: query changed from %q to %q", ErrPresignedURLModified, parsed.RawQuery,
+			ctx.Request.URL.RawQuery)
+	}
+
+	ctx.Request.Header.Del("Authorization")
+	return nil
+}
+
+// applyOpaqueURL sets the opaque part of the request URL so that the original path is sent without modifications.
+func (ctx *fetchContext) applyOpaqueURL() error {
+	raw, _, _ := strings.Cut(ctx.RawURL, "#")
+	raw, _, _ = strings.Cut(raw, "?")
+
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+
+	if parsed.Scheme != "" {
+		raw = raw[len(parsed.Scheme)+1:]
+	}
+
+	if authority, ok := strings.CutPrefix(raw, "//"); ok {
+		raw = ""
+
+		if i := strings.IndexByte(authority, '/'); i != -1 {
+			raw = authority[i:]
+		}
+	}
+
+	switch {
+	case raw == "":
+		raw = "/"
+	case !strings.HasPrefix(raw, "/"):
+		return fmt.Errorf("opaque URL path %q must start with a slash", raw)
+	}
+
+	ctx.Request.URL.Opaque = "//" + ctx.Request.URL.Host + raw
+	return nil
+}
+
+// WithAddedHeader adds a header parameter.
+//
+// Existing values are kept and the new value is added after them.
+func WithAddedHeader(key, value string) FetchOption {
+	return func(ctx *fetchContext) error {
+		ctx.Request.Header.Add(key, value)
+		return nil
+	}
+}
+
+// WithHeader sets a header.
+//
+// Any existing values for the header are replaced.
+func WithHeader(key, value string) FetchOption {
+	return func(ctx *fetchContext) error {
+		ctx.Request.Header.Set(key, value)
+		return nil
+	}
+}
+
+func asReadCloser(r io.Reader) io.ReadCloser {
+	rc, ok := r.(io.ReadCloser)
+	if !ok {
+		return io.NopCloser(r)
+	}
+	return rc
+}
+
+// WithBody sets the body for the request to the given io.Reader.
+//
+// If the given reader is either a [*bytes.Buffer], [*bytes.Reader] or [*strings.Reader] it will also set the content
+// length to number of bytes available and allow the body to be sent again, for example on redirects or when
+// authentication challenges are answered.
+func WithBody(body io.Reader) FetchOption {
+	return func(ctx *fetchContext) error {
+		ctx.Request.GetBody = nil
+
+		switch v := body.(type) {
+		case *bytes.Buffer:
+			buf := v.Bytes()
+			ctx.Request.ContentLength = int64(len(buf))
+			ctx.Request.GetBody = func() (io.ReadCloser, error) {
+				return io.NopCloser(bytes.NewReader(buf)), nil
+			}
+		case *bytes.Reader:
+			snapshot := *v
+			ctx.Request.ContentLength = int64(v.Len())
+			ctx.Request.GetBody = func() (io.ReadCloser, error) {
+				r := snapshot
+				return io.NopCloser(&r), nil
+			}
+		case *strings.Reader:
+			snapshot := *v
+			ctx.Request.ContentLength = int64(v.Len())
+			ctx.Request.GetBody = func() (io.ReadCloser, error) {
+				r := snapshot
+				return io.NopCloser(&r), nil
+			}
+		}
+
+		ctx.Request.Body = asReadCloser(body)
+		return nil
+	}
+}
+
+// WithBodyJSON encodes the given value as JSON and uses the result as the request body.
+//
+// If the Content-Type header is not set or empty, it will be set to "application/json".
+func WithBodyJSON(v any, opts ...jsontext.Options) FetchOption {
+	return func(ctx *fetchContext) error {
+		body, err := json.Marshal(v, opts...)
+		if err != nil {
+			return err
+		}
+
+		ctx.setJSONBody(body)
+		return nil
+	}
+}
+
+// WithBodyCanonicalJSON is like [WithBodyJSON], but canonicalizes the encoded value as specified by RFC 8785.
+//
+// Canonicalization sorts object members by name, removes insignificant white space and formats strings and numbers
+// in a deterministic way, so that equal values always result in the same bytes. This is needed for example when
+// signing request bodies.
+func WithBodyCanonicalJSON(v any, opts ...jsontext.Options) FetchOption {
+	return func(ctx *fetchContext) error {
+		body, err := json.Marshal(v, opts...)
+		if err != nil {
+			return err
+		}
+
+		value := jsontext.Value(body)
+		if err := value.Canonicalize(); err != nil {
+			return err
+		}
+
+		ctx.setJSONBody(value)
+		return nil
+	}
+}
+
+// setJSONBody sets the request body to the given encoded JSON and sets the Content-Type header if needed.
+func (ctx *fetchContext) setJSONBody(body []byte) {
+	ctx.setBody("application/json", body)
+}
+
+// setBody sets the request body to the given bytes and sets the Content-Type header if needed.
+func (ctx *fetchContext) setBody(contentType string, body []byte) {
+	if ctx.Request.Header.Get("Content-Type") == "" {
+		ctx.Request.Header.Set("Content-Type", contentType)
+	}
+
+	ctx.Request.ContentLength = int64(len(body))
+	ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+	ctx.Request.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+}
+
+// WithBodyJSONStream is like [WithBodyJSON], but encodes the value while the request body is sent instead of
+// buffering the whole encoded value in memory.
+//
+// Since the length of the body is not known in advance, the body is sent using chunked transfer encoding.
+//
+// Encoding starts when the body is first read and happens in a separate goroutine, so v must not be modified until
+// the request completed. Encoding errors cause the request to fail.
+func WithBodyJSONStream(v any, opts ...jsontext.Options) FetchOption {
+	return func(ctx *fetchContext) error {
+		if ctx.Request.Header.Get("Content-Type") == "" {
+			ctx.Request.Header.Set("Content-Type", "application/json")
+		}
+
+		ctx.Request.ContentLength = -1
+		ctx.Request.Body = newJSONStreamBody(v, opts)
+		ctx.Request.GetBody = func() (io.ReadCloser, error) {
+			return newJSONStreamBody(v, opts), nil
+		}
+
+		return nil
+	}
+}
+
+// jsonStreamBody encodes a value as JSON into a pipe once it is first read.
+type jsonStreamBody struct {
+	v    any
+	opts []jsontext.Options
+
+	once sync.Once
+	pr   *io.PipeReader
+	pw   *io.PipeWriter
+}
+
+func newJSONStreamBody(v any, opts []jsontext.Options) *jsonStreamBody {
+	pr, pw := io.Pipe()
+	return &jsonStreamBody{v: v, opts: opts, pr: pr, pw: pw}
+}
+
+func (b *jsonStreamBody) Read(p []byte) (int, error) {
+	b.once.Do(func() {
+		go func() {
+			_ = b.pw.CloseWithError(json.MarshalWrite(b.pw, b.v, b.opts...))
+		}()
+	})
+
+	return b.pr.Read(p)
+}
+
+func (b *jsonStreamBody) Close() error {
+	return b.pr.Close()
+}
+
+// Handler specifies methods for handling responses.
+type Handler interface {
+	// HandleResponse is called after receiving a response and is passed both the response and a pointer to the
+	// value that should be filled with the response.
+	HandleResponse(dst any, resp *http.Response) error
+}
+
+// HandlerFunc implements the [Handler] interface using itself as [Handler.HandleResponse] implementation.
+type HandlerFunc func(dst any, resp *http.Response) error
+
+// HandleResponse returns the result of calling h(dst, resp).
+func (h HandlerFunc) HandleResponse(dst any, resp *http.Response) error {
+	return h(dst, resp)
+}
+
+// ErrUnhandledResponse can be returned by [Handler.HandleResponse] when the implementation can not handle the
+// given response.
+var ErrUnhandledResponse = errors.New("github.com/nussjustin/httpc: unhandled response")
+
+// WithHandler sets the [Handler] used by [Fetch] to process the response.
+//
+// If h is nil, [DefaultHandlers] is used.
+func WithHandler(h Handler) FetchOption {
+	return func(ctx *fetchContext) error {
+		ctx.Handler = h
+		return nil
+	}
+}
+
+// WithKeepBodyOpen causes the response to be returned without calling the [Handler], so that the body can be read by
+// the caller of [FetchWithResponse]. The decoded value is always the zero value.
+//
+// The caller is responsible for closing the body. Responses are never memoized, see [WithMemoize].
+//
+// [Fetch] and [Client.Fetch] still close the body before returning.
+func WithKeepBodyOpen() FetchOption {
+	return func(ctx *fetchContext) error {
+		ctx.KeepBodyOpen = true
+		return nil
+	}
+}
+
+// WithHandlerFunc is a shortcut for WithHandler(HandlerFunc(h)).
+func WithHandlerFunc(h HandlerFunc) FetchOption {
+	return WithHandler(h)
+}
+
+// HandlerChain wraps multiple [Handler] implementations in a single [Handler] that calls each underlying [Handler] in
+// order of first to last, until one returns a nil error or any error that is not [ErrUnhandledResponse], as determined
+// by [errors.Is].
+//
+// If the chain is empty or no [Handler] can handle the response, [ErrUnhandledResponse] is returned.
+//
+// To call all handlers regardless of their result, use [AllHandlers].
+type HandlerChain []Handler
+
+// ErrBodyConsumed is returned by [HandlerChain] when a [Handler] read from or closed the response body, but did not
+// handle the response, so that later handlers could not read the complete body.
+var ErrBodyConsumed = errors.New("github.com/nussjustin/httpc: response body consumed by skipped handler")
+
+// HandleResponse implements the [Handler] interface.
+//
+// If a handler returns [ErrUnhandledResponse] after reading from or closing the response body, no further handlers are
+// called and an error wrapping [ErrBodyConsumed] is returned. Handlers that need to inspect the body before deciding
+// whether to handle the response, like [SniffHandler], must replace the body with one that still returns all data.
+func (h HandlerChain) HandleResponse(dst any, resp *http.Response) error {
+	var body *consumptionTracker
+
+	for i := range h {
+		if resp != nil && resp.Body != nil && resp.Body != http.NoBody && resp.Body != io.ReadCloser(body) {
+			body = &consumptionTracker{ReadCloser: resp.Body}
+			resp.Body = body
+		}
+
+		err := h[i].HandleResponse(dst, resp)
+
+		traceHandler(resp, h[i], err)
+
+		if err == nil || !errors.Is(err, ErrUnhandledResponse) {
+			return err
+		}
+
+		if body != nil && resp.Body == io.ReadCloser(body) && body.consumed {
+			return fmt.Errorf("%w: handler %d (%s)", ErrBodyConsumed, i, handlerName(h[i]))
+		}
+	}
+
+	if body != nil && resp.Body == io.ReadCloser(body) {
+		resp.Body = body.ReadCloser
+	}
+
+	return ErrUnhandledResponse
+}
+
+// consumptionTracker records whether data was read from a body or whether it was closed.
+type consumptionTracker struct {
+	io.ReadCloser
+
+	consumed bool
+}
+
+func (c *consumptionTracker) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	if n > 0 {
+		c.consumed = true
+	}
+	return n, err
+}
+
+func (c *consumptionTracker) Close() error {
+	c.consumed = true
+	return c.ReadCloser.Close()
+}
+
+// FirstOf returns a [Handler] that calls the given handlers in order until one of them handles the response.
+//
+// A handler handles the response if it returns nil or any error that is not [ErrUnhandledResponse], as determined by
+// [errors.Is]. Once a handler handled the response, no further handlers are called, even if it returned an error.
+//
+// This is the same as using a [HandlerChain].
+func FirstOf(handlers ...Handler) Handler {
+	return HandlerChain(handlers)
+}
+
+// AllHandlers returns a [Handler] that calls all given handlers in order, regardless of their result.
+//
+// This can be used to combine handlers with side effects, like recording metrics, with a handler that decodes the
+// response. Since the body can only be read once, at most one of the handlers should read the response body.
+//
+// Handlers returning [ErrUnhandledResponse] are ignored. All other errors are joined using [errors.Join]. If no
+// handler handled the response, [ErrUnhandledResponse] is returned.
+func AllHandlers(handlers ...Handler) HandlerFunc {
+	return func(dst any, resp *http.Response) error {
+		var (
+			handled bool
+			errs    []error
+		)
+
+		for _, h := range handlers {
+			err := h.HandleResponse(dst, resp)
+
+			traceHandler(resp, h, err)
+
+			switch {
+			case err == nil:
+				handled = true
+			case !errors.Is(err, ErrUnhandledResponse):
+				handled = true
+				errs = append(errs, err)
+			}
+		}
+
+		if !handled {
+			return ErrUnhandledResponse
+		}
+
+		return errors.Join(errs...)
+	}
+}
 
 // ErrorHandler returns a [Handler] that returns the given error.
 func ErrorHandler(err error) HandlerFunc {
@@ -321,13 +1584,93 @@ func ErrorHandler(err error) HandlerFunc {
 }
 
 // ConditionalHandler returns a [Handler] that calls the given handler only if cond returns true for the response.
-func ConditionalHandler(cond func(*http.Response) bool, handler Handler) HandlerFunc {
-	return func(dst any, resp *http.Response) error {
-		if !cond(resp) {
-			return ErrUnhandledResponse
+//
+// The returned handler is named after the condition and the given handler, for example
+// "ConditionalHandler(IsSuccess, BytesHandler)".
+func ConditionalHandler(cond func(*http.Response) bool, handler Handler) Handler {
+	return conditionalHandler{cond: cond, handler: handler}
+}
+
+// NotHandler returns a [Handler] that calls the given handler only if cond returns false for the response.
+//
+// The returned handler is named after the condition and the given handler, for example
+// "NotHandler(IsSuccess, BytesHandler)".
+func NotHandler(cond func(*http.Response) bool, handler Handler) Handler {
+	return conditionalHandler{cond: cond, handler: handler, negate: true}
+}
+
+type conditionalHandler struct {
+	cond    func(*http.Response) bool
+	handler Handler
+	negate  bool
+}
+
+// HandleResponse implements the [Handler] interface.
+func (c conditionalHandler) HandleResponse(dst any, resp *http.Response) error {
+	if c.cond(resp) == c.negate {
+		return ErrUnhandledResponse
+	}
+
+	return c.handler.HandleResponse(dst, resp)
+}
+
+// String returns the name of the handler.
+func (c conditionalHandler) String() string {
+	name := "ConditionalHandler"
+	if c.negate {
+		name = "NotHandler"
+	}
+
+	return name + "(" + funcName(c.cond) + ", " + handlerName(c.handler) + ")"
+}
+
+// AndCond returns a condition that is true if all given conditions are true for the response.
+//
+// Conditions are evaluated in order and evaluation stops at the first condition that returns false.
+func AndCond(conds ...func(*http.Response) bool) func(*http.Response) bool {
+	return func(resp *http.Response) bool {
+		for _, cond := range conds {
+			if !cond(resp) {
+				return false
+			}
 		}
+		return true
+	}
+}
 
-		return handler.HandleResponse(dst, resp)
+// OrCond returns a condition that is true if any of the given conditions is true for the response.
+//
+// Conditions are evaluated in order and evaluation stops at the first condition that returns true.
+func OrCond(conds ...func(*http.Response) bool) func(*http.Response) bool {
+	return func(resp *http.Response) bool {
+		for _, cond := range conds {
+			if cond(resp) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// IsSuccess reports whether the response has a 2xx status code.
+func IsSuccess(resp *http.Response) bool {
+	return resp.StatusCode >= 200 && resp.StatusCode <= 299
+}
+
+// IsJSON reports whether the response has a JSON content type, that is either "application/json" or any media type
+// with a "+json" suffix like "application/problem+json".
+func IsJSON(resp *http.Response) bool {
+	mediaType, _, _ := strings.Cut(resp.Header.Get("Content-Type"), ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+
+	return mediaType == "application/json" || (strings.HasPrefix(mediaType, "application/") &&
+		strings.HasSuffix(mediaType, "+json"))
+}
+
+// HasHeader returns a condition that is true if the response has a non-empty header with the given name.
+func HasHeader(name string) func(*http.Response) bool {
+	return func(resp *http.Response) bool {
+		return resp.Header.Get(name) != ""
 	}
 }
 
@@ -335,25 +1678,52 @@ func ConditionalHandler(cond func(*http.Response) bool, handler Handler) Handler
 //
 // The handler will compare the response content type both as is as well as with any parameters removed. So a response
 // content type like "application/json; charset=utf-8" will match against "application/json".
-func ContentTypeHandler(contentType string, handler Handler) HandlerFunc {
-	return ConditionalHandler(
-		func(resp *http.Response) bool {
-			value := resp.Header.Get("Content-Type")
+//
+// The returned handler is named after the content type, for example "ContentTypeHandler(application/json)".
+func ContentTypeHandler(contentType string, handler Handler) Handler {
+	return contentTypeHandler{contentType: contentType, handler: handler}
+}
 
-			if value == contentType {
-				return true
-			}
+type contentTypeHandler struct {
+	contentType string
+	handler     Handler
+}
+
+// HandleResponse implements the [Handler] interface.
+func (c contentTypeHandler) HandleResponse(dst any, resp *http.Response) error {
+	if !hasContentType(resp, c.contentType) {
+		return ErrUnhandledResponse
+	}
+
+	return c.handler.HandleResponse(dst, resp)
+}
+
+// String returns the name of the handler.
+func (c contentTypeHandler) String() string {
+	return "ContentTypeHandler(" + c.contentType + ")"
+}
+
+// hasContentType reports whether the response content type matches the given content type, with or without
+// parameters.
+func hasContentType(resp *http.Response, contentType string) bool {
+	value := resp.Header.Get("Content-Type")
+
+	if value == contentType {
+		return true
+	}
 
-			// Try to match without parameters
-			value, _, _ = strings.Cut(value, ";")
+	// Try to match without parameters
+	value, _, _ = strings.Cut(value, ";")
 
-			return value == contentType
-		},
-		handler,
-	)
+	return value == contentType
 }
 
 func discardBody(resp *http.Response, err *error) {
+	// Bodies that were already closed, for example by a handler, can not be read anymore.
+	if b, ok := resp.Body.(interface{ isClosed() bool }); ok && b.isClosed() {
+		return
+	}
+
 	defer func() {
 		if cErr := resp.Body.Close(); cErr != nil && (err != nil && *err == nil) {
 			*err = cErr
@@ -373,57 +1743,406 @@ func DiscardBodyHandler() HandlerFunc {
 	}
 }
 
+// ReadCloserHandler returns a [Handler] that stores the response body in destinations of type *io.ReadCloser without
+// reading it.
+//
+// Ownership of the body is transferred to the caller, who must close it. The body of the response is replaced with
+// [http.NoBody], so that the original body is not read or closed by [Fetch].
+//
+// This allows streaming responses using Fetch[io.ReadCloser] while still using all options, like for example
+// [WithStats], that also apply to other requests.
+//
+// For other destinations [ErrUnhandledResponse] is returned.
+func ReadCloserHandler() HandlerFunc {
+	return func(dst any, resp *http.Response) error {
+		rc, ok := dst.(*io.ReadCloser)
+		if !ok {
+			return ErrUnhandledResponse
+		}
+
+		*rc, resp.Body = resp.Body, http.NoBody
+		return nil
+	}
+}
+
+// BytesHandler returns a [Handler] that reads the whole response body into destinations of type *[]byte.
+//
+// For other destinations [ErrUnhandledResponse] is returned without reading the body.
+//
+// The response body will automatically be closed.
+func BytesHandler() HandlerFunc {
+	return func(dst any, resp *http.Response) (err error) {
+		b, ok := dst.(*[]byte)
+		if !ok {
+			return ErrUnhandledResponse
+		}
+
+		defer discardBody(resp, &err)
+
+		*b, err = io.ReadAll(resp.Body)
+		return err
+	}
+}
+
 // ProblemHandler returns a [Handler] that detects JSON-encoded problem details as defined by RFC 9457.
 //
 // If the response returned a problem, it will be decoded and returned as error by [Fetch] and the response body will
 // be closed.
 func ProblemHandler() HandlerFunc {
-	return ContentTypeHandler(
-		problem.ContentType,
-		HandlerFunc(func(_ any, resp *http.Response) (err error) {
-			defer discardBody(resp, &err)
+	return func(_ any, resp *http.Response) (err error) {
+		if !hasContentType(resp, problem.ContentType) {
+			return ErrUnhandledResponse
+		}
 
-			details, err := problem.From(resp)
-			if err != nil {
-				return err
-			}
+		defer discardBody(resp, &err)
+
+		details, err := problem.From(resp)
+		if err != nil {
+			return err
+		}
 
-			return details
-		}),
-	)
+		return details
+	}
 }
 
 // StatusHandler executes the given handler if the response status matches the given status.
-func StatusHandler(statusCode int, handler Handler) HandlerFunc {
-	return ConditionalHandler(
-		func(resp *http.Response) bool {
-			return resp.StatusCode == statusCode
-		},
-		handler,
-	)
+//
+// The returned handler is named after the status code, for example "StatusHandler(204)".
+func StatusHandler(statusCode int, handler Handler) Handler {
+	return statusHandler{statusCode: statusCode, handler: handler}
+}
+
+type statusHandler struct {
+	statusCode int
+	handler    Handler
+}
+
+// HandleResponse implements the [Handler] interface.
+func (s statusHandler) HandleResponse(dst any, resp *http.Response) error {
+	if resp.StatusCode != s.statusCode {
+		return ErrUnhandledResponse
+	}
+
+	return s.handler.HandleResponse(dst, resp)
+}
+
+// String returns the name of the handler.
+func (s statusHandler) String() string {
+	return "StatusHandler(" + strconv.Itoa(s.statusCode) + ")"
+}
+
+// SwitchHandler returns a [Handler] that calls the handler registered for the status code of the response.
+//
+// If no handler is registered for the status code, [ErrUnhandledResponse] is returned.
+//
+// Together with [HandleInto] and [HandleAsError] this can be used to decode responses with different status codes
+// into different types:
+//
+//	var accepted AcceptedInfo
+//
+//	result, err := httpc.Fetch[Result](ctx, http.MethodPost, url,
+//		httpc.WithHandler(httpc.SwitchHandler(map[int]httpc.Handler{
+//			http.StatusOK:       httpc.UnmarshalJSONHandler(),
+//			http.StatusAccepted: httpc.HandleInto(&accepted, httpc.UnmarshalJSONHandler()),
+//			http.StatusConflict: httpc.HandleAsError[ConflictDetails](httpc.UnmarshalJSONHandler()),
+//		})))
+func SwitchHandler(handlers map[int]Handler) HandlerFunc {
+	return func(dst any, resp *http.Response) error {
+		handler, ok := handlers[resp.StatusCode]
+		if !ok {
+			return ErrUnhandledResponse
+		}
+
+		return handler.HandleResponse(dst, resp)
+	}
+}
+
+// HandleInto returns a [Handler] that calls the given handler with v as destination instead of the destination passed
+// to it.
+func HandleInto[V any](v *V, handler Handler) HandlerFunc {
+	return func(_ any, resp *http.Response) error {
+		return handler.HandleResponse(v, resp)
+	}
+}
+
+// HandleAsError returns a [Handler] that calls the given handler with a new value of type E as destination and
+// returns a pointer to the value as error, if the handler succeeded.
+//
+// This can be used to decode error responses into custom error types.
+func HandleAsError[E any, PE interface {
+	*E
+	error
+}](handler Handler) HandlerFunc {
+	return func(_ any, resp *http.Response) error {
+		var e E
+
+		if err := handler.HandleResponse(&e, resp); err != nil {
+			return err
+		}
+
+		return PE(&e)
+	}
+}
+
+// maxPooledBufferSize is the maximum size of buffers that are returned to bufferPool.
+//
+// This is also used as the maximum content length for responses that are read into a pooled buffer before decoding.
+const maxPooledBufferSize = 64 << 10
+
+var bufferPool = sync.Pool{
+	New: func() any {
+		return new(bytes.Buffer)
+	},
+}
+
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBufferSize {
+		return
+	}
+
+	buf.Reset()
+	bufferPool.Put(buf)
+}
+
+// WithStrictJSON causes [UnmarshalJSONHandler] to fail when a JSON object contains members that have no matching field
+// in the destination type.
+//
+// This can be used to detect changes in the contract of an API at decode time, instead of silently ignoring them.
+//
+// Duplicate member names are always rejected.
+func WithStrictJSON() FetchOption {
+	return func(ctx *fetchContext) error {
+		ctx.JSONOptions = append(ctx.JSONOptions, json.RejectUnknownMembers(true))
+		return nil
+	}
+}
+
+// jsonOptionsKey is the context key used to pass JSON options from a request to [UnmarshalJSONHandler].
+type jsonOptionsKey struct{}
+
+// requestJSONOptions returns the given options together with the options configured for the request of resp.
+func requestJSONOptions(resp *http.Response, opts []jsontext.Options) []jsontext.Options {
+	if resp.Request == nil {
+		return opts
+	}
+
+	reqOpts, _ := resp.Request.Context().Value(jsonOptionsKey{}).([]jsontext.Options)
+	if len(reqOpts) == 0 {
+		return opts
+	}
+
+	return slices.Concat(opts, reqOpts)
 }
 
 // UnmarshalJSONHandler returns a [Handler] that decodes the response body as JSON.
 //
+// Options configured for the request, for example via [WithStrictJSON], are applied after the given options. If
+// [WithUnknownJSONFields] was used, members without matching field in dst are reported after decoding.
+//
+// Responses with a known content length of up to 64 KiB are read into a pooled buffer and decoded from there, which
+// avoids the overhead of decoding from a stream for small responses.
+//
 // The response body will automatically be closed.
 func UnmarshalJSONHandler(opts ...jsontext.Options) HandlerFunc {
 	return func(dst any, resp *http.Response) (err error) {
 		defer discardBody(resp, &err)
 
+		opts := requestJSONOptions(resp, opts)
+
+		if report := requestUnknownJSONFields(resp); report != nil {
+			data, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return err
+			}
+
+			if err := json.Unmarshal(data, dst, opts...); err != nil {
+				return err
+			}
+
+			reportUnknownJSONFields(resp, data, dst, report)
+			return nil
+		}
+
+		if resp.ContentLength >= 0 && resp.ContentLength <= maxPooledBufferSize {
+			buf := getBuffer()
+			defer putBuffer(buf)
+
+			buf.Grow(int(resp.ContentLength))
+
+			if _, err := buf.ReadFrom(resp.Body); err != nil {
+				return err
+			}
+
+			return json.Unmarshal(buf.Bytes(), dst, opts...)
+		}
+
 		return json.UnmarshalRead(resp.Body, dst, opts...)
 	}
 }
 
-// UnmarshalXMLHandler returns a [Handler] that decodes the response body as JSON.
+// UnmarshalXMLHandler returns a [Handler] that decodes the response body as XML.
 //
 // The response body will automatically be closed.
+//
+// Deprecated: Use [UnmarshalXMLHandlerWithOptions] instead.
 func UnmarshalXMLHandler(strict bool) HandlerFunc {
+	return UnmarshalXMLHandlerWithOptions(XMLOptions{Strict: strict})
+}
+
+// XMLOptions configures the [xml.Decoder] used by [UnmarshalXMLHandlerWithOptions].
+//
+// See the fields of the same name of [xml.Decoder] for details.
+type XMLOptions struct {
+	// Strict enables strict parsing of the XML document.
+	Strict bool
+
+	// Entity maps non-standard entity names to their replacement text.
+	Entity map[string]string
+
+	// DefaultSpace is the namespace used for unadorned tags.
+	DefaultSpace string
+
+	// AutoClose contains the names of elements that are closed automatically, when Strict is false.
+	AutoClose []string
+}
+
+// UnmarshalXMLHandlerWithOptions returns a [Handler] that decodes the response body as XML using a decoder configured
+// with the given options.
+//
+// The response body will automatically be closed.
+func UnmarshalXMLHandlerWithOptions(opts XMLOptions) HandlerFunc {
 	return func(dst any, resp *http.Response) (err error) {
 		defer discardBody(resp, &err)
 
 		dec := xml.NewDecoder(resp.Body)
-		dec.Strict = strict
+		dec.Strict = opts.Strict
+		dec.Entity = opts.Entity
+		dec.DefaultSpace = opts.DefaultSpace
+		dec.AutoClose = opts.AutoClose
 
 		return dec.Decode(dst)
 	}
 }
+
+// UnmarshalTextHandler returns a [Handler] that stores the response body in destinations of type *string or
+// destinations implementing [encoding.TextUnmarshaler].
+//
+// For other destinations [ErrUnhandledResponse] is returned without reading the body.
+//
+// The response body will automatically be closed.
+func UnmarshalTextHandler() HandlerFunc {
+	return func(dst any, resp *http.Response) (err error) {
+		switch dst.(type) {
+		case *string, encoding.TextUnmarshaler:
+		default:
+			return ErrUnhandledResponse
+		}
+
+		defer discardBody(resp, &err)
+
+		b, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+
+		switch dst := dst.(type) {
+		case *string:
+			*dst = string(b)
+			return nil
+		case encoding.TextUnmarshaler:
+			return dst.UnmarshalText(b)
+		default:
+			panic(errors.New("unreachable"))
+		}
+	}
+}
+
+// UnmarshalerHandler returns a [Handler] that passes the raw response body to destinations implementing
+// [encoding.BinaryUnmarshaler] or [encoding.TextUnmarshaler], regardless of the content type.
+//
+// If the destination implements both interfaces, UnmarshalBinary is used. For other destinations
+// [ErrUnhandledResponse] is returned without reading the body.
+//
+// This allows custom types like identifiers or binary messages to be used as result type of [Fetch].
+//
+// The response body will automatically be closed.
+func UnmarshalerHandler() HandlerFunc {
+	return func(dst any, resp *http.Response) (err error) {
+		switch dst.(type) {
+		case encoding.BinaryUnmarshaler, encoding.TextUnmarshaler:
+		default:
+			return ErrUnhandledResponse
+		}
+
+		defer discardBody(resp, &err)
+
+		b, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+
+		switch dst := dst.(type) {
+		case encoding.BinaryUnmarshaler:
+			return dst.UnmarshalBinary(b)
+		case encoding.TextUnmarshaler:
+			return dst.UnmarshalText(b)
+		default:
+			panic(errors.New("unreachable"))
+		}
+	}
+}
+
+// sniffLen is the number of bytes inspected by [SniffHandler], matching [http.DetectContentType].
+const sniffLen = 512
+
+// SniffHandler returns a [Handler] that detects JSON and XML responses without proper content type.
+//
+// If the response has no Content-Type header or the content type is "application/octet-stream", the start of the
+// response body is inspected. Bodies starting with a JSON object, array or string are decoded using
+// [UnmarshalJSONHandler], while bodies detected as XML are decoded using [UnmarshalXMLHandlerWithOptions] in strict
+// mode.
+//
+// If the content could not be detected, [ErrUnhandledResponse] is returned and the response body can be read again
+// from the start.
+func SniffHandler() HandlerFunc {
+	jsonHandler := UnmarshalJSONHandler()
+	xmlHandler := UnmarshalXMLHandlerWithOptions(XMLOptions{Strict: true})
+
+	return func(dst any, resp *http.Response) error {
+		contentType, _, _ := strings.Cut(resp.Header.Get("Content-Type"), ";")
+		if contentType != "" && contentType != "application/octet-stream" {
+			return ErrUnhandledResponse
+		}
+
+		buf := make([]byte, sniffLen)
+
+		n, err := io.ReadFull(resp.Body, buf)
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return err
+		}
+
+		buf = buf[:n]
+
+		resp.Body = &struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(buf), resp.Body), resp.Body}
+
+		trimmed := bytes.TrimLeft(buf, " \t\r\n")
+
+		switch {
+		case len(trimmed) == 0:
+			return ErrUnhandledResponse
+		case trimmed[0] == '{' || trimmed[0] == '[' || trimmed[0] == '"':
+			return jsonHandler(dst, resp)
+		case trimmed[0] == '<' && !strings.HasPrefix(http.DetectContentType(buf), "text/html"):
+			return xmlHandler(dst, resp)
+		default:
+			return ErrUnhandledResponse
+		}
+	}
+}
@@ -1,14 +1,19 @@
 package httpc_test
 
 import (
+	"bytes"
+	"context"
+	"encoding/xml"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-json-experiment/json"
 	"github.com/google/go-cmp/cmp"
@@ -17,6 +22,8 @@ import (
 	"github.com/nussjustin/httpc"
 )
 
+type testUUID [16]byte
+
 type roundTripperFunc func(*http.Request) (*http.Response, error)
 
 func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
@@ -24,12 +31,13 @@ func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
 }
 
 type infoResponse struct {
-	Method string      `json:"method"`
-	Host   string      `json:"host"`
-	Path   string      `json:"path"`
-	Query  url.Values  `json:"query"`
-	Header http.Header `json:"header"`
-	Body   string      `json:"body"`
+	Method  string      `json:"method"`
+	Host    string      `json:"host"`
+	Path    string      `json:"path"`
+	RawPath string      `json:"rawPath"`
+	Query   url.Values  `json:"query"`
+	Header  http.Header `json:"header"`
+	Body    string      `json:"body"`
 }
 
 func testEndpoint(tb testing.TB) (*http.Client, *url.URL) {
@@ -41,17 +49,19 @@ func testEndpoint(tb testing.TB) (*http.Client, *url.URL) {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 		}
 
+		r.Header.Del("Accept")
 		r.Header.Del("Accept-Encoding")
 		r.Header.Del("Content-Length")
 		r.Header.Del("User-Agent")
 
 		resp := &infoResponse{
-			Method: r.Method,
-			Host:   r.Host,
-			Path:   r.URL.Path,
-			Query:  r.URL.Query(),
-			Header: r.Header,
-			Body:   string(body),
+			Method:  r.Method,
+			Host:    r.Host,
+			Path:    r.URL.Path,
+			RawPath: r.URL.RawPath,
+			Query:   r.URL.Query(),
+			Header:  r.Header,
+			Body:    string(body),
 		}
 
 		w.Header().Set("Content-Type", "application/json; charset=utf-8")
@@ -146,7 +156,7 @@ func TestFetch(t *testing.T) {
 		{
 			Name: "WithPathValue - wildcard from wildcard",
 			Expected: infoResponse{
-				Path: "/%7BValueB%7D",
+				Path: "/{ValueB}",
 			},
 			Path: "/{ValueA}",
 			Options: []httpc.FetchOption{
@@ -154,6 +164,130 @@ func TestFetch(t *testing.T) {
 				httpc.WithPathValue("ValueA", "B"),
 			},
 		},
+		{
+			Name: "WithPathValue - escaped",
+			Expected: infoResponse{
+				Path:    "/a/b c",
+				RawPath: "/a%2Fb%20c",
+			},
+			Path: "/{ValueA}",
+			Options: []httpc.FetchOption{
+				httpc.WithPathValue("ValueA", "a/b c"),
+			},
+		},
+		{
+			Name: "WithPathValue - matrix parameters",
+			Expected: infoResponse{
+				Path: "/map;x=1024;y=7%3B68;z/tiles;zoom=1",
+			},
+			Path: "/map{;x,y,z}/tiles{;unknown,zoom}",
+			Options: []httpc.FetchOption{
+				httpc.WithPathValue("x", "1024"),
+				httpc.WithPathValue("y", "7%3B68"),
+				httpc.WithPathValue("z", ""),
+				httpc.WithPathValue("zoom", "1"),
+			},
+		},
+		{
+			Name: "WithPathValue - matrix parameters without values",
+			Expected: infoResponse{
+				Path: "/map",
+			},
+			Path: "/map{;x,y}",
+			Options: []httpc.FetchOption{
+				httpc.WithStrictMode(),
+			},
+		},
+		{
+			Name: "WithPathValueEncoding - PathEncodingEscape",
+			Expected: infoResponse{
+				Path:    "/a/b c/x",
+				RawPath: "/a%2Fb%20c/x",
+			},
+			Path: "/{ValueA}/x",
+			Options: []httpc.FetchOption{
+				httpc.WithPathValueEncoding("ValueA", "a/b c", httpc.PathEncodingEscape),
+			},
+		},
+		{
+			Name: "WithPathValueEncoding - PathEncodingNone",
+			Expected: infoResponse{
+				Path:    "/a/b c/x",
+				RawPath: "/a%2Fb%20c/x",
+			},
+			Path: "/{ValueA}/x",
+			Options: []httpc.FetchOption{
+				httpc.WithPathValueEncoding("ValueA", "a%2Fb%20c", httpc.PathEncodingNone),
+			},
+		},
+		{
+			Name: "WithPathValueEncoding - PathEncodingSegments",
+			Expected: infoResponse{
+				Path: "/a/b c/x",
+			},
+			Path: "/{ValueA}/x",
+			Options: []httpc.FetchOption{
+				httpc.WithPathValueEncoding("ValueA", "a/b c", httpc.PathEncodingSegments),
+			},
+		},
+		{
+			Name: "WithPathValueInt",
+			Expected: infoResponse{
+				Path: "/-12/34/56",
+			},
+			Path: "/{ValueA}/{ValueB}/{ValueC}",
+			Options: []httpc.FetchOption{
+				httpc.WithPathValueInt("ValueA", -12),
+				httpc.WithPathValueInt("ValueB", uint8(34)),
+				httpc.WithPathValueInt("ValueC", time.Duration(56)),
+			},
+		},
+		{
+			Name: "WithPathValueUUID",
+			Expected: infoResponse{
+				Path: "/f81d4fae-7dec-11d0-a765-00a0c91e6bf6",
+			},
+			Path: "/{ValueA}",
+			Options: []httpc.FetchOption{
+				httpc.WithPathValueUUID("ValueA", testUUID{
+					0xf8, 0x1d, 0x4f, 0xae, 0x7d, 0xec, 0x11, 0xd0, 0xa7, 0x65, 0x00, 0xa0, 0xc9, 0x1e, 0x6b, 0xf6,
+				}),
+			},
+		},
+		{
+			Name: "WithPathValueStringer",
+			Expected: infoResponse{
+				Path: "/1.5s",
+			},
+			Path: "/{ValueA}",
+			Options: []httpc.FetchOption{
+				httpc.WithPathValueStringer("ValueA", 1500*time.Millisecond),
+			},
+		},
+		{
+			Name: "WithStrictPathValues",
+			Expected: infoResponse{
+				Path: "/A/B",
+			},
+			Path: "/{ValueA}/{ValueB}",
+			Options: []httpc.FetchOption{
+				httpc.WithStrictPathValues(true),
+				httpc.WithPathValue("ValueA", "A"),
+				httpc.WithPathValue("ValueB", "B"),
+			},
+		},
+		{
+			Name: "WithStrictPathValues - disabled",
+			Expected: infoResponse{
+				Path: "/A/{ValueB}",
+			},
+			Path: "/{ValueA}/{ValueB}",
+			Options: []httpc.FetchOption{
+				httpc.WithStrictMode(),
+				httpc.WithStrictPathValues(false),
+				httpc.WithPathValue("ValueA", "A"),
+			},
+		},
 		{
 			Name: "WithAddedQueryParam",
 			Expected: infoResponse{
@@ -334,6 +468,25 @@ func TestFetch_Errors(t *testing.T) {
 				httpc.WithBody(&errorReader{err: errors.New("body")}),
 			},
 		},
+		{
+			Name:     "Missing path value",
+			Expected: "missing path value \"ValueB\"",
+			Method:   "GET",
+			Path:     "/{ValueA}/{invalid-name}/{ValueB}",
+			Options: []httpc.FetchOption{
+				httpc.WithStrictMode(),
+				httpc.WithPathValue("ValueA", "A"),
+			},
+		},
+		{
+			Name:     "Invalid pre-encoded path value",
+			Expected: "invalid value for wildcard \"ValueA\": invalid URL escape \"%zz\"",
+			Method:   "GET",
+			Path:     "/{ValueA}",
+			Options: []httpc.FetchOption{
+				httpc.WithPathValueEncoding("ValueA", "%zz", httpc.PathEncodingNone),
+			},
+		},
 		{
 			Name:     "Failed request",
 			Expected: "request failed",
@@ -379,6 +532,403 @@ func TestFetch_Errors(t *testing.T) {
 	}
 }
 
+func captureRequest(tb testing.TB, path string, opts ...httpc.FetchOption) *http.Request {
+	tb.Helper()
+
+	var req *http.Request
+
+	client := &http.Client{
+		Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			req = r
+
+			return &http.Response{
+				StatusCode: http.StatusNoContent,
+				Header:     make(http.Header),
+				Body:       http.NoBody,
+				Request:    r,
+			}, nil
+		}),
+	}
+
+	opts = append([]httpc.FetchOption{httpc.WithClient(client)}, opts...)
+
+	if _, err := httpc.Fetch[any](tb.Context(), http.MethodGet, path, opts...); err != nil {
+		tb.Fatalf("failed to fetch: %v", err)
+	}
+
+	return req
+}
+
+func TestWithBodyJSONStream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "/echo", http.StatusTemporaryRedirect)
+			return
+		}
+
+		if got, want := r.TransferEncoding, []string{"chunked"}; !slices.Equal(got, want) {
+			t.Errorf("got transfer encoding %q, want %q", got, want)
+		}
+
+		if got, want := r.Header.Get("Content-Type"), "application/json"; got != want {
+			t.Errorf("got content type %q, want %q", got, want)
+		}
+
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = io.Copy(w, r.Body)
+	}))
+	t.Cleanup(srv.Close)
+
+	t.Run("Success", func(t *testing.T) {
+		got, err := httpc.Fetch[string](t.Context(), http.MethodPost, srv.URL+"/redirect",
+			httpc.WithBodyJSONStream(map[string]int{"key": 1}))
+		if err != nil {
+			t.Fatalf("got error %v", err)
+		}
+
+		if want := `{"key":1}`; got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("Encoding error", func(t *testing.T) {
+		_, err := httpc.Fetch[string](t.Context(), http.MethodPost, srv.URL+"/echo",
+			httpc.WithBodyJSONStream(make(chan int)))
+		if err == nil {
+			t.Error("got no error")
+		}
+	})
+}
+
+func TestWithBodyCanonicalJSON(t *testing.T) {
+	type value struct {
+		B     string            `json:"b"`
+		A     float64           `json:"a"`
+		Map   map[string]string `json:"map"`
+		Empty []int             `json:"empty"`
+	}
+
+	v := value{
+		B:   "<&>",
+		A:   1e21,
+		Map: map[string]string{"z": "1", "y": "2", "x": "3"},
+	}
+
+	want := `{"a":1e+21,"b":"<&>","empty":[],"map":{"x":"3","y":"2","z":"1"}}`
+
+	for range 3 {
+		req := captureRequest(t, "/", httpc.WithBodyCanonicalJSON(v))
+
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			t.Fatalf("failed to read body: %v", err)
+		}
+
+		if got := string(body); got != want {
+			t.Errorf("got %s, want %s", got, want)
+		}
+
+		if got, want := req.ContentLength, int64(len(want)); got != want {
+			t.Errorf("got content length %d, want %d", got, want)
+		}
+	}
+}
+
+func TestWithBaseURLJoin(t *testing.T) {
+	mustParse := func(s string) *url.URL {
+		u, err := url.Parse(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return u
+	}
+
+	testCases := []struct {
+		Name     string
+		Base     string
+		Path     string
+		Join     httpc.PathJoin
+		Expected string
+	}{
+		{
+			Name:     "Resolve",
+			Base:     "https://example.com/api",
+			Path:     "/v1/users",
+			Join:     httpc.PathJoinResolve,
+			Expected: "https://example.com/v1/users",
+		},
+		{
+			Name:     "Resolve - relative",
+			Base:     "https://example.com/api/",
+			Path:     "v1/users",
+			Join:     httpc.PathJoinResolve,
+			Expected: "https://example.com/api/v1/users",
+		},
+		{
+			Name:     "Append",
+			Base:     "https://example.com/api",
+			Path:     "/v1/users",
+			Join:     httpc.PathJoinAppend,
+			Expected: "https://example.com/api/v1/users",
+		},
+		{
+			Name:     "Append - slashes",
+			Base:     "https://example.com/api/",
+			Path:     "/v1/users/",
+			Join:     httpc.PathJoinAppend,
+			Expected: "https://example.com/api/v1/users/",
+		},
+		{
+			Name:     "Append - no slashes",
+			Base:     "https://example.com/api",
+			Path:     "v1/users",
+			Join:     httpc.PathJoinAppend,
+			Expected: "https://example.com/api/v1/users",
+		},
+		{
+			Name:     "Append - empty path",
+			Base:     "https://example.com/api",
+			Path:     "",
+			Join:     httpc.PathJoinAppend,
+			Expected: "https://example.com/api",
+		},
+		{
+			Name:     "Append - escaped",
+			Base:     "https://example.com/a%2Fb",
+			Path:     "/c/{id}",
+			Join:     httpc.PathJoinAppend,
+			Expected: "https://example.com/a%2Fb/c/1%2F2",
+		},
+		{
+			Name:     "Append - query",
+			Base:     "https://example.com/api?key=value",
+			Path:     "/v1/users",
+			Join:     httpc.PathJoinAppend,
+			Expected: "https://example.com/api/v1/users?key=value",
+		},
+		{
+			Name:     "Append - query override",
+			Base:     "https://example.com/api?key=value",
+			Path:     "/v1/users?other=value",
+			Join:     httpc.PathJoinAppend,
+			Expected: "https://example.com/api/v1/users?other=value",
+		},
+		{
+			Name:     "Append - absolute URL",
+			Base:     "https://example.com/api",
+			Path:     "https://example.org/v1/users",
+			Join:     httpc.PathJoinAppend,
+			Expected: "https://example.org/v1/users",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			req := captureRequest(t, testCase.Path,
+				httpc.WithBaseURLJoin(mustParse(testCase.Base), testCase.Join),
+				httpc.WithPathValue("id", "1/2"))
+
+			if got, want := req.URL.String(), testCase.Expected; got != want {
+				t.Errorf("got URL %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestWithTrailingSlash(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Path     string
+		Policy   httpc.TrailingSlash
+		Expected string
+	}{
+		{Name: "Keep", Path: "/users/", Policy: httpc.TrailingSlashKeep, Expected: "/users/"},
+		{Name: "Strip", Path: "/users//", Policy: httpc.TrailingSlashStrip, Expected: "/users"},
+		{Name: "Strip - root", Path: "/", Policy: httpc.TrailingSlashStrip, Expected: "/"},
+		{Name: "Strip - escaped", Path: "/{id}/", Policy: httpc.TrailingSlashStrip, Expected: "/a%2F"},
+		{Name: "Add", Path: "/users", Policy: httpc.TrailingSlashAdd, Expected: "/users/"},
+		{Name: "Add - existing", Path: "/users/", Policy: httpc.TrailingSlashAdd, Expected: "/users/"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			req := captureRequest(t, testCase.Path,
+				httpc.WithTrailingSlash(testCase.Policy),
+				httpc.WithPathValue("id", "a/"))
+
+			if got, want := req.URL.EscapedPath(), testCase.Expected; got != want {
+				t.Errorf("got path %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestWithQueryParam_KeepsEncoding(t *testing.T) {
+	req := captureRequest(t, "/?b=%7e&a=1&c=x&a=3",
+		httpc.WithQueryParam("a", "2"),
+		httpc.WithAddedQueryParam("d", "a b"))
+
+	if got, want := req.URL.RawQuery, "b=%7e&c=x&a=2&d=a+b"; got != want {
+		t.Errorf("got query %q, want %q", got, want)
+	}
+}
+
+func TestWithFragment(t *testing.T) {
+	req := captureRequest(t, "/path#old", httpc.WithFragment("new fragment"))
+
+	if got, want := req.URL.String(), "/path#new%20fragment"; got != want {
+		t.Errorf("got URL %q, want %q", got, want)
+	}
+}
+
+func TestWithKeepBodyOpen(t *testing.T) {
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(`{"name":"value"}`)),
+				Request:    req,
+			}, nil
+		}),
+	}
+
+	var called bool
+
+	got, resp, err := httpc.FetchWithResponse[map[string]string](t.Context(), http.MethodGet, "/",
+		httpc.WithClient(client),
+		httpc.WithHandlerFunc(func(any, *http.Response) error {
+			called = true
+			return nil
+		}),
+		httpc.WithMemoize(time.Hour, nil),
+		httpc.WithKeepBodyOpen())
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if called {
+		t.Error("handler was called")
+	}
+
+	if got != nil {
+		t.Errorf("got value %v, want zero value", got)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read body: %v", err)
+	}
+
+	if want := `{"name":"value"}`; string(body) != want {
+		t.Errorf("got body %q, want %q", body, want)
+	}
+}
+
+func TestWithOpaqueURL(t *testing.T) {
+	baseURL := &url.URL{Scheme: "https", Host: "example.com", Path: "/base/"}
+
+	testCases := []struct {
+		Name     string
+		URL      string
+		Expected string
+	}{
+		{
+			Name:     "Absolute URL",
+			URL:      "https://example.org/a%2fb/{c}/?x=%7e#fragment",
+			Expected: "https://example.org/a%2fb/{c}/?x=%7e&y=1",
+		},
+		{
+			Name:     "Absolute path",
+			URL:      "/a%2fb/{c}/?x=%7e",
+			Expected: "https://example.com/a%2fb/{c}/?x=%7e&y=1",
+		},
+		{
+			Name:     "Empty path",
+			URL:      "https://example.org?x=%7e",
+			Expected: "https://example.org/?x=%7e&y=1",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			req := captureRequest(t, testCase.URL,
+				httpc.WithBaseURL(baseURL),
+				httpc.WithOpaqueURL(),
+				httpc.WithPathValue("c", "value"),
+				httpc.WithTrailingSlash(httpc.TrailingSlashStrip),
+				httpc.WithAddedQueryParam("y", "1"))
+
+			if got, want := req.URL.RequestURI(), testCase.Expected; got != want {
+				t.Errorf("got request URI %q, want %q", got, want)
+			}
+		})
+	}
+
+	t.Run("Relative path", func(t *testing.T) {
+		_, err := httpc.Fetch[any](t.Context(), http.MethodGet, "a/b",
+			httpc.WithBaseURL(baseURL),
+			httpc.WithOpaqueURL())
+
+		if got, want := fmt.Sprint(err), `opaque URL path "a/b" must start with a slash`; got != want {
+			t.Errorf("got error %q, want %q", got, want)
+		}
+	})
+}
+
+func TestWithPresignedURL(t *testing.T) {
+	const presignedURL = "https://bucket.s3.example.com/a%2fb?X-Amz-Credential=a%2Fb&X-Amz-Signature=abc"
+
+	t.Run("Unmodified", func(t *testing.T) {
+		req := captureRequest(t, presignedURL,
+			httpc.WithHeader("Authorization", "Bearer token"),
+			httpc.WithHeader("Content-Type", "text/plain"),
+			httpc.WithPresignedURL())
+
+		if got, want := req.URL.RequestURI(), presignedURL; got != want {
+			t.Errorf("got request URI %q, want %q", got, want)
+		}
+
+		if got := req.Header.Get("Authorization"); got != "" {
+			t.Errorf("got Authorization header %q, want no header", got)
+		}
+
+		if got, want := req.Header.Get("Content-Type"), "text/plain"; got != want {
+			t.Errorf("got Content-Type header %q, want %q", got, want)
+		}
+	})
+
+	t.Run("Token source", func(t *testing.T) {
+		var calls int
+
+		req := captureRequest(t, presignedURL,
+			httpc.WithTokenSource(httpc.TokenSourceFunc(func(context.Context) (string, error) {
+				calls++
+				return "token", nil
+			})),
+			httpc.WithPresignedURL())
+
+		if got := req.Header.Get("Authorization"); got != "" {
+			t.Errorf("got Authorization header %q, want no header", got)
+		}
+
+		if calls != 0 {
+			t.Errorf("token source called %d times, want no calls", calls)
+		}
+	})
+
+	t.Run("Modified query", func(t *testing.T) {
+		_, err := httpc.Fetch[any](t.Context(), http.MethodGet, presignedURL,
+			httpc.WithPresignedURL(),
+			httpc.WithQueryParam("X-Amz-Signature", "def"))
+
+		if !errors.Is(err, httpc.ErrPresignedURLModified) {
+			t.Errorf("got error %v, want %v", err, httpc.ErrPresignedURLModified)
+		}
+	})
+}
+
 func assertPanic[T any](tb testing.TB, fn func()) (res T) {
 	tb.Helper()
 
@@ -503,6 +1053,168 @@ func TestHandlerChain(t *testing.T) {
 	}
 }
 
+func TestHandlerChain_BodyConsumed(t *testing.T) {
+	readAndSkip := httpc.HandlerFunc(func(_ any, resp *http.Response) error {
+		_, _ = resp.Body.Read(make([]byte, 1))
+		return httpc.ErrUnhandledResponse
+	})
+
+	closeAndSkip := httpc.HandlerFunc(func(_ any, resp *http.Response) error {
+		_ = resp.Body.Close()
+		return httpc.ErrUnhandledResponse
+	})
+
+	testCases := []struct {
+		Name          string
+		Handlers      []httpc.Handler
+		Expected      string
+		ExpectedError error
+	}{
+		{
+			Name:     "Not consumed",
+			Handlers: []httpc.Handler{httpc.ErrorHandler(httpc.ErrUnhandledResponse), httpc.UnmarshalTextHandler()},
+			Expected: "body",
+		},
+		{
+			Name:          "Read",
+			Handlers:      []httpc.Handler{readAndSkip, httpc.UnmarshalTextHandler()},
+			ExpectedError: httpc.ErrBodyConsumed,
+		},
+		{
+			Name:          "Closed",
+			Handlers:      []httpc.Handler{closeAndSkip, httpc.UnmarshalTextHandler()},
+			ExpectedError: httpc.ErrBodyConsumed,
+		},
+		{
+			Name:     "Sniffed",
+			Handlers: []httpc.Handler{httpc.SniffHandler(), httpc.UnmarshalTextHandler()},
+			Expected: "body",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			resp := &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": []string{"text/plain"}},
+				Body:       io.NopCloser(strings.NewReader("body")),
+			}
+
+			var got string
+
+			err := httpc.HandlerChain(testCase.Handlers).HandleResponse(&got, resp)
+			if !errors.Is(err, testCase.ExpectedError) {
+				t.Fatalf("got error %v, want %v", err, testCase.ExpectedError)
+			}
+
+			if got != testCase.Expected {
+				t.Errorf("got %q, want %q", got, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestFirstOf(t *testing.T) {
+	errTest := errors.New("test error")
+
+	var calls []int
+
+	handler := func(n int, err error) httpc.HandlerFunc {
+		return func(any, *http.Response) error {
+			calls = append(calls, n)
+			return err
+		}
+	}
+
+	err := httpc.FirstOf(
+		handler(1, httpc.ErrUnhandledResponse),
+		handler(2, errTest),
+		handler(3, nil),
+	).HandleResponse(nil, nil)
+
+	if !errors.Is(err, errTest) {
+		t.Errorf("got error %v, want %v", err, errTest)
+	}
+
+	if diff := cmp.Diff([]int{1, 2}, calls); diff != "" {
+		t.Errorf("calls mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestAllHandlers(t *testing.T) {
+	errTest1 := errors.New("test error 1")
+	errTest2 := errors.New("test error 2")
+
+	handler := func(text string, err error) httpc.HandlerFunc {
+		return func(dst any, _ *http.Response) error {
+			*dst.(*[]string) = append(*dst.(*[]string), text)
+			return err
+		}
+	}
+
+	testCases := []struct {
+		Name           string
+		Expected       []string
+		ExpectedErrors []error
+		Handlers       []httpc.Handler
+	}{
+		{
+			Name:           "Empty",
+			ExpectedErrors: []error{httpc.ErrUnhandledResponse},
+		},
+		{
+			Name:           "Unhandled",
+			Expected:       []string{"handler 1", "handler 2"},
+			ExpectedErrors: []error{httpc.ErrUnhandledResponse},
+			Handlers: []httpc.Handler{
+				handler("handler 1", httpc.ErrUnhandledResponse),
+				handler("handler 2", httpc.ErrUnhandledResponse),
+			},
+		},
+		{
+			Name:     "Handled",
+			Expected: []string{"handler 1", "handler 2", "handler 3"},
+			Handlers: []httpc.Handler{
+				handler("handler 1", nil),
+				handler("handler 2", httpc.ErrUnhandledResponse),
+				handler("handler 3", nil),
+			},
+		},
+		{
+			Name:           "Errors",
+			Expected:       []string{"handler 1", "handler 2", "handler 3"},
+			ExpectedErrors: []error{errTest1, errTest2},
+			Handlers: []httpc.Handler{
+				handler("handler 1", errTest1),
+				handler("handler 2", nil),
+				handler("handler 3", errTest2),
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			var got []string
+
+			gotErr := httpc.AllHandlers(testCase.Handlers...).HandleResponse(&got, nil)
+
+			if diff := cmp.Diff(testCase.Expected, got); diff != "" {
+				t.Errorf("dst mismatch (-want +got):\n%s", diff)
+			}
+
+			if len(testCase.ExpectedErrors) == 0 && gotErr != nil {
+				t.Errorf("got error %q, want no error", gotErr)
+			}
+
+			for _, want := range testCase.ExpectedErrors {
+				if !errors.Is(gotErr, want) {
+					t.Errorf("got error %q, want %q", gotErr, want)
+				}
+			}
+		})
+	}
+}
+
 func TestErrorHandler(t *testing.T) {
 	want := errors.New("test error")
 
@@ -580,6 +1292,69 @@ func TestConditionalHandler(t *testing.T) {
 	wrapped.assertCalls(2)
 }
 
+func TestNotHandler(t *testing.T) {
+	var handler countingHandler
+	handler.tb = t
+
+	h := httpc.NotHandler(httpc.IsSuccess, &handler)
+
+	err := h.HandleResponse(nil, &http.Response{StatusCode: http.StatusOK})
+	if !errors.Is(err, httpc.ErrUnhandledResponse) {
+		t.Errorf("got error %v, want %v", err, httpc.ErrUnhandledResponse)
+	}
+
+	handler.assertCalls(0)
+
+	if err := h.HandleResponse(nil, &http.Response{StatusCode: http.StatusNotFound}); err != nil {
+		t.Errorf("got error %v, want <nil>", err)
+	}
+
+	handler.assertCalls(1)
+}
+
+func TestConditions(t *testing.T) {
+	response := func(status int, contentType string) *http.Response {
+		resp := &http.Response{StatusCode: status, Header: make(http.Header)}
+		if contentType != "" {
+			resp.Header.Set("Content-Type", contentType)
+		}
+		return resp
+	}
+
+	isCreated := func(resp *http.Response) bool { return resp.StatusCode == http.StatusCreated }
+
+	testCases := []struct {
+		Name     string
+		Cond     func(*http.Response) bool
+		Response *http.Response
+		Expected bool
+	}{
+		{"IsSuccess 200", httpc.IsSuccess, response(http.StatusOK, ""), true},
+		{"IsSuccess 299", httpc.IsSuccess, response(299, ""), true},
+		{"IsSuccess 304", httpc.IsSuccess, response(http.StatusNotModified, ""), false},
+		{"IsJSON", httpc.IsJSON, response(http.StatusOK, "application/json"), true},
+		{"IsJSON parameters", httpc.IsJSON, response(http.StatusOK, "Application/JSON; charset=utf-8"), true},
+		{"IsJSON suffix", httpc.IsJSON, response(http.StatusOK, "application/problem+json"), true},
+		{"IsJSON other", httpc.IsJSON, response(http.StatusOK, "text/plain"), false},
+		{"HasHeader", httpc.HasHeader("content-type"), response(http.StatusOK, "text/plain"), true},
+		{"HasHeader missing", httpc.HasHeader("ETag"), response(http.StatusOK, "text/plain"), false},
+		{"AndCond", httpc.AndCond(httpc.IsSuccess, isCreated), response(http.StatusCreated, ""), true},
+		{"AndCond false", httpc.AndCond(httpc.IsSuccess, isCreated), response(http.StatusOK, ""), false},
+		{"AndCond empty", httpc.AndCond(), response(http.StatusOK, ""), true},
+		{"OrCond", httpc.OrCond(httpc.IsJSON, isCreated), response(http.StatusCreated, ""), true},
+		{"OrCond false", httpc.OrCond(httpc.IsJSON, isCreated), response(http.StatusOK, ""), false},
+		{"OrCond empty", httpc.OrCond(), response(http.StatusOK, ""), false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			if got := testCase.Cond(testCase.Response); got != testCase.Expected {
+				t.Errorf("got %t, want %t", got, testCase.Expected)
+			}
+		})
+	}
+}
+
 func TestContentTypeHandler(t *testing.T) {
 	wrapped := newCountingHandler(t)
 
@@ -716,6 +1491,144 @@ func TestJSONHandler(t *testing.T) {
 	})
 }
 
+func TestWithStrictJSON(t *testing.T) {
+	testCases := []struct {
+		Name          string
+		Body          string
+		Strict        bool
+		ExpectedError bool
+	}{
+		{Name: "Known fields", Body: `{"key1":"value1"}`, Strict: true},
+		{Name: "Unknown field", Body: `{"key1":"value1","key2":"value2"}`},
+		{Name: "Unknown field strict", Body: `{"key1":"value1","key2":"value2"}`, Strict: true, ExpectedError: true},
+		{Name: "Duplicate field", Body: `{"key1":"value1","key1":"value2"}`, ExpectedError: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			client := &http.Client{
+				Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Header:     http.Header{"Content-Type": {"application/json"}},
+						Body:       io.NopCloser(strings.NewReader(testCase.Body)),
+						Request:    r,
+					}, nil
+				}),
+			}
+
+			opts := []httpc.FetchOption{httpc.WithClient(client)}
+
+			if testCase.Strict {
+				opts = append(opts, httpc.WithStrictJSON())
+			}
+
+			type response struct {
+				Key1 string `json:"key1"`
+			}
+
+			_, err := httpc.Fetch[response](t.Context(), http.MethodGet, "http://example.com/", opts...)
+
+			if got, want := err != nil, testCase.ExpectedError; got != want {
+				t.Errorf("got error %v, want error %t", err, want)
+			}
+		})
+	}
+}
+
+func TestReadCloserHandler(t *testing.T) {
+	t.Run("Handled", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"key":"value"}`))
+		}))
+		t.Cleanup(srv.Close)
+
+		var stats httpc.Stats
+
+		body, err := httpc.Fetch[io.ReadCloser](t.Context(), http.MethodGet, srv.URL, httpc.WithStats(&stats))
+		if err != nil {
+			t.Fatalf("got error %v", err)
+		}
+
+		b, err := io.ReadAll(body)
+		if err != nil {
+			t.Fatalf("failed to read body: %v", err)
+		}
+
+		if err := body.Close(); err != nil {
+			t.Errorf("failed to close body: %v", err)
+		}
+
+		if got, want := string(b), `{"key":"value"}`; got != want {
+			t.Errorf("got body %q, want %q", got, want)
+		}
+
+		if got, want := stats.ResponseBytes, int64(len(b)); got != want {
+			t.Errorf("got %d response bytes, want %d", got, want)
+		}
+	})
+
+	t.Run("Error status", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			http.Error(w, "not found", http.StatusNotFound)
+		}))
+		t.Cleanup(srv.Close)
+
+		body, err := httpc.Fetch[io.ReadCloser](t.Context(), http.MethodGet, srv.URL)
+
+		var statusErr *httpc.StatusError
+		if !errors.As(err, &statusErr) {
+			t.Fatalf("got error %v, want %T", err, statusErr)
+		}
+
+		if got, want := statusErr.StatusCode, http.StatusNotFound; got != want {
+			t.Errorf("got status %d, want %d", got, want)
+		}
+
+		if body != nil {
+			t.Errorf("got body %v, want nil", body)
+		}
+	})
+
+	t.Run("Unsupported destination", func(t *testing.T) {
+		body := &readCloser{Reader: strings.NewReader("OK")}
+
+		var dst io.Reader
+
+		err := httpc.ReadCloserHandler()(&dst, &http.Response{Body: body})
+		if !errors.Is(err, httpc.ErrUnhandledResponse) {
+			t.Errorf("got error %v, want %v", err, httpc.ErrUnhandledResponse)
+		}
+
+		if body.closed {
+			t.Error("body closed")
+		}
+	})
+}
+
+func TestBytesHandler(t *testing.T) {
+	client := &http.Client{
+		Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": {"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(`{"key":"value"}`)),
+				Request:    r,
+			}, nil
+		}),
+	}
+
+	got, err := httpc.Fetch[[]byte](t.Context(), http.MethodGet, "http://example.com/", httpc.WithClient(client))
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	if want := `{"key":"value"}`; string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
 func TestProblemHandler(t *testing.T) {
 	t.Run("No problem", func(t *testing.T) {
 		resp := &http.Response{
@@ -777,6 +1690,84 @@ func TestStatusHandler(t *testing.T) {
 	wrapped.assertCalls(2)
 }
 
+type conflictDetails struct {
+	Reason string `json:"reason"`
+}
+
+func (c *conflictDetails) Error() string {
+	return "conflict: " + c.Reason
+}
+
+func TestSwitchHandler(t *testing.T) {
+	type result struct {
+		ID int `json:"id"`
+	}
+
+	type acceptedInfo struct {
+		Location string `json:"location"`
+	}
+
+	bodies := map[int]string{
+		http.StatusOK:                  `{"id":1}`,
+		http.StatusAccepted:            `{"location":"/queue/1"}`,
+		http.StatusConflict:            `{"reason":"exists"}`,
+		http.StatusInternalServerError: `{}`,
+	}
+
+	testCases := []struct {
+		Name             string
+		Status           int
+		Expected         result
+		ExpectedAccepted acceptedInfo
+		ExpectedError    error
+	}{
+		{Name: "OK", Status: http.StatusOK, Expected: result{ID: 1}},
+		{Name: "Accepted", Status: http.StatusAccepted, ExpectedAccepted: acceptedInfo{Location: "/queue/1"}},
+		{Name: "Conflict", Status: http.StatusConflict, ExpectedError: &conflictDetails{Reason: "exists"}},
+		{Name: "Unhandled", Status: http.StatusInternalServerError, ExpectedError: httpc.ErrUnhandledResponse},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			client := &http.Client{
+				Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+					return &http.Response{
+						StatusCode: testCase.Status,
+						Header:     http.Header{"Content-Type": {"application/json"}},
+						Body:       io.NopCloser(strings.NewReader(bodies[testCase.Status])),
+						Request:    r,
+					}, nil
+				}),
+			}
+
+			var accepted acceptedInfo
+
+			got, err := httpc.Fetch[result](t.Context(), http.MethodPost, "http://example.com/",
+				httpc.WithClient(client),
+				httpc.WithHandler(httpc.SwitchHandler(map[int]httpc.Handler{
+					http.StatusOK:       httpc.UnmarshalJSONHandler(),
+					http.StatusAccepted: httpc.HandleInto(&accepted, httpc.UnmarshalJSONHandler()),
+					http.StatusConflict: httpc.HandleAsError[conflictDetails](httpc.UnmarshalJSONHandler()),
+				})))
+
+			switch {
+			case testCase.ExpectedError == nil && err != nil:
+				t.Errorf("got error %v, want <nil>", err)
+			case testCase.ExpectedError != nil && (err == nil || err.Error() != testCase.ExpectedError.Error()):
+				t.Errorf("got error %v, want %v", err, testCase.ExpectedError)
+			}
+
+			if got != testCase.Expected {
+				t.Errorf("got %#v, want %#v", got, testCase.Expected)
+			}
+
+			if accepted != testCase.ExpectedAccepted {
+				t.Errorf("got accepted %#v, want %#v", accepted, testCase.ExpectedAccepted)
+			}
+		})
+	}
+}
+
 func TestXMLHandler(t *testing.T) {
 	t.Run("Handled", func(t *testing.T) {
 		body := &readCloser{
@@ -859,6 +1850,358 @@ func TestXMLHandler(t *testing.T) {
 	})
 }
 
+func TestXMLHandlerWithOptions(t *testing.T) {
+	type value struct {
+		XMLName xml.Name `xml:"urn:test root"`
+		Key     string   `xml:"key"`
+	}
+
+	body := &readCloser{
+		Reader: strings.NewReader(`<root><key>&custom;<br></key></root>`),
+	}
+
+	resp := &http.Response{Body: body}
+
+	var dst value
+
+	err := httpc.UnmarshalXMLHandlerWithOptions(httpc.XMLOptions{
+		Entity:       map[string]string{"custom": "value"},
+		DefaultSpace: "urn:test",
+		AutoClose:    []string{"br"},
+	})(&dst, resp)
+	if err != nil {
+		t.Errorf("got error %v, want <nil>", err)
+	}
+
+	if got, want := dst.Key, "value"; got != want {
+		t.Errorf("dst.Key = %v, want %v", got, want)
+	}
+
+	if !body.closed {
+		t.Error("body not closed")
+	}
+}
+
+type textValue struct {
+	Text string
+}
+
+func (v *textValue) UnmarshalText(b []byte) error {
+	if len(b) == 0 {
+		return errors.New("empty text")
+	}
+	v.Text = string(b)
+	return nil
+}
+
+func TestUnmarshalTextHandler(t *testing.T) {
+	t.Run("String", func(t *testing.T) {
+		body := &readCloser{Reader: strings.NewReader("OK")}
+
+		var dst string
+
+		if err := httpc.UnmarshalTextHandler()(&dst, &http.Response{Body: body}); err != nil {
+			t.Errorf("got error %v, want <nil>", err)
+		}
+
+		if got, want := dst, "OK"; got != want {
+			t.Errorf("dst = %q, want %q", got, want)
+		}
+
+		if !body.closed {
+			t.Error("body not closed")
+		}
+	})
+
+	t.Run("TextUnmarshaler", func(t *testing.T) {
+		body := &readCloser{Reader: strings.NewReader("OK")}
+
+		var dst textValue
+
+		if err := httpc.UnmarshalTextHandler()(&dst, &http.Response{Body: body}); err != nil {
+			t.Errorf("got error %v, want <nil>", err)
+		}
+
+		if got, want := dst.Text, "OK"; got != want {
+			t.Errorf("dst.Text = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("TextUnmarshaler error", func(t *testing.T) {
+		body := &readCloser{Reader: strings.NewReader("")}
+
+		var dst textValue
+
+		if err := httpc.UnmarshalTextHandler()(&dst, &http.Response{Body: body}); err == nil {
+			t.Error("got nil error")
+		}
+
+		if !body.closed {
+			t.Error("body not closed")
+		}
+	})
+
+	t.Run("Unsupported destination", func(t *testing.T) {
+		body := &readCloser{Reader: strings.NewReader("OK")}
+
+		var dst int
+
+		err := httpc.UnmarshalTextHandler()(&dst, &http.Response{Body: body})
+		if !errors.Is(err, httpc.ErrUnhandledResponse) {
+			t.Errorf("got error %v, want %v", err, httpc.ErrUnhandledResponse)
+		}
+
+		if body.closed {
+			t.Error("body closed")
+		}
+	})
+}
+
+type binaryValue struct {
+	Data []byte
+}
+
+func (v *binaryValue) UnmarshalBinary(b []byte) error {
+	v.Data = slices.Clone(b)
+	return nil
+}
+
+func (v *binaryValue) UnmarshalText([]byte) error {
+	return errors.New("UnmarshalText called")
+}
+
+func TestUnmarshalerHandler(t *testing.T) {
+	t.Run("BinaryUnmarshaler", func(t *testing.T) {
+		body := &readCloser{Reader: strings.NewReader("\x00\x01")}
+
+		var dst binaryValue
+
+		if err := httpc.UnmarshalerHandler()(&dst, &http.Response{Body: body}); err != nil {
+			t.Errorf("got error %v, want <nil>", err)
+		}
+
+		if got, want := string(dst.Data), "\x00\x01"; got != want {
+			t.Errorf("dst.Data = %q, want %q", got, want)
+		}
+
+		if !body.closed {
+			t.Error("body not closed")
+		}
+	})
+
+	t.Run("TextUnmarshaler", func(t *testing.T) {
+		body := &readCloser{Reader: strings.NewReader("OK")}
+
+		var dst textValue
+
+		if err := httpc.UnmarshalerHandler()(&dst, &http.Response{Body: body}); err != nil {
+			t.Errorf("got error %v, want <nil>", err)
+		}
+
+		if got, want := dst.Text, "OK"; got != want {
+			t.Errorf("dst.Text = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("Unsupported destination", func(t *testing.T) {
+		body := &readCloser{Reader: strings.NewReader("OK")}
+
+		var dst string
+
+		err := httpc.UnmarshalerHandler()(&dst, &http.Response{Body: body})
+		if !errors.Is(err, httpc.ErrUnhandledResponse) {
+			t.Errorf("got error %v, want %v", err, httpc.ErrUnhandledResponse)
+		}
+
+		if body.closed {
+			t.Error("body closed")
+		}
+	})
+}
+
+func TestSniffHandler(t *testing.T) {
+	type value struct {
+		Key string `json:"key" xml:"key"`
+	}
+
+	testCases := []struct {
+		Name          string
+		ContentType   string
+		Body          string
+		Expected      value
+		ExpectedError error
+	}{
+		{
+			Name:     "JSON without content type",
+			Body:     ` {"key":"value"}`,
+			Expected: value{Key: "value"},
+		},
+		{
+			Name:        "JSON as octet stream",
+			ContentType: "application/octet-stream",
+			Body:        `{"key":"value"}`,
+			Expected:    value{Key: "value"},
+		},
+		{
+			Name:     "XML with declaration",
+			Body:     `<?xml version="1.0"?><value><key>value</key></value>`,
+			Expected: value{Key: "value"},
+		},
+		{
+			Name:     "XML without declaration",
+			Body:     "\n<value><key>value</key></value>",
+			Expected: value{Key: "value"},
+		},
+		{
+			Name:          "HTML",
+			Body:          `<html><body>value</body></html>`,
+			ExpectedError: httpc.ErrUnhandledResponse,
+		},
+		{
+			Name:          "Empty",
+			ExpectedError: httpc.ErrUnhandledResponse,
+		},
+		{
+			Name:          "Other content type",
+			ContentType:   "text/plain",
+			Body:          `{"key":"value"}`,
+			ExpectedError: httpc.ErrUnhandledResponse,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			body := &readCloser{Reader: strings.NewReader(testCase.Body)}
+
+			resp := &http.Response{Header: make(http.Header), Body: body}
+
+			if testCase.ContentType != "" {
+				resp.Header.Set("Content-Type", testCase.ContentType)
+			}
+
+			var got value
+
+			err := httpc.SniffHandler()(&got, resp)
+			if !errors.Is(err, testCase.ExpectedError) {
+				t.Fatalf("got error %v, want %v", err, testCase.ExpectedError)
+			}
+
+			if got != testCase.Expected {
+				t.Errorf("got %#v, want %#v", got, testCase.Expected)
+			}
+
+			if testCase.ExpectedError != nil {
+				b, err := io.ReadAll(resp.Body)
+				if err != nil {
+					t.Fatalf("failed to read body: %v", err)
+				}
+
+				if got, want := string(b), testCase.Body; got != want {
+					t.Errorf("got body %q, want %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSniffHandler_ErrorStatus(t *testing.T) {
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusInternalServerError,
+				Header:     make(http.Header),
+				Body:       io.NopCloser(strings.NewReader(`{"key":"value"}`)),
+				Request:    req,
+			}, nil
+		}),
+	}
+
+	got, err := httpc.Fetch[map[string]string](t.Context(), http.MethodGet, "https://example.com/",
+		httpc.WithClient(client))
+
+	var statusErr *httpc.StatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("got error %v, want %T", err, statusErr)
+	}
+
+	if got, want := statusErr.StatusCode, http.StatusInternalServerError; got != want {
+		t.Errorf("got status %d, want %d", got, want)
+	}
+
+	if got != nil {
+		t.Errorf("got %v, want nil", got)
+	}
+}
+
+func BenchmarkFetch(b *testing.B) {
+	type item struct {
+		ID    int      `json:"id"`
+		Name  string   `json:"name"`
+		Tags  []string `json:"tags"`
+		Price float64  `json:"price"`
+	}
+
+	newPayload := func(n int) []byte {
+		items := make([]item, n)
+		for i := range items {
+			items[i] = item{ID: i, Name: fmt.Sprintf("item %d", i), Tags: []string{"a", "b"}, Price: float64(i) / 3}
+		}
+
+		payload, err := json.Marshal(items)
+		if err != nil {
+			b.Fatal(err)
+		}
+		return payload
+	}
+
+	newClient := func(payload []byte, contentType string, contentLength int64) *http.Client {
+		return &http.Client{
+			Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				status := http.StatusOK
+				if payload == nil {
+					status = http.StatusNoContent
+				}
+
+				return &http.Response{
+					StatusCode:    status,
+					Header:        http.Header{"Content-Type": []string{contentType}},
+					Body:          io.NopCloser(bytes.NewReader(payload)),
+					ContentLength: contentLength,
+					Request:       req,
+				}, nil
+			}),
+		}
+	}
+
+	small, large := newPayload(10), newPayload(10_000)
+
+	benchmarks := []struct {
+		Name   string
+		Client *http.Client
+	}{
+		{Name: "NoContent", Client: newClient(nil, "", 0)},
+		{Name: "JSON/Small", Client: newClient(small, "application/json", int64(len(small)))},
+		{Name: "JSON/Small/UnknownLength", Client: newClient(small, "application/json", -1)},
+		{Name: "JSON/Large", Client: newClient(large, "application/json", int64(len(large)))},
+		{Name: "JSON/Large/UnknownLength", Client: newClient(large, "application/json", -1)},
+	}
+
+	for _, bb := range benchmarks {
+		b.Run(bb.Name, func(b *testing.B) {
+			b.ReportAllocs()
+
+			for b.Loop() {
+				_, err := httpc.Fetch[[]item](b.Context(), http.MethodGet, "https://example.com/items/{id}",
+					httpc.WithClient(bb.Client),
+					httpc.WithPathValue("id", "1"))
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
 type errorReader struct {
 	err error
 }
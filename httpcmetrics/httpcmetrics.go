@@ -0,0 +1,277 @@
+// Package httpcmetrics provides metrics for requests made using github.com/nussjustin/httpc.
+//
+// Metrics are exposed in the Prometheus text exposition format, so that they can be scraped by Prometheus and
+// compatible systems without depending on the Prometheus client library.
+package httpcmetrics
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nussjustin/httpc"
+)
+
+// DefaultBuckets are the default upper bounds of the buckets of the request duration histogram, in seconds.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Options configures a [Collector].
+type Options struct {
+	// Buckets are the upper bounds of the buckets of the request duration histogram, in seconds.
+	//
+	// If empty, [DefaultBuckets] is used.
+	Buckets []float64
+
+	// Labels contains the keys of labels added using [httpc.WithLabel] that are used as additional metric labels.
+	//
+	// Requests without a label use an empty value. Labels become metric dimensions, so their values should come from
+	// a small set.
+	Labels []string
+}
+
+// Collector records metrics for requests and serves them in the Prometheus text exposition format.
+//
+// Collector implements [httpc.Metrics] and can be used via [httpc.WithMetrics]. The following metrics are recorded,
+// each labeled with the method, the path template and the operation name set using [httpc.WithOperationName] of the
+// request as well as the labels configured in [Options]:
+//
+//   - httpc_requests_in_flight: a gauge of the requests currently in flight
+//   - httpc_requests_total: a counter of finished requests, additionally labeled by status code or "error" if no
+//     response was received
+//   - httpc_request_duration_seconds: a histogram of the request durations
+//
+// The path template contains wildcards instead of the values, for example "/product/{id}", which keeps the number of
+// series low.
+//
+// A Collector should be created once and reused, for example by passing it to [httpc.New] via [httpc.WithMetrics].
+type Collector struct {
+	buckets []float64
+	labels  []string
+
+	mu        sync.Mutex
+	inFlight  family
+	requests  family
+	durations family
+}
+
+var _ httpc.Metrics = (*Collector)(nil)
+
+var labelNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// New returns a new [Collector] using the given options.
+//
+// New panics if a label is not a valid Prometheus label name or conflicts with one of the built-in labels.
+func New(opts Options) *Collector {
+	buckets := opts.Buckets
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+
+	buckets = slices.Sorted(slices.Values(buckets))
+
+	for _, label := range opts.Labels {
+		if !labelNameRegexp.MatchString(label) || strings.HasPrefix(label, "__") {
+			panic(fmt.Errorf("invalid label name %q", label))
+		}
+
+		if slices.Contains([]string{"method", "path", "operation", "code", "le"}, label) {
+			panic(fmt.Errorf("label name %q is reserved", label))
+		}
+	}
+
+	requestLabels := append([]string{"method", "path", "operation"}, opts.Labels...)
+	resultLabels := append([]string{"method", "path", "operation", "code"}, opts.Labels...)
+
+	return &Collector{
+		buckets: buckets,
+		labels:  slices.Clone(opts.Labels),
+
+		inFlight: newFamily("httpc_requests_in_flight", "Number of requests currently in flight.",
+			"gauge", requestLabels),
+		requests: newFamily("httpc_requests_total", "Number of finished requests.",
+			"counter", resultLabels),
+		durations: newFamily("httpc_request_duration_seconds", "Duration of requests in seconds.",
+			"histogram", requestLabels),
+	}
+}
+
+// RequestStarted implements the [httpc.Metrics] interface.
+func (c *Collector) RequestStarted(info httpc.SpanInfo) {
+	values := c.values(info, "")
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.inFlight.series(values).value++
+}
+
+// RequestFinished implements the [httpc.Metrics] interface.
+func (c *Collector) RequestFinished(info httpc.SpanInfo, result httpc.SpanResult, duration time.Duration) {
+	code := "error"
+	if result.StatusCode != 0 {
+		code = strconv.Itoa(result.StatusCode)
+	}
+
+	values, resultValues := c.values(info, ""), c.values(info, code)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.inFlight.series(values).value--
+	c.requests.series(resultValues).value++
+	c.durations.series(values).observe(c.buckets, duration.Seconds())
+}
+
+// values returns the label values for the request. If code is not empty, it is included after the operation name.
+func (c *Collector) values(info httpc.SpanInfo, code string) []string {
+	values := make([]string, 0, 4+len(c.labels))
+	values = append(values, info.Method, info.PathTemplate, info.OperationName)
+
+	if code != "" {
+		values = append(values, code)
+	}
+
+	for _, label := range c.labels {
+		values = append(values, info.Labels[label])
+	}
+
+	return values
+}
+
+// ServeHTTP implements the [http.Handler] interface by writing all metrics in the Prometheus text exposition format.
+func (c *Collector) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	var buf bytes.Buffer
+
+	c.mu.Lock()
+	c.inFlight.write(&buf, nil)
+	c.requests.write(&buf, nil)
+	c.durations.write(&buf, c.buckets)
+	c.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = w.Write(buf.Bytes())
+}
+
+// family is a group of series with the same name and label names.
+type family struct {
+	name   string
+	help   string
+	typ    string
+	labels []string
+
+	byKey map[string]*series
+}
+
+func newFamily(name, help, typ string, labels []string) family {
+	return family{name: name, help: help, typ: typ, labels: labels, byKey: make(map[string]*series)}
+}
+
+// series returns the series with the given label values, creating it if necessary.
+func (f *family) series(values []string) *series {
+	key := strings.Join(values, "\xff")
+
+	s, ok := f.byKey[key]
+	if !ok {
+		s = &series{values: values}
+		f.byKey[key] = s
+	}
+
+	return s
+}
+
+// write writes all series of the family to buf, sorted by their label values.
+//
+// If buckets is not nil, the series are written as histograms.
+func (f *family) write(buf *bytes.Buffer, buckets []float64) {
+	if len(f.byKey) == 0 {
+		return
+	}
+
+	_, _ = fmt.Fprintf(buf, "# HELP %s %s\n# TYPE %s %s\n", f.name, f.help, f.name, f.typ)
+
+	for _, key := range slices.Sorted(maps.Keys(f.byKey)) {
+		s := f.byKey[key]
+
+		if buckets == nil {
+			writeSample(buf, f.name, f.labels, s.values, "", "", s.value)
+			continue
+		}
+
+		for i, le := range buckets {
+			writeSample(buf, f.name+"_bucket", f.labels, s.values, "le", formatFloat(le), float64(s.counts[i]))
+		}
+
+		writeSample(buf, f.name+"_bucket", f.labels, s.values, "le", "+Inf", float64(s.count))
+		writeSample(buf, f.name+"_sum", f.labels, s.values, "", "", s.value)
+		writeSample(buf, f.name+"_count", f.labels, s.values, "", "", float64(s.count))
+	}
+}
+
+// writeSample writes a single sample. If extraName is not empty, it is added as label after all other labels.
+func writeSample(buf *bytes.Buffer, name string, labels, values []string, extraName, extraValue string, value float64) {
+	buf.WriteString(name)
+	buf.WriteByte('{')
+
+	for i, label := range labels {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		writeLabel(buf, label, values[i])
+	}
+
+	if extraName != "" {
+		buf.WriteByte(',')
+		writeLabel(buf, extraName, extraValue)
+	}
+
+	buf.WriteString("} ")
+	buf.WriteString(formatFloat(value))
+	buf.WriteByte('\n')
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func writeLabel(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(name)
+	buf.WriteString(`="`)
+	_, _ = labelValueReplacer.WriteString(buf, value)
+	buf.WriteByte('"')
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// series is a single time series. For gauges and counters only value is used. For histograms value is the sum of all
+// observations.
+type series struct {
+	values []string
+
+	value  float64
+	counts []uint64
+	count  uint64
+}
+
+// observe records v in the histogram, where counts[i] is the number of observations less than or equal to buckets[i].
+func (s *series) observe(buckets []float64, v float64) {
+	if s.counts == nil {
+		s.counts = make([]uint64, len(buckets))
+	}
+
+	for i, le := range buckets {
+		if v <= le {
+			s.counts[i]++
+		}
+	}
+
+	s.value += v
+	s.count++
+}
@@ -0,0 +1,137 @@
+package httpcmetrics_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nussjustin/httpc"
+	"github.com/nussjustin/httpc/httpcmetrics"
+)
+
+func TestCollector(t *testing.T) {
+	c := httpcmetrics.New(httpcmetrics.Options{Buckets: []float64{1, 0.5}, Labels: []string{"team"}})
+
+	info := httpc.SpanInfo{
+		Method:        http.MethodGet,
+		PathTemplate:  "/i/{id}",
+		OperationName: "Get",
+		Labels:        map[string]string{"team": `"x"`},
+	}
+
+	c.RequestStarted(info)
+	c.RequestStarted(info)
+	c.RequestFinished(info, httpc.SpanResult{StatusCode: http.StatusOK}, 250*time.Millisecond)
+
+	other := httpc.SpanInfo{Method: http.MethodPost, PathTemplate: "/i"}
+
+	c.RequestStarted(other)
+	c.RequestFinished(other, httpc.SpanResult{Err: errors.New("failed")}, 2*time.Second)
+
+	rec := httptest.NewRecorder()
+	c.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if got, want := rec.Header().Get("Content-Type"), "text/plain; version=0.0.4; charset=utf-8"; got != want {
+		t.Errorf("got content type %q, want %q", got, want)
+	}
+
+	want := strings.Join([]string{
+		`# HELP httpc_requests_in_flight Number of requests currently in flight.`,
+		`# TYPE httpc_requests_in_flight gauge`,
+		`httpc_requests_in_flight{method="GET",path="/i/{id}",operation="Get",team="\"x\""} 1`,
+		`httpc_requests_in_flight{method="POST",path="/i",operation="",team=""} 0`,
+		`# HELP httpc_requests_total Number of finished requests.`,
+		`# TYPE httpc_requests_total counter`,
+		`httpc_requests_total{method="GET",path="/i/{id}",operation="Get",code="200",team="\"x\""} 1`,
+		`httpc_requests_total{method="POST",path="/i",operation="",code="error",team=""} 1`,
+		`# HELP httpc_request_duration_seconds Duration of requests in seconds.`,
+		`# TYPE httpc_request_duration_seconds histogram`,
+		`httpc_request_duration_seconds_bucket{method="GET",path="/i/{id}",operation="Get",team="\"x\"",le="0.5"} 1`,
+		`httpc_request_duration_seconds_bucket{method="GET",path="/i/{id}",operation="Get",team="\"x\"",le="1"} 1`,
+		`httpc_request_duration_seconds_bucket{method="GET",path="/i/{id}",operation="Get",team="\"x\"",le="+Inf"} 1`,
+		`httpc_request_duration_seconds_sum{method="GET",path="/i/{id}",operation="Get",team="\"x\""} 0.25`,
+		`httpc_request_duration_seconds_count{method="GET",path="/i/{id}",operation="Get",team="\"x\""} 1`,
+		`httpc_request_duration_seconds_bucket{method="POST",path="/i",operation="",team="",le="0.5"} 0`,
+		`httpc_request_duration_seconds_bucket{method="POST",path="/i",operation="",team="",le="1"} 0`,
+		`httpc_request_duration_seconds_bucket{method="POST",path="/i",operation="",team="",le="+Inf"} 1`,
+		`httpc_request_duration_seconds_sum{method="POST",path="/i",operation="",team=""} 2`,
+		`httpc_request_duration_seconds_count{method="POST",path="/i",operation="",team=""} 1`,
+	}, "\n") + "\n"
+
+	if diff := cmp.Diff(want, rec.Body.String()); diff != "" {
+		t.Errorf("metrics mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCollector_Fetch(t *testing.T) {
+	c := httpcmetrics.New(httpcmetrics.Options{})
+
+	client := httpc.New(
+		httpc.WithClient(&http.Client{
+			Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusNoContent,
+					Header:     make(http.Header),
+					Body:       http.NoBody,
+					Request:    r,
+				}, nil
+			}),
+		}),
+		httpc.WithBaseURL(mustParseURL(t, "https://example.com/api/")),
+		httpc.WithMetrics(c))
+
+	for _, id := range []string{"1", "2"} {
+		err := client.Fetch(t.Context(), http.MethodGet, "product/{id}", nil, httpc.WithPathValue("id", id))
+		if err != nil {
+			t.Fatalf("got error %v", err)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	c.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body, _ := io.ReadAll(rec.Body)
+
+	want := `httpc_requests_total{method="GET",path="/api/product/{id}",operation="",code="204"} 2`
+	if !strings.Contains(string(body), want+"\n") {
+		t.Errorf("metrics do not contain %q:\n%s", want, body)
+	}
+}
+
+func TestNew_InvalidLabel(t *testing.T) {
+	for _, label := range []string{"", "1abc", "a-b", "__name", "code", "operation", "le"} {
+		t.Run(label, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("got no panic for label %q", label)
+				}
+			}()
+
+			httpcmetrics.New(httpcmetrics.Options{Labels: []string{label}})
+		})
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func mustParseURL(tb testing.TB, s string) *url.URL {
+	tb.Helper()
+
+	u, err := url.Parse(s)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return u
+}
@@ -0,0 +1,162 @@
+package httpctest
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/go-json-experiment/json"
+	"github.com/go-json-experiment/json/jsontext"
+
+	"github.com/nussjustin/httpc"
+	"github.com/nussjustin/httpc/internal/jsonshape"
+)
+
+// MismatchKind specifies the kind of a [Mismatch].
+type MismatchKind int
+
+const (
+	// UnknownField is used for members of a JSON object that have no matching field in the Go type.
+	UnknownField MismatchKind = iota + 1
+
+	// MissingField is used for fields of the Go type that are not present in the JSON object.
+	//
+	// Fields using the omitempty or omitzero options are optional and never reported as missing.
+	MissingField
+)
+
+// String implements the [fmt.Stringer] interface.
+func (k MismatchKind) String() string {
+	switch k {
+	case UnknownField:
+		return "unknown field"
+	case MissingField:
+		return "missing field"
+	default:
+		return fmt.Sprintf("MismatchKind(%d)", int(k))
+	}
+}
+
+// Mismatch describes a difference between the shape of a JSON value and a Go type.
+type Mismatch struct {
+	// Kind is the kind of the mismatch.
+	Kind MismatchKind
+
+	// Path is the path to the mismatched field, for example "items[].name".
+	//
+	// Elements of arrays are denoted by "[]" and values of maps by "{}".
+	Path string
+}
+
+// String implements the [fmt.Stringer] interface.
+func (m Mismatch) String() string {
+	return m.Kind.String() + " " + m.Path
+}
+
+// CompareJSON compares the shape of the given JSON value with the Go type of v.
+//
+// v is only used for its type and can be either a value or a pointer.
+//
+// The returned mismatches are sorted by path and each path is reported only once, even if it occurs in multiple
+// elements of an array.
+func CompareJSON(data []byte, v any) ([]Mismatch, error) {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[Mismatch]struct{})
+
+	jsonshape.Walk(value, reflect.TypeOf(v), func(kind jsonshape.Kind, path string) {
+		switch kind {
+		case jsonshape.Unknown:
+			seen[Mismatch{Kind: UnknownField, Path: path}] = struct{}{}
+		case jsonshape.Missing:
+			seen[Mismatch{Kind: MissingField, Path: path}] = struct{}{}
+		}
+	})
+
+	mismatches := make([]Mismatch, 0, len(seen))
+	for m := range seen {
+		mismatches = append(mismatches, m)
+	}
+
+	slices.SortFunc(mismatches, func(a, b Mismatch) int {
+		if c := strings.Compare(a.Path, b.Path); c != 0 {
+			return c
+		}
+		return int(a.Kind - b.Kind)
+	})
+
+	return mismatches, nil
+}
+
+// Contract describes the expected shape of the JSON response of an endpoint.
+type Contract struct {
+	// Name is the name of the contract, used as name of the subtest.
+	Name string
+
+	// Method is the request method. Defaults to [http.MethodGet].
+	Method string
+
+	// URL is the URL of the endpoint.
+	URL string
+
+	// Options contains additional options used for the request, for example for authentication.
+	Options []httpc.FetchOption
+
+	// Value is a value of the Go type the response is decoded into.
+	Value any
+
+	// Recording is the path of a file used to record and replay the response using a [Recorder], if set.
+	//
+	// This allows checking contracts without network access, for example in CI, against a response recorded from the
+	// live API. Since the request is sent using the client of the recorder, any client set via Options is ignored.
+	Recording string
+
+	// RecorderOptions configures the [Recorder] used when Recording is set, for example to record the response again.
+	RecorderOptions RecorderOptions
+}
+
+// CheckContracts requests each endpoint described by the given contracts and reports differences between the
+// response and the expected Go type as test errors.
+//
+// Each contract is checked in its own subtest. This is intended to be run periodically against live APIs to detect
+// changes in their contract. Contracts with a [Contract.Recording] are checked against the recorded response instead,
+// unless there is no recording yet or [RecorderOptions.Update] is set.
+func CheckContracts(t *testing.T, contracts ...Contract) {
+	t.Helper()
+
+	for _, c := range contracts {
+		t.Run(c.Name, func(t *testing.T) {
+			method := c.Method
+			if method == "" {
+				method = http.MethodGet
+			}
+
+			opts := c.Options
+
+			if c.Recording != "" {
+				r := NewRecorder(t, c.Recording, c.RecorderOptions)
+				opts = append(slices.Clip(opts), httpc.WithClient(r.Client()))
+			}
+
+			data, err := httpc.Fetch[jsontext.Value](t.Context(), method, c.URL, opts...)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+
+			mismatches, err := CompareJSON(data, c.Value)
+			if err != nil {
+				t.Fatalf("failed to compare response: %v", err)
+			}
+
+			for _, m := range mismatches {
+				t.Errorf("%s", m)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package httpctest_test
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/nussjustin/httpc/httpctest"
+)
+
+type contractEmbedded struct {
+	ID int `json:"id"`
+}
+
+type contractItem struct {
+	Name string `json:"name"`
+	Tags []string
+}
+
+type contractResponse struct {
+	contractEmbedded
+
+	Items   []contractItem      `json:"items"`
+	Labels  map[string]string   `json:"labels,omitempty"`
+	Nested  map[string]struct{} `json:"nested,omitzero"`
+	Created time.Time           `json:"created"`
+	Ignored string              `json:"-"`
+}
+
+func TestCompareJSON(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Data     string
+		Expected []httpctest.Mismatch
+	}{
+		{
+			Name: "Match",
+			Data: `{"id":1,"items":[{"name":"a","Tags":[]}],"created":"2024-01-01T00:00:00Z"}`,
+		},
+		{
+			Name: "Unknown field",
+			Data: `{"id":1,"items":[],"created":"","extra":true}`,
+			Expected: []httpctest.Mismatch{
+				{Kind: httpctest.UnknownField, Path: "extra"},
+			},
+		},
+		{
+			Name: "Missing field",
+			Data: `{"items":[]}`,
+			Expected: []httpctest.Mismatch{
+				{Kind: httpctest.MissingField, Path: "created"},
+				{Kind: httpctest.MissingField, Path: "id"},
+			},
+		},
+		{
+			Name: "Nested",
+			Data: `{"id":1,"items":[{"name":"a"},{"Tags":[],"color":"red"},{"color":"blue"}],"created":"",` +
+				`"nested":{"a":{"b":1}}}`,
+			Expected: []httpctest.Mismatch{
+				{Kind: httpctest.MissingField, Path: "items[].Tags"},
+				{Kind: httpctest.UnknownField, Path: "items[].color"},
+				{Kind: httpctest.MissingField, Path: "items[].name"},
+				{Kind: httpctest.UnknownField, Path: "nested{}.b"},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			got, err := httpctest.CompareJSON([]byte(testCase.Data), &contractResponse{})
+			if err != nil {
+				t.Fatalf("got error %v", err)
+			}
+
+			if diff := cmp.Diff(testCase.Expected, got, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("CompareJSON() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCheckContracts(t *testing.T) {
+	srv := httpctest.NewServer(t, httpctest.Scenario{
+		Pattern: "GET /items",
+		Responses: []httpctest.Response{{
+			Header: http.Header{"Content-Type": {"application/json"}},
+			Body:   `{"id":1,"items":[{"name":"a","Tags":["b"]}],"created":"2024-01-01T00:00:00Z"}`,
+		}},
+	})
+
+	httpctest.CheckContracts(t, httpctest.Contract{
+		Name:  "Items",
+		URL:   srv.URL + "/items",
+		Value: contractResponse{},
+	})
+}
+
+func TestCheckContracts_Recording(t *testing.T) {
+	srv := httpctest.NewServer(t, httpctest.Scenario{
+		Pattern: "GET /items",
+		Responses: []httpctest.Response{{
+			Header: http.Header{"Content-Type": {"application/json"}},
+			Body:   `{"id":1,"items":[{"name":"a","Tags":["b"]}],"created":"2024-01-01T00:00:00Z"}`,
+		}},
+	})
+
+	contract := httpctest.Contract{
+		Name:      "Items",
+		URL:       srv.URL + "/items",
+		Value:     contractResponse{},
+		Recording: filepath.Join(t.TempDir(), "items.json"),
+	}
+
+	httpctest.CheckContracts(t, contract)
+
+	if _, err := os.Stat(contract.Recording); err != nil {
+		t.Fatalf("failed to stat recording: %v", err)
+	}
+
+	// Without a server the contract can only be checked using the recording.
+	srv.Close()
+
+	httpctest.CheckContracts(t, contract)
+}
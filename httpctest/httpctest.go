@@ -0,0 +1,134 @@
+// Package httpctest provides utilities for testing code that uses github.com/nussjustin/httpc.
+package httpctest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Response describes how a single request is answered by a [Server].
+type Response struct {
+	// Status is the status code of the response. If zero, [http.StatusOK] is used.
+	Status int
+
+	// Header contains additional headers for the response.
+	Header http.Header
+
+	// Body is written as response body.
+	Body string
+
+	// Delay is the time to wait before sending the response.
+	//
+	// If the request is canceled while waiting, no response is sent.
+	Delay time.Duration
+
+	// Drop causes the connection to be closed without sending a response.
+	//
+	// Drop is applied after Delay.
+	//
+	// Note that [http.Transport] may transparently retry idempotent requests that fail on a reused connection.
+	Drop bool
+}
+
+// Scenario defines an ordered sequence of responses for requests matching a pattern.
+type Scenario struct {
+	// Pattern is the pattern of the requests handled by this scenario.
+	//
+	// See [http.ServeMux] for the pattern syntax.
+	Pattern string
+
+	// Responses contains the responses to the matching requests in the order in which they are sent.
+	//
+	// The first request receives the first response, the second request receives the second response and so on.
+	// Once all responses have been used, the last response is repeated for all further requests.
+	Responses []Response
+}
+
+// Server is a test HTTP server that answers requests based on a list of scenarios.
+//
+// Requests not matching any scenario are answered with 404 Not Found.
+type Server struct {
+	*httptest.Server
+
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+// NewServer starts and returns a new [Server] for the given scenarios.
+//
+// The server is closed automatically when the test ends.
+func NewServer(tb testing.TB, scenarios ...Scenario) *Server {
+	tb.Helper()
+
+	s := &Server{calls: make(map[string]int)}
+
+	mux := http.NewServeMux()
+
+	for _, scenario := range scenarios {
+		if len(scenario.Responses) == 0 {
+			tb.Fatalf("httpctest: scenario %q has no responses", scenario.Pattern)
+		}
+
+		mux.HandleFunc(scenario.Pattern, func(w http.ResponseWriter, r *http.Request) {
+			s.serve(tb, scenario, w, r)
+		})
+	}
+
+	s.Server = httptest.NewServer(mux)
+	tb.Cleanup(s.Close)
+
+	return s
+}
+
+// Calls returns the number of requests received for the scenario with the given pattern.
+func (s *Server) Calls(pattern string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.calls[pattern]
+}
+
+func (s *Server) serve(tb testing.TB, scenario Scenario, w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	n := s.calls[scenario.Pattern]
+	s.calls[scenario.Pattern]++
+	s.mu.Unlock()
+
+	resp := scenario.Responses[min(n, len(scenario.Responses)-1)]
+
+	if resp.Delay > 0 {
+		t := time.NewTimer(resp.Delay)
+		defer t.Stop()
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-t.C:
+		}
+	}
+
+	if resp.Drop {
+		conn, _, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			tb.Errorf("httpctest: failed to drop connection: %v", err)
+			return
+		}
+
+		_ = conn.Close()
+		return
+	}
+
+	for k, v := range resp.Header {
+		w.Header()[k] = v
+	}
+
+	if resp.Status == 0 {
+		resp.Status = http.StatusOK
+	}
+
+	w.WriteHeader(resp.Status)
+	_, _ = w.Write([]byte(resp.Body))
+}
@@ -0,0 +1,70 @@
+package httpctest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/nussjustin/httpc"
+	"github.com/nussjustin/httpc/httpctest"
+)
+
+func TestServer(t *testing.T) {
+	srv := httpctest.NewServer(t,
+		httpctest.Scenario{
+			Pattern: "GET /flaky",
+			Responses: []httpctest.Response{
+				{Status: http.StatusInternalServerError},
+				{Drop: true},
+				{Header: http.Header{"Content-Type": {"application/json"}}, Body: `"ok"`},
+			},
+		},
+		httpctest.Scenario{
+			Pattern:   "GET /slow",
+			Responses: []httpctest.Response{{Delay: time.Second}},
+		},
+	)
+
+	// Disable keep-alives, so that dropped connections are not retried by the transport.
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+
+	fetch := func(ctx context.Context, path string) (string, error) {
+		return httpc.Fetch[string](ctx, http.MethodGet, srv.URL+path, httpc.WithClient(client))
+	}
+
+	if _, err := fetch(t.Context(), "/flaky"); !errors.Is(err, httpc.ErrUnexpectedStatus) {
+		t.Errorf("first call: got error %v, want %v", err, httpc.ErrUnexpectedStatus)
+	}
+
+	if _, err := fetch(t.Context(), "/flaky"); err == nil {
+		t.Error("second call: got no error")
+	}
+
+	for i := range 2 {
+		got, err := fetch(t.Context(), "/flaky")
+		if err != nil {
+			t.Fatalf("call %d: got error %v", i+3, err)
+		}
+
+		if want := "ok"; got != want {
+			t.Errorf("call %d: got %q, want %q", i+3, got, want)
+		}
+	}
+
+	if got, want := srv.Calls("GET /flaky"), 4; got != want {
+		t.Errorf("got %d calls, want %d", got, want)
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := fetch(ctx, "/slow"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	if _, err := fetch(t.Context(), "/unknown"); !errors.Is(err, httpc.ErrUnexpectedStatus) {
+		t.Errorf("got error %v, want %v", err, httpc.ErrUnexpectedStatus)
+	}
+}
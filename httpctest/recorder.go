@@ -0,0 +1,273 @@
+package httpctest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/go-json-experiment/json"
+	"github.com/go-json-experiment/json/jsontext"
+
+	"github.com/nussjustin/httpc"
+)
+
+// RecorderOptions configures a [Recorder].
+type RecorderOptions struct {
+	// Transport is used to send requests while recording. If nil, [http.DefaultTransport] is used.
+	Transport http.RoundTripper
+
+	// Redactor is used to remove secrets from the URLs and headers of recorded requests and responses before they are
+	// written to disk. If nil, [httpc.DefaultRedactor] is used.
+	Redactor *httpc.Redactor
+
+	// Update causes requests to be recorded again, even if the file already exists.
+	//
+	// This is typically set using a command line flag or an environment variable.
+	Update bool
+}
+
+// Recorder is an [http.RoundTripper] that records requests and responses to a file and replays them later.
+//
+// If the file does not exist or [RecorderOptions.Update] is set, requests are sent using the configured transport and
+// all requests and responses are written to the file when the test ends, unless the test failed. Otherwise requests
+// are answered using the recorded responses, without any network access.
+//
+// When replaying, each request is answered using the first recorded request with the same method, redacted URL and
+// body that was not used yet. Requests without a matching recording fail the test and return an error.
+type Recorder struct {
+	tb        testing.TB
+	path      string
+	redactor  *httpc.Redactor
+	transport http.RoundTripper
+	replay    bool
+
+	mu           sync.Mutex
+	interactions []recordedInteraction
+	used         []bool
+}
+
+var _ http.RoundTripper = (*Recorder)(nil)
+
+// recordedInteraction is a single request and the response received for it.
+type recordedInteraction struct {
+	Request  recordedRequest  `json:"request"`
+	Response recordedResponse `json:"response"`
+}
+
+type recordedRequest struct {
+	Method string       `json:"method"`
+	URL    string       `json:"url"`
+	Header http.Header  `json:"header,omitempty"`
+	Body   recordedBody `json:"body,omitzero"`
+}
+
+type recordedResponse struct {
+	Status int          `json:"status"`
+	Header http.Header  `json:"header,omitempty"`
+	Body   recordedBody `json:"body,omitzero"`
+}
+
+// recordedBody is a body that is stored as plain text if it is valid UTF-8 or base64 encoded otherwise.
+type recordedBody struct {
+	Text   string `json:"text,omitempty"`
+	Base64 []byte `json:"base64,omitempty"`
+}
+
+func newRecordedBody(b []byte) recordedBody {
+	if utf8.Valid(b) {
+		return recordedBody{Text: string(b)}
+	}
+	return recordedBody{Base64: b}
+}
+
+func (b recordedBody) bytes() []byte {
+	if b.Base64 != nil {
+		return b.Base64
+	}
+	return []byte(b.Text)
+}
+
+// NewRecorder returns a new [Recorder] that records to or replays from the file at the given path.
+//
+// If the file exists and can not be read, the test fails immediately.
+func NewRecorder(tb testing.TB, path string, opts RecorderOptions) *Recorder {
+	tb.Helper()
+
+	r := &Recorder{
+		tb:        tb,
+		path:      path,
+		redactor:  opts.Redactor,
+		transport: opts.Transport,
+	}
+
+	if r.redactor == nil {
+		r.redactor = httpc.DefaultRedactor()
+	}
+
+	if r.transport == nil {
+		r.transport = http.DefaultTransport
+	}
+
+	data, err := os.ReadFile(path)
+
+	switch {
+	case opts.Update || errors.Is(err, fs.ErrNotExist):
+		tb.Cleanup(r.save)
+	case err != nil:
+		tb.Fatalf("httpctest: failed to read recording: %v", err)
+	default:
+		if err := json.Unmarshal(data, &r.interactions); err != nil {
+			tb.Fatalf("httpctest: failed to decode recording %s: %v", path, err)
+		}
+
+		r.replay = true
+		r.used = make([]bool, len(r.interactions))
+	}
+
+	return r
+}
+
+// Client returns a new [http.Client] that uses the recorder.
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Replaying returns true if requests are answered using existing recordings.
+func (r *Recorder) Replaying() bool {
+	return r.replay
+}
+
+// RoundTrip implements the [http.RoundTripper] interface.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+
+	if req.Body != nil {
+		var err error
+
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	recorded := recordedRequest{
+		Method: req.Method,
+		URL:    r.redactor.URL(req.URL),
+		Header: r.redactor.Header(req.Header),
+		Body:   newRecordedBody(body),
+	}
+
+	if r.replay {
+		return r.replayResponse(req, recorded)
+	}
+
+	// Restore the body for the transport.
+	outReq := req.Clone(req.Context())
+	outReq.Body = io.NopCloser(bytes.NewReader(body))
+	outReq.ContentLength = int64(len(body))
+
+	resp, err := r.transport.RoundTrip(outReq)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	resp.ContentLength = int64(len(respBody))
+	resp.Request = req
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.interactions = append(r.interactions, recordedInteraction{
+		Request: recorded,
+		Response: recordedResponse{
+			Status: resp.StatusCode,
+			Header: r.redactor.Header(resp.Header),
+			Body:   newRecordedBody(respBody),
+		},
+	})
+
+	return resp, nil
+}
+
+// replayResponse returns the response for the first unused recording matching the request.
+func (r *Recorder) replayResponse(req *http.Request, recorded recordedRequest) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, interaction := range r.interactions {
+		if r.used[i] || !interaction.Request.matches(recorded) {
+			continue
+		}
+
+		r.used[i] = true
+
+		resp := interaction.Response
+		body := resp.Body.bytes()
+
+		return &http.Response{
+			Status:        strconv.Itoa(resp.Status) + " " + http.StatusText(resp.Status),
+			StatusCode:    resp.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        resp.Header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+
+	err := fmt.Errorf("httpctest: no recording for request %s %s", recorded.Method, recorded.URL)
+	r.tb.Error(err)
+	return nil, err
+}
+
+func (req *recordedRequest) matches(other recordedRequest) bool {
+	return req.Method == other.Method &&
+		req.URL == other.URL &&
+		bytes.Equal(req.Body.bytes(), other.Body.bytes())
+}
+
+// save writes all recorded interactions to the file, unless the test failed.
+func (r *Recorder) save() {
+	if r.tb.Failed() {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data, err := json.Marshal(r.interactions, jsontext.WithIndent("  "))
+	if err != nil {
+		r.tb.Errorf("httpctest: failed to encode recording: %v", err)
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o750); err != nil {
+		r.tb.Errorf("httpctest: failed to create directory for recording: %v", err)
+		return
+	}
+
+	if err := os.WriteFile(r.path, append(data, '\n'), 0o600); err != nil {
+		r.tb.Errorf("httpctest: failed to write recording: %v", err)
+	}
+}
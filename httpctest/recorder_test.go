@@ -0,0 +1,114 @@
+package httpctest_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nussjustin/httpc"
+	"github.com/nussjustin/httpc/httpctest"
+)
+
+func TestRecorder(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("Set-Cookie", "session=secret")
+		_, _ = io.WriteString(w, r.Method+" "+r.URL.Path+" "+string(body))
+	}))
+
+	path := filepath.Join(t.TempDir(), "testdata", "recording.json")
+
+	fetch := func(t *testing.T, client *http.Client, method, path, body string) string {
+		t.Helper()
+
+		got, err := httpc.Fetch[string](t.Context(), method, srv.URL+path+"?token=secret",
+			httpc.WithClient(client),
+			httpc.WithHeader("Authorization", "Bearer secret"),
+			httpc.WithBody(strings.NewReader(body)))
+		if err != nil {
+			t.Fatalf("got error %v", err)
+		}
+
+		return got
+	}
+
+	calls := []struct {
+		Method, Path, Body, Expected string
+	}{
+		{Method: http.MethodGet, Path: "/a", Expected: "GET /a "},
+		{Method: http.MethodPost, Path: "/b", Body: "one", Expected: "POST /b one"},
+		{Method: http.MethodPost, Path: "/b", Body: "two", Expected: "POST /b two"},
+	}
+
+	t.Run("Record", func(t *testing.T) {
+		r := httpctest.NewRecorder(t, path, httpctest.RecorderOptions{})
+
+		if r.Replaying() {
+			t.Fatal("recorder is replaying")
+		}
+
+		for _, call := range calls {
+			if got := fetch(t, r.Client(), call.Method, call.Path, call.Body); got != call.Expected {
+				t.Errorf("got %q, want %q", got, call.Expected)
+			}
+		}
+	})
+
+	srv.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read recording: %v", err)
+	}
+
+	if strings.Contains(string(data), "secret") {
+		t.Errorf("recording contains secret:\n%s", data)
+	}
+
+	t.Run("Replay", func(t *testing.T) {
+		r := httpctest.NewRecorder(t, path, httpctest.RecorderOptions{})
+
+		if !r.Replaying() {
+			t.Fatal("recorder is not replaying")
+		}
+
+		// Replay in a different order, matching requests by method, URL and body.
+		for _, i := range []int{2, 0, 1} {
+			call := calls[i]
+
+			if got := fetch(t, r.Client(), call.Method, call.Path, call.Body); got != call.Expected {
+				t.Errorf("got %q, want %q", got, call.Expected)
+			}
+		}
+	})
+}
+
+func TestRecorder_Unmatched(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recording.json")
+
+	if err := os.WriteFile(path, []byte(`[]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tb := &recordingTB{TB: t}
+
+	r := httpctest.NewRecorder(tb, path, httpctest.RecorderOptions{})
+
+	_, err := httpc.Fetch[string](t.Context(), http.MethodGet, "https://example.com/?key=secret",
+		httpc.WithClient(r.Client()))
+	if err == nil {
+		t.Error("got no error")
+	}
+
+	want := "httpctest: no recording for request GET https://example.com/?key=REDACTED"
+
+	if len(tb.errors) != 1 || tb.errors[0] != want {
+		t.Errorf("got errors %q, want %q", tb.errors, want)
+	}
+}
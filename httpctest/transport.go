@@ -0,0 +1,262 @@
+package httpctest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-json-experiment/json"
+)
+
+// errConnectionDropped is returned by a [Transport] for responses using [Response.Drop].
+var errConnectionDropped = errors.New("httpctest: connection dropped")
+
+// Expectation describes a request expected by a [Transport] and how it is answered.
+type Expectation struct {
+	// Method is the expected method of the request. If empty, any method matches.
+	Method string
+
+	// URL is the expected URL of the request.
+	//
+	// If the URL starts with a slash, only the path and query of the request are compared, otherwise the full URL is
+	// compared. If empty, any URL matches.
+	URL string
+
+	// Header contains headers that must be present in the request with the given values. Other headers are ignored.
+	Header http.Header
+
+	// Body is the expected body of the request. If nil, any body matches.
+	//
+	// If Body is a string or []byte, the request body must match exactly. Otherwise Body is encoded as JSON and the
+	// request body must contain the same JSON value, ignoring differences in formatting and the order of object
+	// members.
+	Body any
+
+	// Response is the response returned for the request.
+	//
+	// If [Response.Drop] is true, the request fails with an error instead.
+	Response Response
+}
+
+// String returns a short description of the expectation, used in test failures.
+func (e *Expectation) String() string {
+	method, u := e.Method, e.URL
+
+	if method == "" {
+		method = "*"
+	}
+
+	if u == "" {
+		u = "*"
+	}
+
+	return method + " " + u
+}
+
+// JSONResponse returns a [Response] with the given status code and v encoded as JSON body.
+//
+// If v can not be encoded, the test fails immediately.
+func JSONResponse(tb testing.TB, status int, v any) Response {
+	tb.Helper()
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		tb.Fatalf("httpctest: failed to encode response: %v", err)
+	}
+
+	return Response{
+		Status: status,
+		Header: http.Header{"Content-Type": {"application/json"}},
+		Body:   string(b),
+	}
+}
+
+// Transport is an [http.RoundTripper] that answers requests based on a list of expectations, without any network
+// access.
+//
+// Each request is matched against the expectations that were not used yet, in the order in which they were added.
+// The first matching expectation is used to answer the request and can not be used again. Requests not matching any
+// expectation fail the test and return an error.
+//
+// When the test ends, all expectations that were not used are reported as test errors.
+type Transport struct {
+	tb testing.TB
+
+	mu           sync.Mutex
+	expectations []Expectation
+}
+
+var _ http.RoundTripper = (*Transport)(nil)
+
+// NewTransport returns a new [Transport] for the given expectations.
+//
+// More expectations can be added later using [Transport.Expect].
+func NewTransport(tb testing.TB, expectations ...Expectation) *Transport {
+	tb.Helper()
+
+	t := &Transport{tb: tb}
+	t.Expect(expectations...)
+
+	tb.Cleanup(func() {
+		for _, e := range t.Pending() {
+			tb.Errorf("httpctest: expected request %s was not sent", &e)
+		}
+	})
+
+	return t
+}
+
+// Client returns a new [http.Client] that uses the transport.
+func (t *Transport) Client() *http.Client {
+	return &http.Client{Transport: t}
+}
+
+// Expect adds the given expectations.
+func (t *Transport) Expect(expectations ...Expectation) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.expectations = append(t.expectations, expectations...)
+}
+
+// Pending returns the expectations that were not used yet.
+func (t *Transport) Pending() []Expectation {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return slices.Clone(t.expectations)
+}
+
+// RoundTrip implements the [http.RoundTripper] interface.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+
+	if req.Body != nil {
+		var err error
+
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	e, ok := t.match(req, body)
+	if !ok {
+		err := fmt.Errorf("httpctest: unexpected request %s %s", req.Method, req.URL)
+		t.tb.Error(err)
+		return nil, err
+	}
+
+	resp := e.Response
+
+	if resp.Delay > 0 {
+		timer := time.NewTimer(resp.Delay)
+		defer timer.Stop()
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+
+	if resp.Drop {
+		return nil, errConnectionDropped
+	}
+
+	if resp.Status == 0 {
+		resp.Status = http.StatusOK
+	}
+
+	return &http.Response{
+		Status:        strconv.Itoa(resp.Status) + " " + http.StatusText(resp.Status),
+		StatusCode:    resp.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        resp.Header.Clone(),
+		Body:          io.NopCloser(strings.NewReader(resp.Body)),
+		ContentLength: int64(len(resp.Body)),
+		Request:       req,
+	}, nil
+}
+
+// match finds, removes and returns the first expectation matching the request.
+func (t *Transport) match(req *http.Request, body []byte) (Expectation, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i, e := range t.expectations {
+		if e.matches(req, body) {
+			t.expectations = append(t.expectations[:i:i], t.expectations[i+1:]...)
+			return e, true
+		}
+	}
+
+	return Expectation{}, false
+}
+
+func (e *Expectation) matches(req *http.Request, body []byte) bool {
+	if e.Method != "" && e.Method != req.Method {
+		return false
+	}
+
+	if e.URL != "" {
+		u := req.URL.String()
+		if strings.HasPrefix(e.URL, "/") {
+			u = req.URL.RequestURI()
+		}
+
+		if e.URL != u {
+			return false
+		}
+	}
+
+	for name, values := range e.Header {
+		if !reflect.DeepEqual(values, req.Header.Values(name)) {
+			return false
+		}
+	}
+
+	switch want := e.Body.(type) {
+	case nil:
+		return true
+	case string:
+		return want == string(body)
+	case []byte:
+		return bytes.Equal(want, body)
+	default:
+		return jsonEqual(want, body)
+	}
+}
+
+// jsonEqual reports whether data contains the same JSON value as want encoded as JSON.
+func jsonEqual(want any, data []byte) bool {
+	wantData, err := json.Marshal(want)
+	if err != nil {
+		return false
+	}
+
+	var wantValue, gotValue any
+
+	if err := json.Unmarshal(wantData, &wantValue); err != nil {
+		return false
+	}
+
+	if err := json.Unmarshal(data, &gotValue); err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(wantValue, gotValue)
+}
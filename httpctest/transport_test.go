@@ -0,0 +1,124 @@
+package httpctest_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nussjustin/httpc"
+	"github.com/nussjustin/httpc/httpctest"
+)
+
+// recordingTB records errors instead of failing the test and runs cleanup functions only when asked to.
+type recordingTB struct {
+	testing.TB
+
+	errors   []string
+	cleanups []func()
+}
+
+func (tb *recordingTB) Cleanup(f func()) {
+	tb.cleanups = append(tb.cleanups, f)
+}
+
+func (tb *recordingTB) Error(args ...any) {
+	tb.errors = append(tb.errors, fmt.Sprint(args...))
+}
+
+func (tb *recordingTB) Errorf(format string, args ...any) {
+	tb.errors = append(tb.errors, fmt.Sprintf(format, args...))
+}
+
+func (tb *recordingTB) runCleanups() {
+	for _, f := range tb.cleanups {
+		f()
+	}
+}
+
+func TestTransport(t *testing.T) {
+	type product struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	transport := httpctest.NewTransport(t,
+		httpctest.Expectation{
+			Method:   http.MethodGet,
+			URL:      "/products/1",
+			Header:   http.Header{"Authorization": {"Bearer token"}},
+			Response: httpctest.JSONResponse(t, http.StatusOK, product{ID: 1, Name: "Chair"}),
+		},
+		httpctest.Expectation{
+			Method:   http.MethodPost,
+			URL:      "https://example.com/products",
+			Body:     map[string]any{"name": "Table", "id": 2},
+			Response: httpctest.Response{Status: http.StatusNoContent},
+		},
+		httpctest.Expectation{
+			URL:      "/products/3",
+			Response: httpctest.Response{Drop: true},
+		},
+	)
+
+	client := httpc.New(httpc.WithClient(transport.Client()), httpc.WithHeader("Authorization", "Bearer token"))
+
+	var got product
+
+	if err := client.Fetch(t.Context(), http.MethodGet, "https://example.com/products/1", &got); err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	if diff := cmp.Diff(product{ID: 1, Name: "Chair"}, got); diff != "" {
+		t.Errorf("product mismatch (-want +got):\n%s", diff)
+	}
+
+	err := client.Fetch(t.Context(), http.MethodPost, "https://example.com/products", nil,
+		httpc.WithBodyJSON(product{ID: 2, Name: "Table"}))
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	if err := client.Fetch(t.Context(), http.MethodGet, "https://example.com/products/3", nil); err == nil {
+		t.Error("got no error for dropped request")
+	}
+
+	if got := transport.Pending(); len(got) != 0 {
+		t.Errorf("got %d pending expectations", len(got))
+	}
+}
+
+func TestTransport_Unmet(t *testing.T) {
+	tb := &recordingTB{TB: t}
+
+	transport := httpctest.NewTransport(tb,
+		httpctest.Expectation{
+			Method:   http.MethodGet,
+			URL:      "/a",
+			Response: httpctest.Response{Status: http.StatusNoContent},
+		},
+		httpctest.Expectation{Method: http.MethodDelete, URL: "/b"},
+	)
+
+	client := httpc.New(httpc.WithClient(transport.Client()))
+
+	if err := client.Fetch(t.Context(), http.MethodGet, "https://example.com/a", nil); err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	if err := client.Fetch(t.Context(), http.MethodGet, "https://example.com/c", nil); err == nil {
+		t.Error("got no error for unexpected request")
+	}
+
+	tb.runCleanups()
+
+	want := []string{
+		"httpctest: unexpected request GET https://example.com/c",
+		"httpctest: expected request DELETE /b was not sent",
+	}
+
+	if diff := cmp.Diff(want, tb.errors); diff != "" {
+		t.Errorf("errors mismatch (-want +got):\n%s", diff)
+	}
+}
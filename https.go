@@ -0,0 +1,187 @@
+package httpc
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInsecureURL is returned when a request using plain HTTP is sent with [WithHTTPSOnly].
+var ErrInsecureURL = errors.New("github.com/nussjustin/httpc: refusing to send request over plain HTTP")
+
+// WithHTTPSOnly causes requests to http:// URLs to fail with [ErrInsecureURL].
+//
+// This also applies to redirects and protects against accidentally sending credentials, like bearer tokens, over
+// unencrypted connections, for example because of a misconfigured base URL.
+//
+// When used together with [WithHSTS], requests to hosts known to support HTTPS are upgraded instead.
+func WithHTTPSOnly() FetchOption {
+	return func(ctx *fetchContext) error {
+		ctx.HTTPSOnly = true
+		return nil
+	}
+}
+
+// HSTSCache stores hosts that sent a Strict-Transport-Security header as defined by RFC 6797.
+//
+// A HSTSCache is safe for concurrent use by multiple goroutines.
+type HSTSCache struct {
+	mu    sync.Mutex
+	hosts map[string]hstsEntry
+}
+
+type hstsEntry struct {
+	expires           time.Time
+	includeSubDomains bool
+}
+
+// NewHSTSCache returns a new, empty [HSTSCache].
+func NewHSTSCache() *HSTSCache {
+	return &HSTSCache{hosts: make(map[string]hstsEntry)}
+}
+
+// Known reports whether requests to the given host must use HTTPS.
+func (c *HSTSCache) Known(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+
+	for domain, superdomain := host, false; domain != ""; superdomain = true {
+		if entry, ok := c.hosts[domain]; ok {
+			if now.After(entry.expires) {
+				delete(c.hosts, domain)
+			} else if !superdomain || entry.includeSubDomains {
+				return true
+			}
+		}
+
+		_, domain, _ = strings.Cut(domain, ".")
+	}
+
+	return false
+}
+
+// update updates the entry for the given host based on the Strict-Transport-Security header value.
+func (c *HSTSCache) update(host string, value string) {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+
+	// IP addresses are not allowed as known hosts, see RFC 6797, Section 8.1.
+	if net.ParseIP(host) != nil {
+		return
+	}
+
+	var (
+		maxAge            = -1
+		includeSubDomains bool
+	)
+
+	for directive := range strings.SplitSeq(value, ";") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(directive), "=")
+
+		switch strings.ToLower(name) {
+		case "max-age":
+			if n, err := strconv.Atoi(strings.Trim(arg, `"`)); err == nil && n >= 0 {
+				maxAge = n
+			}
+		case "includesubdomains":
+			includeSubDomains = true
+		}
+	}
+
+	if maxAge < 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if maxAge == 0 {
+		delete(c.hosts, host)
+		return
+	}
+
+	c.hosts[host] = hstsEntry{
+		expires:           time.Now().Add(time.Duration(maxAge) * time.Second),
+		includeSubDomains: includeSubDomains,
+	}
+}
+
+// WithHSTS upgrades requests to http:// URLs to https:// for hosts known to support HTTPS.
+//
+// Hosts are added to the cache when they send a Strict-Transport-Security header over HTTPS. The header is ignored
+// for plain HTTP responses.
+//
+// The cache should be shared between requests, for example by passing the option to [New].
+func WithHSTS(cache *HSTSCache) FetchOption {
+	return func(ctx *fetchContext) error {
+		ctx.HSTS = cache
+		return nil
+	}
+}
+
+// applyHTTPS replaces the client with one that upgrades or rejects plain HTTP requests, if configured.
+func (ctx *fetchContext) applyHTTPS() {
+	if ctx.HTTPSOnly {
+		ctx.wrapTransport(func(rt http.RoundTripper) http.RoundTripper {
+			return &httpsOnlyTransport{next: rt}
+		})
+	}
+
+	if ctx.HSTS != nil {
+		ctx.wrapTransport(func(rt http.RoundTripper) http.RoundTripper {
+			return &hstsTransport{next: rt, cache: ctx.HSTS}
+		})
+	}
+}
+
+type httpsOnlyTransport struct {
+	next http.RoundTripper
+}
+
+func (t *httpsOnlyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme != "https" {
+		closeRequestBody(req)
+		return nil, ErrInsecureURL
+	}
+
+	return t.next.RoundTrip(req)
+}
+
+type hstsTransport struct {
+	next  http.RoundTripper
+	cache *HSTSCache
+}
+
+func (t *hstsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme == "http" && t.cache.Known(req.URL.Hostname()) {
+		upgraded := req.Clone(req.Context())
+		upgraded.URL.Scheme = "https"
+
+		// See RFC 6797, Section 8.3
+		if upgraded.URL.Port() == "80" {
+			upgraded.URL.Host = net.JoinHostPort(upgraded.URL.Hostname(), "443")
+		}
+
+		upgraded.Host = ""
+
+		req = upgraded
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || req.URL.Scheme != "https" {
+		return resp, err
+	}
+
+	if sts := resp.Header.Get("Strict-Transport-Security"); sts != "" {
+		t.cache.update(req.URL.Hostname(), sts)
+	}
+
+	return resp, nil
+}
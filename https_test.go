@@ -0,0 +1,108 @@
+package httpc_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nussjustin/httpc"
+)
+
+func TestWithHTTPSOnly(t *testing.T) {
+	plain := httptest.NewServer(http.RedirectHandler("/target", http.StatusFound))
+	t.Cleanup(plain.Close)
+
+	secure := httptest.NewTLSServer(http.RedirectHandler(plain.URL, http.StatusFound))
+	t.Cleanup(secure.Close)
+
+	c := httpc.New(httpc.WithClient(secure.Client()), httpc.WithHTTPSOnly())
+
+	if err := c.Fetch(t.Context(), http.MethodGet, plain.URL, nil); !errors.Is(err, httpc.ErrInsecureURL) {
+		t.Errorf("got error %v, want %v", err, httpc.ErrInsecureURL)
+	}
+
+	if err := c.Fetch(t.Context(), http.MethodGet, secure.URL, nil); !errors.Is(err, httpc.ErrInsecureURL) {
+		t.Errorf("got error %v for redirect, want %v", err, httpc.ErrInsecureURL)
+	}
+}
+
+func TestWithHSTS(t *testing.T) {
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Must be ignored, since it was not sent over HTTPS.
+		w.Header().Set("Strict-Transport-Security", "max-age=60; includeSubDomains")
+		_, _ = w.Write([]byte("plain"))
+	}))
+	t.Cleanup(plain.Close)
+
+	secure := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/subdomains":
+			w.Header().Set("Strict-Transport-Security", "max-age=60; includeSubDomains")
+		case "/clear":
+			w.Header().Set("Strict-Transport-Security", "max-age=0")
+		default:
+			w.Header().Set("Strict-Transport-Security", "max-age=60")
+		}
+		_, _ = w.Write([]byte("secure"))
+	}))
+	t.Cleanup(secure.Close)
+
+	transport := secure.Client().Transport.(*http.Transport).Clone()
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		_, port, _ := net.SplitHostPort(addr)
+
+		target := plain.Listener.Addr().String()
+		if port == "443" {
+			target = secure.Listener.Addr().String()
+		}
+
+		return (&net.Dialer{}).DialContext(ctx, network, target)
+	}
+
+	cache := httpc.NewHSTSCache()
+
+	c := httpc.New(
+		httpc.WithClient(&http.Client{Transport: transport}),
+		httpc.WithHSTS(cache),
+		httpc.WithHTTPSOnly(),
+	)
+
+	fetch := func(url string, want string) {
+		t.Helper()
+
+		var got string
+
+		err := c.Fetch(t.Context(), http.MethodGet, url, &got)
+
+		switch {
+		case want == "" && !errors.Is(err, httpc.ErrInsecureURL):
+			t.Errorf("%s: got error %v, want %v", url, err, httpc.ErrInsecureURL)
+		case want != "" && err != nil:
+			t.Errorf("%s: got error %v", url, err)
+		case got != want:
+			t.Errorf("%s: got %q, want %q", url, got, want)
+		}
+	}
+
+	fetch("http://example.com/", "")
+	fetch("https://example.com/", "secure")
+	fetch("http://example.com/", "secure")
+	fetch("http://api.example.com/", "")
+
+	fetch("https://example.com/subdomains", "secure")
+	fetch("http://api.example.com/", "secure")
+
+	fetch("https://example.com/clear", "secure")
+	fetch("http://example.com/", "")
+
+	if !cache.Known("API.example.com.") {
+		t.Error("got subdomain that sent its own header as unknown")
+	}
+
+	if cache.Known("www.example.com") {
+		t.Error("got subdomain as known after clearing parent")
+	}
+}
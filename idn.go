@@ -0,0 +1,295 @@
+package httpc
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// Parameters for Punycode as defined in RFC 3492, Section 5.
+const (
+	punycodeBase        = 36
+	punycodeTMin        = 1
+	punycodeTMax        = 26
+	punycodeSkew        = 38
+	punycodeDamp        = 700
+	punycodeInitialBias = 72
+	punycodeInitialN    = 128
+)
+
+// errInvalidPunycode is returned when decoding invalid Punycode.
+var errInvalidPunycode = errors.New("invalid punycode")
+
+// applyIDN converts an internationalized host name in the request URL to its ASCII form.
+//
+// Without this, the handling of such hosts depends on the used transport and may differ between the connection, the
+// Host header, TLS and cookies.
+func (ctx *fetchContext) applyIDN() error {
+	u := ctx.Request.URL
+
+	hostname := u.Hostname()
+	if isASCII(hostname) {
+		return nil
+	}
+
+	ascii, err := idnaToASCII(hostname)
+	if err != nil {
+		return fmt.Errorf("invalid host %q: %w", hostname, err)
+	}
+
+	host := ascii
+	if port := u.Port(); port != "" {
+		host += ":" + port
+	}
+
+	if ctx.Request.Host == u.Host {
+		ctx.Request.Host = host
+	}
+
+	u.Host = host
+	return nil
+}
+
+// displayURL returns the given URL with any Punycode encoded labels in the host decoded, for use in errors.
+func displayURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || !strings.Contains(strings.ToLower(u.Host), "xn--") {
+		return rawURL
+	}
+
+	return strings.Replace(rawURL, u.Host, idnaToUnicode(u.Host), 1)
+}
+
+// idnaToASCII converts the host name to lower case and encodes all labels containing non-ASCII characters using
+// Punycode.
+//
+// Unlike a full IDNA implementation, no Unicode normalization is performed.
+func idnaToASCII(hostname string) (string, error) {
+	hostname = strings.NewReplacer("。", ".", "．", ".", "｡", ".").Replace(strings.ToLower(hostname))
+
+	labels := strings.Split(hostname, ".")
+
+	for i, label := range labels {
+		if isASCII(label) {
+			continue
+		}
+
+		encoded, err := punycodeEncode(label)
+		if err != nil {
+			return "", err
+		}
+
+		labels[i] = "xn--" + encoded
+
+		if len(labels[i]) > 63 {
+			return "", fmt.Errorf("label %q is too long", label)
+		}
+	}
+
+	return strings.Join(labels, "."), nil
+}
+
+// idnaToUnicode decodes all Punycode encoded labels of the host name. Labels that can not be decoded are kept as is.
+func idnaToUnicode(hostname string) string {
+	labels := strings.Split(hostname, ".")
+
+	for i, label := range labels {
+		if len(label) < 4 || !strings.EqualFold(label[:4], "xn--") {
+			continue
+		}
+
+		if decoded, err := punycodeDecode(label[4:]); err == nil {
+			labels[i] = decoded
+		}
+	}
+
+	return strings.Join(labels, ".")
+}
+
+// punycodeEncode encodes s using Punycode as defined in RFC 3492, Section 6.3.
+func punycodeEncode(s string) (string, error) {
+	runes := []rune(s)
+
+	// Limit the input length, so that delta can not overflow.
+	if len(runes) > 1024 {
+		return "", errInvalidPunycode
+	}
+
+	var out []byte
+
+	for _, r := range runes {
+		if r < utf8.RuneSelf {
+			out = append(out, byte(r))
+		}
+	}
+
+	basic := len(out)
+	handled := basic
+
+	if basic > 0 {
+		out = append(out, '-')
+	}
+
+	n, delta, bias := punycodeInitialN, 0, punycodeInitialBias
+
+	for handled < len(runes) {
+		m := int(utf8.MaxRune) + 1
+		for _, r := range runes {
+			if int(r) >= n && int(r) < m {
+				m = int(r)
+			}
+		}
+
+		delta += (m - n) * (handled + 1)
+		n = m
+
+		for _, r := range runes {
+			if int(r) < n {
+				delta++
+			}
+
+			if int(r) != n {
+				continue
+			}
+
+			q := delta
+
+			for k := punycodeBase; ; k += punycodeBase {
+				t := punycodeThreshold(k, bias)
+				if q < t {
+					break
+				}
+
+				out = append(out, punycodeDigit(t+(q-t)%(punycodeBase-t)))
+				q = (q - t) / (punycodeBase - t)
+			}
+
+			out = append(out, punycodeDigit(q))
+
+			bias = punycodeAdapt(delta, handled+1, handled == basic)
+			delta = 0
+			handled++
+		}
+
+		delta++
+		n++
+	}
+
+	return string(out), nil
+}
+
+// punycodeDecode decodes s using Punycode as defined in RFC 3492, Section 6.2.
+func punycodeDecode(s string) (string, error) {
+	var out []rune
+
+	if i := strings.LastIndexByte(s, '-'); i != -1 {
+		for _, c := range []byte(s[:i]) {
+			if c >= utf8.RuneSelf {
+				return "", errInvalidPunycode
+			}
+
+			out = append(out, rune(c))
+		}
+
+		s = s[i+1:]
+	}
+
+	n, i, bias := punycodeInitialN, 0, punycodeInitialBias
+
+	for pos := 0; pos < len(s); {
+		oldi, w := i, 1
+
+		for k := punycodeBase; ; k += punycodeBase {
+			if pos >= len(s) {
+				return "", errInvalidPunycode
+			}
+
+			digit, ok := punycodeDigitValue(s[pos])
+			if !ok {
+				return "", errInvalidPunycode
+			}
+			pos++
+
+			i += digit * w
+			if i > utf8.MaxRune*(len(out)+1) {
+				return "", errInvalidPunycode
+			}
+
+			t := punycodeThreshold(k, bias)
+			if digit < t {
+				break
+			}
+
+			w *= punycodeBase - t
+		}
+
+		bias = punycodeAdapt(i-oldi, len(out)+1, oldi == 0)
+
+		n += i / (len(out) + 1)
+		i %= len(out) + 1
+
+		if n > utf8.MaxRune {
+			return "", errInvalidPunycode
+		}
+
+		out = append(out[:i], append([]rune{rune(n)}, out[i:]...)...)
+		i++
+	}
+
+	return string(out), nil
+}
+
+func punycodeAdapt(delta, numPoints int, first bool) int {
+	if first {
+		delta /= punycodeDamp
+	} else {
+		delta /= 2
+	}
+
+	delta += delta / numPoints
+
+	k := 0
+	for delta > ((punycodeBase-punycodeTMin)*punycodeTMax)/2 {
+		delta /= punycodeBase - punycodeTMin
+		k += punycodeBase
+	}
+
+	return k + (punycodeBase-punycodeTMin+1)*delta/(delta+punycodeSkew)
+}
+
+func punycodeThreshold(k, bias int) int {
+	return min(max(k-bias, punycodeTMin), punycodeTMax)
+}
+
+func punycodeDigit(d int) byte {
+	if d < 26 {
+		return byte('a' + d)
+	}
+
+	return byte('0' + d - 26)
+}
+
+func punycodeDigitValue(c byte) (int, bool) {
+	switch {
+	case 'a' <= c && c <= 'z':
+		return int(c - 'a'), true
+	case 'A' <= c && c <= 'Z':
+		return int(c - 'A'), true
+	case '0' <= c && c <= '9':
+		return int(c-'0') + 26, true
+	default:
+		return 0, false
+	}
+}
+
+func isASCII(s string) bool {
+	for i := range len(s) {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,59 @@
+package httpc_test
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/nussjustin/httpc"
+)
+
+func TestFetch_IDN(t *testing.T) {
+	testCases := []struct {
+		URL  string
+		Want string
+	}{
+		{URL: "http://example.com/", Want: "example.com"},
+		{URL: "http://münchen.de/", Want: "xn--mnchen-3ya.de"},
+		{URL: "http://Bücher.example:8080/", Want: "xn--bcher-kva.example:8080"},
+		{URL: "http://例え.テスト/", Want: "xn--r8jz45g.xn--zckzah"},
+		{URL: "http://ドメイン名例。jp/", Want: "xn--eckwd4c7cu47r2wf.jp"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.URL, func(t *testing.T) {
+			var gotURL, gotHost string
+
+			client := &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				gotURL, gotHost = req.URL.Host, req.Host
+				return &http.Response{StatusCode: http.StatusNoContent, Body: http.NoBody, Request: req}, nil
+			})}
+
+			_, err := httpc.Fetch[struct{}](t.Context(), http.MethodGet, testCase.URL, httpc.WithClient(client))
+			if err != nil {
+				t.Fatalf("got error %v", err)
+			}
+
+			if gotURL != testCase.Want || gotHost != testCase.Want {
+				t.Errorf("got URL host %q and Host %q, want %q", gotURL, gotHost, testCase.Want)
+			}
+		})
+	}
+}
+
+func TestFetch_IDNError(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	_, err := httpc.Fetch[struct{}](t.Context(), http.MethodGet, "http://例え.テスト/",
+		httpc.WithClient(&http.Client{Transport: roundTripperFunc(func(*http.Request) (*http.Response, error) {
+			return nil, errFailed
+		})}))
+	if !errors.Is(err, errFailed) {
+		t.Fatalf("got error %v, want %v", err, errFailed)
+	}
+
+	if !strings.Contains(err.Error(), "http://例え.テスト/") {
+		t.Errorf("got error %q, want decoded host", err)
+	}
+}
@@ -0,0 +1,173 @@
+// Package jsonshape compares the shape of decoded JSON values with Go types.
+package jsonshape
+
+import (
+	"encoding"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/go-json-experiment/json"
+)
+
+// Kind specifies the kind of difference reported by [Walk].
+type Kind int
+
+const (
+	// Unknown is used for members of a JSON object that have no matching field in the Go type.
+	Unknown Kind = iota + 1
+
+	// Missing is used for fields of the Go type that are not present in the JSON object.
+	//
+	// Fields using the omitempty or omitzero options are optional and never reported as missing.
+	Missing
+)
+
+var (
+	jsonUnmarshalerType     = reflect.TypeFor[json.Unmarshaler]()
+	jsonUnmarshalerFromType = reflect.TypeFor[json.UnmarshalerFrom]()
+	textUnmarshalerType     = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// Walk compares value, which must be the result of decoding JSON into an any, with the Go type t and calls fn for
+// each difference.
+//
+// Paths are joined using dots, for example "items[].name", with elements of arrays denoted by "[]" and values of maps
+// by "{}". Differences in nested values are reported once per occurrence, for example once per element of an array.
+//
+// Only struct types are checked. Types with custom decoding logic, like types implementing [json.Unmarshaler], are
+// skipped. Members of structs with a field for unknown members are never reported as unknown.
+func Walk(value any, t reflect.Type, fn func(kind Kind, path string)) {
+	walk(fn, "", value, t)
+}
+
+func walk(fn func(Kind, string), path string, value any, t reflect.Type) {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() == reflect.Interface {
+		return
+	}
+
+	// Types with custom decoding can not be inspected.
+	if pt := reflect.PointerTo(t); pt.Implements(jsonUnmarshalerType) ||
+		pt.Implements(jsonUnmarshalerFromType) ||
+		pt.Implements(textUnmarshalerType) {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return
+		}
+
+		fields, unknown := structFields(t)
+
+		for name, v := range obj {
+			f, ok := fields[name]
+			if !ok {
+				if !unknown {
+					fn(Unknown, joinPath(path, name))
+				}
+				continue
+			}
+
+			walk(fn, joinPath(path, name), v, f.typ)
+		}
+
+		for name, f := range fields {
+			if _, ok := obj[name]; !ok && !f.optional {
+				fn(Missing, joinPath(path, name))
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		arr, ok := value.([]any)
+		if !ok {
+			return
+		}
+
+		for _, v := range arr {
+			walk(fn, path+"[]", v, t.Elem())
+		}
+	case reflect.Map:
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return
+		}
+
+		for _, v := range obj {
+			walk(fn, path+"{}", v, t.Elem())
+		}
+	default:
+	}
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+type structField struct {
+	typ      reflect.Type
+	optional bool
+}
+
+// structFields returns the JSON fields of the given struct type by name and whether the type accepts unknown members.
+func structFields(t reflect.Type) (fields map[string]structField, unknown bool) {
+	fields = make(map[string]structField)
+
+	for i := range t.NumField() {
+		f := t.Field(i)
+
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		options := strings.Split(opts, ",")
+
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+
+		inline := slices.Contains(options, "inline") || (f.Anonymous && name == "" && ft.Kind() == reflect.Struct)
+
+		switch {
+		case slices.Contains(options, "unknown"):
+			unknown = true
+			continue
+		case inline && ft.Kind() == reflect.Struct:
+			embedded, embeddedUnknown := structFields(ft)
+			for n, ef := range embedded {
+				if _, ok := fields[n]; !ok {
+					fields[n] = ef
+				}
+			}
+			unknown = unknown || embeddedUnknown
+			continue
+		case inline:
+			unknown = true
+			continue
+		case !f.IsExported():
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		fields[name] = structField{
+			typ:      f.Type,
+			optional: slices.Contains(options, "omitempty") || slices.Contains(options, "omitzero"),
+		}
+	}
+
+	return fields, unknown
+}
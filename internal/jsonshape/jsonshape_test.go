@@ -0,0 +1,75 @@
+package jsonshape_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-json-experiment/json"
+	"github.com/go-json-experiment/json/jsontext"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nussjustin/httpc/internal/jsonshape"
+)
+
+func TestWalk(t *testing.T) {
+	type item struct {
+		Name     string `json:"name"`
+		Optional string `json:"optional,omitzero"`
+	}
+
+	type unknownFields struct {
+		Known   string         `json:"known"`
+		Unknown jsontext.Value `json:",unknown"`
+	}
+
+	type value struct {
+		Items  []item            `json:"items"`
+		ByName map[string]item   `json:"byName"`
+		Any    any               `json:"any"`
+		Extra  unknownFields     `json:"extra"`
+		Raw    jsontext.Value    `json:"raw"`
+		Nested *map[string][]int `json:"nested"`
+	}
+
+	data := `{
+		"items": [{"name": "a", "new": 1}, {"new": 2}],
+		"byName": {"a": {"name": "a", "other": true}},
+		"any": {"anything": 1},
+		"extra": {"known": "x", "other": 1},
+		"raw": {"anything": 1},
+		"top": 1
+	}`
+
+	var decoded any
+	if err := json.Unmarshal([]byte(data), &decoded); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+
+	type difference struct {
+		Kind jsonshape.Kind
+		Path string
+	}
+
+	var got []difference
+
+	jsonshape.Walk(decoded, reflect.TypeFor[*value](), func(kind jsonshape.Kind, path string) {
+		got = append(got, difference{kind, path})
+	})
+
+	want := map[difference]int{
+		{jsonshape.Unknown, "items[].new"}:    2,
+		{jsonshape.Missing, "items[].name"}:   1,
+		{jsonshape.Unknown, "byName{}.other"}: 1,
+		{jsonshape.Missing, "nested"}:         1,
+		{jsonshape.Unknown, "top"}:            1,
+	}
+
+	counts := make(map[difference]int)
+	for _, d := range got {
+		counts[d]++
+	}
+
+	if diff := cmp.Diff(want, counts); diff != "" {
+		t.Errorf("differences mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,95 @@
+package httpc
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/go-json-experiment/json"
+
+	"github.com/nussjustin/httpc/internal/jsonshape"
+)
+
+// UnknownJSONField describes a member of a JSON object that has no matching field in the Go type it was decoded into.
+type UnknownJSONField struct {
+	// Path is the path to the member, for example "items[].name".
+	//
+	// Elements of arrays are denoted by "[]" and values of maps by "{}".
+	Path string
+
+	// Count is the number of times the member was found in the response, for example once per element of an array.
+	Count int
+}
+
+// WithUnknownJSONFields causes [UnmarshalJSONHandler] to report members of JSON objects that have no matching field in
+// the destination type to the given function.
+//
+// Unlike [WithStrictJSON], decoding does not fail because of unknown members. Instead fn is called after the response
+// was decoded successfully, with the unknown members sorted by path. fn is not called if there are no unknown members.
+//
+// This can be used to notice new fields added to an API, for example by logging them or recording them as metrics,
+// without breaking existing clients.
+//
+// Members are only checked for struct types. Types with custom decoding logic, like types implementing
+// [json.Unmarshaler], are skipped. Members of structs with a field for unknown members are never reported.
+//
+// To be able to check the response, the whole body is buffered in memory before it is decoded.
+func WithUnknownJSONFields(fn func(resp *http.Response, fields []UnknownJSONField)) FetchOption {
+	return func(ctx *fetchContext) error {
+		ctx.UnknownJSONFields = fn
+		return nil
+	}
+}
+
+// unknownJSONFieldsKey is the context key used to pass the function set using [WithUnknownJSONFields] to
+// [UnmarshalJSONHandler].
+type unknownJSONFieldsKey struct{}
+
+// withUnknownJSONFields returns a copy of req that passes fn to [UnmarshalJSONHandler].
+func withUnknownJSONFields(req *http.Request, fn func(*http.Response, []UnknownJSONField)) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), unknownJSONFieldsKey{}, fn))
+}
+
+// requestUnknownJSONFields returns the function set using [WithUnknownJSONFields] for the request of resp, if any.
+func requestUnknownJSONFields(resp *http.Response) func(*http.Response, []UnknownJSONField) {
+	if resp.Request == nil {
+		return nil
+	}
+
+	fn, _ := resp.Request.Context().Value(unknownJSONFieldsKey{}).(func(*http.Response, []UnknownJSONField))
+	return fn
+}
+
+// reportUnknownJSONFields checks data for members that have no matching field in the type of dst and passes them
+// to fn.
+func reportUnknownJSONFields(resp *http.Response, data []byte, dst any, fn func(*http.Response, []UnknownJSONField)) {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return
+	}
+
+	counts := make(map[string]int)
+
+	jsonshape.Walk(value, reflect.TypeOf(dst), func(kind jsonshape.Kind, path string) {
+		if kind == jsonshape.Unknown {
+			counts[path]++
+		}
+	})
+
+	if len(counts) == 0 {
+		return
+	}
+
+	fields := make([]UnknownJSONField, 0, len(counts))
+	for path, count := range counts {
+		fields = append(fields, UnknownJSONField{Path: path, Count: count})
+	}
+
+	slices.SortFunc(fields, func(a, b UnknownJSONField) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	fn(resp, fields)
+}
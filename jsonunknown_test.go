@@ -0,0 +1,85 @@
+package httpc_test
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-json-experiment/json/jsontext"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nussjustin/httpc"
+)
+
+func TestWithUnknownJSONFields(t *testing.T) {
+	type item struct {
+		Name string `json:"name"`
+	}
+
+	type response struct {
+		Items []item            `json:"items"`
+		Meta  map[string]item   `json:"meta"`
+		Raw   jsontext.Value    `json:"raw"`
+		Extra map[string]any    `json:",unknown"`
+		Other *struct{ ID int } `json:"other"`
+		Any   any               `json:"any"`
+		Named map[string]*item  `json:"named"`
+	}
+
+	testCases := []struct {
+		Name     string
+		Body     string
+		Expected []httpc.UnknownJSONField
+	}{
+		{
+			Name: "Known fields",
+			Body: `{"items":[{"name":"a"}],"raw":{"x":1},"any":{"y":2},"other":{"ID":1}}`,
+		},
+		{
+			Name: "Unknown fields",
+			Body: `{"items":[{"name":"a","price":1},{"name":"b","price":2}],"meta":{"x":{"size":1}},"top":1,` +
+				`"other":{"ID":1,"Kind":"x"},"named":{"y":{"name":"y","id":1}}}`,
+			Expected: []httpc.UnknownJSONField{
+				{Path: "items[].price", Count: 2},
+				{Path: "meta{}.size", Count: 1},
+				{Path: "named{}.id", Count: 1},
+				{Path: "other.Kind", Count: 1},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			client := &http.Client{
+				Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Header:     http.Header{"Content-Type": {"application/json"}},
+						Body:       io.NopCloser(strings.NewReader(testCase.Body)),
+						Request:    r,
+					}, nil
+				}),
+			}
+
+			var got []httpc.UnknownJSONField
+
+			_, err := httpc.Fetch[response](t.Context(), http.MethodGet, "http://example.com/",
+				httpc.WithClient(client),
+				httpc.WithUnknownJSONFields(func(_ *http.Response, fields []httpc.UnknownJSONField) {
+					if fields == nil {
+						t.Error("called without fields")
+					}
+
+					got = fields
+				}))
+			if err != nil {
+				t.Fatalf("got error %v", err)
+			}
+
+			if diff := cmp.Diff(testCase.Expected, got); diff != "" {
+				t.Errorf("fields mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
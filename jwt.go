@@ -0,0 +1,180 @@
+package httpc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-json-experiment/json"
+)
+
+// clientAssertionType is the client assertion type for JWT bearer tokens as defined by RFC 7523.
+const clientAssertionType = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+// ClientAssertion builds signed JWT client assertions as defined by RFC 7523, as used by OAuth2 token endpoints for
+// the private_key_jwt client authentication method.
+type ClientAssertion struct {
+	// ClientID is the OAuth2 client ID, used as issuer and subject of the assertion.
+	ClientID string
+
+	// Audience is the intended audience of the assertion, usually the URL of the token endpoint.
+	Audience string
+
+	// Key is the private key used to sign the assertion.
+	//
+	// RSA keys are used with RS256, ECDSA keys using the P-256 curve are used with ES256. Other keys are not supported.
+	Key crypto.Signer
+
+	// KeyID is included as "kid" header in the assertion, if not empty.
+	KeyID string
+
+	// Lifetime specifies how long the assertion is valid. Defaults to 5 minutes.
+	Lifetime time.Duration
+}
+
+// Sign returns a new signed assertion.
+//
+// Each assertion uses a random "jti" claim, so that it can only be used once.
+func (a ClientAssertion) Sign() (string, error) {
+	var alg string
+
+	switch key := a.Key.(type) {
+	case *rsa.PrivateKey:
+		alg = "RS256"
+	case *ecdsa.PrivateKey:
+		if key.Curve != elliptic.P256() {
+			return "", errors.New("github.com/nussjustin/httpc: unsupported ECDSA curve for client assertion")
+		}
+		alg = "ES256"
+	default:
+		return "", fmt.Errorf("github.com/nussjustin/httpc: unsupported key type %T for client assertion", a.Key)
+	}
+
+	lifetime := a.Lifetime
+	if lifetime <= 0 {
+		lifetime = 5 * time.Minute
+	}
+
+	now := time.Now()
+
+	header, err := json.Marshal(struct {
+		Alg string `json:"alg"`
+		Typ string `json:"typ"`
+		Kid string `json:"kid,omitempty"`
+	}{alg, "JWT", a.KeyID})
+	if err != nil {
+		return "", err
+	}
+
+	claims, err := json.Marshal(struct {
+		Iss string `json:"iss"`
+		Sub string `json:"sub"`
+		Aud string `json:"aud"`
+		Jti string `json:"jti"`
+		Iat int64  `json:"iat"`
+		Exp int64  `json:"exp"`
+	}{a.ClientID, a.ClientID, a.Audience, rand.Text(), now.Unix(), now.Add(lifetime).Unix()})
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	digest := sha256.Sum256([]byte(signingInput))
+
+	var sig []byte
+
+	switch key := a.Key.(type) {
+	case *rsa.PrivateKey:
+		sig, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	case *ecdsa.PrivateKey:
+		var r, s *big.Int
+		if r, s, err = ecdsa.Sign(rand.Reader, key, digest[:]); err == nil {
+			// JWS uses the fixed size concatenation of r and s instead of ASN.1.
+			sig = make([]byte, 64)
+			r.FillBytes(sig[:32])
+			s.FillBytes(sig[32:])
+		}
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// ClientAssertionTokenSource returns a [TokenSource] that requests access tokens from the given token endpoint using
+// the OAuth2 client credentials grant, authenticating using a signed JWT client assertion.
+//
+// If the audience of the assertion is empty, tokenURL is used. The given options are used for requests to the token
+// endpoint.
+//
+// The returned [TokenSource] can be used with [WithTokenSource].
+func ClientAssertionTokenSource(
+	tokenURL string,
+	assertion ClientAssertion,
+	scopes []string,
+	opts ...FetchOption,
+) TokenSource {
+	if assertion.Audience == "" {
+		assertion.Audience = tokenURL
+	}
+
+	return TokenSourceFunc(func(ctx context.Context) (string, error) {
+		jwt, err := assertion.Sign()
+		if err != nil {
+			return "", err
+		}
+
+		form := url.Values{
+			"grant_type":            {"client_credentials"},
+			"client_assertion_type": {clientAssertionType},
+			"client_assertion":      {jwt},
+		}
+
+		if len(scopes) > 0 {
+			form.Set("scope", strings.Join(scopes, " "))
+		}
+
+		resp, err := Fetch[tokenResponse](ctx, http.MethodPost, tokenURL, slices.Concat(opts, []FetchOption{
+			WithHeader("Content-Type", "application/x-www-form-urlencoded"),
+			WithBody(strings.NewReader(form.Encode())),
+			// Error responses are JSON encoded as well, see RFC 6749, section 5.2.
+			WithHandler(HandlerChain{ContentTypeHandler("application/json", UnmarshalJSONHandler()), DefaultHandlers}),
+		})...)
+		if err != nil {
+			return "", err
+		}
+
+		switch {
+		case resp.Error != "":
+			return "", fmt.Errorf("github.com/nussjustin/httpc: token request failed: %s: %s",
+				resp.Error, resp.ErrorDescription)
+		case resp.AccessToken == "":
+			return "", errors.New("github.com/nussjustin/httpc: token response contains no access token")
+		}
+
+		return resp.AccessToken, nil
+	})
+}
+
+// tokenResponse contains the fields of a successful or failed OAuth2 token response used by this package.
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
@@ -0,0 +1,234 @@
+package httpc_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-json-experiment/json"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nussjustin/httpc"
+)
+
+type jwtClaims struct {
+	Iss string `json:"iss"`
+	Sub string `json:"sub"`
+	Aud string `json:"aud"`
+	Jti string `json:"jti"`
+	Iat int64  `json:"iat"`
+	Exp int64  `json:"exp"`
+}
+
+// verifyJWT verifies the signature of the given token and returns the header and claims.
+func verifyJWT(t *testing.T, token string, pub crypto.PublicKey) (header map[string]string, claims jwtClaims) {
+	t.Helper()
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("got %d parts, want 3", len(parts))
+	}
+
+	decode := func(s string) []byte {
+		b, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil {
+			t.Fatalf("failed to decode %q: %v", s, err)
+		}
+		return b
+	}
+
+	if err := json.Unmarshal(decode(parts[0]), &header); err != nil {
+		t.Fatalf("failed to decode header: %v", err)
+	}
+
+	if err := json.Unmarshal(decode(parts[1]), &claims); err != nil {
+		t.Fatalf("failed to decode claims: %v", err)
+	}
+
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	sig := decode(parts[2])
+
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig); err != nil {
+			t.Errorf("invalid signature: %v", err)
+		}
+	case *ecdsa.PublicKey:
+		if len(sig) != 64 {
+			t.Fatalf("got signature of length %d, want 64", len(sig))
+		}
+
+		r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+
+		if !ecdsa.Verify(pub, digest[:], r, s) {
+			t.Error("invalid signature")
+		}
+	}
+
+	return header, claims
+}
+
+func TestClientAssertion_Sign(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate ECDSA key: %v", err)
+	}
+
+	testCases := []struct {
+		Name string
+		Key  crypto.Signer
+		Alg  string
+	}{
+		{Name: "RS256", Key: rsaKey, Alg: "RS256"},
+		{Name: "ES256", Key: ecKey, Alg: "ES256"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			a := httpc.ClientAssertion{
+				ClientID: "client",
+				Audience: "https://example.com/token",
+				Key:      testCase.Key,
+				KeyID:    "key-1",
+				Lifetime: time.Minute,
+			}
+
+			token, err := a.Sign()
+			if err != nil {
+				t.Fatalf("got error %v", err)
+			}
+
+			header, claims := verifyJWT(t, token, testCase.Key.Public())
+
+			wantHeader := map[string]string{"alg": testCase.Alg, "typ": "JWT", "kid": "key-1"}
+
+			if diff := cmp.Diff(wantHeader, header); diff != "" {
+				t.Errorf("header mismatch (-want +got):\n%s", diff)
+			}
+
+			if claims.Iss != "client" || claims.Sub != "client" {
+				t.Errorf("got iss %q and sub %q, want %q", claims.Iss, claims.Sub, "client")
+			}
+
+			if claims.Aud != "https://example.com/token" {
+				t.Errorf("got aud %q, want %q", claims.Aud, "https://example.com/token")
+			}
+
+			if claims.Jti == "" {
+				t.Error("got empty jti")
+			}
+
+			if got, want := claims.Exp-claims.Iat, int64(60); got != want {
+				t.Errorf("got lifetime %ds, want %ds", got, want)
+			}
+
+			other, err := a.Sign()
+			if err != nil {
+				t.Fatalf("got error %v", err)
+			}
+
+			if _, otherClaims := verifyJWT(t, other, testCase.Key.Public()); otherClaims.Jti == claims.Jti {
+				t.Error("got same jti for multiple assertions")
+			}
+		})
+	}
+
+	t.Run("Unsupported curve", func(t *testing.T) {
+		key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+		if err != nil {
+			t.Fatalf("failed to generate ECDSA key: %v", err)
+		}
+
+		if _, err := (httpc.ClientAssertion{ClientID: "client", Key: key}).Sign(); err == nil {
+			t.Error("got no error")
+		}
+	})
+}
+
+func TestClientAssertionTokenSource(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate ECDSA key: %v", err)
+	}
+
+	var tokenURL string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.PostForm.Get("grant_type") != "client_credentials" ||
+			r.PostForm.Get("client_assertion_type") != "urn:ietf:params:oauth:client-assertion-type:jwt-bearer" ||
+			r.PostForm.Get("scope") != "read write" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_request","error_description":"bad form"}`))
+			return
+		}
+
+		if _, claims := verifyJWT(t, r.PostForm.Get("client_assertion"), key.Public()); claims.Aud != tokenURL {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"invalid_client"}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"access_token":"secret","token_type":"Bearer","expires_in":3600}`))
+	})
+	mux.HandleFunc("GET /api", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	tokenURL = srv.URL + "/token"
+
+	a := httpc.ClientAssertion{ClientID: "client", Key: key}
+
+	t.Run("Success", func(t *testing.T) {
+		ts := httpc.ClientAssertionTokenSource(tokenURL, a, []string{"read", "write"})
+
+		c := httpc.New(httpc.WithTokenSource(ts))
+
+		if err := c.Fetch(t.Context(), http.MethodGet, srv.URL+"/api", nil); err != nil {
+			t.Errorf("got error %v", err)
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		ts := httpc.ClientAssertionTokenSource(tokenURL, a, nil)
+
+		_, err := ts.Token(t.Context())
+		if err == nil {
+			t.Fatal("got no error")
+		}
+
+		if !strings.Contains(err.Error(), "invalid_request: bad form") {
+			t.Errorf("got error %q, want error containing %q", err, "invalid_request: bad form")
+		}
+	})
+}
@@ -0,0 +1,20 @@
+package httpc
+
+// WithLabel adds a label with the given key and value to the request, replacing any existing label with the same key.
+//
+// Labels tag requests with additional context, like the name of the feature making the request, and are passed to
+// all observability integrations, including [Tracer] implementations via [SpanInfo.Labels], [Hooks] via
+// [HookEvent.Labels] and [Client.InFlight] via [RequestInfo.Labels].
+//
+// Labels are commonly used as metric dimensions, so values should come from a small set. Sensitive values, like
+// customer IDs, should be hashed.
+func WithLabel(key, value string) FetchOption {
+	return func(ctx *fetchContext) error {
+		if ctx.Labels == nil {
+			ctx.Labels = make(map[string]string)
+		}
+
+		ctx.Labels[key] = value
+		return nil
+	}
+}
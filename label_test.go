@@ -0,0 +1,36 @@
+package httpc_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nussjustin/httpc"
+)
+
+func TestWithLabel(t *testing.T) {
+	tracer := &testTracer{}
+
+	var hookLabels map[string]string
+
+	captureRequest(t, "/",
+		httpc.WithLabel("feature", "checkout"),
+		httpc.WithLabel("team", "payments"),
+		httpc.WithLabel("feature", "refund"),
+		httpc.WithTracer(tracer),
+		httpc.WithHooks(httpc.Hooks{
+			OnRequest: func(e httpc.HookEvent) {
+				hookLabels = e.Labels
+			},
+		}))
+
+	want := map[string]string{"feature": "refund", "team": "payments"}
+
+	if diff := cmp.Diff(want, tracer.info.Labels); diff != "" {
+		t.Errorf("span labels mismatch (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff(want, hookLabels); diff != "" {
+		t.Errorf("hook labels mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,72 @@
+package httpc
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// WithAcceptLanguage sets the Accept-Language header to the given language tags, in order of preference.
+//
+// Quality values are added automatically, starting with 1 for the first tag and decreasing with each tag. For example
+// calling WithAcceptLanguage("de-DE", "de", "en") results in the header "de-DE, de;q=0.9, en;q=0.8".
+//
+// If no tags are given, any existing Accept-Language header is removed.
+func WithAcceptLanguage(tags ...string) FetchOption {
+	value := acceptLanguage(tags)
+
+	return func(ctx *fetchContext) error {
+		if value == "" {
+			ctx.Request.Header.Del("Accept-Language")
+		} else {
+			ctx.Request.Header.Set("Accept-Language", value)
+		}
+
+		return nil
+	}
+}
+
+// acceptLanguage returns the value of the Accept-Language header for the given tags.
+func acceptLanguage(tags []string) string {
+	step := 0.1
+	if len(tags) > 10 {
+		step = 0.9 / float64(len(tags)-1)
+	}
+
+	var b strings.Builder
+
+	for i, tag := range tags {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+
+		b.WriteString(tag)
+
+		if i > 0 {
+			q := math.Round((1-float64(i)*step)*1000) / 1000
+
+			b.WriteString(";q=")
+			b.WriteString(strconv.FormatFloat(max(q, 0.001), 'f', -1, 64))
+		}
+	}
+
+	return b.String()
+}
+
+// ContentLanguage returns the language tags from the Content-Language header of the response.
+//
+// If the header is not set, nil is returned.
+func ContentLanguage(resp *http.Response) []string {
+	var tags []string
+
+	for _, value := range resp.Header.Values("Content-Language") {
+		for tag := range strings.SplitSeq(value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+
+	return tags
+}
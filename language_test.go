@@ -0,0 +1,69 @@
+package httpc_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nussjustin/httpc"
+)
+
+func TestWithAcceptLanguage(t *testing.T) {
+	many := make([]string, 19)
+	for i := range many {
+		many[i] = "l" + string(rune('a'+i))
+	}
+
+	testCases := []struct {
+		Name string
+		Tags []string
+		Want string
+	}{
+		{Name: "None", Want: ""},
+		{Name: "Single", Tags: []string{"de"}, Want: "de"},
+		{Name: "Multiple", Tags: []string{"de-DE", "de", "en"}, Want: "de-DE, de;q=0.9, en;q=0.8"},
+		{
+			Name: "Many",
+			Tags: many,
+			Want: "la, lb;q=0.95, lc;q=0.9, ld;q=0.85, le;q=0.8, lf;q=0.75, lg;q=0.7, " +
+				"lh;q=0.65, li;q=0.6, lj;q=0.55, lk;q=0.5, ll;q=0.45, lm;q=0.4, ln;q=0.35, " +
+				"lo;q=0.3, lp;q=0.25, lq;q=0.2, lr;q=0.15, ls;q=0.1",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			var got string
+
+			client := &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				got = req.Header.Get("Accept-Language")
+				return &http.Response{StatusCode: http.StatusNoContent, Body: http.NoBody, Request: req}, nil
+			})}
+
+			_, err := httpc.Fetch[struct{}](t.Context(), http.MethodGet, "http://example.com/",
+				httpc.WithHeader("Accept-Language", "fr"),
+				httpc.WithAcceptLanguage(testCase.Tags...),
+				httpc.WithClient(client))
+			if err != nil {
+				t.Fatalf("got error %v", err)
+			}
+
+			if got != testCase.Want {
+				t.Errorf("got %q, want %q", got, testCase.Want)
+			}
+		})
+	}
+}
+
+func TestContentLanguage(t *testing.T) {
+	resp := &http.Response{Header: http.Header{"Content-Language": {"de-DE, en-CA", " fr ,"}}}
+
+	if diff := cmp.Diff([]string{"de-DE", "en-CA", "fr"}, httpc.ContentLanguage(resp)); diff != "" {
+		t.Errorf("tags mismatch (-want +got):\n%s", diff)
+	}
+
+	if got := httpc.ContentLanguage(&http.Response{Header: http.Header{}}); got != nil {
+		t.Errorf("got %q, want nil", got)
+	}
+}
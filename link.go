@@ -0,0 +1,228 @@
+package httpc
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Link is a link from a Link header as defined by RFC 8288.
+type Link struct {
+	// URL is the target of the link.
+	//
+	// Links returned by [ResponseLinks] are resolved against the URL of the request.
+	URL string
+
+	// Params contains the parameters of the link by their lowercase name, for example "rel" or "title".
+	Params map[string]string
+}
+
+// HasRel returns true if the rel parameter of the link contains the given relation type.
+//
+// Relation types are compared case-insensitively.
+func (l Link) HasRel(rel string) bool {
+	return slices.ContainsFunc(strings.Fields(l.Params["rel"]), func(s string) bool {
+		return strings.EqualFold(s, rel)
+	})
+}
+
+// ParseLinks parses the given values of Link headers.
+//
+// Malformed links are skipped.
+func ParseLinks(values []string) []Link {
+	var links []Link
+
+	for _, value := range values {
+		for {
+			value = strings.TrimLeft(value, " \t,")
+			if value == "" {
+				break
+			}
+
+			var link Link
+			var ok bool
+
+			link, value, ok = parseLink(value)
+			if ok {
+				links = append(links, link)
+			}
+		}
+	}
+
+	return links
+}
+
+// parseLink parses a single link from the start of s and returns the link and the rest of s.
+//
+// If the link is malformed, ok is false and rest starts after the next comma outside of a quoted string.
+func parseLink(s string) (link Link, rest string, ok bool) {
+	if !strings.HasPrefix(s, "<") {
+		return Link{}, skipLink(s), false
+	}
+
+	target, s, found := strings.Cut(s[1:], ">")
+	if !found {
+		return Link{}, "", false
+	}
+
+	link = Link{URL: strings.TrimSpace(target), Params: make(map[string]string)}
+
+	for {
+		s = strings.TrimLeft(s, " \t")
+
+		if !strings.HasPrefix(s, ";") {
+			break
+		}
+
+		s = strings.TrimLeft(s[1:], " \t")
+
+		end := strings.IndexAny(s, "=;,")
+		if end == -1 {
+			end = len(s)
+		}
+
+		name := strings.ToLower(strings.TrimSpace(s[:end]))
+		s = s[end:]
+
+		var value string
+
+		if strings.HasPrefix(s, "=") {
+			value, s = parseLinkParamValue(strings.TrimLeft(s[1:], " \t"))
+		}
+
+		// Only the first occurrence of a parameter is used.
+		if _, exists := link.Params[name]; name != "" && !exists {
+			link.Params[name] = value
+		}
+	}
+
+	if s != "" && !strings.HasPrefix(s, ",") {
+		return Link{}, skipLink(s), false
+	}
+
+	return link, s, true
+}
+
+// parseLinkParamValue parses a token or quoted string from the start of s.
+func parseLinkParamValue(s string) (value, rest string) {
+	if !strings.HasPrefix(s, `"`) {
+		end := strings.IndexAny(s, ";,")
+		if end == -1 {
+			end = len(s)
+		}
+
+		return strings.TrimSpace(s[:end]), s[end:]
+	}
+
+	var b strings.Builder
+
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\\':
+			if i+1 < len(s) {
+				i++
+				b.WriteByte(s[i])
+			}
+		case '"':
+			return b.String(), s[i+1:]
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String(), ""
+}
+
+// skipLink returns s after the next comma that is not inside a quoted string.
+func skipLink(s string) string {
+	quoted := false
+
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			quoted = !quoted
+		case ',':
+			if !quoted {
+				return s[i+1:]
+			}
+		}
+	}
+
+	return ""
+}
+
+// ResponseLinks returns the links from the Link headers of the response.
+//
+// Relative links are resolved against the URL of the request of the response. Links that can not be parsed are
+// skipped.
+func ResponseLinks(resp *http.Response) []Link {
+	links := ParseLinks(resp.Header.Values("Link"))
+
+	if resp.Request == nil || resp.Request.URL == nil {
+		return links
+	}
+
+	resolved := links[:0]
+
+	for _, link := range links {
+		u, err := resp.Request.URL.Parse(link.URL)
+		if err != nil {
+			continue
+		}
+
+		link.URL = u.String()
+		resolved = append(resolved, link)
+	}
+
+	return resolved
+}
+
+// nextLink returns the URL of the first link with the relation type "next", or an empty string if there is none.
+func nextLink(resp *http.Response) string {
+	for _, link := range ResponseLinks(resp) {
+		if link.HasRel("next") {
+			return link.URL
+		}
+	}
+
+	return ""
+}
+
+// FetchPages returns an iterator over the pages of a paginated endpoint, starting at the given URL.
+//
+// Each page is requested using GET and decoded as with [Fetch]. After a page was yielded, the next page is requested
+// from the URL of the link with the relation type "next" in the Link header of the response, as used by GitHub and
+// many other APIs. Iteration stops after a page without such a link.
+//
+// The given options are used for every page. Since the URLs of subsequent pages usually contain all query parameters
+// of the first page, options modifying the query should replace existing values, for example [WithQueryParam].
+//
+// If a request fails, the error is yielded and iteration stops. Iteration also stops if a page links to itself.
+func FetchPages[T any](ctx context.Context, url string, opts ...FetchOption) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for next := url; next != ""; {
+			t, resp, err := FetchWithResponse[T](ctx, http.MethodGet, next, opts...)
+			if resp != nil {
+				discardBody(resp, nil)
+			}
+
+			if err != nil {
+				yield(t, err)
+				return
+			}
+
+			// Stop if the server links back to the same page, to avoid requesting it forever.
+			if next = nextLink(resp); resp.Request != nil && next == resp.Request.URL.String() {
+				next = ""
+			}
+
+			if !yield(t, nil) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package httpc_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nussjustin/httpc"
+)
+
+func TestParseLinks(t *testing.T) {
+	got := httpc.ParseLinks([]string{
+		`<https://api.example.com/items?page=2>; rel="next", <https://api.example.com/items?page=5>; rel=last`,
+		`invalid; rel=next, </a,b>; rel="prev first"; title="a \"quoted\", title"; REL=ignored`,
+		`<https://example.com/unterminated`,
+	})
+
+	want := []httpc.Link{
+		{URL: "https://api.example.com/items?page=2", Params: map[string]string{"rel": "next"}},
+		{URL: "https://api.example.com/items?page=5", Params: map[string]string{"rel": "last"}},
+		{URL: "/a,b", Params: map[string]string{"rel": "prev first", "title": `a "quoted", title`}},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("links mismatch (-want +got):\n%s", diff)
+	}
+
+	if !got[2].HasRel("First") {
+		t.Error("link has no relation type first")
+	}
+
+	if got[2].HasRel("next") {
+		t.Error("link has relation type next")
+	}
+}
+
+func TestResponseLinks(t *testing.T) {
+	resp := &http.Response{
+		Header:  http.Header{"Link": {`</items?page=2>; rel="next"`}},
+		Request: &http.Request{URL: &url.URL{Scheme: "https", Host: "example.com", Path: "/api/items"}},
+	}
+
+	want := []httpc.Link{{URL: "https://example.com/items?page=2", Params: map[string]string{"rel": "next"}}}
+
+	if diff := cmp.Diff(want, httpc.ResponseLinks(resp)); diff != "" {
+		t.Errorf("links mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFetchPages(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("per_page") != "2" {
+			http.Error(w, "missing per_page", http.StatusBadRequest)
+			return
+		}
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page == 0 {
+			page = 1
+		}
+
+		if page == 4 {
+			http.Error(w, "failed", http.StatusInternalServerError)
+			return
+		}
+
+		if page < 3 || r.URL.Query().Get("fail") != "" {
+			w.Header().Set("Link", `</items?per_page=2&page=`+strconv.Itoa(page+1)+`>; rel="next"`)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[` + strconv.Itoa(page*2-1) + `,` + strconv.Itoa(page*2) + `]`))
+	}))
+	t.Cleanup(srv.Close)
+
+	t.Run("All pages", func(t *testing.T) {
+		var got []int
+
+		for page, err := range httpc.FetchPages[[]int](t.Context(), srv.URL+"/items",
+			httpc.WithQueryParam("per_page", "2")) {
+			if err != nil {
+				t.Fatalf("got error %v", err)
+			}
+
+			got = append(got, page...)
+		}
+
+		if diff := cmp.Diff([]int{1, 2, 3, 4, 5, 6}, got); diff != "" {
+			t.Errorf("items mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		var pages int
+
+		for range httpc.FetchPages[[]int](t.Context(), srv.URL+"/items", httpc.WithQueryParam("per_page", "2")) {
+			pages++
+			break
+		}
+
+		if pages != 1 {
+			t.Errorf("got %d pages, want 1", pages)
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		var errs []error
+
+		for _, err := range httpc.FetchPages[[]int](t.Context(), srv.URL+"/items?page=3&fail=1",
+			httpc.WithQueryParam("per_page", "2")) {
+			errs = append(errs, err)
+		}
+
+		if len(errs) != 2 || errs[0] != nil || !errors.Is(errs[1], httpc.ErrUnexpectedStatus) {
+			t.Errorf("got errors %v", errs)
+		}
+	})
+}
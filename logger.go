@@ -0,0 +1,143 @@
+package httpc
+
+import (
+	"log/slog"
+	"maps"
+	"slices"
+	"time"
+)
+
+// LoggerOptions configures the logging of requests using [WithLoggerOptions].
+type LoggerOptions struct {
+	// StartLevel is the level used when a request is started.
+	StartLevel slog.Level
+
+	// FinishLevel is the level used when a request finished successfully.
+	FinishLevel slog.Level
+
+	// ErrorLevel is the level used when a request failed.
+	ErrorLevel slog.Level
+
+	// Headers contains the names of the request headers that are logged when a request is started.
+	//
+	// Values of sensitive headers, like Authorization, are redacted using the [Redactor] set via [WithRedactor] or
+	// [DefaultRedactor].
+	Headers []string
+
+	// OmitQuery causes the query to be removed from logged URLs.
+	//
+	// If false, only the values of sensitive query parameters are redacted.
+	OmitQuery bool
+}
+
+// DefaultLoggerOptions returns the options used by [WithLogger].
+//
+// The returned options log the start of requests at [slog.LevelDebug], finished requests at [slog.LevelInfo] and
+// failed requests at [slog.LevelError]. No headers are logged.
+func DefaultLoggerOptions() LoggerOptions {
+	return LoggerOptions{
+		StartLevel:  slog.LevelDebug,
+		FinishLevel: slog.LevelInfo,
+		ErrorLevel:  slog.LevelError,
+	}
+}
+
+// WithLogger causes the start and end of each request to be logged using the given logger.
+//
+// This is the same as calling [WithLoggerOptions] with the result of [DefaultLoggerOptions].
+func WithLogger(logger *slog.Logger) FetchOption {
+	return WithLoggerOptions(logger, DefaultLoggerOptions())
+}
+
+// WithLoggerOptions causes the start and end of each request to be logged using the given logger and options.
+//
+// Messages include the method and URL of the request, the operation name set via [WithOperationName] and any labels
+// added via [WithLabel]. Once the request is done, the status code of the last response, the number of attempts,
+// the duration and the error, if any, are logged as well. Secrets in URLs and headers are redacted using the
+// [Redactor] set via [WithRedactor] or [DefaultRedactor].
+//
+// Like spans created using [WithTracer], log messages cover the whole request, including retries, redirects and
+// handling of the response. Requests answered using [WithMemoize] are not logged.
+func WithLoggerOptions(logger *slog.Logger, opts LoggerOptions) FetchOption {
+	opts.Headers = slices.Clone(opts.Headers)
+
+	return func(ctx *fetchContext) error {
+		ctx.Logger, ctx.LoggerOptions = logger, opts
+		return nil
+	}
+}
+
+// startLogging logs the start of the request and returns a function that logs the result.
+func (ctx *fetchContext) startLogging() func(SpanResult) {
+	req, opts, start := ctx.Request, ctx.LoggerOptions, time.Now()
+
+	u := req.URL
+	if opts.OmitQuery && u.RawQuery != "" {
+		withoutQuery := *u
+		withoutQuery.RawQuery = ""
+		u = &withoutQuery
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("url", ctx.redactor().URL(u)),
+	}
+
+	if ctx.OperationName != "" {
+		attrs = append(attrs, slog.String("operation", ctx.OperationName))
+	}
+
+	if len(ctx.Labels) > 0 {
+		labels := make([]any, 0, len(ctx.Labels))
+
+		for _, key := range slices.Sorted(maps.Keys(ctx.Labels)) {
+			labels = append(labels, slog.String(key, ctx.Labels[key]))
+		}
+
+		attrs = append(attrs, slog.Group("labels", labels...))
+	}
+
+	startAttrs := slices.Clip(attrs)
+
+	if headers := ctx.loggedHeaders(); len(headers) > 0 {
+		startAttrs = append(startAttrs, slog.Group("headers", headers...))
+	}
+
+	ctx.Logger.LogAttrs(req.Context(), opts.StartLevel, "request started", startAttrs...)
+
+	return func(result SpanResult) {
+		attrs := append(attrs,
+			slog.Int("status", result.StatusCode),
+			slog.Int("attempts", result.Attempts),
+			slog.Duration("duration", time.Since(start)))
+
+		if result.Err != nil {
+			attrs = append(attrs, slog.Any("error", result.Err))
+
+			ctx.Logger.LogAttrs(req.Context(), opts.ErrorLevel, "request failed", attrs...)
+			return
+		}
+
+		ctx.Logger.LogAttrs(req.Context(), opts.FinishLevel, "request finished", attrs...)
+	}
+}
+
+// loggedHeaders returns the request headers configured in [LoggerOptions.Headers] as attributes, with secrets
+// redacted.
+func (ctx *fetchContext) loggedHeaders() []any {
+	if len(ctx.LoggerOptions.Headers) == 0 {
+		return nil
+	}
+
+	headers := make([]any, 0, len(ctx.LoggerOptions.Headers))
+
+	redacted := ctx.redactor().Header(ctx.Request.Header)
+
+	for _, name := range ctx.LoggerOptions.Headers {
+		if values := redacted.Values(name); len(values) > 0 {
+			headers = append(headers, slog.Any(name, values))
+		}
+	}
+
+	return headers
+}
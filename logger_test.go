@@ -0,0 +1,90 @@
+package httpc_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nussjustin/httpc"
+)
+
+// newTestLogger returns a logger that writes all messages to w, omitting the time and duration.
+func newTestLogger(w io.Writer) *slog.Logger {
+	return slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey || a.Key == "duration" {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+}
+
+func TestWithLogger(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := newTestLogger(&buf)
+
+	captureRequest(t, "https://example.com/product/{id}?token=secret",
+		httpc.WithPathValue("id", "1"),
+		httpc.WithHeader("Authorization", "Bearer secret"),
+		httpc.WithHeader("X-Request-Id", "abc"),
+		httpc.WithOperationName("GetProduct"),
+		httpc.WithLabel("team", "shop"),
+		httpc.WithLoggerOptions(logger, httpc.LoggerOptions{
+			StartLevel:  slog.LevelDebug,
+			FinishLevel: slog.LevelInfo,
+			ErrorLevel:  slog.LevelError,
+			Headers:     []string{"Authorization", "X-Request-Id", "X-Missing"},
+		}))
+
+	want := []string{
+		`level=DEBUG msg="request started" method=GET url="https://example.com/product/1?token=REDACTED" ` +
+			`operation=GetProduct labels.team=shop headers.Authorization=[REDACTED] headers.X-Request-Id=[abc]`,
+		`level=INFO msg="request finished" method=GET url="https://example.com/product/1?token=REDACTED" ` +
+			`operation=GetProduct labels.team=shop status=204 attempts=1`,
+	}
+
+	if diff := cmp.Diff(want, strings.Split(strings.TrimSpace(buf.String()), "\n")); diff != "" {
+		t.Errorf("log mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWithLogger_Error(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := newTestLogger(&buf)
+
+	errTest := errors.New("test error")
+
+	_, err := httpc.Fetch[any](t.Context(), http.MethodGet, "https://example.com/?a=b",
+		httpc.WithClient(&http.Client{
+			Transport: roundTripperFunc(func(*http.Request) (*http.Response, error) {
+				return nil, errTest
+			}),
+		}),
+		httpc.WithLoggerOptions(logger, httpc.LoggerOptions{
+			StartLevel: slog.LevelDebug,
+			ErrorLevel: slog.LevelWarn,
+			OmitQuery:  true,
+		}))
+	if !errors.Is(err, errTest) {
+		t.Fatalf("got error %v, want %v", err, errTest)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	got := lines[len(lines)-1]
+
+	want := `level=WARN msg="request failed" method=GET url=https://example.com/ status=0 attempts=1 error=`
+
+	if !strings.HasPrefix(got, want) {
+		t.Errorf("got %s, want prefix %s", got, want)
+	}
+}
@@ -0,0 +1,38 @@
+package httpc
+
+import (
+	"errors"
+	"net/http"
+)
+
+// WithMaxResponseBytes limits the number of bytes that can be read from the response body to n.
+//
+// Reading more than n bytes fails with a [*ResponseTooLargeError]. If the response has a Content-Length larger than n,
+// the first read fails without reading any data. This protects against servers sending huge responses that would
+// otherwise be read into memory completely, for example by [UnmarshalJSONHandler].
+//
+// The limit also applies to bodies passed on to the caller, for example when using [ReadCloserHandler] or
+// [WithKeepBodyOpen]. See [LimitBody] for limiting the body read by a single [Handler].
+func WithMaxResponseBytes(n int64) FetchOption {
+	if n <= 0 {
+		panic(errors.New("n must be positive"))
+	}
+
+	return func(ctx *fetchContext) error {
+		ctx.MaxResponseBytes = n
+		return nil
+	}
+}
+
+// limitResponseBytes wraps the response body so that at most MaxResponseBytes bytes can be read.
+func (ctx *fetchContext) limitResponseBytes(resp *http.Response) {
+	limit := ctx.MaxResponseBytes
+
+	l := &limitedReadCloser{ReadCloser: resp.Body, n: limit, limit: limit}
+
+	if resp.ContentLength > limit {
+		l.n = -1
+	}
+
+	resp.Body = l
+}
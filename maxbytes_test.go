@@ -0,0 +1,69 @@
+package httpc_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nussjustin/httpc"
+)
+
+func TestWithMaxResponseBytes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		// Without flushing, the response is sent using a Content-Length.
+		if r.URL.Query().Has("chunked") {
+			w.(http.Flusher).Flush()
+		}
+
+		_, _ = w.Write([]byte(`"` + strings.Repeat("x", 64) + `"`))
+	}))
+	t.Cleanup(srv.Close)
+
+	testCases := []struct {
+		Name  string
+		Query string
+		Limit int64
+		Error bool
+	}{
+		{Name: "Below limit", Limit: 128},
+		{Name: "At limit", Limit: 66},
+		{Name: "Content-Length above limit", Limit: 65, Error: true},
+		{Name: "Chunked above limit", Query: "?chunked", Limit: 65, Error: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			got, err := httpc.Fetch[string](t.Context(), http.MethodGet, srv.URL+testCase.Query,
+				httpc.WithMaxResponseBytes(testCase.Limit))
+
+			if !testCase.Error {
+				if err != nil {
+					t.Fatalf("got error %v", err)
+				}
+
+				if want := strings.Repeat("x", 64); got != want {
+					t.Errorf("got %q, want %q", got, want)
+				}
+
+				return
+			}
+
+			var tooLarge *httpc.ResponseTooLargeError
+			if !errors.As(err, &tooLarge) {
+				t.Fatalf("got error %v, want %T", err, tooLarge)
+			}
+
+			if tooLarge.Limit != testCase.Limit {
+				t.Errorf("got limit %d, want %d", tooLarge.Limit, testCase.Limit)
+			}
+
+			if !errors.Is(err, httpc.ErrBodyTooLarge) {
+				t.Errorf("got error %v, want %v", err, httpc.ErrBodyTooLarge)
+			}
+		})
+	}
+}
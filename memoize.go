@@ -0,0 +1,219 @@
+package httpc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+)
+
+// WithMemoize caches decoded responses for the given duration.
+//
+// Unlike HTTP caching, the decoded value is cached, so that later requests with the same key are not sent at all and
+// do not need to decode the response again. This can be useful for example for frequently fetched configuration or
+// metadata.
+//
+// The key function is called with the final request and must return a key that identifies the request. If the key is
+// empty, the request is not memoized. If key is nil, the method, URL and credential headers of GET requests are used
+// as key and other requests are not memoized. Credential headers are the Authorization, Proxy-Authorization and Cookie
+// headers as well as all headers redacted by the [Redactor] of the request, like the header used by [WithAPIKey]. Only
+// a hash of their values is kept. Query parameters in the default key are sorted by name, so that URLs that only
+// differ in the order of their query parameters share an entry. Credentials added while sending the request, for
+// example by [WithTokenSource], are not part of the default key.
+//
+// Only successfully handled responses with a 2xx status code are cached and responses fetched as [io.ReadCloser] are
+// never cached. Values are cached separately per destination type. Since the same value is returned for all requests
+// with the same key, callers must not modify returned values.
+//
+// When a cached value is used, [FetchWithResponse] returns a copy of the original response with an empty body. If
+// statistics are recorded using [WithStats], they are reset, so that [Stats.Attempts] is 0.
+//
+// Since the cache is shared only by requests using the same option, the returned option should be created once and
+// reused, for example by passing it to [New].
+func WithMemoize(ttl time.Duration, key func(*http.Request) string) FetchOption {
+	m := &memoCache{ttl: ttl, key: key}
+
+	return func(ctx *fetchContext) error {
+		ctx.Memo = m
+		return nil
+	}
+}
+
+// memoCredentialHeaders contains the headers included in the default key in addition to the headers of the redactor.
+var memoCredentialHeaders = []string{"Authorization", "Cookie", "Proxy-Authorization"}
+
+func defaultMemoizeKey(req *http.Request, r *Redactor) string {
+	if req.Method != http.MethodGet {
+		return ""
+	}
+
+	key := cacheKey(req.Method, req.URL)
+
+	names := make([]string, 0, len(memoCredentialHeaders)+len(r.Headers))
+
+	for _, name := range slices.Concat(memoCredentialHeaders, r.Headers) {
+		names = append(names, http.CanonicalHeaderKey(name))
+	}
+
+	slices.Sort(names)
+
+	// Do not share values between requests using different credentials, without keeping the credentials in memory.
+	h := sha256.New()
+
+	var found bool
+
+	for _, name := range slices.Compact(names) {
+		values := req.Header.Values(name)
+		if len(values) == 0 {
+			continue
+		}
+
+		found = true
+
+		_, _ = fmt.Fprintf(h, "%s: %q\n", name, values)
+	}
+
+	if found {
+		key += " " + hex.EncodeToString(h.Sum(nil))
+	}
+
+	return key
+}
+
+// minMemoSweep is the minimum number of cached entries before expired entries are removed.
+const minMemoSweep = 64
+
+type memoKey struct {
+	typ reflect.Type
+	key string
+}
+
+type memoEntry struct {
+	value   reflect.Value
+	resp    *http.Response
+	expires time.Time
+}
+
+type memoCache struct {
+	ttl time.Duration
+	key func(*http.Request) string
+
+	mu        sync.Mutex
+	entries   map[memoKey]memoEntry
+	nextSweep int
+}
+
+// cacheKey returns the key for the given destination and request.
+//
+// The redactor is used to find credential headers for the default key.
+func (m *memoCache) cacheKey(dst any, req *http.Request, r *Redactor) (memoKey, bool) {
+	if v := reflect.ValueOf(dst); v.Kind() != reflect.Pointer || v.IsNil() {
+		return memoKey{}, false
+	}
+
+	// Response bodies can only be read once.
+	if _, ok := dst.(*io.ReadCloser); ok {
+		return memoKey{}, false
+	}
+
+	var key string
+
+	if m.key != nil {
+		key = m.key(req)
+	} else {
+		key = defaultMemoizeKey(req, r)
+	}
+
+	if key == "" {
+		return memoKey{}, false
+	}
+
+	return memoKey{typ: reflect.TypeOf(dst), key: key}, true
+}
+
+// load copies a cached value into dst and returns a copy of the cached response.
+func (m *memoCache) load(dst any, req *http.Request, r *Redactor) (*http.Response, bool) {
+	key, ok := m.cacheKey(dst, req, r)
+	if !ok {
+		return nil, false
+	}
+
+	m.mu.Lock()
+	entry, ok := m.entries[key]
+	m.mu.Unlock()
+
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false
+	}
+
+	reflect.ValueOf(dst).Elem().Set(entry.value)
+
+	resp := copyResponse(entry.resp)
+	resp.Request = req
+
+	return resp, true
+}
+
+// store caches the value in dst as well as a copy of the response.
+func (m *memoCache) store(dst any, req *http.Request, r *Redactor, resp *http.Response) {
+	if !IsSuccess(resp) {
+		return
+	}
+
+	key, ok := m.cacheKey(dst, req, r)
+	if !ok {
+		return
+	}
+
+	elem := reflect.ValueOf(dst).Elem()
+
+	value := reflect.New(elem.Type()).Elem()
+	value.Set(elem)
+
+	entry := memoEntry{
+		value:   value,
+		resp:    copyResponse(resp),
+		expires: time.Now().Add(m.ttl),
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.entries == nil {
+		m.entries = make(map[memoKey]memoEntry)
+	}
+
+	m.entries[key] = entry
+
+	if len(m.entries) >= max(m.nextSweep, minMemoSweep) {
+		now := time.Now()
+
+		for key, entry := range m.entries {
+			if now.After(entry.expires) {
+				delete(m.entries, key)
+			}
+		}
+
+		m.nextSweep = 2 * len(m.entries)
+	}
+}
+
+// copyResponse returns a copy of the given response without body and request.
+func copyResponse(resp *http.Response) *http.Response {
+	return &http.Response{
+		Status:        resp.Status,
+		StatusCode:    resp.StatusCode,
+		Proto:         resp.Proto,
+		ProtoMajor:    resp.ProtoMajor,
+		ProtoMinor:    resp.ProtoMinor,
+		Header:        resp.Header.Clone(),
+		Body:          http.NoBody,
+		ContentLength: 0,
+		Trailer:       resp.Trailer.Clone(),
+	}
+}
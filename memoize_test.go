@@ -0,0 +1,290 @@
+package httpc_test
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nussjustin/httpc"
+)
+
+func TestWithMemoize(t *testing.T) {
+	var calls int
+
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			calls++
+
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       http.NoBody,
+				Request:    req,
+			}, nil
+		}),
+	}
+
+	handler := httpc.HandlerFunc(func(dst any, _ *http.Response) error {
+		switch dst := dst.(type) {
+		case *int:
+			*dst = calls
+		case *string:
+			*dst = strconv.Itoa(calls)
+		}
+		return nil
+	})
+
+	c := httpc.New(
+		httpc.WithClient(client),
+		httpc.WithHandler(handler),
+		httpc.WithMemoize(time.Hour, nil),
+	)
+
+	fetchInt := func(method, url string) int {
+		t.Helper()
+
+		var got int
+		if err := c.Fetch(t.Context(), method, url, &got); err != nil {
+			t.Fatalf("failed to fetch: %v", err)
+		}
+		return got
+	}
+
+	if got, want := fetchInt(http.MethodGet, "/a"), 1; got != want {
+		t.Errorf("got %d, want %d", got, want)
+	}
+
+	if got, want := fetchInt(http.MethodGet, "/a"), 1; got != want {
+		t.Errorf("got %d, want %d", got, want)
+	}
+
+	if got, want := fetchInt(http.MethodGet, "/a?x=1&y=2"), 2; got != want {
+		t.Errorf("got %d, want %d", got, want)
+	}
+
+	if got, want := fetchInt(http.MethodGet, "/a?y=2&x=1"), 2; got != want {
+		t.Errorf("got %d, want %d", got, want)
+	}
+
+	if got, want := fetchInt(http.MethodGet, "/b"), 3; got != want {
+		t.Errorf("got %d, want %d", got, want)
+	}
+
+	if got, want := fetchInt(http.MethodPost, "/a"), 4; got != want {
+		t.Errorf("got %d, want %d", got, want)
+	}
+
+	var gotString string
+	if err := c.Fetch(t.Context(), http.MethodGet, "/a", &gotString); err != nil {
+		t.Fatalf("failed to fetch: %v", err)
+	}
+
+	if want := "5"; gotString != want {
+		t.Errorf("got %q, want %q", gotString, want)
+	}
+}
+
+func TestWithMemoize_Expiration(t *testing.T) {
+	var calls int
+
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			calls++
+
+			return &http.Response{
+				StatusCode: http.StatusNoContent,
+				Header:     http.Header{"X-Call": []string{strconv.Itoa(calls)}},
+				Body:       http.NoBody,
+				Request:    req,
+			}, nil
+		}),
+	}
+
+	memoize := httpc.WithMemoize(10*time.Millisecond, func(req *http.Request) string {
+		return req.URL.Path
+	})
+
+	fetch := func() string {
+		t.Helper()
+
+		_, resp, err := httpc.FetchWithResponse[any](t.Context(), http.MethodPost, "/",
+			httpc.WithClient(client), memoize)
+		if err != nil {
+			t.Fatalf("failed to fetch: %v", err)
+		}
+		return resp.Header.Get("X-Call")
+	}
+
+	if got, want := fetch(), "1"; got != want {
+		t.Errorf("got call %q, want %q", got, want)
+	}
+
+	if got, want := fetch(), "1"; got != want {
+		t.Errorf("got call %q, want %q", got, want)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	if got, want := fetch(), "2"; got != want {
+		t.Errorf("got call %q, want %q", got, want)
+	}
+}
+
+func TestWithMemoize_ReadCloser(t *testing.T) {
+	var calls int
+
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			calls++
+
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader("body")),
+				Request:    req,
+			}, nil
+		}),
+	}
+
+	c := httpc.New(httpc.WithClient(client), httpc.WithMemoize(time.Hour, nil))
+
+	for range 2 {
+		var body io.ReadCloser
+		if err := c.Fetch(t.Context(), http.MethodGet, "/a", &body); err != nil {
+			t.Fatalf("failed to fetch: %v", err)
+		}
+		_ = body.Close()
+	}
+
+	if got, want := calls, 2; got != want {
+		t.Errorf("got %d calls, want %d", got, want)
+	}
+}
+
+func TestWithMemoize_ErrorStatus(t *testing.T) {
+	var calls int
+
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			calls++
+
+			return &http.Response{
+				StatusCode: http.StatusNotFound,
+				Body:       http.NoBody,
+				Request:    req,
+			}, nil
+		}),
+	}
+
+	c := httpc.New(
+		httpc.WithClient(client),
+		httpc.WithHandler(httpc.DiscardBodyHandler()),
+		httpc.WithMemoize(time.Hour, nil),
+	)
+
+	for range 2 {
+		var got string
+		if err := c.Fetch(t.Context(), http.MethodGet, "/a", &got); err != nil {
+			t.Fatalf("failed to fetch: %v", err)
+		}
+	}
+
+	if got, want := calls, 2; got != want {
+		t.Errorf("got %d calls, want %d", got, want)
+	}
+}
+
+func TestWithMemoize_Credentials(t *testing.T) {
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			body := req.Header.Get("Authorization") + req.Header.Get("Cookie") + req.Header.Get("X-Api-Key")
+
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": []string{"text/plain"}},
+				Body:       io.NopCloser(strings.NewReader(body)),
+				Request:    req,
+			}, nil
+		}),
+	}
+
+	testCases := []struct {
+		Name   string
+		Option func(credential string) httpc.FetchOption
+	}{
+		{
+			Name: "Authorization",
+			Option: func(credential string) httpc.FetchOption {
+				return httpc.WithHeader("Authorization", credential)
+			},
+		},
+		{
+			Name: "API key in header",
+			Option: func(credential string) httpc.FetchOption {
+				return httpc.WithAPIKey(credential, httpc.KeyInHeader, "X-Api-Key")
+			},
+		},
+		{
+			Name: "API key in cookie",
+			Option: func(credential string) httpc.FetchOption {
+				return httpc.WithAPIKey(credential, httpc.KeyInCookie, "key")
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			c := httpc.New(httpc.WithClient(client), httpc.WithMemoize(time.Hour, nil))
+
+			fetch := func(credential string) string {
+				t.Helper()
+
+				var got string
+				if err := c.Fetch(t.Context(), http.MethodGet, "/a", &got, testCase.Option(credential)); err != nil {
+					t.Fatalf("failed to fetch: %v", err)
+				}
+				return got
+			}
+
+			a, b := fetch("a"), fetch("b")
+
+			if a == b {
+				t.Errorf("got same value %q for different credentials", a)
+			}
+
+			if got := fetch("a"); got != a {
+				t.Errorf("got %q, want %q", got, a)
+			}
+		})
+	}
+}
+
+func TestWithMemoize_Stats(t *testing.T) {
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": []string{"text/plain"}},
+				Body:       io.NopCloser(strings.NewReader("value")),
+				Request:    req,
+			}, nil
+		}),
+	}
+
+	c := httpc.New(httpc.WithClient(client), httpc.WithMemoize(time.Hour, nil))
+
+	var stats httpc.Stats
+
+	for i, want := range []httpc.Stats{{Attempts: 1, ResponseBytes: 5}, {}} {
+		var got string
+		if err := c.Fetch(t.Context(), http.MethodGet, "/a", &got, httpc.WithStats(&stats)); err != nil {
+			t.Fatalf("failed to fetch: %v", err)
+		}
+
+		if stats != want {
+			t.Errorf("call %d: got stats %+v, want %+v", i+1, stats, want)
+		}
+	}
+}
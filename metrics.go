@@ -0,0 +1,42 @@
+package httpc
+
+import (
+	"time"
+)
+
+// Metrics records metrics for requests made using [WithMetrics].
+//
+// Metrics allows integrating metrics libraries like the Prometheus client without adding them as dependency of this
+// package. The httpcmetrics package provides an implementation that exposes metrics in the Prometheus text format.
+//
+// Implementations must be safe for concurrent use.
+type Metrics interface {
+	// RequestStarted is called before the request described by info is sent.
+	RequestStarted(info SpanInfo)
+
+	// RequestFinished is called once the request described by info is done, with the result of the request and the
+	// time since RequestStarted was called.
+	RequestFinished(info SpanInfo, result SpanResult, duration time.Duration)
+}
+
+// WithMetrics causes metrics to be recorded using the given [Metrics] for each request.
+//
+// Like spans created using [WithTracer], metrics cover the whole request, including retries, redirects and handling of
+// the response. Requests answered using [WithMemoize] are not recorded.
+func WithMetrics(m Metrics) FetchOption {
+	return func(ctx *fetchContext) error {
+		ctx.Metrics = m
+		return nil
+	}
+}
+
+// startMetrics reports the start of the request and returns a function that reports the result.
+func (ctx *fetchContext) startMetrics() func(SpanResult) {
+	info, start := ctx.spanInfo(), time.Now()
+
+	ctx.Metrics.RequestStarted(info)
+
+	return func(result SpanResult) {
+		ctx.Metrics.RequestFinished(info, result, time.Since(start))
+	}
+}
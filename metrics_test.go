@@ -0,0 +1,56 @@
+package httpc_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/nussjustin/httpc"
+)
+
+type testMetrics struct {
+	started  []httpc.SpanInfo
+	finished []httpc.SpanResult
+}
+
+func (m *testMetrics) RequestStarted(info httpc.SpanInfo) {
+	m.started = append(m.started, info)
+}
+
+func (m *testMetrics) RequestFinished(info httpc.SpanInfo, result httpc.SpanResult, duration time.Duration) {
+	if duration <= 0 {
+		result.Err = errors.New("invalid duration")
+	}
+
+	m.finished = append(m.finished, result)
+}
+
+func TestWithMetrics(t *testing.T) {
+	metrics := &testMetrics{}
+
+	captureRequest(t, "https://example.com/product/{id}",
+		httpc.WithPathValue("id", "1"),
+		httpc.WithMetrics(metrics))
+
+	wantStarted := []httpc.SpanInfo{{
+		Name:         "GET /product/{id}",
+		Method:       http.MethodGet,
+		URLTemplate:  "https://example.com/product/{id}",
+		PathTemplate: "/product/{id}",
+		URL:          "https://example.com/product/1",
+	}}
+
+	if diff := cmp.Diff(wantStarted, metrics.started); diff != "" {
+		t.Errorf("started mismatch (-want +got):\n%s", diff)
+	}
+
+	wantFinished := []httpc.SpanResult{{StatusCode: http.StatusNoContent, Attempts: 1}}
+
+	if diff := cmp.Diff(wantFinished, metrics.finished, cmpopts.EquateErrors()); diff != "" {
+		t.Errorf("finished mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,47 @@
+package httpc
+
+import (
+	"net/http"
+)
+
+// Middleware wraps the [http.RoundTripper] used to send requests to add behavior around it, for example to sign
+// requests or to add tracing.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc is an adapter that allows using ordinary functions as [http.RoundTripper].
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip implements the [http.RoundTripper] interface by calling f.
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// WithMiddleware adds the given middlewares to the request.
+//
+// Middlewares can be added multiple times, for example using [New] for middlewares that apply to all requests of a
+// [Client] and as per-request option. The first middleware added is the outermost one, so it is called first and
+// returns last.
+//
+// Middlewares wrap the transport of the underlying client directly, before any wrappers added by other options. They
+// see requests as they are sent to the server, including headers added for authentication, retries and requests sent
+// when following redirects. Responses served from a cache configured using [WithCache] do not pass the middlewares.
+func WithMiddleware(mws ...Middleware) FetchOption {
+	return func(ctx *fetchContext) error {
+		ctx.Middlewares = append(ctx.Middlewares, mws...)
+		return nil
+	}
+}
+
+// applyMiddlewares wraps the transport of the client with the configured middlewares.
+func (ctx *fetchContext) applyMiddlewares() {
+	if len(ctx.Middlewares) == 0 {
+		return
+	}
+
+	ctx.wrapTransport(func(rt http.RoundTripper) http.RoundTripper {
+		for i := len(ctx.Middlewares) - 1; i >= 0; i-- {
+			rt = ctx.Middlewares[i](rt)
+		}
+		return rt
+	})
+}
@@ -0,0 +1,63 @@
+package httpc_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nussjustin/httpc"
+)
+
+func TestWithMiddleware(t *testing.T) {
+	var calls []string
+
+	named := func(name string) httpc.Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return httpc.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, name+" "+req.Header.Get("Authorization"))
+				resp, err := next.RoundTrip(req)
+				calls = append(calls, name+" done")
+				return resp, err
+			})
+		}
+	}
+
+	client := &http.Client{
+		Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusNoContent,
+				Header:     make(http.Header),
+				Body:       http.NoBody,
+				Request:    r,
+			}, nil
+		}),
+	}
+
+	c := httpc.New(
+		httpc.WithClient(client),
+		httpc.WithMiddleware(named("client")),
+		httpc.WithTokenSource(httpc.TokenSourceFunc(func(context.Context) (string, error) {
+			return "token", nil
+		})),
+	)
+
+	if err := c.Fetch(t.Context(), http.MethodGet, "https://example.com/", nil,
+		httpc.WithMiddleware(named("first"), named("second"))); err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	want := []string{
+		"client Bearer token",
+		"first Bearer token",
+		"second Bearer token",
+		"second done",
+		"first done",
+		"client done",
+	}
+
+	if diff := cmp.Diff(want, calls); diff != "" {
+		t.Errorf("calls mismatch (-want +got):\n%s", diff)
+	}
+}
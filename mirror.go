@@ -0,0 +1,232 @@
+package httpc
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// WithMirrors configures mirrors that are used when a request to the primary URL fails.
+//
+// Each mirror is given as base URL. Requests are sent to a mirror by replacing the scheme and host of the request URL
+// with those of the mirror and prefixing the request path with the path of the mirror. For example with the mirror
+// "https://mirror.example.org/example", a request for "https://example.com/releases/app.tar.gz" is sent to
+// "https://mirror.example.org/example/releases/app.tar.gz".
+//
+// Mirrors are only used for GET and HEAD requests. If a request fails with an error or a response with a status code of
+// 400 or above, it is sent to the next mirror in the given order. If all mirrors fail, the result of the last mirror is
+// returned.
+//
+// If reading the response body fails, for example because the connection was dropped in the middle of a download, the
+// rest of the body is requested from the next mirror using a Range request starting after the bytes already read. This
+// allows handlers like the ones used by [DownloadVerified] and [DownloadAndExtract] to continue where the download left
+// off. Mirrors that do not respond with the requested range are skipped. Bodies are not resumed for requests that set
+// their own Range header or for responses that were transparently decompressed by the [http.Transport].
+//
+// Authorization and Cookie headers are not sent to mirrors with a different origin than the request URL.
+//
+// Opaque request URLs, for example as used by [WithOpaqueURL], are only sent to mirrors if they contain an absolute
+// path, which is prefixed with the path of the mirror as is.
+//
+// Mirrors must be absolute URLs. Otherwise requests using the returned option fail with an error.
+func WithMirrors(urls ...string) FetchOption {
+	mirrors := make([]*url.URL, 0, len(urls))
+
+	for _, rawURL := range urls {
+		u, err := url.Parse(rawURL)
+		if err == nil && (u.Scheme == "" || u.Host == "") {
+			err = fmt.Errorf("%q is not an absolute URL", rawURL)
+		}
+
+		if err != nil {
+			return func(*fetchContext) error {
+				return fmt.Errorf("github.com/nussjustin/httpc: invalid mirror: %w", err)
+			}
+		}
+
+		mirrors = append(mirrors, u)
+	}
+
+	return func(ctx *fetchContext) error {
+		ctx.Mirrors = mirrors
+		return nil
+	}
+}
+
+// applyMirrors replaces the client with one that falls back to the configured mirrors, if any.
+func (ctx *fetchContext) applyMirrors() {
+	if len(ctx.Mirrors) == 0 {
+		return
+	}
+
+	ctx.wrapTransport(func(rt http.RoundTripper) http.RoundTripper {
+		return &mirrorTransport{next: rt, mirrors: ctx.Mirrors}
+	})
+}
+
+type mirrorTransport struct {
+	next    http.RoundTripper
+	mirrors []*url.URL
+}
+
+func (t *mirrorTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return t.next.RoundTrip(req)
+	}
+
+	if _, ok := opaquePath(req.URL); req.URL.Opaque != "" && !ok {
+		return t.next.RoundTrip(req)
+	}
+
+	// Source -1 is the request URL itself, all other sources are indices into mirrors.
+	for source := -1; ; source++ {
+		resp, err := t.next.RoundTrip(t.request(req, source, 0))
+
+		failed := err != nil || resp.StatusCode >= http.StatusBadRequest
+
+		if !failed {
+			if req.Method == http.MethodGet && req.Header.Get("Range") == "" && !resp.Uncompressed {
+				resp.Body = &mirrorBody{ReadCloser: resp.Body, t: t, req: req, source: source}
+			}
+
+			return resp, nil
+		}
+
+		if source == len(t.mirrors)-1 || req.Context().Err() != nil {
+			return resp, err
+		}
+
+		if resp != nil {
+			_ = resp.Body.Close()
+		}
+	}
+}
+
+// request returns the request for the given source, requesting the body starting at offset.
+func (t *mirrorTransport) request(req *http.Request, source int, offset int64) *http.Request {
+	if source < 0 && offset == 0 {
+		return req
+	}
+
+	r := req.Clone(req.Context())
+
+	if source >= 0 {
+		mirror := t.mirrors[source]
+
+		r.Host = ""
+		r.URL.Scheme = mirror.Scheme
+		r.URL.Host = mirror.Host
+		r.URL.Path = strings.TrimSuffix(mirror.Path, "/") + req.URL.Path
+
+		if req.URL.RawPath != "" {
+			r.URL.RawPath = strings.TrimSuffix(mirror.EscapedPath(), "/") + req.URL.RawPath
+		}
+
+		if path, ok := opaquePath(req.URL); ok {
+			r.URL.Opaque = "//" + mirror.Host + strings.TrimSuffix(mirror.EscapedPath(), "/") + path
+		}
+
+		// Similar to redirects by the http.Client, do not leak credentials to other origins.
+		if !sameOrigin(mirror, req.URL) {
+			for _, name := range []string{"Authorization", "Cookie", "Cookie2", "Www-Authenticate"} {
+				r.Header.Del(name)
+			}
+		}
+	}
+
+	if offset > 0 {
+		r.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+	}
+
+	return r
+}
+
+// opaquePath returns the path of an opaque URL, which may include the host as done by [WithOpaqueURL].
+func opaquePath(u *url.URL) (string, bool) {
+	if u.Opaque == "" {
+		return "", false
+	}
+
+	path := strings.TrimPrefix(u.Opaque, "//"+u.Host)
+	return path, strings.HasPrefix(path, "/") && !strings.HasPrefix(path, "//")
+}
+
+// mirrorBody is a response body that continues reading from the next mirror when a read fails.
+type mirrorBody struct {
+	io.ReadCloser
+
+	t      *mirrorTransport
+	req    *http.Request
+	source int
+	offset int64
+}
+
+func (b *mirrorBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.offset += int64(n)
+
+	if err == nil || errors.Is(err, io.EOF) || b.req.Context().Err() != nil {
+		return n, err
+	}
+
+	if !b.resume() {
+		return n, err
+	}
+
+	if n == 0 {
+		return b.Read(p)
+	}
+
+	return n, nil
+}
+
+// resume replaces the body with the rest of the body as returned by the next mirror that supports the request and
+// reports whether a mirror was found.
+func (b *mirrorBody) resume() bool {
+	_ = b.ReadCloser.Close()
+
+	for b.source < len(b.t.mirrors)-1 {
+		b.source++
+
+		resp, err := b.t.next.RoundTrip(b.t.request(b.req, b.source, b.offset))
+		if err != nil {
+			continue
+		}
+
+		if resumesAt(resp, b.offset) {
+			b.ReadCloser = resp.Body
+			return true
+		}
+
+		_ = resp.Body.Close()
+	}
+
+	return false
+}
+
+// resumesAt reports whether resp contains the body starting at the given offset.
+func resumesAt(resp *http.Response, offset int64) bool {
+	if resp.Uncompressed {
+		return false
+	}
+
+	if offset == 0 {
+		return resp.StatusCode == http.StatusOK
+	}
+
+	if resp.StatusCode != http.StatusPartialContent {
+		return false
+	}
+
+	spec, ok := strings.CutPrefix(resp.Header.Get("Content-Range"), "bytes ")
+	if !ok {
+		return false
+	}
+
+	start, _, ok := strings.Cut(spec, "-")
+	return ok && start == strconv.FormatInt(offset, 10)
+}
@@ -0,0 +1,179 @@
+package httpc_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nussjustin/httpc"
+)
+
+func TestWithMirrors(t *testing.T) {
+	const content = "hello world"
+
+	errDropped := errors.New("connection dropped")
+
+	respond := func(req *http.Request, status int, body io.Reader) *http.Response {
+		return &http.Response{
+			StatusCode: status,
+			Header:     http.Header{"Content-Type": []string{"text/plain"}},
+			Body:       io.NopCloser(body),
+			Request:    req,
+		}
+	}
+
+	var got []string
+
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			got = append(got, req.URL.String()+" "+req.Header.Get("Range")+" "+req.Header.Get("Authorization"))
+
+			switch req.URL.Host {
+			case "down.example.com":
+				return nil, errDropped
+			case "broken.example.com":
+				return respond(req, http.StatusServiceUnavailable, strings.NewReader("unavailable")), nil
+			case "dropping.example.com":
+				return respond(req, http.StatusOK,
+					io.MultiReader(strings.NewReader(content[:5]), &errorReader{err: errDropped})), nil
+			case "norange.example.com":
+				return respond(req, http.StatusOK, strings.NewReader(content)), nil
+			}
+
+			rangeSpec, ok := strings.CutPrefix(req.Header.Get("Range"), "bytes=5-")
+			if !ok || rangeSpec != "" {
+				return respond(req, http.StatusOK, strings.NewReader(content)), nil
+			}
+
+			resp := respond(req, http.StatusPartialContent, strings.NewReader(content[5:]))
+			resp.Header.Set("Content-Range", "bytes 5-10/11")
+			return resp, nil
+		}),
+	}
+
+	tests := []struct {
+		Name     string
+		URL      string
+		Mirrors  []string
+		Options  []httpc.FetchOption
+		Want     string
+		WantErr  error
+		Requests []string
+	}{
+		{
+			Name:    "Primary",
+			URL:     "https://example.com/file",
+			Mirrors: []string{"https://down.example.com/"},
+			Want:    content,
+			Requests: []string{
+				"https://example.com/file  token",
+			},
+		},
+		{
+			Name:    "Fallback",
+			URL:     "https://down.example.com/file",
+			Mirrors: []string{"https://broken.example.com/", "https://mirror.example.com/base/"},
+			Want:    content,
+			Requests: []string{
+				"https://down.example.com/file  token",
+				"https://broken.example.com/file  ",
+				"https://mirror.example.com/base/file  ",
+			},
+		},
+		{
+			Name: "Resume",
+			URL:  "https://dropping.example.com/file",
+			Mirrors: []string{
+				"https://down.example.com/",
+				"https://norange.example.com/",
+				"https://mirror.example.com/",
+			},
+			Want: content,
+			Requests: []string{
+				"https://dropping.example.com/file  token",
+				"https://down.example.com/file bytes=5- ",
+				"https://norange.example.com/file bytes=5- ",
+				"https://mirror.example.com/file bytes=5- ",
+			},
+		},
+		{
+			Name:    "Different scheme and port",
+			URL:     "https://down.example.com/file",
+			Mirrors: []string{"http://down.example.com:8080/"},
+			Want:    content,
+			Requests: []string{
+				"https://down.example.com/file  token",
+				"http://down.example.com:8080/file  ",
+			},
+		},
+		{
+			Name:    "Same origin",
+			URL:     "https://down.example.com/file",
+			Mirrors: []string{"https://down.example.com:443/mirror/"},
+			Want:    content,
+			Requests: []string{
+				"https://down.example.com/file  token",
+				"https://down.example.com:443/mirror/file  token",
+			},
+		},
+		{
+			Name:    "Opaque URL",
+			URL:     "https://down.example.com/a%2Fb",
+			Mirrors: []string{"https://mirror.example.com/base/"},
+			Options: []httpc.FetchOption{httpc.WithOpaqueURL()},
+			Want:    content,
+			Requests: []string{
+				"https://down.example.com/a%2Fb  token",
+				"https://mirror.example.com/base/a%2Fb  ",
+			},
+		},
+		{
+			Name:    "All failed",
+			URL:     "https://dropping.example.com/file",
+			Mirrors: []string{"https://down.example.com/"},
+			WantErr: errDropped,
+			Requests: []string{
+				"https://dropping.example.com/file  token",
+				"https://down.example.com/file bytes=5- ",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			got = nil
+
+			opts := append([]httpc.FetchOption{
+				httpc.WithClient(client),
+				httpc.WithHeader("Authorization", "token"),
+				httpc.WithMirrors(tt.Mirrors...),
+			}, tt.Options...)
+
+			body, err := httpc.Fetch[string](t.Context(), http.MethodGet, tt.URL, opts...)
+			if !errors.Is(err, tt.WantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.WantErr)
+			}
+
+			if body != tt.Want {
+				t.Errorf("got body %q, want %q", body, tt.Want)
+			}
+
+			if diff := cmp.Diff(tt.Requests, got); diff != "" {
+				t.Errorf("requests mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("Invalid mirror", func(t *testing.T) {
+		_, err := httpc.Fetch[string](t.Context(), http.MethodGet, "https://example.com/file",
+			httpc.WithClient(client),
+			httpc.WithMirrors("mirror.example.com"))
+		if err == nil {
+			t.Error("got no error")
+		}
+	})
+}
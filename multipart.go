@@ -0,0 +1,190 @@
+package httpc
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// MultipartPart is a single part of a multipart/form-data body created by [WithBodyMultipart].
+//
+// The content of the part is taken from Body, if set, or the file at Path, if set, or Value otherwise.
+type MultipartPart struct {
+	// Name is the name of the form field.
+	Name string
+
+	// FileName is the file name sent for the part, if any.
+	FileName string
+
+	// Header contains additional headers for the part, for example a Content-Type header.
+	//
+	// The Content-Disposition header is generated using Name and FileName, unless it is set explicitly.
+	Header http.Header
+
+	// Value is the content of the part, if neither Body nor Path is set.
+	Value string
+
+	// Body is read to get the content of the part.
+	//
+	// If Body implements [io.Closer], it is closed after the part was written.
+	Body io.Reader
+
+	// Path is the path of a file that is read to get the content of the part.
+	//
+	// The file is opened when the part is written.
+	Path string
+}
+
+// MultipartField returns a [MultipartPart] for a form field with the given value.
+func MultipartField(name, value string) MultipartPart {
+	return MultipartPart{Name: name, Value: value}
+}
+
+// MultipartFile returns a [MultipartPart] for a file whose content is read from r.
+func MultipartFile(name, fileName string, r io.Reader) MultipartPart {
+	return MultipartPart{Name: name, FileName: fileName, Body: r}
+}
+
+// MultipartFilePath returns a [MultipartPart] for the file at the given path.
+//
+// The base name of the path is used as file name.
+func MultipartFilePath(name, path string) MultipartPart {
+	return MultipartPart{Name: name, FileName: filepath.Base(path), Path: path}
+}
+
+// WithBodyMultipart encodes the given parts as multipart/form-data and uses the result as the request body.
+//
+// The body is encoded while it is sent, so the content of the parts is never buffered in memory completely. Since the
+// length of the body is not known in advance, the body is sent using chunked transfer encoding.
+//
+// If the Content-Type header is not set or empty, it will be set to "multipart/form-data" including the boundary.
+// Parts for files without a Content-Type header use "application/octet-stream".
+//
+// The body can be sent again, for example on redirects, only if no part uses [MultipartPart.Body], since readers can
+// only be read once. Encoding errors, for example because a file could not be opened, cause the request to fail.
+func WithBodyMultipart(parts ...MultipartPart) FetchOption {
+	return func(ctx *fetchContext) error {
+		boundary := multipart.NewWriter(nil).Boundary()
+
+		if ctx.Request.Header.Get("Content-Type") == "" {
+			ctx.Request.Header.Set("Content-Type", mime.FormatMediaType("multipart/form-data",
+				map[string]string{"boundary": boundary}))
+		}
+
+		ctx.Request.ContentLength = -1
+		ctx.Request.Body = newMultipartBody(boundary, parts)
+		ctx.Request.GetBody = nil
+
+		for _, part := range parts {
+			if part.Body != nil {
+				return nil
+			}
+		}
+
+		ctx.Request.GetBody = func() (io.ReadCloser, error) {
+			return newMultipartBody(boundary, parts), nil
+		}
+
+		return nil
+	}
+}
+
+// multipartBody encodes parts into a pipe once it is first read.
+type multipartBody struct {
+	boundary string
+	parts    []MultipartPart
+
+	once sync.Once
+	pr   *io.PipeReader
+	pw   *io.PipeWriter
+}
+
+func newMultipartBody(boundary string, parts []MultipartPart) *multipartBody {
+	pr, pw := io.Pipe()
+	return &multipartBody{boundary: boundary, parts: parts, pr: pr, pw: pw}
+}
+
+func (b *multipartBody) Read(p []byte) (int, error) {
+	b.once.Do(func() {
+		go func() {
+			_ = b.pw.CloseWithError(b.write())
+		}()
+	})
+
+	return b.pr.Read(p)
+}
+
+func (b *multipartBody) Close() error {
+	return b.pr.Close()
+}
+
+// write writes all parts to the pipe.
+func (b *multipartBody) write() error {
+	w := multipart.NewWriter(b.pw)
+
+	if err := w.SetBoundary(b.boundary); err != nil {
+		return err
+	}
+
+	for _, part := range b.parts {
+		if err := writeMultipartPart(w, part); err != nil {
+			return err
+		}
+	}
+
+	return w.Close()
+}
+
+// writeMultipartPart writes a single part using the given writer.
+func writeMultipartPart(w *multipart.Writer, part MultipartPart) error {
+	header := make(textproto.MIMEHeader, len(part.Header)+2)
+
+	for name, values := range part.Header {
+		header[textproto.CanonicalMIMEHeaderKey(name)] = values
+	}
+
+	if header.Get("Content-Disposition") == "" {
+		params := map[string]string{"name": part.Name}
+		if part.FileName != "" {
+			params["filename"] = part.FileName
+		}
+
+		header.Set("Content-Disposition", mime.FormatMediaType("form-data", params))
+	}
+
+	if header.Get("Content-Type") == "" && (part.FileName != "" || part.Path != "") {
+		header.Set("Content-Type", "application/octet-stream")
+	}
+
+	pw, err := w.CreatePart(header)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case part.Body != nil:
+		if c, ok := part.Body.(io.Closer); ok {
+			defer func() { _ = c.Close() }()
+		}
+
+		_, err = io.Copy(pw, part.Body)
+		return err
+	case part.Path != "":
+		f, err := os.Open(part.Path)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = f.Close() }()
+
+		_, err = io.Copy(pw, f)
+		return err
+	default:
+		_, err = io.WriteString(pw, part.Value)
+		return err
+	}
+}
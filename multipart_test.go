@@ -0,0 +1,147 @@
+package httpc_test
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/go-json-experiment/json"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nussjustin/httpc"
+)
+
+func TestWithBodyMultipart(t *testing.T) {
+	type part struct {
+		Name        string
+		FileName    string
+		ContentType string
+		Extra       string
+		Content     string
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "/echo", http.StatusTemporaryRedirect)
+			return
+		}
+
+		if got, want := r.TransferEncoding, []string{"chunked"}; !slices.Equal(got, want) {
+			t.Errorf("got transfer encoding %q, want %q", got, want)
+		}
+
+		mr, err := r.MultipartReader()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var parts []part
+
+		for {
+			p, err := mr.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			content, _ := io.ReadAll(p)
+
+			parts = append(parts, part{
+				Name:        p.FormName(),
+				FileName:    p.FileName(),
+				ContentType: p.Header.Get("Content-Type"),
+				Extra:       p.Header.Get("X-Extra"),
+				Content:     string(content),
+			})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.MarshalWrite(w, parts)
+	}))
+	t.Cleanup(srv.Close)
+
+	path := filepath.Join(t.TempDir(), "file.txt")
+	if err := os.WriteFile(path, []byte("file content"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		reader := httpc.MultipartFile("upload", "upload.csv", strings.NewReader("a,b"))
+		reader.Header = http.Header{"Content-Type": []string{"text/csv"}, "X-Extra": []string{"extra"}}
+
+		got, err := httpc.Fetch[[]part](t.Context(), http.MethodPost, srv.URL+"/echo",
+			httpc.WithBodyMultipart(
+				httpc.MultipartField("field", "value"),
+				reader,
+				httpc.MultipartFilePath("file", path),
+			))
+		if err != nil {
+			t.Fatalf("got error %v", err)
+		}
+
+		want := []part{
+			{Name: "field", Content: "value"},
+			{Name: "upload", FileName: "upload.csv", ContentType: "text/csv", Extra: "extra", Content: "a,b"},
+			{Name: "file", FileName: "file.txt", ContentType: "application/octet-stream", Content: "file content"},
+		}
+
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("parts mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("Redirect", func(t *testing.T) {
+		got, err := httpc.Fetch[[]part](t.Context(), http.MethodPost, srv.URL+"/redirect",
+			httpc.WithBodyMultipart(
+				httpc.MultipartField("field", "value"),
+				httpc.MultipartFilePath("file", path),
+			))
+		if err != nil {
+			t.Fatalf("got error %v", err)
+		}
+
+		if got, want := len(got), 2; got != want {
+			t.Errorf("got %d parts, want %d", got, want)
+		}
+	})
+
+	t.Run("Missing file", func(t *testing.T) {
+		_, err := httpc.Fetch[[]part](t.Context(), http.MethodPost, srv.URL+"/echo",
+			httpc.WithBodyMultipart(httpc.MultipartFilePath("file", filepath.Join(t.TempDir(), "missing"))))
+		if err == nil {
+			t.Error("got no error")
+		}
+	})
+}
+
+func TestWithBodyMultipart_Request(t *testing.T) {
+	req := captureRequest(t, "/",
+		httpc.WithBodyMultipart(httpc.MultipartFile("file", "file.txt", strings.NewReader("content"))))
+
+	mediaType, params, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	if got, want := mediaType, "multipart/form-data"; got != want {
+		t.Errorf("got media type %q, want %q", got, want)
+	}
+
+	if params["boundary"] == "" {
+		t.Error("boundary not set")
+	}
+
+	if req.GetBody != nil {
+		t.Error("GetBody set for one-shot reader")
+	}
+}
@@ -0,0 +1,104 @@
+package httpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"slices"
+
+	"github.com/go-json-experiment/json"
+	"github.com/go-json-experiment/json/jsontext"
+)
+
+// errStopSeq is used by [FetchSeq] to stop decoding once the caller stopped iterating.
+var errStopSeq = errors.New("github.com/nussjustin/httpc: iteration stopped")
+
+// UnmarshalNDJSONHandler returns a [Handler] that decodes a response body consisting of newline delimited JSON values,
+// also known as NDJSON or JSON Lines, and calls fn for each value.
+//
+// Values are decoded one at a time while the body is read, so that large responses, for example streaming exports,
+// are never buffered completely. The destination passed to the handler is ignored.
+//
+// If fn returns an error, decoding stops, the body is closed without reading the remaining values and the error is
+// returned.
+//
+// Options configured for the request, for example via [WithStrictJSON], are applied after the given options.
+//
+// The response body will automatically be closed.
+func UnmarshalNDJSONHandler[T any](fn func(T) error, opts ...jsontext.Options) HandlerFunc {
+	return func(_ any, resp *http.Response) (err error) {
+		defer discardBody(resp, &err)
+
+		dec := jsontext.NewDecoder(resp.Body, requestJSONOptions(resp, opts)...)
+
+		for {
+			var v T
+
+			if err := json.UnmarshalDecode(dec, &v); err != nil {
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+
+				return err
+			}
+
+			if err := fn(v); err != nil {
+				// Close the body directly instead of reading all remaining values.
+				_ = resp.Body.Close()
+				return err
+			}
+		}
+	}
+}
+
+// FetchSeq requests the given endpoint and returns an iterator over the values of the newline delimited JSON response
+// body, using [UnmarshalNDJSONHandler].
+//
+// The request is sent once iteration starts. Values are yielded while the body is read, so iteration should not block
+// for too long, since the request, including options like [WithDecodeTimeout], is active until iteration stops. If
+// iteration stops early, the remaining body is not read.
+//
+// Unless set via the given options, the Accept header is set to "application/x-ndjson". Any [Handler] configured via
+// the given options is ignored.
+//
+// If the request fails, the response has a non-2xx status code or a value can not be decoded, the error is yielded and
+// iteration stops. For non-2xx responses the error wraps [ErrUnexpectedStatus].
+func FetchSeq[T any](ctx context.Context, method string, url string, opts ...FetchOption) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var stopped bool
+
+		handler := UnmarshalNDJSONHandler(func(v T) error {
+			if !yield(v, nil) {
+				stopped = true
+				return errStopSeq
+			}
+			return nil
+		})
+
+		_, err := Fetch[struct{}](ctx, method, url, slices.Concat(
+			[]FetchOption{WithHeader("Accept", "application/x-ndjson")},
+			opts,
+			[]FetchOption{
+				WithHandlerFunc(func(dst any, resp *http.Response) (err error) {
+					if !IsSuccess(resp) {
+						defer discardBody(resp, &err)
+						return fmt.Errorf("%w %q", ErrUnexpectedStatus, resp.Status)
+					}
+
+					if err := handler(dst, resp); !errors.Is(err, errStopSeq) {
+						return err
+					}
+
+					return nil
+				}),
+			},
+		)...)
+		if err != nil && !stopped {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
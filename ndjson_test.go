@@ -0,0 +1,136 @@
+package httpc_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nussjustin/httpc"
+)
+
+type ndjsonItem struct {
+	ID int `json:"id"`
+}
+
+func TestUnmarshalNDJSONHandler(t *testing.T) {
+	t.Run("All values", func(t *testing.T) {
+		var got []ndjsonItem
+
+		h := httpc.UnmarshalNDJSONHandler(func(item ndjsonItem) error {
+			got = append(got, item)
+			return nil
+		})
+
+		body := &readCloser{Reader: strings.NewReader("{\"id\":1}\n{\"id\":2}\n\n")}
+
+		mustHandle(t, h, nil, &http.Response{Body: body})
+
+		if diff := cmp.Diff([]ndjsonItem{{ID: 1}, {ID: 2}}, got); diff != "" {
+			t.Errorf("values mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("Callback error", func(t *testing.T) {
+		errTest := errors.New("test error")
+
+		var calls int
+
+		h := httpc.UnmarshalNDJSONHandler(func(ndjsonItem) error {
+			calls++
+			return errTest
+		})
+
+		body := &readCloser{Reader: strings.NewReader("{\"id\":1}\n{\"id\":2}\n")}
+
+		if err := h(nil, &http.Response{Body: body}); !errors.Is(err, errTest) {
+			t.Errorf("got error %v, want %v", err, errTest)
+		}
+
+		if calls != 1 {
+			t.Errorf("got %d calls, want 1", calls)
+		}
+
+		if !body.closed {
+			t.Error("body not closed")
+		}
+	})
+
+	t.Run("Invalid value", func(t *testing.T) {
+		h := httpc.UnmarshalNDJSONHandler(func(ndjsonItem) error { return nil })
+
+		body := &readCloser{Reader: strings.NewReader("{\"id\":1}\n{\"id\":")}
+
+		if err := h(nil, &http.Response{Body: body}); err == nil {
+			t.Error("got no error")
+		}
+	})
+}
+
+func TestFetchSeq(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != "application/x-ndjson" {
+			http.Error(w, "unexpected Accept header", http.StatusNotAcceptable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/x-ndjson")
+
+		for i := range 3 {
+			_, _ = fmt.Fprintf(w, "{\"id\":%d,\"name\":\"item\"}\n", i+1)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	t.Run("All values", func(t *testing.T) {
+		got, err := httpc.CollectAll(httpc.FetchSeq[ndjsonItem](t.Context(), http.MethodGet, srv.URL))
+		if err != nil {
+			t.Fatalf("got error %v", err)
+		}
+
+		if diff := cmp.Diff([]ndjsonItem{{ID: 1}, {ID: 2}, {ID: 3}}, got); diff != "" {
+			t.Errorf("values mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("Stop early", func(t *testing.T) {
+		var got []ndjsonItem
+
+		seq := httpc.FetchSeq[ndjsonItem](t.Context(), http.MethodGet, srv.URL,
+			httpc.WithBodyOwnershipCheck(func(err error) { t.Error(err) }))
+
+		for item, err := range seq {
+			if err != nil {
+				t.Fatalf("got error %v", err)
+			}
+
+			if got = append(got, item); len(got) == 2 {
+				break
+			}
+		}
+
+		if diff := cmp.Diff([]ndjsonItem{{ID: 1}, {ID: 2}}, got); diff != "" {
+			t.Errorf("values mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("Strict JSON", func(t *testing.T) {
+		_, err := httpc.CollectAll(httpc.FetchSeq[ndjsonItem](t.Context(), http.MethodGet, srv.URL,
+			httpc.WithStrictJSON()))
+		if err == nil {
+			t.Error("got no error")
+		}
+	})
+
+	t.Run("Unexpected status", func(t *testing.T) {
+		_, err := httpc.CollectAll(httpc.FetchSeq[ndjsonItem](t.Context(), http.MethodGet, srv.URL,
+			httpc.WithHeader("Accept", "application/json")))
+		if !errors.Is(err, httpc.ErrUnexpectedStatus) {
+			t.Errorf("got error %v, want %v", err, httpc.ErrUnexpectedStatus)
+		}
+	})
+}
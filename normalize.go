@@ -0,0 +1,164 @@
+package httpc
+
+import (
+	"net"
+	"strings"
+)
+
+// WithNormalizedURL normalizes the request URL before sending it, using the syntax-based normalizations described in
+// RFC 3986, Section 6.2.2, as well as scheme-based normalization.
+//
+// This includes:
+//
+//   - converting the scheme and host to lower case,
+//   - removing the port, if it is the default port for the scheme,
+//   - converting percent-encodings to upper case and decoding percent-encoded unreserved characters,
+//   - removing dot segments ("." and "..") from the path and
+//   - using "/" as path, if the path is empty.
+//
+// Normalization ensures that equivalent URLs are sent the same way, so that cache keys, signatures and logs are
+// stable.
+//
+// The URL is normalized after wildcards are replaced and trailing slashes are handled. Normalization is skipped when
+// using [WithOpaqueURL] or [WithPresignedURL] and for URLs passed to [Client.FetchURL].
+func WithNormalizedURL() FetchOption {
+	return func(ctx *fetchContext) error {
+		ctx.NormalizeURL = true
+		return nil
+	}
+}
+
+// applyNormalizedURL normalizes the request URL.
+func (ctx *fetchContext) applyNormalizedURL() error {
+	u := ctx.Request.URL
+
+	oldHost := u.Host
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = normalizeHost(u.Scheme, u.Host)
+
+	if ctx.Request.Host == oldHost {
+		ctx.Request.Host = u.Host
+	}
+
+	path := removeDotSegments(normalizeEscapes(u.EscapedPath()))
+	if path == "" && u.Host != "" {
+		path = "/"
+	}
+
+	if err := setEscapedPath(u, path); err != nil {
+		return err
+	}
+
+	u.RawQuery = normalizeEscapes(u.RawQuery)
+	return nil
+}
+
+// normalizeHost converts the host to lower case and removes the port, if it is the default port for the scheme.
+func normalizeHost(scheme, host string) string {
+	host = strings.ToLower(host)
+
+	hostname, port, err := net.SplitHostPort(host)
+	if err != nil {
+		return host
+	}
+
+	switch {
+	case port == "",
+		port == "80" && scheme == "http",
+		port == "443" && scheme == "https":
+		if strings.Contains(hostname, ":") {
+			return "[" + hostname + "]"
+		}
+
+		return hostname
+	default:
+		return host
+	}
+}
+
+// normalizeEscapes converts percent-encodings in s to upper case and decodes percent-encoded unreserved characters.
+func normalizeEscapes(s string) string {
+	if !strings.Contains(s, "%") {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' || i+2 >= len(s) || !isHex(s[i+1]) || !isHex(s[i+2]) {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		c := unhex(s[i+1])<<4 | unhex(s[i+2])
+
+		if isUnreserved(c) {
+			b.WriteByte(c)
+		} else {
+			b.WriteByte('%')
+			b.WriteString(strings.ToUpper(s[i+1 : i+3]))
+		}
+
+		i += 2
+	}
+
+	return b.String()
+}
+
+// removeDotSegments removes "." and ".." segments from the path as described in RFC 3986, Section 5.2.4.
+func removeDotSegments(path string) string {
+	if !strings.Contains(path, ".") {
+		return path
+	}
+
+	var out []string
+
+	segments := strings.Split(path, "/")
+
+	for i, segment := range segments {
+		last := i == len(segments)-1
+
+		switch segment {
+		case ".":
+			if last {
+				out = append(out, "")
+			}
+		case "..":
+			// Keep the empty segment for the leading slash of absolute paths.
+			if len(out) > 1 || (len(out) == 1 && out[0] != "") {
+				out = out[:len(out)-1]
+			}
+
+			if last {
+				out = append(out, "")
+			}
+		default:
+			out = append(out, segment)
+		}
+	}
+
+	return strings.Join(out, "/")
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
+
+// isUnreserved reports whether c is an unreserved character as defined by RFC 3986, Section 2.3.
+func isUnreserved(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
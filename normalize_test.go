@@ -0,0 +1,48 @@
+package httpc_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/nussjustin/httpc"
+)
+
+func TestWithNormalizedURL(t *testing.T) {
+	testCases := []struct {
+		URL  string
+		Want string
+	}{
+		{URL: "HTTP://Example.COM:80/a/b", Want: "http://example.com/a/b"},
+		{URL: "https://example.com:443", Want: "https://example.com/"},
+		{URL: "https://example.com:8443/", Want: "https://example.com:8443/"},
+		{URL: "http://[::1]:80/", Want: "http://[::1]/"},
+		{URL: "http://example.com/a/./b/../c", Want: "http://example.com/a/c"},
+		{URL: "http://example.com/a/b/..", Want: "http://example.com/a/"},
+		{URL: "http://example.com/../../a", Want: "http://example.com/a"},
+		{URL: "http://example.com/a/%2e%2E/b", Want: "http://example.com/b"},
+		{URL: "http://example.com/%7euser/%2fx", Want: "http://example.com/~user/%2Fx"},
+		{URL: "http://example.com/?q=%7e%2f&x=%41", Want: "http://example.com/?q=~%2F&x=A"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.URL, func(t *testing.T) {
+			var got string
+
+			client := &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				got = req.URL.String()
+				return &http.Response{StatusCode: http.StatusNoContent, Body: http.NoBody, Request: req}, nil
+			})}
+
+			_, err := httpc.Fetch[struct{}](t.Context(), http.MethodGet, testCase.URL,
+				httpc.WithNormalizedURL(),
+				httpc.WithClient(client))
+			if err != nil {
+				t.Fatalf("got error %v", err)
+			}
+
+			if got != testCase.Want {
+				t.Errorf("got URL %q, want %q", got, testCase.Want)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package httpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+)
+
+// OAuth2Token is implemented by OAuth 2.0 access tokens, like *oauth2.Token from the golang.org/x/oauth2 package.
+type OAuth2Token interface {
+	// SetAuthHeader sets the Authorization header of the given request using the token.
+	SetAuthHeader(r *http.Request)
+
+	// Valid reports whether the token can be used, for example because it has not expired yet.
+	Valid() bool
+}
+
+// OAuth2TokenSource provides OAuth 2.0 access tokens for [WithOAuth2].
+//
+// OAuth2TokenSource is compatible with the TokenSource interface from the golang.org/x/oauth2 package.
+type OAuth2TokenSource[T OAuth2Token] interface {
+	// Token returns a token.
+	Token() (T, error)
+}
+
+// WithOAuth2 adds an OAuth 2.0 access token obtained from the given source to the Authorization header of the request.
+//
+// Tokens are cached and reused until they are no longer valid, as reported by [OAuth2Token.Valid]. If the request is
+// answered with 401 Unauthorized, the token is invalidated, a new token is requested and the request is sent again
+// once using the new token. Concurrent requests share a single refresh. See [WithTokenSource] for details.
+//
+// Token sources that cache tokens themselves, like those returned by the golang.org/x/oauth2 package, may return the
+// same token again after the server rejected it. In this case the request is still sent again only once.
+//
+// Since tokens are shared only by requests using the same option, the returned option should be created once and
+// reused, for example by passing it to [New].
+func WithOAuth2[T OAuth2Token](ts OAuth2TokenSource[T]) FetchOption {
+	s := &oauth2Source[T]{source: ts}
+	c := &tokenCache{source: s, valid: s.valid}
+
+	return func(ctx *fetchContext) error {
+		ctx.Tokens, ctx.TokenAuthorization = c, oauth2Authorization
+		return nil
+	}
+}
+
+// oauth2Authorization returns the given Authorization header value, which is used as token by [WithOAuth2].
+func oauth2Authorization(header string) string {
+	return header
+}
+
+// oauth2Source adapts an [OAuth2TokenSource] to the [TokenSource] interface.
+//
+// The returned tokens are the Authorization header values set by the OAuth 2.0 tokens.
+type oauth2Source[T OAuth2Token] struct {
+	source OAuth2TokenSource[T]
+
+	mu     sync.Mutex
+	last   T
+	header string
+}
+
+// Token implements the [TokenSource] interface.
+func (s *oauth2Source[T]) Token(context.Context) (string, error) {
+	token, err := s.source.Token()
+	if err != nil {
+		return "", err
+	}
+
+	req := &http.Request{Header: make(http.Header)}
+	token.SetAuthHeader(req)
+
+	header := req.Header.Get("Authorization")
+	if header == "" {
+		return "", errors.New("OAuth 2.0 token did not set Authorization header")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.last, s.header = token, header
+	return header, nil
+}
+
+// valid reports whether the token that resulted in the given header value is still valid.
+func (s *oauth2Source[T]) valid(header string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.header == header && s.last.Valid()
+}
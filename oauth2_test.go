@@ -0,0 +1,115 @@
+package httpc_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nussjustin/httpc"
+)
+
+type testOAuth2Token struct {
+	AccessToken string
+	Expiry      time.Time
+}
+
+func (t *testOAuth2Token) SetAuthHeader(r *http.Request) {
+	r.Header.Set("Authorization", "Bearer "+t.AccessToken)
+}
+
+func (t *testOAuth2Token) Valid() bool {
+	return t.Expiry.IsZero() || time.Now().Before(t.Expiry)
+}
+
+type testOAuth2TokenSource struct {
+	calls atomic.Int64
+	last  atomic.Pointer[testOAuth2Token]
+}
+
+func (s *testOAuth2TokenSource) Token() (*testOAuth2Token, error) {
+	token := &testOAuth2Token{AccessToken: "token-" + strconv.FormatInt(s.calls.Add(1), 10)}
+	s.last.Store(token)
+	return token, nil
+}
+
+func oauth2Server(t *testing.T, valid *atomic.Value, rejected *atomic.Int64) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+valid.Load().(string) {
+			rejected.Add(1)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestWithOAuth2(t *testing.T) {
+	var (
+		valid    atomic.Value
+		rejected atomic.Int64
+	)
+	valid.Store("token-2")
+
+	srv := oauth2Server(t, &valid, &rejected)
+
+	ts := &testOAuth2TokenSource{}
+
+	c := httpc.New(httpc.WithOAuth2(ts))
+
+	for range 2 {
+		if err := c.Fetch(t.Context(), http.MethodGet, srv.URL, nil); err != nil {
+			t.Errorf("got error %v", err)
+		}
+	}
+
+	if got, want := ts.calls.Load(), int64(2); got != want {
+		t.Errorf("got %d token requests, want %d", got, want)
+	}
+
+	if got, want := rejected.Load(), int64(1); got != want {
+		t.Errorf("got %d rejected requests, want %d", got, want)
+	}
+}
+
+func TestWithOAuth2_Expired(t *testing.T) {
+	var (
+		valid    atomic.Value
+		rejected atomic.Int64
+	)
+	valid.Store("token-1")
+
+	srv := oauth2Server(t, &valid, &rejected)
+
+	ts := &testOAuth2TokenSource{}
+
+	c := httpc.New(httpc.WithOAuth2(ts))
+
+	if err := c.Fetch(t.Context(), http.MethodGet, srv.URL, nil); err != nil {
+		t.Errorf("got error %v", err)
+	}
+
+	ts.last.Load().Expiry = time.Now().Add(-time.Minute)
+	valid.Store("token-2")
+
+	// The expired token is replaced before sending the request.
+	if err := c.Fetch(t.Context(), http.MethodGet, srv.URL, nil); err != nil {
+		t.Errorf("got error %v", err)
+	}
+
+	if got, want := ts.calls.Load(), int64(2); got != want {
+		t.Errorf("got %d token requests, want %d", got, want)
+	}
+
+	if got := rejected.Load(); got != 0 {
+		t.Errorf("got %d rejected requests, want 0", got)
+	}
+}
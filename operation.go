@@ -0,0 +1,35 @@
+package httpc
+
+import (
+	"context"
+	"net/http"
+)
+
+// WithOperationName sets the name of the logical operation performed by the request, for example "GetProduct".
+//
+// Unlike the URL or the path template, the name stays the same when an API is moved to a different path and can be
+// chosen to match the names used in generated clients or API specifications.
+//
+// The name is used as name for spans created using [WithTracer] and passed to [Metrics] and [Hooks] as well as
+// included in [RequestInfo] and [StatusError].
+func WithOperationName(name string) FetchOption {
+	return func(ctx *fetchContext) error {
+		ctx.OperationName = name
+		return nil
+	}
+}
+
+// operationNameKey is the context key used to pass the operation name of a request to handlers and transports.
+type operationNameKey struct{}
+
+// RequestOperationName returns the operation name set using [WithOperationName] for the request, or an empty string
+// if no name was set.
+func RequestOperationName(req *http.Request) string {
+	s, _ := req.Context().Value(operationNameKey{}).(string)
+	return s
+}
+
+// withOperationName returns a copy of req that passes the given operation name to handlers and transports.
+func withOperationName(req *http.Request, name string) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), operationNameKey{}, name))
+}
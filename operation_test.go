@@ -0,0 +1,56 @@
+package httpc_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nussjustin/httpc"
+)
+
+func TestWithOperationName(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(srv.Close)
+
+	tracer := &testTracer{}
+
+	var transportName string
+
+	_, err := httpc.Fetch[any](t.Context(), http.MethodGet, srv.URL+"/product/{id}",
+		httpc.WithPathValue("id", "1"),
+		httpc.WithOperationName("GetProduct"),
+		httpc.WithTracer(tracer),
+		httpc.WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+			return httpc.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				transportName = httpc.RequestOperationName(req)
+				return next.RoundTrip(req)
+			})
+		}))
+
+	var statusErr *httpc.StatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("got error %v, want %T", err, statusErr)
+	}
+
+	if got, want := statusErr.OperationName, "GetProduct"; got != want {
+		t.Errorf("got error operation name %q, want %q", got, want)
+	}
+
+	want := `github.com/nussjustin/httpc: unexpected status "404 Not Found" for GetProduct (GET ` + srv.URL +
+		`/product/1)`
+
+	if got := err.Error(); got != want {
+		t.Errorf("got error %q, want %q", got, want)
+	}
+
+	if got, want := tracer.info.Name, "GetProduct"; got != want {
+		t.Errorf("got span name %q, want %q", got, want)
+	}
+
+	if got, want := transportName, "GetProduct"; got != want {
+		t.Errorf("got transport operation name %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,97 @@
+package httpc
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"net/http"
+	"slices"
+)
+
+// PaginateOptions configures how pages are requested by [Paginate].
+type PaginateOptions[T any] struct {
+	// CursorParam is the name of the query parameter used to send the cursor of the next page.
+	CursorParam string
+
+	// NextCursor returns the cursor for the page following the given page or an empty string if there are no more
+	// pages.
+	NextCursor func(page T) string
+
+	// MaxPages is the maximum number of pages that are requested. If zero or negative, the number of pages is not
+	// limited.
+	MaxPages int
+}
+
+// Paginate returns an iterator over the pages of an endpoint using cursor-based pagination, starting at the given URL.
+//
+// Each page is requested using GET and decoded as with [Fetch]. After a page was yielded, the cursor for the next page
+// is extracted using [PaginateOptions.NextCursor] and the next page is requested from the same URL, with the cursor
+// set as value of the query parameter [PaginateOptions.CursorParam]. Iteration stops when there are no more pages,
+// when [PaginateOptions.MaxPages] pages were requested or when the same cursor is returned twice in a row.
+//
+// The given options are used for every page.
+//
+// If a request fails, the error is yielded and iteration stops.
+//
+// Paginate panics if CursorParam is empty or NextCursor is nil.
+func Paginate[T any](
+	ctx context.Context,
+	url string,
+	popts PaginateOptions[T],
+	opts ...FetchOption,
+) iter.Seq2[T, error] {
+	if popts.CursorParam == "" {
+		panic(errors.New("cursor param must not be empty"))
+	}
+
+	if popts.NextCursor == nil {
+		panic(errors.New("next cursor function must not be nil"))
+	}
+
+	return func(yield func(T, error) bool) {
+		var cursor string
+
+		for pages := 1; ; pages++ {
+			pageOpts := opts
+
+			if cursor != "" {
+				pageOpts = slices.Concat(opts, []FetchOption{WithQueryParam(popts.CursorParam, cursor)})
+			}
+
+			t, err := Fetch[T](ctx, http.MethodGet, url, pageOpts...)
+			if err != nil {
+				yield(t, err)
+				return
+			}
+
+			next := popts.NextCursor(t)
+
+			if !yield(t, nil) {
+				return
+			}
+
+			if next == "" || next == cursor || (popts.MaxPages > 0 && pages >= popts.MaxPages) {
+				return
+			}
+
+			cursor = next
+		}
+	}
+}
+
+// CollectAll collects all values yielded by seq, for example the pages returned by [Paginate] or [FetchPages].
+//
+// If seq yields an error, the values collected so far are returned together with the error.
+func CollectAll[T any](seq iter.Seq2[T, error]) ([]T, error) {
+	var values []T
+
+	for v, err := range seq {
+		if err != nil {
+			return values, err
+		}
+
+		values = append(values, v)
+	}
+
+	return values, nil
+}
@@ -0,0 +1,96 @@
+package httpc_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nussjustin/httpc"
+)
+
+type cursorPage struct {
+	Items []int  `json:"items"`
+	Next  string `json:"next"`
+}
+
+func TestPaginate(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("limit") != "2" {
+			http.Error(w, "missing limit", http.StatusBadRequest)
+			return
+		}
+
+		start, _ := strconv.Atoi(r.URL.Query().Get("cursor"))
+
+		var next string
+		if start+2 < 6 {
+			next = strconv.Itoa(start + 2)
+		}
+
+		if r.URL.Path == "/fail" && start > 0 {
+			http.Error(w, "failed", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"items":[%d,%d],"next":%q}`, start, start+1, next)
+	}))
+	t.Cleanup(srv.Close)
+
+	popts := httpc.PaginateOptions[cursorPage]{
+		CursorParam: "cursor",
+		NextCursor:  func(page cursorPage) string { return page.Next },
+	}
+
+	items := func(pages []cursorPage) []int {
+		var items []int
+		for _, page := range pages {
+			items = append(items, page.Items...)
+		}
+		return items
+	}
+
+	t.Run("All pages", func(t *testing.T) {
+		pages, err := httpc.CollectAll(httpc.Paginate(t.Context(), srv.URL+"/items", popts,
+			httpc.WithQueryParam("limit", "2")))
+		if err != nil {
+			t.Fatalf("got error %v", err)
+		}
+
+		if diff := cmp.Diff([]int{0, 1, 2, 3, 4, 5}, items(pages)); diff != "" {
+			t.Errorf("items mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("Max pages", func(t *testing.T) {
+		popts := popts
+		popts.MaxPages = 2
+
+		pages, err := httpc.CollectAll(httpc.Paginate(t.Context(), srv.URL+"/items", popts,
+			httpc.WithQueryParam("limit", "2")))
+		if err != nil {
+			t.Fatalf("got error %v", err)
+		}
+
+		if diff := cmp.Diff([]int{0, 1, 2, 3}, items(pages)); diff != "" {
+			t.Errorf("items mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		pages, err := httpc.CollectAll(httpc.Paginate(t.Context(), srv.URL+"/fail", popts,
+			httpc.WithQueryParam("limit", "2")))
+		if !errors.Is(err, httpc.ErrUnexpectedStatus) {
+			t.Errorf("got error %v, want %v", err, httpc.ErrUnexpectedStatus)
+		}
+
+		if diff := cmp.Diff([]int{0, 1}, items(pages)); diff != "" {
+			t.Errorf("items mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
@@ -0,0 +1,23 @@
+package httpc
+
+import (
+	"github.com/go-json-experiment/json/jsontext"
+)
+
+// Partial can be used as result type to decode a JSON object into a struct of type T while preserving all members
+// that have no matching field in T.
+//
+// Members matching a field of T are decoded into Value, while all other members are stored unchanged in Remainder.
+// When encoding a Partial as JSON, the members in Remainder are written together with the fields of Value.
+//
+// This can be used for example by proxies that need to pass through data they do not model.
+//
+// T must be a struct type that does not have its own field for unknown members. Partial can not be used together with
+// [WithStrictJSON], which rejects all unknown members.
+type Partial[T any] struct {
+	// Value contains the decoded known members.
+	Value T `json:",inline"`
+
+	// Remainder contains the raw values of all members without matching field in Value, keyed by name.
+	Remainder map[string]jsontext.Value `json:",unknown"`
+}
@@ -0,0 +1,61 @@
+package httpc_test
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-json-experiment/json"
+	"github.com/go-json-experiment/json/jsontext"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nussjustin/httpc"
+)
+
+func TestPartial(t *testing.T) {
+	type item struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	const body = `{"id":1,"name":"test","color":"red","tags":["a","b"]}`
+
+	client := &http.Client{
+		Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": {"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(body)),
+				Request:    r,
+			}, nil
+		}),
+	}
+
+	got, err := httpc.Fetch[httpc.Partial[item]](t.Context(), http.MethodGet, "http://example.com/",
+		httpc.WithClient(client))
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	want := httpc.Partial[item]{
+		Value: item{ID: 1, Name: "test"},
+		Remainder: map[string]jsontext.Value{
+			"color": jsontext.Value(`"red"`),
+			"tags":  jsontext.Value(`["a","b"]`),
+		},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Fetch() mismatch (-want +got):\n%s", diff)
+	}
+
+	encoded, err := json.Marshal(got, json.Deterministic(true))
+	if err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+
+	if got, want := string(encoded), body; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
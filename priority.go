@@ -0,0 +1,108 @@
+package httpc
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	// defaultUrgency is the urgency used by RFC 9218 when no urgency is given.
+	defaultUrgency = 3
+
+	// maxUrgency is the lowest urgency defined by RFC 9218.
+	maxUrgency = 7
+)
+
+// PriorityHeader contains the priority parameters of the Priority header defined in RFC 9218.
+type PriorityHeader struct {
+	// Urgency is the urgency of the response, from 0 (highest) to 7 (lowest). The default is 3.
+	Urgency int
+
+	// Incremental specifies whether the response can be processed incrementally.
+	Incremental bool
+}
+
+// String returns the value of the Priority header for the parameters.
+//
+// Parameters with default values are omitted, so the result is empty for the default priority.
+func (p PriorityHeader) String() string {
+	var parts []string
+
+	if p.Urgency != defaultUrgency {
+		parts = append(parts, "u="+strconv.Itoa(min(max(p.Urgency, 0), maxUrgency)))
+	}
+
+	if p.Incremental {
+		parts = append(parts, "i")
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// ParsePriorityHeader parses the value of a Priority header as defined by RFC 9218.
+//
+// As required by the RFC, unknown parameters and parameters with invalid values are ignored and missing parameters
+// use their default value.
+func ParsePriorityHeader(value string) PriorityHeader {
+	p := PriorityHeader{Urgency: defaultUrgency}
+
+	for member := range strings.SplitSeq(value, ",") {
+		member, _, _ = strings.Cut(member, ";")
+
+		key, val, hasValue := strings.Cut(strings.TrimSpace(member), "=")
+
+		switch key {
+		case "u":
+			if u, err := strconv.Atoi(val); err == nil && u >= 0 && u <= maxUrgency {
+				p.Urgency = u
+			}
+		case "i":
+			switch {
+			case !hasValue || val == "?1":
+				p.Incremental = true
+			case val == "?0":
+				p.Incremental = false
+			}
+		}
+	}
+
+	return p
+}
+
+// ResponsePriority returns the priority parameters from the Priority header of the response.
+//
+// Servers can use the header to signal the priority they used for the response. If the header is not set, false is
+// returned.
+func ResponsePriority(resp *http.Response) (PriorityHeader, bool) {
+	values := resp.Header.Values("Priority")
+	if len(values) == 0 {
+		return PriorityHeader{}, false
+	}
+
+	return ParsePriorityHeader(strings.Join(values, ",")), true
+}
+
+// WithRequestPriorityHeaders causes the [Priority] of the request set using [WithPriority] to be sent to the server
+// using the Priority header defined in RFC 9218.
+//
+// [PriorityHigh] uses an urgency of 2, [PriorityNormal] the default urgency of 3 and [PriorityLow] an urgency of 4,
+// with other priorities being mapped accordingly. No header is sent for the default urgency or if the request already
+// has a Priority header.
+func WithRequestPriorityHeaders() FetchOption {
+	return func(ctx *fetchContext) error {
+		ctx.PriorityHeaders = true
+		return nil
+	}
+}
+
+// applyPriorityHeader sets the Priority header based on the priority of the request.
+func (ctx *fetchContext) applyPriorityHeader() {
+	if _, ok := ctx.Request.Header["Priority"]; ok {
+		return
+	}
+
+	if value := (PriorityHeader{Urgency: defaultUrgency - int(ctx.Priority)}).String(); value != "" {
+		ctx.Request.Header.Set("Priority", value)
+	}
+}
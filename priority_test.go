@@ -0,0 +1,106 @@
+package httpc_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nussjustin/httpc"
+)
+
+func TestPriorityHeader_String(t *testing.T) {
+	testCases := []struct {
+		Priority httpc.PriorityHeader
+		Expected string
+	}{
+		{Priority: httpc.PriorityHeader{Urgency: 3}, Expected: ""},
+		{Priority: httpc.PriorityHeader{Urgency: 0}, Expected: "u=0"},
+		{Priority: httpc.PriorityHeader{Urgency: 3, Incremental: true}, Expected: "i"},
+		{Priority: httpc.PriorityHeader{Urgency: 5, Incremental: true}, Expected: "u=5, i"},
+		{Priority: httpc.PriorityHeader{Urgency: 10}, Expected: "u=7"},
+	}
+
+	for _, testCase := range testCases {
+		if got := testCase.Priority.String(); got != testCase.Expected {
+			t.Errorf("got %q for %+v, want %q", got, testCase.Priority, testCase.Expected)
+		}
+	}
+}
+
+func TestParsePriorityHeader(t *testing.T) {
+	testCases := []struct {
+		Value    string
+		Expected httpc.PriorityHeader
+	}{
+		{Value: "", Expected: httpc.PriorityHeader{Urgency: 3}},
+		{Value: "u=1", Expected: httpc.PriorityHeader{Urgency: 1}},
+		{Value: "u=5, i", Expected: httpc.PriorityHeader{Urgency: 5, Incremental: true}},
+		{Value: "i=?1;x=y, u=0", Expected: httpc.PriorityHeader{Urgency: 0, Incremental: true}},
+		{Value: "i, i=?0", Expected: httpc.PriorityHeader{Urgency: 3}},
+		{Value: "u=8, x=1, i=1", Expected: httpc.PriorityHeader{Urgency: 3}},
+	}
+
+	for _, testCase := range testCases {
+		got := httpc.ParsePriorityHeader(testCase.Value)
+
+		if diff := cmp.Diff(testCase.Expected, got); diff != "" {
+			t.Errorf("%q: priority mismatch (-want +got):\n%s", testCase.Value, diff)
+		}
+	}
+}
+
+func TestResponsePriority(t *testing.T) {
+	if _, ok := httpc.ResponsePriority(&http.Response{Header: http.Header{}}); ok {
+		t.Error("got priority for response without header")
+	}
+
+	got, ok := httpc.ResponsePriority(&http.Response{Header: http.Header{"Priority": {"u=2", "i"}}})
+	if !ok {
+		t.Fatal("got no priority")
+	}
+
+	if diff := cmp.Diff(httpc.PriorityHeader{Urgency: 2, Incremental: true}, got); diff != "" {
+		t.Errorf("priority mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWithRequestPriorityHeaders(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Options  []httpc.FetchOption
+		Expected string
+	}{
+		{Name: "Disabled", Options: []httpc.FetchOption{httpc.WithPriority(httpc.PriorityHigh)}},
+		{Name: "Default", Options: []httpc.FetchOption{httpc.WithRequestPriorityHeaders()}},
+		{
+			Name:     "High",
+			Options:  []httpc.FetchOption{httpc.WithRequestPriorityHeaders(), httpc.WithPriority(httpc.PriorityHigh)},
+			Expected: "u=2",
+		},
+		{
+			Name:     "Low",
+			Options:  []httpc.FetchOption{httpc.WithPriority(httpc.PriorityLow), httpc.WithRequestPriorityHeaders()},
+			Expected: "u=4",
+		},
+		{
+			Name: "Explicit header",
+			Options: []httpc.FetchOption{
+				httpc.WithRequestPriorityHeaders(),
+				httpc.WithPriority(httpc.PriorityHigh),
+				httpc.WithHeader("Priority", "u=0, i"),
+			},
+			Expected: "u=0, i",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			req := captureRequest(t, "/", testCase.Options...)
+
+			if got := req.Header.Get("Priority"); got != testCase.Expected {
+				t.Errorf("got %q, want %q", got, testCase.Expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package httpc
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+)
+
+// WithProxyBasicAuth authenticates requests to HTTP proxies using basic authentication.
+//
+// See [WithProxyCredentials] for details.
+func WithProxyBasicAuth(username, password string) FetchOption {
+	authorization := "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+
+	return WithProxyCredentials(func(context.Context, *url.URL) (string, error) {
+		return authorization, nil
+	})
+}
+
+// WithProxyToken authenticates requests to HTTP proxies using the given bearer token.
+//
+// See [WithProxyCredentials] for details.
+func WithProxyToken(token string) FetchOption {
+	return WithProxyCredentials(func(context.Context, *url.URL) (string, error) {
+		return "Bearer " + token, nil
+	})
+}
+
+// WithProxyCredentials authenticates requests to HTTP proxies using the Proxy-Authorization header returned by the
+// given function.
+//
+// The function is called with the URL of the proxy as returned by [http.Transport.Proxy] and can be used to return
+// different credentials for each proxy. If it returns an empty string, no header is added.
+//
+// For https:// URLs the header is sent as part of the CONNECT request, for http:// URLs it is added to the request
+// itself. Requests that do not use a proxy or use a SOCKS5 proxy are not modified. Credentials that are part of the
+// proxy URL take precedence.
+//
+// When the option is first applied, the [*http.Transport] of the underlying client is cloned and configured to use the
+// credentials. Since each call creates new copies with their own connection pools, the returned option should be
+// created once and reused, for example by passing it to [New].
+//
+// The transport of the underlying client must be an [*http.Transport]. If the client has no transport,
+// [http.DefaultTransport] is used.
+func WithProxyCredentials(
+	creds func(ctx context.Context, proxy *url.URL) (authorization string, err error),
+) FetchOption {
+	transports := &transportCache{}
+
+	configure := func(t *http.Transport) http.RoundTripper {
+		configured := transports.get(t, func(t *http.Transport) *http.Transport {
+			configured := t.Clone()
+
+			getProxyConnectHeader := configured.GetProxyConnectHeader
+			proxyConnectHeader := configured.ProxyConnectHeader
+
+			configured.GetProxyConnectHeader = func(
+				ctx context.Context,
+				proxy *url.URL,
+				target string,
+			) (http.Header, error) {
+				h := proxyConnectHeader
+
+				if getProxyConnectHeader != nil {
+					var err error
+					if h, err = getProxyConnectHeader(ctx, proxy, target); err != nil {
+						return nil, err
+					}
+				}
+
+				authorization, err := creds(ctx, proxy)
+				if err != nil || authorization == "" {
+					return h, err
+				}
+
+				h = h.Clone()
+				if h == nil {
+					h = make(http.Header)
+				}
+				h.Set("Proxy-Authorization", authorization)
+
+				return h, nil
+			}
+
+			return configured
+		})
+
+		return &proxyAuthTransport{next: configured, creds: creds}
+	}
+
+	return func(ctx *fetchContext) error {
+		ctx.ProxyAuth = configure
+		return nil
+	}
+}
+
+// applyProxyAuth replaces the client with one that uses a transport configured with proxy credentials, if configured.
+func (ctx *fetchContext) applyProxyAuth() error {
+	if ctx.ProxyAuth == nil {
+		return nil
+	}
+
+	t, err := ctx.transport()
+	if err != nil {
+		return err
+	}
+
+	client := *ctx.Client
+	client.Transport = ctx.ProxyAuth(t)
+
+	ctx.Client = &client
+	return nil
+}
+
+// proxyAuthTransport adds the Proxy-Authorization header to plain HTTP requests sent via a proxy.
+//
+// Requests to https:// URLs are handled by [http.Transport.GetProxyConnectHeader] instead.
+type proxyAuthTransport struct {
+	next  *http.Transport
+	creds func(ctx context.Context, proxy *url.URL) (string, error)
+}
+
+func (t *proxyAuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme != "http" || t.next.Proxy == nil {
+		return t.next.RoundTrip(req)
+	}
+
+	proxy, err := t.next.Proxy(req)
+	if err != nil || proxy == nil || proxy.Scheme == "socks5" || proxy.Scheme == "socks5h" {
+		return t.next.RoundTrip(req)
+	}
+
+	authorization, err := t.creds(req.Context(), proxy)
+	if err != nil {
+		closeRequestBody(req)
+		return nil, err
+	}
+
+	if authorization == "" {
+		return t.next.RoundTrip(req)
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("Proxy-Authorization", authorization)
+
+	return t.next.RoundTrip(req)
+}
+
+// WithProxyFromEnvironment causes requests to use the proxy configured using the HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+// environment variables, or their lowercase versions, regardless of the proxy configuration of the transport of the
+// underlying client. See [http.ProxyFromEnvironment] for details.
+//
+// See [WithProxyURL] for details on how the transport is configured.
+func WithProxyFromEnvironment() FetchOption {
+	return withProxy(http.ProxyFromEnvironment)
+}
+
+// WithProxyURL causes all requests to be sent using the proxy at the given URL, ignoring the environment and the proxy
+// configuration of the transport of the underlying client.
+//
+// When the option is first applied, the [*http.Transport] of the underlying client is cloned and configured to use the
+// proxy. Since each call creates new copies with their own connection pools, the returned option should be created
+// once and reused, for example by passing it to [New].
+//
+// If statistics are recorded using [WithStats], the chosen proxy is recorded in [Stats.Proxy].
+//
+// The transport of the underlying client must be an [*http.Transport]. If the client has no transport,
+// [http.DefaultTransport] is used.
+func WithProxyURL(u *url.URL) FetchOption {
+	return withProxy(http.ProxyURL(u))
+}
+
+// WithoutProxy causes requests to be sent directly to the server, ignoring the environment and the proxy
+// configuration of the transport of the underlying client.
+//
+// See [WithProxyURL] for details on how the transport is configured.
+func WithoutProxy() FetchOption {
+	return withProxy(nil)
+}
+
+// proxyStatsKey is the context key used to pass the [Stats] of a request to the proxy function of the transport.
+type proxyStatsKey struct{}
+
+// withProxy returns an option that configures transports to use the given proxy function.
+func withProxy(proxy func(*http.Request) (*url.URL, error)) FetchOption {
+	transports := &transportCache{}
+
+	configure := func(t *http.Transport) *http.Transport {
+		return transports.get(t, func(t *http.Transport) *http.Transport {
+			configured := t.Clone()
+			configured.Proxy = func(req *http.Request) (*url.URL, error) {
+				var u *url.URL
+				var err error
+
+				if proxy != nil {
+					u, err = proxy(req)
+				}
+
+				if stats, ok := req.Context().Value(proxyStatsKey{}).(*Stats); ok && err == nil && u != nil {
+					stats.Proxy = u.Redacted()
+				}
+
+				return u, err
+			}
+
+			return configured
+		})
+	}
+
+	return func(ctx *fetchContext) error {
+		ctx.ConfigureProxy = configure
+		ctx.ProxyEnabled = proxy != nil
+		return nil
+	}
+}
+
+// applyProxy replaces the client with one that uses a transport configured with the chosen proxy, if configured.
+func (ctx *fetchContext) applyProxy() error {
+	if ctx.ConfigureProxy == nil {
+		return nil
+	}
+
+	t, err := ctx.transport()
+	if err != nil {
+		return err
+	}
+
+	client := *ctx.Client
+	client.Transport = ctx.ConfigureProxy(t)
+
+	ctx.Client = &client
+	return nil
+}
@@ -0,0 +1,182 @@
+package httpc_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/nussjustin/httpc"
+)
+
+func TestWithProxyCredentials(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		seen []string
+	)
+
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen = append(seen, r.Method+" "+r.Header.Get("Proxy-Authorization"))
+		mu.Unlock()
+
+		if r.Method == http.MethodConnect {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(proxy.Close)
+
+	proxyURL, _ := url.Parse(proxy.URL)
+
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+
+	testCases := []struct {
+		Name   string
+		Option httpc.FetchOption
+		Want   string
+	}{
+		{
+			Name:   "Basic",
+			Option: httpc.WithProxyBasicAuth("user", "pass"),
+			Want:   "Basic dXNlcjpwYXNz",
+		},
+		{
+			Name:   "Token",
+			Option: httpc.WithProxyToken("secret"),
+			Want:   "Bearer secret",
+		},
+		{
+			Name: "Per proxy",
+			Option: httpc.WithProxyCredentials(func(_ context.Context, proxy *url.URL) (string, error) {
+				if proxy.Host != proxyURL.Host {
+					return "", nil
+				}
+
+				return "Custom credentials", nil
+			}),
+			Want: "Custom credentials",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			seen = nil
+
+			opts := []httpc.FetchOption{httpc.WithClient(client), testCase.Option}
+
+			if err := httpc.New(opts...).Fetch(t.Context(), http.MethodGet, "http://example.com/", nil); err != nil {
+				t.Errorf("got error %v", err)
+			}
+
+			if err := httpc.New(opts...).Fetch(t.Context(), http.MethodGet, "https://example.com/", nil); err == nil {
+				t.Error("got no error for rejected CONNECT")
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			want := []string{"GET " + testCase.Want, "CONNECT " + testCase.Want}
+
+			if len(seen) != len(want) || seen[0] != want[0] || seen[1] != want[1] {
+				t.Errorf("got requests %q, want %q", seen, want)
+			}
+		})
+	}
+}
+
+func TestWithProxyCredentials_NoProxy(t *testing.T) {
+	var got string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("Proxy-Authorization")
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+
+	c := httpc.New(httpc.WithClient(srv.Client()), httpc.WithProxyToken("secret"))
+
+	if err := c.Fetch(t.Context(), http.MethodGet, srv.URL, nil); err != nil {
+		t.Errorf("got error %v", err)
+	}
+
+	if got != "" {
+		t.Errorf("got Proxy-Authorization %q for request without proxy", got)
+	}
+}
+
+func TestWithProxyURL(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("proxy " + r.URL.Host))
+	}))
+	t.Cleanup(proxy.Close)
+
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("direct"))
+	}))
+	t.Cleanup(target.Close)
+
+	proxyURL, _ := url.Parse(proxy.URL)
+	proxyURL.User = url.UserPassword("user", "secret")
+
+	// The transport of the client uses the proxy, which is overridden by the options.
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+
+	testCases := []struct {
+		Name      string
+		Option    httpc.FetchOption
+		URL       string
+		Expected  string
+		WantProxy string
+	}{
+		{
+			Name:      "Proxy URL",
+			Option:    httpc.WithProxyURL(proxyURL),
+			URL:       "http://example.invalid/",
+			Expected:  "proxy example.invalid",
+			WantProxy: "http://user:xxxxx@" + proxyURL.Host,
+		},
+		{
+			Name:     "Without proxy",
+			Option:   httpc.WithoutProxy(),
+			URL:      target.URL,
+			Expected: "direct",
+		},
+		{
+			// Requests to localhost are never sent using a proxy from the environment.
+			Name:     "Environment",
+			Option:   httpc.WithProxyFromEnvironment(),
+			URL:      target.URL,
+			Expected: "direct",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			var stats httpc.Stats
+
+			got, err := httpc.Fetch[string](t.Context(), http.MethodGet, testCase.URL,
+				httpc.WithClient(client),
+				httpc.WithStats(&stats),
+				testCase.Option)
+			if err != nil {
+				t.Fatalf("got error %v", err)
+			}
+
+			if got != testCase.Expected {
+				t.Errorf("got %q, want %q", got, testCase.Expected)
+			}
+
+			if stats.Proxy != testCase.WantProxy {
+				t.Errorf("got proxy %q, want %q", stats.Proxy, testCase.WantProxy)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package httpc
+
+import (
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// WithRand sets the source of randomness used by the request, for example for [WithFaultInjection].
+//
+// This can be used to make tests and simulations reproducible by using a [rand.Rand] with a fixed seed.
+//
+// Access to the given [rand.Rand] is synchronized, so the returned option can be shared by concurrent requests, as
+// long as r is not used anywhere else. Since each call to WithRand creates a new lock, the returned option should be
+// created once and reused.
+//
+// By default, the global source of the math/rand/v2 package is used.
+func WithRand(r *rand.Rand) FetchOption {
+	src := &randSource{r: r}
+
+	return func(ctx *fetchContext) error {
+		ctx.Rand = src
+		return nil
+	}
+}
+
+// randSource is a synchronized wrapper around a [rand.Rand].
+//
+// A nil randSource uses the global source of the math/rand/v2 package.
+type randSource struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+// Float64 returns a pseudo-random number in the half-open interval [0.0,1.0).
+func (s *randSource) Float64() float64 {
+	if s == nil {
+		return rand.Float64()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.r.Float64()
+}
+
+// Duration returns a pseudo-random duration in the half-open interval [0,d).
+func (s *randSource) Duration(d time.Duration) time.Duration {
+	if s == nil {
+		return rand.N(d)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return time.Duration(s.r.Int64N(int64(d)))
+}
@@ -0,0 +1,52 @@
+package httpc_test
+
+import (
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nussjustin/httpc"
+)
+
+func TestWithRand(t *testing.T) {
+	client := &http.Client{
+		Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusNoContent,
+				Header:     make(http.Header),
+				Body:       http.NoBody,
+				Request:    r,
+			}, nil
+		}),
+	}
+
+	run := func(seed uint64) []bool {
+		opts := []httpc.FetchOption{
+			httpc.WithClient(client),
+			httpc.WithFaultInjection(httpc.FaultConfig{ErrorProbability: 0.5}),
+			httpc.WithRand(rand.New(rand.NewPCG(seed, seed))),
+		}
+
+		failed := make([]bool, 32)
+
+		for i := range failed {
+			_, err := httpc.Fetch[any](t.Context(), http.MethodGet, "http://example.com/", opts...)
+			failed[i] = errors.Is(err, httpc.ErrUnexpectedStatus)
+		}
+
+		return failed
+	}
+
+	first, second := run(1), run(1)
+
+	if diff := cmp.Diff(first, second); diff != "" {
+		t.Errorf("results for same seed differ (-first +second):\n%s", diff)
+	}
+
+	if diff := cmp.Diff(first, run(2)); diff == "" {
+		t.Error("results for different seeds are equal")
+	}
+}
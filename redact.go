@@ -0,0 +1,155 @@
+package httpc
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// redactedValue replaces redacted values.
+const redactedValue = "REDACTED"
+
+// Redactor removes secrets from headers and URLs before they are included in diagnostics, like errors.
+//
+// Names are matched case-insensitively.
+type Redactor struct {
+	// Headers contains the names of headers whose values are redacted.
+	Headers []string
+
+	// QueryParams contains the names of query parameters whose values are redacted.
+	QueryParams []string
+}
+
+// DefaultRedactor returns a new [Redactor] for commonly used headers and query parameters that contain secrets.
+//
+// This is used by [Fetch] if no other [Redactor] was set using [WithRedactor].
+func DefaultRedactor() *Redactor {
+	return &Redactor{
+		Headers: []string{
+			"Authorization",
+			"Cookie",
+			"Proxy-Authorization",
+			"Set-Cookie",
+			"X-Api-Key",
+			"X-Auth-Token",
+			"X-Csrf-Token",
+		},
+		QueryParams: []string{
+			"access_token",
+			"api_key",
+			"apikey",
+			"client_secret",
+			"id_token",
+			"key",
+			"password",
+			"refresh_token",
+			"sig",
+			"signature",
+			"token",
+			"X-Amz-Credential",
+			"X-Amz-Security-Token",
+			"X-Amz-Signature",
+			"X-Goog-Credential",
+			"X-Goog-Signature",
+		},
+	}
+}
+
+// Header returns a copy of h with the values of sensitive headers redacted.
+func (r *Redactor) Header(h http.Header) http.Header {
+	redacted := h.Clone()
+
+	for name, values := range redacted {
+		if !r.sensitiveHeader(name) {
+			continue
+		}
+
+		for i := range values {
+			values[i] = redactedValue
+		}
+	}
+
+	return redacted
+}
+
+// URL returns u as string, with the password and the values of sensitive query parameters redacted.
+//
+// The order of query parameters is kept as is.
+func (r *Redactor) URL(u *url.URL) string {
+	if u.RawQuery == "" {
+		return u.Redacted()
+	}
+
+	redacted := *u
+	redacted.RawQuery = r.query(u.RawQuery)
+
+	return redacted.Redacted()
+}
+
+// RawURL is the same as [Redactor.URL], but takes a URL string.
+//
+// If the string can not be parsed, it is returned with the query removed.
+func (r *Redactor) RawURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		before, _, _ := strings.Cut(rawURL, "?")
+		return before
+	}
+
+	return r.URL(u)
+}
+
+// query redacts the values of sensitive parameters in the given raw query.
+func (r *Redactor) query(rawQuery string) string {
+	parts := strings.Split(rawQuery, "&")
+
+	for i, part := range parts {
+		name, _, _ := strings.Cut(part, "=")
+
+		if unescaped, err := url.QueryUnescape(name); err == nil && r.sensitiveQueryParam(unescaped) {
+			parts[i] = name + "=" + redactedValue
+		}
+	}
+
+	return strings.Join(parts, "&")
+}
+
+func (r *Redactor) sensitiveHeader(name string) bool {
+	return slices.ContainsFunc(r.Headers, func(s string) bool { return strings.EqualFold(s, name) })
+}
+
+func (r *Redactor) sensitiveQueryParam(name string) bool {
+	return slices.ContainsFunc(r.QueryParams, func(s string) bool { return strings.EqualFold(s, name) })
+}
+
+// WithRedactor sets the [Redactor] used to remove secrets from diagnostics, like URLs included in errors.
+//
+// If no Redactor is set, [DefaultRedactor] is used.
+func WithRedactor(r *Redactor) FetchOption {
+	return func(ctx *fetchContext) error {
+		ctx.Redactor = r
+		return nil
+	}
+}
+
+// redactor returns the configured [Redactor] or the default one.
+func (ctx *fetchContext) redactor() *Redactor {
+	if ctx.Redactor == nil {
+		ctx.Redactor = DefaultRedactor()
+	}
+
+	return ctx.Redactor
+}
+
+// formatError redacts the URL of any [*url.Error] in the given error chain and decodes internationalized host names for
+// display.
+func (ctx *fetchContext) formatError(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		urlErr.URL = displayURL(ctx.redactor().RawURL(urlErr.URL))
+	}
+
+	return err
+}
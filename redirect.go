@@ -0,0 +1,158 @@
+package httpc
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrPresignedURLRedirected is returned by [Fetch] when a request using [WithPresignedURL] is redirected.
+//
+// Redirects are not followed for presigned requests, since the signature or headers that are part of it could leak to
+// the redirect target.
+var ErrPresignedURLRedirected = errors.New("github.com/nussjustin/httpc: presigned request redirected")
+
+// defaultSensitiveHeaders contains the headers removed on cross-origin redirects by default.
+var defaultSensitiveHeaders = []string{"Authorization", "Cookie"}
+
+// WithSensitiveHeaders sets the headers that are removed from requests when following a redirect to a different
+// origin, that is a different scheme, host or port.
+//
+// By default, the Authorization and Cookie headers are removed. This includes Authorization headers added by options
+// like [WithTokenSource] or [WithDigestAuth]. Cookies stored in the cookie jar of the client for the redirect target
+// are still sent.
+//
+// Unlike [http.Client], which keeps these headers for redirects to subdomains, all cross-origin redirects are
+// affected. Other headers, like the header used by [WithAPIKey], are kept, unless given here. Calling
+// WithSensitiveHeaders without arguments disables the removal of any headers.
+func WithSensitiveHeaders(names ...string) FetchOption {
+	names = append([]string{}, names...)
+
+	for i := range names {
+		names[i] = http.CanonicalHeaderKey(names[i])
+	}
+
+	return func(ctx *fetchContext) error {
+		ctx.SensitiveHeaders = names
+		return nil
+	}
+}
+
+// applyRedirectPolicy replaces the client with one that removes sensitive headers on cross-origin redirects and
+// rejects redirects of presigned requests.
+//
+// If the request is not made to the origin the options were configured for, sensitive headers are removed from the
+// request itself as well.
+func (ctx *fetchContext) applyRedirectPolicy() {
+	headers := ctx.SensitiveHeaders
+	if headers == nil {
+		headers = defaultSensitiveHeaders
+	}
+
+	if len(headers) == 0 && !ctx.PresignedURL {
+		return
+	}
+
+	origin := ctx.Request.URL
+
+	if ctx.Origin != nil && !sameOrigin(ctx.Request.URL, ctx.Origin) {
+		origin = ctx.Origin
+
+		for _, name := range headers {
+			ctx.Request.Header.Del(name)
+		}
+	}
+
+	ctx.wrapTransport(func(rt http.RoundTripper) http.RoundTripper {
+		return &redirectTransport{next: rt, origin: origin, headers: headers}
+	})
+
+	checkRedirect := ctx.Client.CheckRedirect
+	presigned := ctx.PresignedURL
+	redactor := ctx.redactor()
+
+	ctx.Client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if presigned {
+			return fmt.Errorf("%w to %s", ErrPresignedURLRedirected, redactor.URL(req.URL))
+		}
+
+		if !sameOrigin(req.URL, origin) {
+			for _, name := range headers {
+				req.Header.Del(name)
+			}
+		}
+
+		if checkRedirect != nil {
+			return checkRedirect(req, via)
+		}
+
+		// Same as the default policy of http.Client.
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+
+		return nil
+	}
+}
+
+// redirectTransport removes sensitive headers from requests to other origins.
+//
+// Headers set on the original request are already removed by the CheckRedirect function of the client, but headers
+// can also be added by other transports, for example when adding authentication.
+type redirectTransport struct {
+	next    http.RoundTripper
+	origin  *url.URL
+	headers []string
+}
+
+func (t *redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if sameOrigin(req.URL, t.origin) {
+		return t.next.RoundTrip(req)
+	}
+
+	var stripped *http.Request
+
+	for _, name := range t.headers {
+		// Cookies at this point were added by the cookie jar for the target.
+		if name == "Cookie" || req.Header.Get(name) == "" {
+			continue
+		}
+
+		if stripped == nil {
+			stripped = req.Clone(req.Context())
+		}
+
+		stripped.Header.Del(name)
+	}
+
+	if stripped != nil {
+		req = stripped
+	}
+
+	return t.next.RoundTrip(req)
+}
+
+// sameOrigin reports whether both URLs have the same scheme, host and port.
+func sameOrigin(a, b *url.URL) bool {
+	return strings.EqualFold(a.Scheme, b.Scheme) &&
+		strings.EqualFold(a.Hostname(), b.Hostname()) &&
+		urlPort(a) == urlPort(b)
+}
+
+// urlPort returns the port of u, or the default port of the scheme if none is set.
+func urlPort(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return port
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "http":
+		return "80"
+	case "https":
+		return "443"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,110 @@
+package httpc_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nussjustin/httpc"
+)
+
+func TestSensitiveHeaders(t *testing.T) {
+	var got []string
+
+	record := func(name string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = append(got, name+": "+r.Header.Get("Authorization")+"|"+r.Header.Get("Cookie")+"|"+
+				r.Header.Get("X-Api-Key"))
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+
+	other := httptest.NewServer(record("other"))
+	t.Cleanup(other.Close)
+
+	mux := http.NewServeMux()
+	mux.Handle("/same", record("same"))
+	mux.Handle("/redirect-same", http.RedirectHandler("/same", http.StatusFound))
+	mux.Handle("/redirect-other", http.RedirectHandler(other.URL, http.StatusFound))
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	tokens := httpc.TokenSourceFunc(func(context.Context) (string, error) {
+		return "token", nil
+	})
+
+	testCases := []struct {
+		Name    string
+		Options []httpc.FetchOption
+		Want    []string
+	}{
+		{
+			Name: "Default",
+			Want: []string{
+				"same: Basic dXNlcjpwYXNz|a=b|key",
+				"other: ||key",
+			},
+		},
+		{
+			Name:    "Custom",
+			Options: []httpc.FetchOption{httpc.WithSensitiveHeaders("Authorization", "x-api-key")},
+			Want: []string{
+				"same: Basic dXNlcjpwYXNz|a=b|key",
+				"other: |a=b|",
+			},
+		},
+		{
+			Name:    "Disabled",
+			Options: []httpc.FetchOption{httpc.WithSensitiveHeaders()},
+			Want: []string{
+				"same: Basic dXNlcjpwYXNz|a=b|key",
+				"other: Basic dXNlcjpwYXNz|a=b|key",
+			},
+		},
+		{
+			Name:    "Token source",
+			Options: []httpc.FetchOption{httpc.WithTokenSource(tokens)},
+			Want: []string{
+				"same: Bearer token|a=b|key",
+				"other: ||key",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			got = nil
+
+			opts := append([]httpc.FetchOption{
+				httpc.WithHeader("Authorization", "Basic dXNlcjpwYXNz"),
+				httpc.WithHeader("Cookie", "a=b"),
+				httpc.WithHeader("X-API-Key", "key"),
+			}, testCase.Options...)
+
+			for _, path := range []string{"/redirect-same", "/redirect-other"} {
+				if err := httpc.New(opts...).Fetch(t.Context(), http.MethodGet, srv.URL+path, nil); err != nil {
+					t.Fatalf("got error %v", err)
+				}
+			}
+
+			if diff := cmp.Diff(testCase.Want, got); diff != "" {
+				t.Errorf("requests mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWithPresignedURL_Redirect(t *testing.T) {
+	srv := httptest.NewServer(http.RedirectHandler("/elsewhere", http.StatusFound))
+	t.Cleanup(srv.Close)
+
+	err := httpc.New(httpc.WithPresignedURL()).Fetch(t.Context(), http.MethodGet, srv.URL+"/?X-Amz-Signature=abc", nil)
+	if !errors.Is(err, httpc.ErrPresignedURLRedirected) {
+		t.Errorf("got error %v, want %v", err, httpc.ErrPresignedURLRedirected)
+	}
+}
@@ -0,0 +1,183 @@
+package httpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy configures how failed requests are retried by [WithRetry].
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times a request is sent, including the first attempt.
+	MaxAttempts int
+
+	// Backoff returns the delay before the next attempt, given the number of the last attempt, starting at 1.
+	//
+	// If nil, an exponential backoff starting at 100 milliseconds with full jitter and a maximum of 10 seconds is used.
+	Backoff func(attempt int) time.Duration
+
+	// Retryable reports whether a request should be retried, given the response or error of the last attempt.
+	//
+	// If nil, requests using idempotent methods or with an Idempotency-Key header are retried on network errors as
+	// well as on 429 Too Many Requests, 502 Bad Gateway, 503 Service Unavailable and 504 Gateway Timeout responses.
+	Retryable func(req *http.Request, resp *http.Response, err error) bool
+
+	// MaxRetryAfter is the maximum delay requested by a Retry-After header that is honored.
+	//
+	// 429 Too Many Requests and 503 Service Unavailable responses with a Retry-After header are retried after the
+	// requested delay, instead of the delay returned by Backoff. If the requested delay exceeds MaxRetryAfter, the
+	// response is returned without retrying. If zero, a maximum of one minute is used.
+	MaxRetryAfter time.Duration
+}
+
+// defaultMaxRetryAfter is the default value of [RetryPolicy.MaxRetryAfter].
+const defaultMaxRetryAfter = time.Minute
+
+// WithRetry causes failed requests to be retried according to the given [RetryPolicy].
+//
+// Requests with a body can only be retried if [http.Request.GetBody] is set.
+//
+// A request is never retried if the delay before the next attempt would exceed the deadline of the request context.
+// Instead, the last response or error is returned.
+func WithRetry(policy RetryPolicy) FetchOption {
+	if policy.MaxAttempts < 1 {
+		panic(errors.New("MaxAttempts must be at least 1"))
+	}
+
+	return func(ctx *fetchContext) error {
+		ctx.Retry = &policy
+		return nil
+	}
+}
+
+// doWithRetry sends the request using [fetchContext.do], retrying it according to the configured [RetryPolicy].
+func (ctx *fetchContext) doWithRetry() (*http.Response, error) {
+	resp, err := ctx.do()
+
+	if ctx.Retry == nil {
+		return resp, err
+	}
+
+	for ctx.Attempt < ctx.Retry.MaxAttempts && ctx.retryable(resp, err) {
+		delay, ok := ctx.retryDelay(resp)
+		if !ok {
+			break
+		}
+
+		retry, ok := rewindRequest(ctx.Request)
+		if !ok {
+			break
+		}
+
+		if ctx.Hooks.OnRetry != nil {
+			event := ctx.hookEvent(resp, err)
+			event.Delay = delay
+
+			ctx.Hooks.OnRetry(event)
+		}
+
+		if resp != nil {
+			discardBody(resp, nil)
+		}
+
+		if err := sleep(retry, delay); err != nil {
+			closeRequestBody(retry)
+			return nil, err
+		}
+
+		ctx.Request = retry
+
+		resp, err = ctx.do()
+	}
+
+	return resp, err
+}
+
+// retryable reports whether the request should be retried given the result of the last attempt.
+func (ctx *fetchContext) retryable(resp *http.Response, err error) bool {
+	if ctx.Retry.Retryable != nil {
+		return ctx.Retry.Retryable(ctx.Request, resp, err)
+	}
+
+	switch ctx.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+	default:
+		if ctx.Request.Header.Get("Idempotency-Key") == "" {
+			return false
+		}
+	}
+
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryDelay returns the delay before the next attempt.
+//
+// If the request should not be retried, because the delay is too long, false is returned.
+func (ctx *fetchContext) retryDelay(resp *http.Response) (time.Duration, bool) {
+	var delay time.Duration
+
+	if retryAfter, ok := parseRetryAfter(resp, time.Now()); ok {
+		maxRetryAfter := ctx.Retry.MaxRetryAfter
+		if maxRetryAfter == 0 {
+			maxRetryAfter = defaultMaxRetryAfter
+		}
+
+		if retryAfter > maxRetryAfter {
+			return 0, false
+		}
+
+		delay = retryAfter
+	} else if ctx.Retry.Backoff != nil {
+		delay = ctx.Retry.Backoff(ctx.Attempt)
+	} else {
+		delay = ctx.Rand.Duration(min(100*time.Millisecond<<min(ctx.Attempt-1, 16), 10*time.Second) + 1)
+	}
+
+	if deadline, ok := ctx.Request.Context().Deadline(); ok && time.Until(deadline) < delay {
+		return 0, false
+	}
+
+	return delay, true
+}
+
+// parseRetryAfter returns the delay requested by the Retry-After header of 429 and 503 responses.
+//
+// The header can contain either a number of seconds or an HTTP date. See RFC 9110, Section 10.2.3.
+func parseRetryAfter(resp *http.Response, now time.Time) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+	default:
+		return 0, false
+	}
+
+	value := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.ParseUint(value, 10, 32); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(0, date.Sub(now)), true
+	}
+
+	return 0, false
+}
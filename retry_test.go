@@ -0,0 +1,277 @@
+package httpc_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nussjustin/httpc"
+)
+
+func TestWithRetry(t *testing.T) {
+	type response struct {
+		Status     int
+		RetryAfter string
+	}
+
+	noBackoff := func(int) time.Duration { return 0 }
+
+	testCases := []struct {
+		Name      string
+		Method    string
+		Header    http.Header
+		Policy    httpc.RetryPolicy
+		Timeout   time.Duration
+		Responses []response
+		Attempts  int
+		Status    int
+	}{
+		{
+			Name:      "Success",
+			Policy:    httpc.RetryPolicy{MaxAttempts: 3, Backoff: noBackoff},
+			Responses: []response{{Status: http.StatusOK}},
+			Attempts:  1,
+			Status:    http.StatusOK,
+		},
+		{
+			Name:   "Retried",
+			Policy: httpc.RetryPolicy{MaxAttempts: 3, Backoff: noBackoff},
+			Responses: []response{
+				{Status: http.StatusServiceUnavailable},
+				{Status: http.StatusBadGateway},
+				{Status: http.StatusOK},
+			},
+			Attempts: 3,
+			Status:   http.StatusOK,
+		},
+		{
+			Name:   "Max attempts",
+			Policy: httpc.RetryPolicy{MaxAttempts: 2, Backoff: noBackoff},
+			Responses: []response{
+				{Status: http.StatusServiceUnavailable},
+				{Status: http.StatusServiceUnavailable},
+				{Status: http.StatusOK},
+			},
+			Attempts: 2,
+			Status:   http.StatusServiceUnavailable,
+		},
+		{
+			Name:   "Not retryable status",
+			Policy: httpc.RetryPolicy{MaxAttempts: 3, Backoff: noBackoff},
+			Responses: []response{
+				{Status: http.StatusInternalServerError},
+				{Status: http.StatusOK},
+			},
+			Attempts: 1,
+			Status:   http.StatusInternalServerError,
+		},
+		{
+			Name:   "Not idempotent",
+			Method: http.MethodPost,
+			Policy: httpc.RetryPolicy{MaxAttempts: 3, Backoff: noBackoff},
+			Responses: []response{
+				{Status: http.StatusServiceUnavailable},
+				{Status: http.StatusOK},
+			},
+			Attempts: 1,
+			Status:   http.StatusServiceUnavailable,
+		},
+		{
+			Name:   "Idempotency key",
+			Method: http.MethodPost,
+			Header: http.Header{"Idempotency-Key": []string{"key"}},
+			Policy: httpc.RetryPolicy{MaxAttempts: 3, Backoff: noBackoff},
+			Responses: []response{
+				{Status: http.StatusServiceUnavailable},
+				{Status: http.StatusOK},
+			},
+			Attempts: 2,
+			Status:   http.StatusOK,
+		},
+		{
+			Name: "Retry-After seconds",
+			Policy: httpc.RetryPolicy{MaxAttempts: 3, Backoff: func(int) time.Duration {
+				return time.Hour
+			}},
+			Responses: []response{
+				{Status: http.StatusTooManyRequests, RetryAfter: "0"},
+				{Status: http.StatusOK},
+			},
+			Attempts: 2,
+			Status:   http.StatusOK,
+		},
+		{
+			Name: "Retry-After date",
+			Policy: httpc.RetryPolicy{MaxAttempts: 3, Backoff: func(int) time.Duration {
+				return time.Hour
+			}},
+			Responses: []response{
+				{Status: http.StatusServiceUnavailable, RetryAfter: "Sun, 06 Nov 1994 08:49:37 GMT"},
+				{Status: http.StatusOK},
+			},
+			Attempts: 2,
+			Status:   http.StatusOK,
+		},
+		{
+			Name:   "Retry-After exceeds maximum",
+			Policy: httpc.RetryPolicy{MaxAttempts: 3, Backoff: noBackoff, MaxRetryAfter: time.Second},
+			Responses: []response{
+				{Status: http.StatusTooManyRequests, RetryAfter: "2"},
+				{Status: http.StatusOK},
+			},
+			Attempts: 1,
+			Status:   http.StatusTooManyRequests,
+		},
+		{
+			Name:    "Retry-After exceeds deadline",
+			Policy:  httpc.RetryPolicy{MaxAttempts: 3, Backoff: noBackoff, MaxRetryAfter: time.Hour},
+			Timeout: time.Minute,
+			Responses: []response{
+				{Status: http.StatusTooManyRequests, RetryAfter: "120"},
+				{Status: http.StatusOK},
+			},
+			Attempts: 1,
+			Status:   http.StatusTooManyRequests,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			var attempts int
+
+			client := &http.Client{
+				Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+					r := testCase.Responses[attempts]
+					attempts++
+
+					if body, _ := io.ReadAll(req.Body); string(body) != "body" {
+						t.Errorf("attempt %d: got body %q, want %q", attempts, body, "body")
+					}
+
+					header := make(http.Header)
+					if r.RetryAfter != "" {
+						header.Set("Retry-After", r.RetryAfter)
+					}
+
+					return &http.Response{
+						StatusCode: r.Status,
+						Header:     header,
+						Body:       http.NoBody,
+						Request:    req,
+					}, nil
+				}),
+			}
+
+			ctx := t.Context()
+
+			if testCase.Timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, testCase.Timeout)
+				defer cancel()
+			}
+
+			method := testCase.Method
+			if method == "" {
+				method = http.MethodGet
+			}
+
+			opts := []httpc.FetchOption{
+				httpc.WithClient(client),
+				httpc.WithRetry(testCase.Policy),
+				httpc.WithBody(strings.NewReader("body")),
+				httpc.WithHandler(httpc.ReadCloserHandler()),
+			}
+
+			for name, values := range testCase.Header {
+				opts = append(opts, httpc.WithHeader(name, values[0]))
+			}
+
+			var stats httpc.Stats
+
+			opts = append(opts, httpc.WithStats(&stats))
+
+			body, resp, err := httpc.FetchWithResponse[io.ReadCloser](ctx, method, "https://example.com/", opts...)
+			if err != nil {
+				t.Fatalf("got error %v", err)
+			}
+			_ = body.Close()
+
+			if got, want := resp.StatusCode, testCase.Status; got != want {
+				t.Errorf("got status %d, want %d", got, want)
+			}
+
+			if got, want := attempts, testCase.Attempts; got != want {
+				t.Errorf("got %d attempts, want %d", got, want)
+			}
+
+			if got, want := stats.Attempts, testCase.Attempts; got != want {
+				t.Errorf("got %d attempts in stats, want %d", got, want)
+			}
+
+			if got, want := stats.RequestBytes, int64(4*testCase.Attempts); got != want {
+				t.Errorf("got %d request bytes, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestWithRetry_Errors(t *testing.T) {
+	var attempts int
+
+	errNetwork := errors.New("network error")
+
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			attempts++
+			return nil, errNetwork
+		}),
+	}
+
+	_, err := httpc.Fetch[any](t.Context(), http.MethodGet, "https://example.com/",
+		httpc.WithClient(client),
+		httpc.WithRetry(httpc.RetryPolicy{MaxAttempts: 3, Backoff: func(int) time.Duration { return 0 }}))
+	if !errors.Is(err, errNetwork) {
+		t.Errorf("got error %v, want %v", err, errNetwork)
+	}
+
+	if attempts != 3 {
+		t.Errorf("got %d attempts, want 3", attempts)
+	}
+}
+
+func TestWithRetry_Canceled(t *testing.T) {
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusServiceUnavailable,
+				Body:       http.NoBody,
+				Request:    req,
+			}, nil
+		}),
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	_, err := httpc.Fetch[any](ctx, http.MethodGet, "https://example.com/",
+		httpc.WithClient(client),
+		httpc.WithRetry(httpc.RetryPolicy{MaxAttempts: 3, Backoff: func(int) time.Duration { return time.Hour }}))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestWithRetry_Panic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic")
+		}
+	}()
+
+	httpc.WithRetry(httpc.RetryPolicy{})
+}
@@ -0,0 +1,220 @@
+package httpc
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Priority specifies the priority of a request scheduled by a [Scheduler].
+//
+// Requests with a higher priority are admitted before requests with a lower priority.
+type Priority int
+
+const (
+	// PriorityLow can be used for background requests that should not delay other requests.
+	PriorityLow Priority = -1
+
+	// PriorityNormal is the default priority.
+	PriorityNormal Priority = 0
+
+	// PriorityHigh can be used for requests that should be admitted before all other requests, for example for
+	// interactive requests.
+	PriorityHigh Priority = 1
+)
+
+// Scheduler limits the number of concurrently executed requests and admits waiting requests by priority.
+//
+// When the limit is reached, new requests wait until a running request finishes. Waiting requests are admitted in
+// order of their [Priority], with requests of the same priority being admitted in the order they arrived.
+//
+// A Scheduler can be shared between multiple requests using [WithScheduler], for example by passing the option to
+// [New]. The zero value is ready to use and does not limit requests.
+//
+// A Scheduler must not be copied after first use.
+type Scheduler struct {
+	// MaxInFlight is the maximum number of concurrently executed requests.
+	//
+	// If MaxInFlight is zero or negative, the number of requests is not limited.
+	MaxInFlight int
+
+	// MaxWaiting is the maximum number of requests that can wait to be admitted.
+	//
+	// Requests that would exceed this limit fail immediately with [ErrQueueFull]. If MaxWaiting is zero or negative,
+	// the number of waiting requests is not limited.
+	MaxWaiting int
+
+	// MaxWait is the maximum time a request waits to be admitted.
+	//
+	// Requests that are not admitted in time fail with [ErrQueueTimeout]. If MaxWait is zero or negative, requests
+	// wait until their context is done.
+	MaxWait time.Duration
+
+	mu      sync.Mutex
+	active  int
+	seq     uint64
+	waiting schedulerQueue
+}
+
+// ErrQueueFull is returned by [Fetch] when a request could not be queued by a [Scheduler], because the maximum number
+// of waiting requests was reached.
+var ErrQueueFull = errors.New("github.com/nussjustin/httpc: queue full")
+
+// ErrQueueTimeout is returned by [Fetch] when a request was not admitted by a [Scheduler] within the configured maximum
+// wait time.
+var ErrQueueTimeout = errors.New("github.com/nussjustin/httpc: queue timeout")
+
+// Waiting returns the number of requests currently waiting to be admitted.
+func (s *Scheduler) Waiting() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.waiting)
+}
+
+// acquire blocks until a request with the given priority can be executed or the context is done.
+//
+// If acquire returns nil, release must be called once the request is done.
+func (s *Scheduler) acquire(ctx context.Context, p Priority) error {
+	s.mu.Lock()
+
+	if s.MaxInFlight <= 0 || (s.active < s.MaxInFlight && len(s.waiting) == 0) {
+		s.active++
+		s.mu.Unlock()
+		return nil
+	}
+
+	if s.MaxWaiting > 0 && len(s.waiting) >= s.MaxWaiting {
+		s.mu.Unlock()
+		return ErrQueueFull
+	}
+
+	w := &schedulerWaiter{priority: p, seq: s.seq, ready: make(chan struct{})}
+	s.seq++
+
+	heap.Push(&s.waiting, w)
+
+	s.mu.Unlock()
+
+	var timeout <-chan time.Time
+
+	if s.MaxWait > 0 {
+		timer := time.NewTimer(s.MaxWait)
+		defer timer.Stop()
+
+		timeout = timer.C
+	}
+
+	var err error
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		err = context.Cause(ctx)
+	case <-timeout:
+		err = ErrQueueTimeout
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		// The request was admitted concurrently, so pass the slot on to the next request.
+		s.releaseLocked()
+	default:
+		heap.Remove(&s.waiting, w.index)
+	}
+
+	return err
+}
+
+// release marks a request as done and admits the next waiting request, if any.
+func (s *Scheduler) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.releaseLocked()
+}
+
+func (s *Scheduler) releaseLocked() {
+	if len(s.waiting) == 0 {
+		s.active--
+		return
+	}
+
+	w := heap.Pop(&s.waiting).(*schedulerWaiter)
+	close(w.ready)
+}
+
+type schedulerWaiter struct {
+	priority Priority
+	seq      uint64
+	index    int
+	ready    chan struct{}
+}
+
+// schedulerQueue implements [heap.Interface] ordering waiters by priority and arrival.
+type schedulerQueue []*schedulerWaiter
+
+func (q schedulerQueue) Len() int {
+	return len(q)
+}
+
+func (q schedulerQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q schedulerQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *schedulerQueue) Push(x any) {
+	w := x.(*schedulerWaiter)
+	w.index = len(*q)
+	*q = append(*q, w)
+}
+
+func (q *schedulerQueue) Pop() any {
+	old := *q
+	w := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	return w
+}
+
+// WithScheduler causes requests to be admitted by the given [Scheduler].
+func WithScheduler(s *Scheduler) FetchOption {
+	return func(ctx *fetchContext) error {
+		ctx.Scheduler = s
+		return nil
+	}
+}
+
+// WithMaxInFlight limits the number of concurrently executed requests.
+//
+// This is a shortcut for WithScheduler(&Scheduler{MaxInFlight: n}). Since the limit is only shared by requests using
+// the same option, the returned option should be created once and reused, for example by passing it to [New].
+//
+// To configure a bounded queue for waiting requests, use [WithScheduler] with a custom [Scheduler].
+func WithMaxInFlight(n int) FetchOption {
+	return WithScheduler(&Scheduler{MaxInFlight: n})
+}
+
+// WithPriority sets the priority used when the request is admitted by a [Scheduler].
+//
+// Defaults to [PriorityNormal].
+func WithPriority(p Priority) FetchOption {
+	return func(ctx *fetchContext) error {
+		ctx.Priority = p
+		return nil
+	}
+}
@@ -0,0 +1,255 @@
+package httpc_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nussjustin/httpc"
+)
+
+func waitForQueue(tb testing.TB, s *httpc.Scheduler, n int) {
+	tb.Helper()
+
+	for s.Waiting() != n {
+		select {
+		case <-tb.Context().Done():
+			tb.Fatalf("timeout waiting for %d queued requests", n)
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		order []string
+	)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path == "/blocking" {
+				close(started)
+				<-release
+			}
+
+			mu.Lock()
+			order = append(order, req.URL.Path)
+			mu.Unlock()
+
+			return &http.Response{
+				StatusCode: http.StatusNoContent,
+				Header:     make(http.Header),
+				Body:       http.NoBody,
+				Request:    req,
+			}, nil
+		}),
+	}
+
+	s := &httpc.Scheduler{MaxInFlight: 1}
+
+	var wg sync.WaitGroup
+
+	fetch := func(path string, opts ...httpc.FetchOption) {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			opts = append([]httpc.FetchOption{httpc.WithClient(client), httpc.WithScheduler(s)}, opts...)
+
+			if _, err := httpc.Fetch[any](t.Context(), http.MethodGet, path, opts...); err != nil {
+				t.Errorf("failed to fetch %s: %v", path, err)
+			}
+		}()
+	}
+
+	fetch("/blocking")
+	<-started
+
+	fetch("/low-1", httpc.WithPriority(httpc.PriorityLow))
+	waitForQueue(t, s, 1)
+
+	fetch("/normal-1")
+	waitForQueue(t, s, 2)
+
+	fetch("/high", httpc.WithPriority(httpc.PriorityHigh))
+	waitForQueue(t, s, 3)
+
+	fetch("/low-2", httpc.WithPriority(httpc.PriorityLow))
+	waitForQueue(t, s, 4)
+
+	fetch("/normal-2", httpc.WithPriority(httpc.PriorityNormal))
+	waitForQueue(t, s, 5)
+
+	close(release)
+	wg.Wait()
+
+	want := []string{"/blocking", "/high", "/normal-1", "/normal-2", "/low-1", "/low-2"}
+
+	if diff := cmp.Diff(want, order); diff != "" {
+		t.Errorf("order mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestScheduler_ContextCanceled(t *testing.T) {
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			started <- struct{}{}
+			<-release
+
+			return &http.Response{
+				StatusCode: http.StatusNoContent,
+				Header:     make(http.Header),
+				Body:       http.NoBody,
+				Request:    req,
+			}, nil
+		}),
+	}
+
+	s := &httpc.Scheduler{MaxInFlight: 1}
+
+	errs := make(chan error, 1)
+
+	go func() {
+		_, err := httpc.Fetch[any](t.Context(), http.MethodGet, "/", httpc.WithClient(client), httpc.WithScheduler(s))
+		errs <- err
+	}()
+
+	<-started
+
+	ctx, cancel := context.WithCancel(t.Context())
+
+	go func() {
+		waitForQueue(t, s, 1)
+		cancel()
+	}()
+
+	_, err := httpc.Fetch[any](ctx, http.MethodGet, "/", httpc.WithClient(client), httpc.WithScheduler(s))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, want %v", err, context.Canceled)
+	}
+
+	if got := s.Waiting(); got != 0 {
+		t.Errorf("got %d waiting requests, want 0", got)
+	}
+
+	close(release)
+
+	if err := <-errs; err != nil {
+		t.Errorf("failed to fetch: %v", err)
+	}
+}
+
+func TestScheduler_Queue(t *testing.T) {
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			started <- struct{}{}
+			<-release
+
+			return &http.Response{
+				StatusCode: http.StatusNoContent,
+				Header:     make(http.Header),
+				Body:       http.NoBody,
+				Request:    req,
+			}, nil
+		}),
+	}
+
+	s := &httpc.Scheduler{MaxInFlight: 1, MaxWaiting: 1, MaxWait: 50 * time.Millisecond}
+
+	fetch := func() error {
+		_, err := httpc.Fetch[any](t.Context(), http.MethodGet, "/", httpc.WithClient(client), httpc.WithScheduler(s))
+		return err
+	}
+
+	errs := make(chan error, 2)
+
+	go func() { errs <- fetch() }()
+
+	<-started
+
+	go func() { errs <- fetch() }()
+
+	waitForQueue(t, s, 1)
+
+	if err := fetch(); !errors.Is(err, httpc.ErrQueueFull) {
+		t.Errorf("got error %v, want %v", err, httpc.ErrQueueFull)
+	}
+
+	if err := <-errs; !errors.Is(err, httpc.ErrQueueTimeout) {
+		t.Errorf("got error %v, want %v", err, httpc.ErrQueueTimeout)
+	}
+
+	close(release)
+
+	if err := <-errs; err != nil {
+		t.Errorf("failed to fetch: %v", err)
+	}
+}
+
+func TestWithMaxInFlight(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		running int
+		maxSeen int
+	)
+
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			running++
+			maxSeen = max(maxSeen, running)
+			mu.Unlock()
+
+			time.Sleep(time.Millisecond)
+
+			mu.Lock()
+			running--
+			mu.Unlock()
+
+			return &http.Response{
+				StatusCode: http.StatusNoContent,
+				Header:     make(http.Header),
+				Body:       http.NoBody,
+				Request:    req,
+			}, nil
+		}),
+	}
+
+	c := httpc.New(httpc.WithClient(client), httpc.WithMaxInFlight(2))
+
+	var wg sync.WaitGroup
+
+	for range 10 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if err := c.Fetch(t.Context(), http.MethodGet, "/", nil); err != nil {
+				t.Errorf("failed to fetch: %v", err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if maxSeen != 2 {
+		t.Errorf("got %d concurrent requests, want 2", maxSeen)
+	}
+}
@@ -0,0 +1,194 @@
+package httpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/cookiejar"
+	"slices"
+	"sync"
+)
+
+// NewSessionClient returns a new [Client] for APIs that use a login call to create a session.
+//
+// Before the first request, login is called with the returned client to create a session. Cookies set by responses are
+// stored in a cookie jar, unless the underlying [http.Client] already has one, and tokens returned by the login call
+// can be stored using [SetSessionHeader].
+//
+// If a request is answered with 401 Unauthorized or 419, login is called again and the request is sent again once.
+// Concurrent requests share a single login call.
+//
+// Requests made by login must use the context passed to it. These requests are not authenticated and are never
+// retried.
+//
+// Requests with a body can only be sent again if [http.Request.GetBody] is set. Otherwise, the response is returned
+// as is.
+func NewSessionClient(login func(ctx context.Context, c *Client) error, opts ...FetchOption) *Client {
+	jar, _ := cookiejar.New(nil)
+
+	s := &session{login: login, jar: jar}
+
+	c := New(append(slices.Clip(opts), func(ctx *fetchContext) error {
+		ctx.Session = s
+		return nil
+	})...)
+
+	s.client = c
+
+	return c
+}
+
+// SetSessionHeader sets a header that is added to all requests of a session.
+//
+// SetSessionHeader must be called with the context passed to the login function given to [NewSessionClient]. Otherwise,
+// it does nothing.
+func SetSessionHeader(ctx context.Context, name, value string) {
+	s, ok := ctx.Value(sessionLoginKey{}).(*session)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.header.Set(name, value)
+}
+
+// sessionLoginKey marks contexts of requests made by the login function of a session.
+type sessionLoginKey struct{}
+
+type session struct {
+	login  func(ctx context.Context, c *Client) error
+	jar    http.CookieJar
+	client *Client
+
+	// loginMu serializes calls to login.
+	loginMu sync.Mutex
+
+	mu         sync.Mutex
+	generation int
+	header     http.Header
+}
+
+// state returns the current session generation and headers, or ok=false if there is no session yet.
+func (s *session) state() (generation int, header http.Header, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.generation, s.header, s.header != nil
+}
+
+// relogin creates a new session, unless the session was already replaced since the given generation.
+func (s *session) relogin(ctx context.Context, generation int) error {
+	s.loginMu.Lock()
+	defer s.loginMu.Unlock()
+
+	if current, _, ok := s.state(); ok && current != generation {
+		return nil
+	}
+
+	s.mu.Lock()
+	s.header = nil
+	s.mu.Unlock()
+
+	// Headers set by login are collected separately, so that requests made by login are sent without headers.
+	pending := &session{header: make(http.Header)}
+
+	if err := s.login(context.WithValue(ctx, sessionLoginKey{}, pending), s.client); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.generation++
+	s.header = pending.header
+	s.mu.Unlock()
+
+	return nil
+}
+
+// applySession replaces the client with one that authenticates requests using the session, if configured.
+func (ctx *fetchContext) applySession() {
+	if ctx.Session == nil || ctx.PresignedURL {
+		return
+	}
+
+	jar := ctx.Client.Jar
+	if jar == nil {
+		jar = ctx.Session.jar
+	}
+
+	ctx.wrapTransport(func(rt http.RoundTripper) http.RoundTripper {
+		return &sessionTransport{next: rt, session: ctx.Session, jar: jar}
+	})
+
+	ctx.Client.Jar = jar
+}
+
+type sessionTransport struct {
+	next    http.RoundTripper
+	session *session
+	jar     http.CookieJar
+}
+
+func (t *sessionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Context().Value(sessionLoginKey{}) != nil {
+		return t.next.RoundTrip(req)
+	}
+
+	first := req.Clone(req.Context())
+
+	generation, header, ok := t.session.state()
+	if !ok {
+		if err := t.session.relogin(req.Context(), -1); err != nil {
+			closeRequestBody(req)
+			return nil, err
+		}
+
+		generation, header, _ = t.session.state()
+
+		t.replaceCookies(first)
+	}
+
+	resp, err := t.next.RoundTrip(withSessionHeader(first, header))
+	if err != nil || (resp.StatusCode != http.StatusUnauthorized && resp.StatusCode != 419) {
+		return resp, err
+	}
+
+	retry, ok := rewindRequest(req)
+	if !ok {
+		return resp, nil
+	}
+
+	discardBody(resp, nil)
+
+	if err := t.session.relogin(req.Context(), generation); err != nil {
+		closeRequestBody(retry)
+		return nil, err
+	}
+
+	_, header, _ = t.session.state()
+
+	t.replaceCookies(retry)
+
+	return t.next.RoundTrip(withSessionHeader(retry, header))
+}
+
+// replaceCookies replaces the cookies of req with the cookies from the jar.
+//
+// Cookies are added by the client before the request reaches the transport, so after a login they must be replaced
+// with the new session cookies.
+func (t *sessionTransport) replaceCookies(req *http.Request) {
+	req.Header.Del("Cookie")
+
+	for _, cookie := range t.jar.Cookies(req.URL) {
+		req.AddCookie(cookie)
+	}
+}
+
+// withSessionHeader adds the given session headers to req, which must be a copy of the original request.
+func withSessionHeader(req *http.Request, header http.Header) *http.Request {
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	return req
+}
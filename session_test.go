@@ -0,0 +1,118 @@
+package httpc_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/nussjustin/httpc"
+)
+
+func TestNewSessionClient(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		session string
+		logins  atomic.Int64
+	)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /login", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		session = "session-" + strconv.FormatInt(logins.Add(1), 10)
+		mu.Unlock()
+
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: session, Path: "/"})
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"token":"token-` + session + `"}`))
+	})
+	mux.HandleFunc("GET /api", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		cookie, err := r.Cookie("session")
+		if err != nil || cookie.Value != session || r.Header.Get("X-Token") != "token-"+session {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	login := func(ctx context.Context, c *httpc.Client) error {
+		var resp struct {
+			Token string `json:"token"`
+		}
+
+		if err := c.Fetch(ctx, http.MethodPost, srv.URL+"/login", &resp); err != nil {
+			return err
+		}
+
+		httpc.SetSessionHeader(ctx, "X-Token", resp.Token)
+		return nil
+	}
+
+	c := httpc.NewSessionClient(login)
+
+	fetchAll := func() {
+		var wg sync.WaitGroup
+
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				if err := c.Fetch(t.Context(), http.MethodGet, srv.URL+"/api", nil); err != nil {
+					t.Errorf("got error %v", err)
+				}
+			}()
+		}
+
+		wg.Wait()
+	}
+
+	fetchAll()
+
+	if got, want := logins.Load(), int64(1); got != want {
+		t.Errorf("got %d logins, want %d", got, want)
+	}
+
+	// Expire the session
+	mu.Lock()
+	session = "expired"
+	mu.Unlock()
+
+	fetchAll()
+
+	if got, want := logins.Load(), int64(2); got != want {
+		t.Errorf("got %d logins, want %d", got, want)
+	}
+}
+
+func TestNewSessionClient_LoginError(t *testing.T) {
+	errLogin := errors.New("login failed")
+
+	var calls atomic.Int64
+
+	c := httpc.NewSessionClient(func(context.Context, *httpc.Client) error {
+		calls.Add(1)
+		return errLogin
+	})
+
+	for range 2 {
+		if err := c.Fetch(t.Context(), http.MethodGet, "http://example.com/", nil); !errors.Is(err, errLogin) {
+			t.Errorf("got error %v, want %v", err, errLogin)
+		}
+	}
+
+	if got, want := calls.Load(), int64(2); got != want {
+		t.Errorf("got %d login calls, want %d", got, want)
+	}
+}
@@ -0,0 +1,104 @@
+package httpc
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/nussjustin/problem"
+)
+
+// WithErrorBodySnippet causes errors returned by the response handler to include up to the first n bytes of the
+// response body that were read by the handler.
+//
+// Text is included as quoted string, binary data is hex encoded. Errors returned for RFC 9457 problem details are not
+// modified.
+//
+// The returned error wraps the original error.
+func WithErrorBodySnippet(n int) FetchOption {
+	if n < 0 {
+		panic(errors.New("n must not be negative"))
+	}
+
+	return func(ctx *fetchContext) error {
+		ctx.ErrorBodySnippet = n
+		return nil
+	}
+}
+
+// recordSnippet wraps the response body so that the first bytes read are recorded for use in error messages.
+func (ctx *fetchContext) recordSnippet(resp *http.Response) *snippetReadCloser {
+	s := &snippetReadCloser{ReadCloser: resp.Body, buf: make([]byte, 0, ctx.ErrorBodySnippet)}
+
+	// Spooled bodies can be read multiple times, so the snippet can be taken directly, keeping the body seekable.
+	if b, ok := resp.Body.(*spooledBody); ok {
+		n, _ := b.ReadAt(s.buf[:cap(s.buf)], 0)
+		s.buf = s.buf[:n]
+		return s
+	}
+
+	resp.Body = s
+	return s
+}
+
+type snippetReadCloser struct {
+	io.ReadCloser
+
+	buf []byte
+}
+
+func (s *snippetReadCloser) Read(p []byte) (int, error) {
+	n, err := s.ReadCloser.Read(p)
+	if free := cap(s.buf) - len(s.buf); free > 0 {
+		s.buf = append(s.buf, p[:min(n, free)]...)
+	}
+	return n, err
+}
+
+// wrap adds the recorded snippet to the given error.
+func (s *snippetReadCloser) wrap(err error) error {
+	if len(s.buf) == 0 || errors.As(err, new(*problem.Details)) {
+		return err
+	}
+
+	return fmt.Errorf("%w (response body: %s)", err, formatSnippet(s.buf))
+}
+
+// formatSnippet returns a quoted string for text and a hex encoded string for binary data.
+func formatSnippet(b []byte) string {
+	if !isText(b) {
+		return "0x" + hex.EncodeToString(b)
+	}
+
+	return strconv.Quote(string(b))
+}
+
+// isText reports whether b contains valid UTF-8 without control characters other than white space.
+func isText(b []byte) bool {
+	// The snippet may end in the middle of a multibyte character.
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(b[i]) {
+			if !utf8.FullRune(b[i:]) {
+				b = b[:i]
+			}
+			break
+		}
+	}
+
+	if !utf8.Valid(b) {
+		return false
+	}
+
+	for _, r := range string(b) {
+		if unicode.IsControl(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,83 @@
+package httpc_test
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/nussjustin/problem"
+
+	"github.com/nussjustin/httpc"
+)
+
+func TestWithErrorBodySnippet(t *testing.T) {
+	testCases := []struct {
+		Name        string
+		ContentType string
+		Body        string
+		Snippet     int
+		Expected    string
+	}{
+		{
+			Name:        "Disabled",
+			ContentType: "application/json",
+			Body:        `invalid`,
+			Expected:    `jsontext: invalid character 'i' at start of value`,
+		},
+		{
+			Name:        "Text",
+			ContentType: "application/json",
+			Body:        `invalid json`,
+			Snippet:     7,
+			Expected:    `(response body: "invalid")`,
+		},
+		{
+			Name:        "Cut off multibyte character",
+			ContentType: "application/json",
+			Body:        "ää",
+			Snippet:     3,
+			Expected:    `(response body: "ä\xc3")`,
+		},
+		{
+			Name:        "Binary",
+			ContentType: "application/json",
+			Body:        "\x00\x01\x02",
+			Snippet:     16,
+			Expected:    `(response body: 0x000102)`,
+		},
+		{
+			Name:        "Problem",
+			ContentType: problem.ContentType,
+			Body:        `{"title":"problem"}`,
+			Snippet:     16,
+			Expected:    `problem`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			client := &http.Client{
+				Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Header:     http.Header{"Content-Type": {testCase.ContentType}},
+						Body:       io.NopCloser(strings.NewReader(testCase.Body)),
+						Request:    r,
+					}, nil
+				}),
+			}
+
+			_, err := httpc.Fetch[map[string]any](t.Context(), http.MethodGet, "http://example.com/",
+				httpc.WithClient(client),
+				httpc.WithErrorBodySnippet(testCase.Snippet))
+			if err == nil {
+				t.Fatal("got no error")
+			}
+
+			if got := err.Error(); !strings.HasSuffix(got, testCase.Expected) {
+				t.Errorf("got error %q, want suffix %q", got, testCase.Expected)
+			}
+		})
+	}
+}
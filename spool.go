@@ -0,0 +1,105 @@
+package httpc
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+)
+
+// WithSpooledBody reads the whole response body before it is passed to the [Handler], keeping up to n bytes in memory
+// and spooling larger bodies to a temporary file.
+//
+// The body passed to handlers implements [io.Seeker] and [io.ReaderAt], so that it can be read multiple times, for
+// example to first validate and then decode the content. The Content-Length of the response is set to the size of the
+// body. The temporary file is removed once the body is closed.
+//
+// Errors while reading the body or writing the temporary file are returned by [Fetch] before any handler is called.
+func WithSpooledBody(n int64) FetchOption {
+	if n <= 0 {
+		panic(errors.New("n must be positive"))
+	}
+
+	return func(ctx *fetchContext) error {
+		ctx.SpoolThreshold = n
+		return nil
+	}
+}
+
+// spoolBody reads the response body into memory or a temporary file, depending on its size.
+func (ctx *fetchContext) spoolBody(resp *http.Response) (err error) {
+	body := resp.Body
+	defer func() {
+		if cErr := body.Close(); cErr != nil && err == nil {
+			err = cErr
+		}
+	}()
+
+	var buf bytes.Buffer
+
+	n, err := io.Copy(&buf, io.LimitReader(body, ctx.SpoolThreshold+1))
+	if err != nil {
+		return err
+	}
+
+	if n <= ctx.SpoolThreshold {
+		resp.Body, resp.ContentLength = &spooledBody{spoolReader: bytes.NewReader(buf.Bytes())}, n
+		return nil
+	}
+
+	f, err := os.CreateTemp("", "httpc-spool-*")
+	if err != nil {
+		return err
+	}
+
+	size, err := io.Copy(f, io.MultiReader(&buf, body))
+	if err == nil {
+		_, err = f.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		return errors.Join(err, f.Close(), os.Remove(f.Name()))
+	}
+
+	resp.Body, resp.ContentLength = &spooledBody{spoolReader: f, file: f}, size
+	return nil
+}
+
+// spoolReader is implemented by [*bytes.Reader] and [*os.File].
+type spoolReader interface {
+	io.ReadSeeker
+	io.ReaderAt
+}
+
+// spooledBody is a response body that was read into memory or a temporary file.
+type spooledBody struct {
+	spoolReader
+
+	file *os.File
+}
+
+// Close removes the temporary file, if any.
+func (b *spooledBody) Close() error {
+	if b.file == nil {
+		return nil
+	}
+
+	return errors.Join(b.file.Close(), os.Remove(b.file.Name()))
+}
+
+// ownedSpooledBody is an [ownedBody] for a [spooledBody] that keeps the body seekable.
+type ownedSpooledBody struct {
+	*ownedBody
+
+	spooled *spooledBody
+}
+
+// Seek implements the [io.Seeker] interface.
+func (b *ownedSpooledBody) Seek(offset int64, whence int) (int64, error) {
+	return b.spooled.Seek(offset, whence)
+}
+
+// ReadAt implements the [io.ReaderAt] interface.
+func (b *ownedSpooledBody) ReadAt(p []byte, off int64) (int, error) {
+	return b.spooled.ReadAt(p, off)
+}
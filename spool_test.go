@@ -0,0 +1,78 @@
+package httpc_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/nussjustin/httpc"
+)
+
+func TestWithSpooledBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = io.WriteString(w, strings.Repeat("x", 1024))
+	}))
+	t.Cleanup(srv.Close)
+
+	// twoPass reads the body twice, once to validate it and once to store it.
+	twoPass := httpc.HandlerFunc(func(dst any, resp *http.Response) error {
+		defer func() { _ = resp.Body.Close() }()
+
+		seeker, ok := resp.Body.(io.ReadSeeker)
+		if !ok {
+			return errors.New("body is not seekable")
+		}
+
+		if n, err := io.Copy(io.Discard, seeker); err != nil || n != resp.ContentLength {
+			return errors.New("validation failed")
+		}
+
+		if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+
+		b, err := io.ReadAll(seeker)
+		*dst.(*string) = string(b)
+		return err
+	})
+
+	testCases := []struct {
+		Name      string
+		Threshold int64
+	}{
+		{Name: "Memory", Threshold: 4096},
+		{Name: "File", Threshold: 16},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			dir := t.TempDir()
+			t.Setenv("TMPDIR", dir)
+
+			got, err := httpc.Fetch[string](t.Context(), http.MethodGet, srv.URL,
+				httpc.WithHandler(twoPass),
+				httpc.WithSpooledBody(testCase.Threshold))
+			if err != nil {
+				t.Fatalf("got error %v", err)
+			}
+
+			if want := strings.Repeat("x", 1024); got != want {
+				t.Errorf("got %d bytes, want %d", len(got), len(want))
+			}
+
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(entries) != 0 {
+				t.Errorf("got %d temporary files left", len(entries))
+			}
+		})
+	}
+}
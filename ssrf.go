@@ -0,0 +1,246 @@
+package httpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+)
+
+// ErrBlockedAddress is returned when a request with [WithSSRFGuard] would connect to a blocked address.
+var ErrBlockedAddress = errors.New("github.com/nussjustin/httpc: connection to blocked address")
+
+// blockedPrefixes contains the address ranges blocked by [WithSSRFGuard] by default.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // "This" network
+	netip.MustParsePrefix("10.0.0.0/8"),     // Private
+	netip.MustParsePrefix("100.64.0.0/10"),  // Shared address space, also used for some metadata services
+	netip.MustParsePrefix("127.0.0.0/8"),    // Loopback
+	netip.MustParsePrefix("169.254.0.0/16"), // Link-local, including cloud metadata services
+	netip.MustParsePrefix("172.16.0.0/12"),  // Private
+	netip.MustParsePrefix("192.0.0.0/24"),   // IETF protocol assignments
+	netip.MustParsePrefix("192.168.0.0/16"), // Private
+	netip.MustParsePrefix("198.18.0.0/15"),  // Benchmarking
+	netip.MustParsePrefix("224.0.0.0/4"),    // Multicast
+	netip.MustParsePrefix("240.0.0.0/4"),    // Reserved, including broadcast
+	netip.MustParsePrefix("::/128"),         // Unspecified
+	netip.MustParsePrefix("::1/128"),        // Loopback
+	netip.MustParsePrefix("64:ff9b:1::/48"), // Local-use IPv4/IPv6 translation
+	netip.MustParsePrefix("100::/64"),       // Discard-only
+	netip.MustParsePrefix("2001::/23"),      // IETF protocol assignments, including Teredo
+	netip.MustParsePrefix("2001:db8::/32"),  // Documentation
+	netip.MustParsePrefix("fc00::/7"),       // Unique local, including fd00:ec2::254
+	netip.MustParsePrefix("fe80::/10"),      // Link-local
+	netip.MustParsePrefix("ff00::/8"),       // Multicast
+}
+
+var (
+	// nat64Prefix is the well-known prefix for NAT64 addresses, which embed an IPv4 address in the last 32 bits.
+	nat64Prefix = netip.MustParsePrefix("64:ff9b::/96")
+
+	// sixToFourPrefix is the prefix for 6to4 addresses, which embed an IPv4 address after the first 16 bits.
+	sixToFourPrefix = netip.MustParsePrefix("2002::/16")
+)
+
+// SSRFPolicy configures which addresses are blocked by [WithSSRFGuard].
+type SSRFPolicy struct {
+	// Allow contains address ranges that are allowed, even if they are blocked by default or by Deny.
+	Allow []netip.Prefix
+
+	// Deny contains address ranges that are blocked in addition to the default ranges.
+	Deny []netip.Prefix
+}
+
+// allowed reports whether connections to the given address are allowed.
+func (p *SSRFPolicy) allowed(addr netip.Addr) bool {
+	// Prefixes never contain zoned addresses, so the zone must be removed to match link-local addresses like fe80::1%eth0.
+	addr = addr.Unmap().WithZone("")
+
+	for _, prefix := range p.Allow {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	if v4, ok := embeddedIPv4(addr); ok && !p.allowed(v4) {
+		return false
+	}
+
+	for _, prefixes := range [][]netip.Prefix{blockedPrefixes, p.Deny} {
+		for _, prefix := range prefixes {
+			if prefix.Contains(addr) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// embeddedIPv4 returns the IPv4 address embedded in a NAT64 or 6to4 address.
+func embeddedIPv4(addr netip.Addr) (netip.Addr, bool) {
+	b := addr.As16()
+
+	switch {
+	case nat64Prefix.Contains(addr):
+		return netip.AddrFrom4([4]byte(b[12:16])), true
+	case sixToFourPrefix.Contains(addr):
+		return netip.AddrFrom4([4]byte(b[2:6])), true
+	default:
+		return netip.Addr{}, false
+	}
+}
+
+// WithSSRFGuard blocks requests that would connect to private, loopback, link-local, multicast or other special
+// purpose addresses, including cloud metadata services, to protect against server-side request forgery (SSRF) when
+// requesting user-supplied URLs. NAT64 and 6to4 addresses are blocked if the IPv4 address embedded in them is blocked.
+//
+// Host names are resolved before connecting and the connection is made to the checked address, so that the check can
+// not be circumvented using DNS rebinding. Since each connection is checked, this also applies to redirects. Requests
+// to blocked addresses fail with an error wrapping [ErrBlockedAddress].
+//
+// If the transport uses a custom DialTLSContext function, the host name is passed to it as is, so that it can still be
+// used for verifying the certificate of the server. In this case all addresses of the host are checked before
+// connecting, but since the function resolves the host again, the check does not protect against DNS rebinding.
+//
+// When the option is first applied, the [*http.Transport] of the underlying client is cloned and configured to check
+// addresses. Proxies configured on the transport are disabled for the copy, since they would connect to the target on
+// behalf of the client without any check. Using the option together with [WithProxyURL] or
+// [WithProxyFromEnvironment] causes [Fetch] to fail. The returned option should be created once and reused, for
+// example by passing it to [New].
+//
+// The transport of the underlying client must be an [*http.Transport]. If the client has no transport,
+// [http.DefaultTransport] is used.
+func WithSSRFGuard(policy SSRFPolicy) FetchOption {
+	transports := &transportCache{}
+
+	guard := func(t *http.Transport) *http.Transport {
+		return transports.get(t, func(t *http.Transport) *http.Transport {
+			guarded := t.Clone()
+			guarded.Proxy = nil
+
+			dial := guarded.DialContext
+			if dial == nil {
+				dial = (&net.Dialer{}).DialContext
+			}
+
+			guarded.DialContext = guardedDial(&policy, dial)
+
+			if guarded.DialTLSContext != nil {
+				guarded.DialTLSContext = checkedDial(&policy, guarded.DialTLSContext)
+			}
+
+			return guarded
+		})
+	}
+
+	return func(ctx *fetchContext) error {
+		ctx.SSRFGuard = guard
+		return nil
+	}
+}
+
+// applySSRFGuard replaces the client with one that uses a transport that checks addresses, if configured.
+func (ctx *fetchContext) applySSRFGuard() error {
+	if ctx.SSRFGuard == nil {
+		return nil
+	}
+
+	if ctx.ProxyEnabled {
+		return errors.New("github.com/nussjustin/httpc: SSRF guard can not be used with a proxy")
+	}
+
+	t, err := ctx.transport()
+	if err != nil {
+		return err
+	}
+
+	client := *ctx.Client
+	client.Transport = ctx.SSRFGuard(t)
+
+	ctx.Client = &client
+	return nil
+}
+
+type dialFunc = func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// guardedDial returns a dial function that resolves the host and only connects to allowed addresses using dial.
+func guardedDial(policy *SSRFPolicy, dial dialFunc) dialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+
+		addrs, err := lookupHost(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+
+		var errs []error
+
+		for _, ip := range addrs {
+			if !policy.allowed(ip) {
+				errs = append(errs, blockedAddressError(ip, host))
+				continue
+			}
+
+			conn, err := dial(ctx, network, net.JoinHostPort(ip.Unmap().String(), port))
+			if err == nil {
+				return conn, nil
+			}
+
+			errs = append(errs, err)
+		}
+
+		if len(errs) == 0 {
+			return nil, fmt.Errorf("no addresses found for host %s", host)
+		}
+
+		return nil, errors.Join(errs...)
+	}
+}
+
+// checkedDial returns a dial function that calls dial with the original address, but only if all addresses of the
+// host are allowed.
+func checkedDial(policy *SSRFPolicy, dial dialFunc) dialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+
+		addrs, err := lookupHost(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(addrs) == 0 {
+			return nil, fmt.Errorf("no addresses found for host %s", host)
+		}
+
+		for _, ip := range addrs {
+			if !policy.allowed(ip) {
+				return nil, blockedAddressError(ip, host)
+			}
+		}
+
+		return dial(ctx, network, addr)
+	}
+}
+
+// lookupHost returns the addresses of the given host, which may already be an IP address.
+func lookupHost(ctx context.Context, host string) ([]netip.Addr, error) {
+	if ip, err := netip.ParseAddr(host); err == nil {
+		return []netip.Addr{ip}, nil
+	}
+
+	return net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+}
+
+// blockedAddressError returns an error wrapping [ErrBlockedAddress] for the given address of host.
+func blockedAddressError(ip netip.Addr, host string) error {
+	return fmt.Errorf("%w %s for host %s", ErrBlockedAddress, ip, host)
+}
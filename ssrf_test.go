@@ -0,0 +1,172 @@
+package httpc_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/nussjustin/httpc"
+)
+
+func TestWithSSRFGuard(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if target := r.URL.Query().Get("redirect"); target != "" {
+			http.Redirect(w, r, target, http.StatusFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+
+	_, port, _ := strings.Cut(strings.TrimPrefix(srv.URL, "http://"), ":")
+
+	testCases := []struct {
+		Name    string
+		Policy  httpc.SSRFPolicy
+		URL     string
+		Blocked bool
+	}{
+		{
+			Name:    "Loopback",
+			URL:     srv.URL,
+			Blocked: true,
+		},
+		{
+			Name:    "Localhost",
+			URL:     "http://localhost:" + port,
+			Blocked: true,
+		},
+		{
+			Name:    "Metadata",
+			URL:     "http://169.254.169.254/latest/meta-data/",
+			Blocked: true,
+		},
+		{
+			Name:    "Metadata via NAT64",
+			URL:     "http://[64:ff9b::a9fe:a9fe]/latest/meta-data/",
+			Blocked: true,
+		},
+		{
+			Name:    "Metadata via 6to4",
+			URL:     "http://[2002:a9fe:a9fe::]/latest/meta-data/",
+			Blocked: true,
+		},
+		{
+			Name:    "IPv4-mapped IPv6",
+			URL:     "http://[::ffff:127.0.0.1]:" + port,
+			Blocked: true,
+		},
+		{
+			Name:    "Zoned loopback",
+			URL:     "http://[::1%25lo]:" + port,
+			Blocked: true,
+		},
+		{
+			Name:    "Zoned link-local",
+			URL:     "http://[fe80::1%25eth0]/",
+			Blocked: true,
+		},
+		{
+			Name:   "Allowed",
+			Policy: httpc.SSRFPolicy{Allow: []netip.Prefix{netip.MustParsePrefix("127.0.0.1/32")}},
+			URL:    srv.URL,
+		},
+		{
+			Name:    "Redirect",
+			Policy:  httpc.SSRFPolicy{Allow: []netip.Prefix{netip.MustParsePrefix("127.0.0.1/32")}},
+			URL:     srv.URL + "?redirect=http://127.0.0.2:" + port + "/",
+			Blocked: true,
+		},
+		{
+			Name: "Allow takes precedence over deny",
+			Policy: httpc.SSRFPolicy{
+				Allow: []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")},
+				Deny:  []netip.Prefix{netip.MustParsePrefix("127.0.0.1/32")},
+			},
+			URL: srv.URL,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			c := httpc.New(httpc.WithSSRFGuard(testCase.Policy))
+
+			err := c.Fetch(t.Context(), http.MethodGet, testCase.URL, nil)
+
+			if got := errors.Is(err, httpc.ErrBlockedAddress); got != testCase.Blocked {
+				t.Errorf("got error %v, want blocked %v", err, testCase.Blocked)
+			}
+
+			if !testCase.Blocked && err != nil {
+				t.Errorf("got error %v", err)
+			}
+		})
+	}
+}
+
+func TestWithSSRFGuard_DialTLSContext(t *testing.T) {
+	errDial := errors.New("dial error")
+
+	var dialed []string
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialTLSContext: func(_ context.Context, _, addr string) (net.Conn, error) {
+				dialed = append(dialed, addr)
+				return nil, errDial
+			},
+		},
+	}
+
+	loopback := httpc.SSRFPolicy{Allow: []netip.Prefix{
+		netip.MustParsePrefix("127.0.0.0/8"),
+		netip.MustParsePrefix("::1/128"),
+	}}
+
+	err := httpc.New(httpc.WithClient(client), httpc.WithSSRFGuard(loopback)).
+		Fetch(t.Context(), http.MethodGet, "https://localhost:8443/", nil)
+	if !errors.Is(err, errDial) {
+		t.Errorf("got error %v, want %v", err, errDial)
+	}
+
+	if want := []string{"localhost:8443"}; !slices.Equal(dialed, want) {
+		t.Errorf("got dialed addresses %q, want %q", dialed, want)
+	}
+
+	dialed = nil
+
+	err = httpc.New(httpc.WithClient(client), httpc.WithSSRFGuard(httpc.SSRFPolicy{})).
+		Fetch(t.Context(), http.MethodGet, "https://localhost:8443/", nil)
+	if !errors.Is(err, httpc.ErrBlockedAddress) {
+		t.Errorf("got error %v, want %v", err, httpc.ErrBlockedAddress)
+	}
+
+	if len(dialed) != 0 {
+		t.Errorf("got dialed addresses %q, want none", dialed)
+	}
+}
+
+func TestWithSSRFGuard_Proxy(t *testing.T) {
+	proxyURL, _ := url.Parse("http://proxy.example.com:8080")
+
+	c := httpc.New(httpc.WithProxyURL(proxyURL), httpc.WithSSRFGuard(httpc.SSRFPolicy{}))
+
+	if err := c.Fetch(t.Context(), http.MethodGet, "http://127.0.0.1/", nil); err == nil ||
+		errors.Is(err, httpc.ErrBlockedAddress) {
+		t.Errorf("got error %v, want error for proxy", err)
+	}
+
+	c = httpc.New(httpc.WithoutProxy(), httpc.WithSSRFGuard(httpc.SSRFPolicy{}))
+
+	if err := c.Fetch(t.Context(), http.MethodGet, "http://127.0.0.1/", nil); !errors.Is(err, httpc.ErrBlockedAddress) {
+		t.Errorf("got error %v, want %v", err, httpc.ErrBlockedAddress)
+	}
+}
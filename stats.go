@@ -0,0 +1,76 @@
+package httpc
+
+import (
+	"io"
+	"net/http"
+	"sync/atomic"
+)
+
+// Stats contains statistics about a single call to [Fetch].
+type Stats struct {
+	// Attempts is the number of times the request was sent.
+	Attempts int
+
+	// RequestBytes is the number of request body bytes that were sent, including bytes sent for redirects and
+	// repeated attempts.
+	RequestBytes int64
+
+	// ResponseBytes is the number of response body bytes that were read.
+	//
+	// If the response body is read after [FetchWithResponse] returned, the value is updated as the body is read.
+	ResponseBytes int64
+
+	// Proxy is the URL of the proxy used for the request, with the password redacted, or empty if no proxy was used.
+	//
+	// The proxy is only recorded if it was chosen using [WithProxyFromEnvironment], [WithProxyURL] or [WithoutProxy].
+	Proxy string
+}
+
+// WithStats causes statistics about the request to be recorded into the given [Stats].
+//
+// This can be used for example to track bandwidth usage per endpoint.
+//
+// The given [Stats] is reset when the request is sent and must not be used by multiple requests concurrently.
+func WithStats(s *Stats) FetchOption {
+	return func(ctx *fetchContext) error {
+		ctx.Stats = s
+		return nil
+	}
+}
+
+// countRequestBytes wraps the request body, if any, so that read bytes are recorded in the configured [Stats].
+func (ctx *fetchContext) countRequestBytes() {
+	*ctx.Stats = Stats{}
+
+	if ctx.Request.Body == nil || ctx.Request.Body == http.NoBody {
+		return
+	}
+
+	ctx.Request.Body = &countingReadCloser{ReadCloser: ctx.Request.Body, n: &ctx.Stats.RequestBytes}
+
+	if getBody := ctx.Request.GetBody; getBody != nil {
+		ctx.Request.GetBody = func() (io.ReadCloser, error) {
+			body, err := getBody()
+			if err != nil {
+				return nil, err
+			}
+			return &countingReadCloser{ReadCloser: body, n: &ctx.Stats.RequestBytes}, nil
+		}
+	}
+}
+
+// countResponseBytes wraps the response body so that read bytes are recorded in the configured [Stats].
+func (ctx *fetchContext) countResponseBytes(resp *http.Response) {
+	resp.Body = &countingReadCloser{ReadCloser: resp.Body, n: &ctx.Stats.ResponseBytes}
+}
+
+type countingReadCloser struct {
+	io.ReadCloser
+	n *int64
+}
+
+func (c *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	atomic.AddInt64(c.n, int64(n))
+	return n, err
+}
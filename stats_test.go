@@ -0,0 +1,40 @@
+package httpc_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/nussjustin/httpc"
+)
+
+func TestWithStats(t *testing.T) {
+	client, baseURL := testEndpoint(t)
+
+	stats := httpc.Stats{Attempts: 5, RequestBytes: 5, ResponseBytes: 5}
+
+	got, resp, err := httpc.FetchWithResponse[infoResponse](t.Context(), http.MethodPost, "/",
+		httpc.WithClient(client),
+		httpc.WithBaseURL(baseURL),
+		httpc.WithBody(strings.NewReader("hello world")),
+		httpc.WithStats(&stats))
+	if err != nil {
+		t.Fatalf("failed to fetch: %v", err)
+	}
+
+	if got, want := stats.Attempts, 1; got != want {
+		t.Errorf("got %d attempts, want %d", got, want)
+	}
+
+	if got, want := stats.RequestBytes, int64(len("hello world")); got != want {
+		t.Errorf("got %d request bytes, want %d", got, want)
+	}
+
+	if got.Body != "hello world" {
+		t.Errorf("got body %q, want %q", got.Body, "hello world")
+	}
+
+	if got, want := stats.ResponseBytes, resp.ContentLength; got != want {
+		t.Errorf("got %d response bytes, want %d", got, want)
+	}
+}
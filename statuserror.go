@@ -0,0 +1,161 @@
+package httpc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// StatusError is returned by handlers created using [ErrorStatusHandler] for responses with a non-2xx status code.
+//
+// StatusError wraps [ErrUnexpectedStatus].
+type StatusError struct {
+	// Method is the method of the request.
+	Method string
+
+	// URL is the URL of the request, with secrets redacted.
+	URL string
+
+	// OperationName is the name set using [WithOperationName], if any.
+	OperationName string
+
+	// PathTemplate is the path of the request before any wildcards were replaced, for example "/product/{id}".
+	//
+	// Unlike URL, the template does not contain request specific values, which makes it suitable for grouping errors.
+	PathTemplate string
+
+	// StatusCode is the status code of the response.
+	StatusCode int
+
+	// Status is the status of the response, for example "404 Not Found".
+	Status string
+
+	// Header contains the selected response headers, with secrets redacted.
+	Header http.Header
+
+	// Body contains the beginning of the response body.
+	Body []byte
+}
+
+// Error implements the error interface.
+func (e *StatusError) Error() string {
+	var b strings.Builder
+	b.WriteString(ErrUnexpectedStatus.Error())
+	_, _ = fmt.Fprintf(&b, " %q", e.Status)
+
+	switch {
+	case e.OperationName != "" && e.URL != "":
+		_, _ = fmt.Fprintf(&b, " for %s (%s %s)", e.OperationName, e.Method, e.URL)
+	case e.OperationName != "":
+		_, _ = fmt.Fprintf(&b, " for %s", e.OperationName)
+	case e.URL != "":
+		_, _ = fmt.Fprintf(&b, " for %s %s", e.Method, e.URL)
+	}
+
+	if len(e.Body) > 0 {
+		_, _ = fmt.Fprintf(&b, " (response body: %s)", formatSnippet(e.Body))
+	}
+
+	return b.String()
+}
+
+// Unwrap returns [ErrUnexpectedStatus].
+func (e *StatusError) Unwrap() error {
+	return ErrUnexpectedStatus
+}
+
+// StatusErrorOptions configures the handler returned by [ErrorStatusHandlerWithOptions].
+type StatusErrorOptions struct {
+	// Headers contains the names of the response headers included in the error.
+	Headers []string
+
+	// MaxBodyBytes is the maximum number of body bytes included in the error.
+	MaxBodyBytes int
+}
+
+// DefaultStatusErrorOptions returns the options used by [ErrorStatusHandler].
+//
+// The returned options include the Content-Type, Retry-After, WWW-Authenticate and X-Request-Id headers and up to 512
+// bytes of the body.
+func DefaultStatusErrorOptions() StatusErrorOptions {
+	return StatusErrorOptions{
+		Headers:      []string{"Content-Type", "Retry-After", "WWW-Authenticate", "X-Request-Id"},
+		MaxBodyBytes: 512,
+	}
+}
+
+// ErrorStatusHandler returns a [Handler] that handles responses with a non-2xx status code by returning a
+// [*StatusError].
+//
+// This is the same as calling [ErrorStatusHandlerWithOptions] with the result of [DefaultStatusErrorOptions].
+func ErrorStatusHandler() HandlerFunc {
+	return ErrorStatusHandlerWithOptions(DefaultStatusErrorOptions())
+}
+
+// ErrorStatusHandlerWithOptions returns a [Handler] that handles responses with a non-2xx status code by returning a
+// [*StatusError] configured by the given options.
+//
+// Responses with a 2xx status code are not handled. The URL and headers in the error are redacted using the
+// [Redactor] set via [WithRedactor] or [DefaultRedactor].
+//
+// The response body will automatically be closed. Only the part included in the error is read.
+func ErrorStatusHandlerWithOptions(opts StatusErrorOptions) HandlerFunc {
+	return func(_ any, resp *http.Response) error {
+		if IsSuccess(resp) {
+			return ErrUnhandledResponse
+		}
+
+		r := requestRedactor(resp.Request)
+
+		statusErr := &StatusError{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Header:     make(http.Header, len(opts.Headers)),
+		}
+
+		if req := resp.Request; req != nil && req.URL != nil {
+			statusErr.Method, statusErr.URL = req.Method, r.URL(req.URL)
+			statusErr.OperationName = RequestOperationName(req)
+			statusErr.PathTemplate = RequestPathTemplate(req)
+		}
+
+		for _, name := range opts.Headers {
+			if values := resp.Header.Values(name); len(values) > 0 {
+				statusErr.Header[http.CanonicalHeaderKey(name)] = values
+			}
+		}
+
+		statusErr.Header = r.Header(statusErr.Header)
+
+		if opts.MaxBodyBytes > 0 && resp.Body != nil {
+			statusErr.Body, _ = io.ReadAll(io.LimitReader(resp.Body, int64(opts.MaxBodyBytes)))
+		}
+
+		if resp.Body != nil {
+			_ = resp.Body.Close()
+		}
+
+		return statusErr
+	}
+}
+
+// redactorKey is the context key used to pass a custom [Redactor] from a request to handlers.
+type redactorKey struct{}
+
+// requestRedactor returns the [Redactor] set for the given request, or [DefaultRedactor] if none was set.
+func requestRedactor(req *http.Request) *Redactor {
+	if req != nil {
+		if r, _ := req.Context().Value(redactorKey{}).(*Redactor); r != nil {
+			return r
+		}
+	}
+
+	return DefaultRedactor()
+}
+
+// withRedactor returns a copy of req that passes the given [Redactor] to handlers.
+func withRedactor(req *http.Request, r *Redactor) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), redactorKey{}, r))
+}
@@ -0,0 +1,120 @@
+package httpc_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nussjustin/httpc"
+)
+
+func TestErrorStatusHandler(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "120")
+		w.Header().Set("Www-Authenticate", `Bearer realm="test"`)
+		w.Header().Set("X-Other", "other")
+
+		if r.URL.Path == "/ok" {
+			w.Header().Set("Content-Type", "application/octet-stream")
+			_, _ = w.Write([]byte("ok"))
+			return
+		}
+
+		if r.URL.Path == "/json" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"error":"internal"}`))
+			return
+		}
+
+		http.Error(w, strings.Repeat("x", 1024), http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(srv.Close)
+
+	t.Run("Default handlers", func(t *testing.T) {
+		_, err := httpc.Fetch[map[string]any](t.Context(), http.MethodGet, srv.URL+"/{name}?token=secret",
+			httpc.WithPathValue("name", "error"))
+
+		var statusErr *httpc.StatusError
+		if !errors.As(err, &statusErr) {
+			t.Fatalf("got error %v, want %T", err, statusErr)
+		}
+
+		if !errors.Is(err, httpc.ErrUnexpectedStatus) {
+			t.Errorf("got error %v, want %v", err, httpc.ErrUnexpectedStatus)
+		}
+
+		want := &httpc.StatusError{
+			Method:       http.MethodGet,
+			URL:          srv.URL + "/error?token=REDACTED",
+			PathTemplate: "/{name}",
+			StatusCode:   http.StatusServiceUnavailable,
+			Status:       "503 Service Unavailable",
+			Header: http.Header{
+				"Content-Type":     []string{"text/plain; charset=utf-8"},
+				"Retry-After":      []string{"120"},
+				"Www-Authenticate": []string{`Bearer realm="test"`},
+			},
+			Body: []byte(strings.Repeat("x", 512)),
+		}
+
+		if diff := cmp.Diff(want, statusErr); diff != "" {
+			t.Errorf("error mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("JSON error", func(t *testing.T) {
+		got, err := httpc.Fetch[map[string]any](t.Context(), http.MethodGet, srv.URL+"/json")
+
+		var statusErr *httpc.StatusError
+		if !errors.As(err, &statusErr) {
+			t.Fatalf("got error %v, want %T", err, statusErr)
+		}
+
+		if statusErr.StatusCode != http.StatusInternalServerError {
+			t.Errorf("got status code %d, want %d", statusErr.StatusCode, http.StatusInternalServerError)
+		}
+
+		if want := `{"error":"internal"}`; string(statusErr.Body) != want {
+			t.Errorf("got body %q, want %q", statusErr.Body, want)
+		}
+
+		if got != nil {
+			t.Errorf("got result %v, want nil", got)
+		}
+	})
+
+	t.Run("Options", func(t *testing.T) {
+		_, err := httpc.Fetch[any](t.Context(), http.MethodGet, srv.URL+"/error",
+			httpc.WithHandler(httpc.ErrorStatusHandlerWithOptions(httpc.StatusErrorOptions{
+				Headers:      []string{"x-other"},
+				MaxBodyBytes: 4,
+			})))
+
+		want := `github.com/nussjustin/httpc: unexpected status "503 Service Unavailable" for GET ` + srv.URL +
+			`/error (response body: "xxxx")`
+
+		if err == nil || err.Error() != want {
+			t.Errorf("got error %v, want %s", err, want)
+		}
+
+		var statusErr *httpc.StatusError
+		if errors.As(err, &statusErr) {
+			if diff := cmp.Diff(http.Header{"X-Other": []string{"other"}}, statusErr.Header); diff != "" {
+				t.Errorf("header mismatch (-want +got):\n%s", diff)
+			}
+		}
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		_, err := httpc.Fetch[any](t.Context(), http.MethodGet, srv.URL+"/ok",
+			httpc.WithHandler(httpc.ErrorStatusHandler()))
+		if !errors.Is(err, httpc.ErrUnhandledResponse) {
+			t.Errorf("got error %v, want %v", err, httpc.ErrUnhandledResponse)
+		}
+	})
+}
@@ -0,0 +1,92 @@
+package httpc
+
+import (
+	"io"
+	"net/http"
+)
+
+// BodyWriter receives a copy of a response body while the body is read.
+//
+// Exactly one of Commit or Abort is called for each BodyWriter.
+type BodyWriter interface {
+	io.Writer
+
+	// Commit is called once the body was read completely.
+	Commit()
+
+	// Abort is called instead of Commit if writing to the BodyWriter failed or if the body was closed before it was
+	// read completely.
+	Abort()
+}
+
+// WithBodyTee copies response bodies into the [BodyWriter] returned by fn while they are read.
+//
+// fn is called for each response before it is passed to the [Handler]. If fn returns nil, the body is not copied.
+//
+// The body is written to the BodyWriter at the same time it is read by the handler, so that it does not need to be
+// buffered in memory. This allows storing or archiving large responses while decoding them, without increasing memory
+// usage.
+func WithBodyTee(fn func(resp *http.Response) BodyWriter) FetchOption {
+	return func(ctx *fetchContext) error {
+		ctx.BodyTee = fn
+		return nil
+	}
+}
+
+// teeResponseBody wraps the response body so that it is copied into the writer returned by BodyTee.
+func (ctx *fetchContext) teeResponseBody(resp *http.Response) {
+	w := ctx.BodyTee(resp)
+	if w == nil {
+		return
+	}
+
+	resp.Body = &teeBody{ReadCloser: resp.Body, w: w, done: w.Commit, abort: w.Abort}
+}
+
+// teeBody passes the body through while writing it to w and calls done once the body was read completely.
+//
+// If writing fails or the body is closed early, abort is called instead, if set.
+type teeBody struct {
+	io.ReadCloser
+
+	w     io.Writer
+	done  func()
+	abort func()
+}
+
+func (b *teeBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+
+	if b.w != nil && n > 0 {
+		if _, wErr := b.w.Write(p[:n]); wErr != nil {
+			b.finish(false)
+		}
+	}
+
+	if err == io.EOF {
+		b.finish(true)
+	}
+
+	return n, err
+}
+
+func (b *teeBody) Close() error {
+	b.finish(false)
+	return b.ReadCloser.Close()
+}
+
+// finish calls done or abort, depending on whether the body was read completely. Only the first call has an effect.
+func (b *teeBody) finish(complete bool) {
+	if b.w == nil {
+		return
+	}
+
+	b.w = nil
+
+	switch {
+	case complete:
+		b.done()
+	case b.abort != nil:
+		b.abort()
+	}
+}
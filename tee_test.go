@@ -0,0 +1,108 @@
+package httpc_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/nussjustin/httpc"
+)
+
+type recordingBodyWriter struct {
+	bytes.Buffer
+
+	committed bool
+	aborted   bool
+}
+
+func (w *recordingBodyWriter) Commit() {
+	w.committed = true
+}
+
+func (w *recordingBodyWriter) Abort() {
+	w.aborted = true
+}
+
+func TestWithBodyTee(t *testing.T) {
+	const body = `{"name":"value"}`
+
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(body)),
+				Request:    req,
+			}, nil
+		}),
+	}
+
+	var w *recordingBodyWriter
+
+	opts := []httpc.FetchOption{
+		httpc.WithClient(client),
+		httpc.WithBodyTee(func(*http.Response) httpc.BodyWriter {
+			w = &recordingBodyWriter{}
+			return w
+		}),
+	}
+
+	t.Run("Decoded", func(t *testing.T) {
+		got, err := httpc.Fetch[map[string]string](t.Context(), http.MethodGet, "https://example.com/", opts...)
+		if err != nil {
+			t.Fatalf("got error %v", err)
+		}
+
+		if got["name"] != "value" {
+			t.Errorf("got %v, want decoded body", got)
+		}
+
+		if !w.committed || w.aborted {
+			t.Errorf("got committed=%v aborted=%v, want committed", w.committed, w.aborted)
+		}
+
+		if got := w.String(); got != body {
+			t.Errorf("got copy %q, want %q", got, body)
+		}
+	})
+
+	t.Run("Closed early", func(t *testing.T) {
+		rc, err := httpc.Fetch[io.ReadCloser](t.Context(), http.MethodGet, "https://example.com/", opts...)
+		if err != nil {
+			t.Fatalf("got error %v", err)
+		}
+
+		if _, err := rc.Read(make([]byte, 4)); err != nil {
+			t.Fatalf("failed to read body: %v", err)
+		}
+
+		if err := rc.Close(); err != nil {
+			t.Fatalf("failed to close body: %v", err)
+		}
+
+		if w.committed || !w.aborted {
+			t.Errorf("got committed=%v aborted=%v, want aborted", w.committed, w.aborted)
+		}
+
+		if got, want := w.String(), body[:4]; got != want {
+			t.Errorf("got copy %q, want %q", got, want)
+		}
+	})
+
+	t.Run("Nil writer", func(t *testing.T) {
+		got, err := httpc.Fetch[map[string]string](t.Context(), http.MethodGet, "https://example.com/",
+			httpc.WithClient(client),
+			httpc.WithBodyTee(func(*http.Response) httpc.BodyWriter {
+				return nil
+			}))
+		if err != nil {
+			t.Fatalf("got error %v", err)
+		}
+
+		if got["name"] != "value" {
+			t.Errorf("got %v, want decoded body", got)
+		}
+	})
+}
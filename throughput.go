@@ -0,0 +1,150 @@
+package httpc
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ThroughputError is returned when a response body was received slower than allowed by [WithMinThroughput].
+type ThroughputError struct {
+	// Received is the number of bytes received during the window.
+	Received int64
+
+	// Window is the duration of the window.
+	Window time.Duration
+
+	// MinBytesPerSecond is the configured minimum throughput.
+	MinBytesPerSecond int64
+}
+
+// Error implements the error interface.
+func (e *ThroughputError) Error() string {
+	return fmt.Sprintf("github.com/nussjustin/httpc: throughput too low: received %d bytes in %s, want at least %d "+
+		"bytes per second", e.Received, e.Window, e.MinBytesPerSecond)
+}
+
+// WithMinThroughput aborts reading the response body when less than bytesPerSec bytes per second were received
+// during a window of the given duration.
+//
+// This protects against transfers that effectively hang, because the server only sends a few bytes at a time.
+//
+// The throughput is only checked while the body is read, so time spent processing already received data does not
+// count against the server. Once the throughput is too low, the body is closed and reads from the body fail with a
+// [*ThroughputError].
+func WithMinThroughput(bytesPerSec int64, window time.Duration) FetchOption {
+	if bytesPerSec <= 0 {
+		panic(errors.New("bytesPerSec must be positive"))
+	}
+
+	if window <= 0 {
+		panic(errors.New("window must be positive"))
+	}
+
+	return func(ctx *fetchContext) error {
+		ctx.MinThroughput, ctx.MinThroughputWindow = bytesPerSec, window
+		return nil
+	}
+}
+
+// watchThroughput wraps the response body so that it is closed when the throughput drops below MinThroughput.
+func (ctx *fetchContext) watchThroughput(resp *http.Response) {
+	b := &throughputBody{
+		ReadCloser: resp.Body,
+		min:        ctx.MinThroughput,
+		window:     ctx.MinThroughputWindow,
+	}
+
+	// The timer may fire before AfterFunc returns, so check must not run before the timer was assigned.
+	b.mu.Lock()
+	b.timer = time.AfterFunc(b.window, b.check)
+	b.mu.Unlock()
+
+	resp.Body = b
+}
+
+// throughputBody is a response body that fails with a [*ThroughputError] when read too slowly.
+type throughputBody struct {
+	io.ReadCloser
+
+	min    int64
+	window time.Duration
+	timer  *time.Timer
+
+	mu       sync.Mutex
+	reading  int
+	received int64
+	err      error
+	closed   bool
+}
+
+func (b *throughputBody) Read(p []byte) (int, error) {
+	b.mu.Lock()
+	if b.err != nil {
+		b.mu.Unlock()
+		return 0, b.err
+	}
+	b.reading++
+	b.mu.Unlock()
+
+	n, err := b.ReadCloser.Read(p)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.reading--
+	b.received += int64(n)
+
+	// Closing the body interrupts pending reads, which then fail with an error specific to the underlying body.
+	if err != nil && b.err != nil {
+		err = b.err
+	}
+
+	return n, err
+}
+
+func (b *throughputBody) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.timer.Stop()
+
+	return b.close()
+}
+
+// check is called at the end of each window and closes the body if the throughput was too low.
+func (b *throughputBody) check() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+
+	received := b.received
+	b.received = 0
+
+	want := int64(float64(b.min) * b.window.Seconds())
+
+	// If no read is pending, the reader is busy with other work and the server can not be blamed.
+	if b.reading == 0 || received >= want {
+		b.timer.Reset(b.window)
+		return
+	}
+
+	b.err = &ThroughputError{Received: received, Window: b.window, MinBytesPerSecond: b.min}
+	_ = b.close()
+}
+
+// close closes the underlying body once. b.mu must be held.
+func (b *throughputBody) close() error {
+	if b.closed {
+		return nil
+	}
+
+	b.closed = true
+	return b.ReadCloser.Close()
+}
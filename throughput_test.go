@@ -0,0 +1,87 @@
+package httpc_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nussjustin/httpc"
+)
+
+func TestWithMinThroughput(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+
+		if r.URL.Path == "/fast" {
+			_, _ = w.Write([]byte(strings.Repeat("x", 1024)))
+			return
+		}
+
+		for {
+			_, _ = w.Write([]byte("x"))
+			w.(http.Flusher).Flush()
+
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	t.Run("Success", func(t *testing.T) {
+		got, err := httpc.Fetch[string](t.Context(), http.MethodGet, srv.URL+"/fast",
+			httpc.WithMinThroughput(1024, 50*time.Millisecond))
+		if err != nil {
+			t.Fatalf("got error %v", err)
+		}
+
+		if got, want := len(got), 1024; got != want {
+			t.Errorf("got %d bytes, want %d", got, want)
+		}
+	})
+
+	t.Run("Too slow", func(t *testing.T) {
+		_, err := httpc.Fetch[string](t.Context(), http.MethodGet, srv.URL+"/slow",
+			httpc.WithMinThroughput(1024, 50*time.Millisecond))
+
+		var throughputErr *httpc.ThroughputError
+		if !errors.As(err, &throughputErr) {
+			t.Fatalf("got error %v, want %T", err, throughputErr)
+		}
+
+		if got, want := throughputErr.Window, 50*time.Millisecond; got != want {
+			t.Errorf("got window %s, want %s", got, want)
+		}
+
+		if got, want := throughputErr.MinBytesPerSecond, int64(1024); got != want {
+			t.Errorf("got minimum %d, want %d", got, want)
+		}
+	})
+
+	t.Run("Idle reader", func(t *testing.T) {
+		_, resp, err := httpc.FetchWithResponse[any](t.Context(), http.MethodGet, srv.URL+"/fast",
+			httpc.WithMinThroughput(1024, 10*time.Millisecond),
+			httpc.WithKeepBodyOpen())
+		if err != nil {
+			t.Fatalf("got error %v", err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		time.Sleep(50 * time.Millisecond)
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Errorf("got error %v", err)
+		}
+
+		if got, want := len(body), 1024; got != want {
+			t.Errorf("got %d bytes, want %d", got, want)
+		}
+	})
+}
@@ -0,0 +1,130 @@
+package httpc
+
+import (
+	"crypto/tls"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// WithClientCertificate uses the given callback to obtain the TLS client certificate for requests.
+//
+// The callback is called for each TLS handshake that requests a client certificate, so that rotated certificates, for
+// example issued by SPIFFE or Vault, are picked up by new connections without restarting the process. See
+// [tls.Config.GetClientCertificate] for details on the callback. [CertificateFiles] can be used to load certificates
+// from files.
+//
+// When the option is first applied, the [*http.Transport] of the underlying client is cloned and configured to use the
+// callback. Since each call to WithClientCertificate creates new copies with their own connection pools, the returned
+// option should be created once and reused, for example by passing it to [New].
+//
+// The transport of the underlying client must be an [*http.Transport]. If the client has no transport,
+// [http.DefaultTransport] is used.
+func WithClientCertificate(get func(*tls.CertificateRequestInfo) (*tls.Certificate, error)) FetchOption {
+	transports := &transportCache{}
+
+	configure := func(t *http.Transport) *http.Transport {
+		return transports.get(t, func(t *http.Transport) *http.Transport {
+			configured := t.Clone()
+
+			if configured.TLSClientConfig == nil {
+				configured.TLSClientConfig = &tls.Config{}
+			}
+
+			configured.TLSClientConfig.Certificates = nil
+			configured.TLSClientConfig.GetClientCertificate = get
+
+			return configured
+		})
+	}
+
+	return func(ctx *fetchContext) error {
+		ctx.ClientCertificate = configure
+		return nil
+	}
+}
+
+// applyClientCertificate replaces the client with one that uses a transport configured with a client certificate, if
+// configured.
+func (ctx *fetchContext) applyClientCertificate() error {
+	if ctx.ClientCertificate == nil {
+		return nil
+	}
+
+	t, err := ctx.transport()
+	if err != nil {
+		return err
+	}
+
+	client := *ctx.Client
+	client.Transport = ctx.ClientCertificate(t)
+
+	ctx.Client = &client
+	return nil
+}
+
+// CertificateFiles provides a TLS certificate loaded from PEM encoded files, reloading it when the files change.
+//
+// Changes are detected by comparing the modification times of the files each time the certificate is requested.
+type CertificateFiles struct {
+	certFile, keyFile string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	certMod time.Time
+	keyMod  time.Time
+}
+
+// NewCertificateFiles returns a new [CertificateFiles] for the given certificate and key files.
+//
+// The certificate is loaded immediately and an error is returned if loading fails.
+func NewCertificateFiles(certFile, keyFile string) (*CertificateFiles, error) {
+	c := &CertificateFiles{certFile: certFile, keyFile: keyFile}
+
+	if _, err := c.Certificate(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Certificate returns the current certificate, reloading it if either file has changed.
+//
+// If the files have changed but can not be loaded, for example because only one of them was written so far, the
+// previously loaded certificate is returned and loading is tried again on the next call.
+func (c *CertificateFiles) Certificate() (*tls.Certificate, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	certInfo, certErr := os.Stat(c.certFile)
+	keyInfo, keyErr := os.Stat(c.keyFile)
+
+	if certErr == nil && keyErr == nil &&
+		certInfo.ModTime().Equal(c.certMod) && keyInfo.ModTime().Equal(c.keyMod) && c.cert != nil {
+		return c.cert, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		if c.cert != nil {
+			return c.cert, nil
+		}
+
+		return nil, err
+	}
+
+	c.cert = &cert
+
+	if certErr == nil && keyErr == nil {
+		c.certMod, c.keyMod = certInfo.ModTime(), keyInfo.ModTime()
+	}
+
+	return c.cert, nil
+}
+
+// GetClientCertificate returns the current certificate and can be used with [WithClientCertificate] or as
+// [tls.Config.GetClientCertificate].
+func (c *CertificateFiles) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return c.Certificate()
+}
@@ -0,0 +1,135 @@
+package httpc_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nussjustin/httpc"
+)
+
+// writeCertificate writes a new self-signed certificate with the given common name and its key to the given files.
+func writeCertificate(t *testing.T, certFile, keyFile, commonName string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+
+	if err := os.WriteFile(certFile, certPEM, 0o600); err != nil {
+		t.Fatalf("failed to write certificate: %v", err)
+	}
+
+	if err := os.WriteFile(keyFile, keyPEM, 0o600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+}
+
+func clientCertificateServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	srv.Config.SetKeepAlivesEnabled(false)
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestWithClientCertificate(t *testing.T) {
+	srv := clientCertificateServer(t)
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+
+	writeCertificate(t, certFile, keyFile, "first")
+
+	files, err := httpc.NewCertificateFiles(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("failed to load certificate: %v", err)
+	}
+
+	c := httpc.New(httpc.WithClient(srv.Client()), httpc.WithClientCertificate(files.GetClientCertificate))
+
+	fetch := func() string {
+		t.Helper()
+
+		var got string
+
+		if err := c.Fetch(t.Context(), http.MethodGet, srv.URL, &got); err != nil {
+			t.Fatalf("failed to fetch: %v", err)
+		}
+
+		return got
+	}
+
+	if got, want := fetch(), "first"; got != want {
+		t.Errorf("got common name %q, want %q", got, want)
+	}
+
+	writeCertificate(t, certFile, keyFile, "second")
+
+	// Make sure the change is detected even on file systems with coarse modification times.
+	future := time.Now().Add(time.Minute)
+
+	if err := os.Chtimes(certFile, future, future); err != nil {
+		t.Fatalf("failed to change modification time: %v", err)
+	}
+
+	if got, want := fetch(), "second"; got != want {
+		t.Errorf("got common name %q, want %q", got, want)
+	}
+
+	// Broken files keep the last certificate
+	if err := os.WriteFile(keyFile, []byte("invalid"), 0o600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+
+	if got, want := fetch(), "second"; got != want {
+		t.Errorf("got common name %q, want %q", got, want)
+	}
+}
+
+func TestNewCertificateFiles_Error(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := httpc.NewCertificateFiles(filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")); err == nil {
+		t.Error("got no error")
+	}
+}
@@ -0,0 +1,166 @@
+package httpc
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// TokenSource provides bearer tokens used by [WithTokenSource].
+type TokenSource interface {
+	// Token returns a new token.
+	//
+	// Tokens are cached and Token is only called when there is no cached token or the cached token was rejected by
+	// the server, so implementations should return a fresh token each time.
+	Token(ctx context.Context) (string, error)
+}
+
+// TokenSourceFunc implements the [TokenSource] interface using a function.
+type TokenSourceFunc func(ctx context.Context) (string, error)
+
+// Token implements the [TokenSource] interface.
+func (f TokenSourceFunc) Token(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// WithTokenSource adds a bearer token obtained from the given [TokenSource] to the Authorization header of the request.
+//
+// If the request is answered with 401 Unauthorized, the token is invalidated, a new token is requested and the
+// request is sent again once using the new token. Concurrent requests share a single refresh, so that a burst of
+// rejected requests does not cause a burst of requests to the token endpoint.
+//
+// Requests with a body can only be sent again if [http.Request.GetBody] is set. Otherwise, the 401 response is
+// returned as is.
+//
+// Since tokens are shared only by requests using the same option, the returned option should be created once and
+// reused, for example by passing it to [New].
+func WithTokenSource(ts TokenSource) FetchOption {
+	c := &tokenCache{source: ts}
+
+	return func(ctx *fetchContext) error {
+		ctx.Tokens, ctx.TokenAuthorization = c, nil
+		return nil
+	}
+}
+
+// applyTokenSource replaces the client with one that adds tokens to requests, if configured.
+func (ctx *fetchContext) applyTokenSource() {
+	if ctx.Tokens == nil || ctx.PresignedURL {
+		return
+	}
+
+	ctx.wrapTransport(func(rt http.RoundTripper) http.RoundTripper {
+		return &tokenTransport{next: rt, tokens: ctx.Tokens, authorization: ctx.TokenAuthorization}
+	})
+}
+
+type tokenCache struct {
+	source TokenSource
+
+	// valid reports whether the cached token can still be used, if set. Invalid tokens are refreshed before use.
+	valid func(token string) bool
+
+	mu         sync.Mutex
+	token      string
+	refreshing chan struct{}
+}
+
+// get returns the cached token or requests a new one, if there is none.
+func (c *tokenCache) get(ctx context.Context) (string, error) {
+	return c.refresh(ctx, "")
+}
+
+// refresh returns a new token, replacing the given rejected token.
+//
+// If the cached token is not the rejected one, for example because another request already refreshed it, the cached
+// token is returned. Concurrent calls wait for a single refresh.
+func (c *tokenCache) refresh(ctx context.Context, rejected string) (string, error) {
+	for {
+		c.mu.Lock()
+
+		if c.token != "" && c.token != rejected && (c.valid == nil || c.valid(c.token)) {
+			token := c.token
+			c.mu.Unlock()
+			return token, nil
+		}
+
+		if ch := c.refreshing; ch != nil {
+			c.mu.Unlock()
+
+			select {
+			case <-ctx.Done():
+				return "", ctx.Err()
+			case <-ch:
+				continue
+			}
+		}
+
+		ch := make(chan struct{})
+		c.refreshing, c.token = ch, ""
+		c.mu.Unlock()
+
+		token, err := c.source.Token(ctx)
+
+		c.mu.Lock()
+		if err == nil {
+			c.token = token
+		}
+		c.refreshing = nil
+		c.mu.Unlock()
+
+		close(ch)
+
+		return token, err
+	}
+}
+
+type tokenTransport struct {
+	next   http.RoundTripper
+	tokens *tokenCache
+
+	// authorization returns the value of the Authorization header for a token, if set. Otherwise, tokens are sent as
+	// bearer tokens.
+	authorization func(token string) string
+}
+
+// header returns the value of the Authorization header for the given token.
+func (t *tokenTransport) header(token string) string {
+	if t.authorization != nil {
+		return t.authorization(token)
+	}
+
+	return "Bearer " + token
+}
+
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.tokens.get(req.Context())
+	if err != nil {
+		closeRequestBody(req)
+		return nil, err
+	}
+
+	first := req.Clone(req.Context())
+	first.Header.Set("Authorization", t.header(token))
+
+	resp, err := t.next.RoundTrip(first)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	retry, ok := rewindRequest(req)
+	if !ok {
+		return resp, nil
+	}
+
+	token, err = t.tokens.refresh(req.Context(), token)
+	if err != nil {
+		discardBody(resp, nil)
+		return nil, err
+	}
+
+	discardBody(resp, nil)
+
+	retry.Header.Set("Authorization", t.header(token))
+
+	return t.next.RoundTrip(retry)
+}
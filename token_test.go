@@ -0,0 +1,104 @@
+package httpc_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/nussjustin/httpc"
+)
+
+func TestWithTokenSource(t *testing.T) {
+	var valid atomic.Value
+	valid.Store("token-2")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+valid.Load().(string) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+
+	var calls atomic.Int64
+
+	ts := httpc.TokenSourceFunc(func(context.Context) (string, error) {
+		return "token-" + strconv.FormatInt(calls.Add(1), 10), nil
+	})
+
+	c := httpc.New(httpc.WithTokenSource(ts))
+
+	var wg sync.WaitGroup
+
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if err := c.Fetch(t.Context(), http.MethodGet, srv.URL, nil); err != nil {
+				t.Errorf("got error %v", err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if got, want := calls.Load(), int64(2); got != want {
+		t.Errorf("got %d token requests, want %d", got, want)
+	}
+
+	// Token is reused
+	if err := c.Fetch(t.Context(), http.MethodGet, srv.URL, nil); err != nil {
+		t.Errorf("got error %v", err)
+	}
+
+	if got, want := calls.Load(), int64(2); got != want {
+		t.Errorf("got %d token requests, want %d", got, want)
+	}
+
+	// Token rejected even after refresh
+	valid.Store("never")
+
+	if err := c.Fetch(t.Context(), http.MethodGet, srv.URL, nil); !errors.Is(err, httpc.ErrUnexpectedStatus) {
+		t.Errorf("got error %v, want %v", err, httpc.ErrUnexpectedStatus)
+	}
+
+	if got, want := calls.Load(), int64(3); got != want {
+		t.Errorf("got %d token requests, want %d", got, want)
+	}
+}
+
+func TestWithTokenSource_Error(t *testing.T) {
+	errToken := errors.New("token error")
+
+	ts := httpc.TokenSourceFunc(func(context.Context) (string, error) {
+		return "", errToken
+	})
+
+	req := 0
+
+	client := &http.Client{
+		Transport: roundTripperFunc(func(*http.Request) (*http.Response, error) {
+			req++
+			return nil, errors.New("unexpected request")
+		}),
+	}
+
+	_, err := httpc.Fetch[any](t.Context(), http.MethodGet, "http://example.com/",
+		httpc.WithClient(client),
+		httpc.WithTokenSource(ts))
+	if !errors.Is(err, errToken) {
+		t.Errorf("got error %v, want %v", err, errToken)
+	}
+
+	if req != 0 {
+		t.Errorf("got %d requests, want 0", req)
+	}
+}
@@ -0,0 +1,142 @@
+package httpc
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Tracer creates spans for requests made using [WithTracer].
+//
+// Tracer allows integrating tracing libraries like OpenTelemetry without adding them as dependency of this package.
+// An OpenTelemetry based implementation would start a span of kind client named [SpanInfo.Name] using a
+// trace.Tracer, inject the trace context into the header using a propagation.TextMapPropagator and record the
+// values of [SpanResult] as attributes when the span ends.
+type Tracer interface {
+	// Start starts a new span for the request described by info and returns a context containing the span.
+	//
+	// Implementations should inject the trace context into header, so that the server can continue the trace.
+	Start(ctx context.Context, info SpanInfo, header http.Header) (context.Context, Span)
+}
+
+// Span is a span started by a [Tracer].
+type Span interface {
+	// End ends the span, recording the given result.
+	End(result SpanResult)
+}
+
+// SpanInfo describes the request for which a span is started.
+type SpanInfo struct {
+	// Name is the name of the span, consisting of the method and the path template, for example
+	// "GET /product/{id}", or the operation name if one was set using [WithOperationName].
+	//
+	// Since the path template contains the wildcards instead of the values, the number of distinct names stays low.
+	Name string
+
+	// OperationName is the name set using [WithOperationName], if any.
+	OperationName string
+
+	// Method is the HTTP method of the request.
+	Method string
+
+	// URLTemplate is the URL of the request before any wildcards were replaced.
+	URLTemplate string
+
+	// PathTemplate is the path of the request before any wildcards were replaced.
+	PathTemplate string
+
+	// URL is the URL of the request, with secrets redacted.
+	URL string
+
+	// Labels contains the labels added using [WithLabel]. It must not be modified.
+	Labels map[string]string
+}
+
+// SpanResult describes the result of a request.
+type SpanResult struct {
+	// StatusCode is the status code of the last response, or 0 if no response was received.
+	StatusCode int
+
+	// Attempts is the number of times the request was sent.
+	Attempts int
+
+	// Err is the error returned for the request, if any.
+	Err error
+}
+
+// WithTracer causes a span to be created using the given [Tracer] for each request.
+//
+// The span covers the whole request, including retries, redirects and handling of the response, and is ended before
+// [Fetch] returns. Requests answered using [WithMemoize] are not traced.
+func WithTracer(t Tracer) FetchOption {
+	return func(ctx *fetchContext) error {
+		ctx.Tracer = t
+		return nil
+	}
+}
+
+// spanInfo returns the [SpanInfo] describing the request.
+func (ctx *fetchContext) spanInfo() SpanInfo {
+	name := ctx.OperationName
+	if name == "" {
+		name = ctx.Request.Method + " " + ctx.PathTemplate
+	}
+
+	return SpanInfo{
+		Name:          name,
+		OperationName: ctx.OperationName,
+		Method:        ctx.Request.Method,
+		URLTemplate:   ctx.URLTemplate,
+		PathTemplate:  ctx.PathTemplate,
+		URL:           ctx.redactor().URL(ctx.Request.URL),
+		Labels:        ctx.Labels,
+	}
+}
+
+// startSpan starts a span for the request using the configured [Tracer].
+func (ctx *fetchContext) startSpan() Span {
+	spanCtx, span := ctx.Tracer.Start(ctx.Request.Context(), ctx.spanInfo(), ctx.Request.Header)
+
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+	return span
+}
+
+// spanResult returns the [SpanResult] for the given response and error.
+func (ctx *fetchContext) spanResult(resp *http.Response, err error) SpanResult {
+	result := SpanResult{Attempts: ctx.Attempt, Err: err}
+
+	if resp != nil {
+		result.StatusCode = resp.StatusCode
+	}
+
+	return result
+}
+
+// pathTemplateKey is the context key used to pass the path template of a request to handlers and transports.
+type pathTemplateKey struct{}
+
+// RequestPathTemplate returns the path of the request before any wildcards were replaced, for example
+// "/product/{id}", or an empty string if the request was not made using this package.
+//
+// Unlike the expanded path, the template does not contain request specific values and can be used to aggregate
+// requests, for example in a [Middleware] recording metrics or audit records.
+func RequestPathTemplate(req *http.Request) string {
+	s, _ := req.Context().Value(pathTemplateKey{}).(string)
+	return s
+}
+
+// withPathTemplate returns a copy of req that passes the given path template to handlers and transports.
+func withPathTemplate(req *http.Request, template string) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), pathTemplateKey{}, template))
+}
+
+// pathTemplate returns the path of the given URL, keeping any wildcards unescaped.
+func pathTemplate(u *url.URL) string {
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+
+	return strings.NewReplacer("%7B", "{", "%7D", "}").Replace(path)
+}
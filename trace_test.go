@@ -0,0 +1,98 @@
+package httpc_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nussjustin/httpc"
+)
+
+type testSpanKey struct{}
+
+type testTracer struct {
+	info   httpc.SpanInfo
+	result *httpc.SpanResult
+}
+
+func (t *testTracer) Start(ctx context.Context, info httpc.SpanInfo, header http.Header) (context.Context, httpc.Span) {
+	t.info = info
+	header.Set("Traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	return context.WithValue(ctx, testSpanKey{}, "span"), t
+}
+
+func (t *testTracer) End(result httpc.SpanResult) {
+	t.result = &result
+}
+
+func TestWithTracer(t *testing.T) {
+	var req *http.Request
+
+	client := &http.Client{
+		Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			req = r
+
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": []string{"text/plain"}},
+				Body:       io.NopCloser(strings.NewReader("body")),
+				Request:    r,
+			}, nil
+		}),
+	}
+
+	tracer := &testTracer{}
+
+	_, err := httpc.Fetch[string](t.Context(), http.MethodGet, "https://example.com/product/{id}?key=secret",
+		httpc.WithClient(client),
+		httpc.WithPathValue("id", "1"),
+		httpc.WithTracer(tracer))
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+
+	wantInfo := httpc.SpanInfo{
+		Name:         "GET /product/{id}",
+		Method:       http.MethodGet,
+		URLTemplate:  "https://example.com/product/{id}?key=secret",
+		PathTemplate: "/product/{id}",
+		URL:          "https://example.com/product/1?key=REDACTED",
+	}
+
+	if diff := cmp.Diff(wantInfo, tracer.info); diff != "" {
+		t.Errorf("info mismatch (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff(&httpc.SpanResult{StatusCode: http.StatusOK, Attempts: 1}, tracer.result); diff != "" {
+		t.Errorf("result mismatch (-want +got):\n%s", diff)
+	}
+
+	if got := req.Header.Get("Traceparent"); got == "" {
+		t.Error("trace context not injected")
+	}
+
+	if got := req.Context().Value(testSpanKey{}); got != "span" {
+		t.Error("span context not used for request")
+	}
+}
+
+func TestRequestPathTemplate(t *testing.T) {
+	var got string
+
+	captureRequest(t, "https://example.com/product/{id}",
+		httpc.WithPathValue("id", "1"),
+		httpc.WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+			return httpc.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				got = httpc.RequestPathTemplate(req)
+				return next.RoundTrip(req)
+			})
+		}))
+
+	if want := "/product/{id}"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
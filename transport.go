@@ -0,0 +1,252 @@
+package httpc
+
+import (
+	"fmt"
+	"net/http"
+	"runtime"
+	"slices"
+	"sync"
+	"time"
+	"weak"
+)
+
+// HTTPVersion specifies the HTTP version used for requests.
+type HTTPVersion int
+
+const (
+	// HTTPVersionAuto uses the protocols as configured on the underlying transport.
+	//
+	// This is the default.
+	HTTPVersionAuto HTTPVersion = iota
+
+	// HTTPVersion1 forces the use of HTTP/1.1.
+	HTTPVersion1
+
+	// HTTPVersion2 forces the use of HTTP/2 over TLS.
+	HTTPVersion2
+
+	// HTTPVersion2Cleartext forces the use of unencrypted HTTP/2 (h2c) with prior knowledge for http:// URLs.
+	HTTPVersion2Cleartext
+)
+
+// protocols returns the [http.Protocols] that must be set on a transport to use the version.
+func (v HTTPVersion) protocols() *http.Protocols {
+	var p http.Protocols
+
+	switch v {
+	case HTTPVersionAuto:
+		return nil
+	case HTTPVersion1:
+		p.SetHTTP1(true)
+	case HTTPVersion2:
+		p.SetHTTP2(true)
+	case HTTPVersion2Cleartext:
+		p.SetUnencryptedHTTP2(true)
+	}
+
+	return &p
+}
+
+// WithHTTPVersion forces the use of the given HTTP version.
+//
+// The transport of the underlying client must be an [*http.Transport]. If the client has no transport,
+// [http.DefaultTransport] is used.
+//
+// For each transport and version a copy of the transport is created that is configured to only use the given version.
+// Copies are cached, so that connections can be reused by later requests.
+func WithHTTPVersion(v HTTPVersion) FetchOption {
+	switch v {
+	case HTTPVersionAuto, HTTPVersion1, HTTPVersion2, HTTPVersion2Cleartext:
+	default:
+		panic(fmt.Errorf("unknown HTTP version %d", v))
+	}
+
+	return func(ctx *fetchContext) error {
+		ctx.HTTPVersion = v
+		return nil
+	}
+}
+
+// applyHTTPVersion replaces the client with one that uses a transport for the configured HTTP version.
+func (ctx *fetchContext) applyHTTPVersion() error {
+	if ctx.HTTPVersion == HTTPVersionAuto {
+		return nil
+	}
+
+	t, err := ctx.transport()
+	if err != nil {
+		return fmt.Errorf("can not set HTTP version: %w", err)
+	}
+
+	client := *ctx.Client
+	client.Transport = versionedTransport(t, ctx.HTTPVersion)
+
+	ctx.Client = &client
+	return nil
+}
+
+// ConnectionLimits configures the connection pool of transports created by [WithIsolatedTransport].
+//
+// Zero values keep the value of the transport that is cloned.
+type ConnectionLimits struct {
+	// MaxIdleConns limits the total number of idle connections. See [http.Transport.MaxIdleConns].
+	MaxIdleConns int
+
+	// MaxIdleConnsPerHost limits the number of idle connections per host. See [http.Transport.MaxIdleConnsPerHost].
+	MaxIdleConnsPerHost int
+
+	// MaxConnsPerHost limits the total number of connections per host. See [http.Transport.MaxConnsPerHost].
+	MaxConnsPerHost int
+
+	// IdleConnTimeout specifies how long idle connections are kept. See [http.Transport.IdleConnTimeout].
+	IdleConnTimeout time.Duration
+}
+
+// WithIsolatedTransport causes requests to use a separate connection pool.
+//
+// When the option is first applied, the [*http.Transport] of the underlying client is cloned and the given limits are
+// applied to the copy. All requests using the returned option share the copy and its connection pool, while requests
+// using other transports are not affected.
+//
+// This can be used to prevent requests to a single API from exhausting the connection pool shared with other APIs.
+// Since each call to WithIsolatedTransport creates a new pool, the returned option should be created once and reused,
+// for example by passing it to [New].
+//
+// The transport of the underlying client must be an [*http.Transport]. If the client has no transport,
+// [http.DefaultTransport] is used.
+func WithIsolatedTransport(limits ConnectionLimits) FetchOption {
+	transports := &transportCache{}
+
+	isolate := func(t *http.Transport) *http.Transport {
+		return transports.get(t, func(t *http.Transport) *http.Transport {
+			isolated := t.Clone()
+
+			if limits.MaxIdleConns != 0 {
+				isolated.MaxIdleConns = limits.MaxIdleConns
+			}
+
+			if limits.MaxIdleConnsPerHost != 0 {
+				isolated.MaxIdleConnsPerHost = limits.MaxIdleConnsPerHost
+			}
+
+			if limits.MaxConnsPerHost != 0 {
+				isolated.MaxConnsPerHost = limits.MaxConnsPerHost
+			}
+
+			if limits.IdleConnTimeout != 0 {
+				isolated.IdleConnTimeout = limits.IdleConnTimeout
+			}
+
+			return isolated
+		})
+	}
+
+	return func(ctx *fetchContext) error {
+		ctx.IsolateTransport = isolate
+		return nil
+	}
+}
+
+// applyIsolatedTransport replaces the client with one that uses an isolated transport, if configured.
+func (ctx *fetchContext) applyIsolatedTransport() error {
+	if ctx.IsolateTransport == nil {
+		return nil
+	}
+
+	t, err := ctx.transport()
+	if err != nil {
+		return err
+	}
+
+	client := *ctx.Client
+	client.Transport = ctx.IsolateTransport(t)
+
+	ctx.Client = &client
+	return nil
+}
+
+// wrapTransport replaces the client with one that uses the transport returned by wrap.
+//
+// wrap is called with the transport of the underlying client or [http.DefaultTransport], if the client has none.
+func (ctx *fetchContext) wrapTransport(wrap func(http.RoundTripper) http.RoundTripper) {
+	rt := ctx.Client.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+
+	client := *ctx.Client
+	client.Transport = wrap(rt)
+
+	ctx.Client = &client
+}
+
+// transport returns the [*http.Transport] of the underlying client.
+func (ctx *fetchContext) transport() (*http.Transport, error) {
+	rt := ctx.Client.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+
+	t, ok := rt.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("unsupported transport of type %T", rt)
+	}
+
+	return t, nil
+}
+
+// transportCache caches configured copies of transports.
+//
+// Copies are keyed by weak pointers to the original transports, so that the cache keeps neither the originals nor, once
+// the cache itself is no longer used, their copies alive. Entries are removed and idle connections of the copy are
+// closed once the original transport is garbage collected.
+type transportCache struct {
+	copies sync.Map // weak.Pointer[http.Transport] -> *http.Transport
+}
+
+// get returns the cached copy of t, calling configure to create the copy if there is none.
+//
+// configure must not keep a reference to t, since t could otherwise never be garbage collected.
+func (c *transportCache) get(t *http.Transport, configure func(*http.Transport) *http.Transport) *http.Transport {
+	key := weak.Make(t)
+
+	if configured, ok := c.copies.Load(key); ok {
+		return configured.(*http.Transport)
+	}
+
+	configured, loaded := c.copies.LoadOrStore(key, configure(t))
+	if !loaded {
+		cache, clone := weak.Make(c), weak.Make(configured.(*http.Transport))
+
+		runtime.AddCleanup(t, func(key weak.Pointer[http.Transport]) {
+			if c := cache.Value(); c != nil {
+				c.copies.Delete(key)
+			}
+
+			if clone := clone.Value(); clone != nil {
+				clone.CloseIdleConnections()
+			}
+		}, key)
+	}
+
+	return configured.(*http.Transport)
+}
+
+// versionedTransports caches copies of transports configured for a specific HTTP version, indexed by version.
+var versionedTransports [HTTPVersion2Cleartext + 1]transportCache
+
+// versionedTransport returns a copy of t that only uses the given HTTP version.
+func versionedTransport(t *http.Transport, v HTTPVersion) *http.Transport {
+	return versionedTransports[v].get(t, func(t *http.Transport) *http.Transport {
+		clone := t.Clone()
+		clone.Protocols = v.protocols()
+
+		// The TLS config may already have been configured to negotiate HTTP/2 by the original transport.
+		if v == HTTPVersion1 && clone.TLSClientConfig != nil {
+			clone.TLSClientConfig.NextProtos = slices.DeleteFunc(slices.Clone(clone.TLSClientConfig.NextProtos),
+				func(proto string) bool { return proto == "h2" })
+		}
+
+		return clone
+	})
+}
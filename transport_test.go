@@ -0,0 +1,157 @@
+package httpc_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/nussjustin/httpc"
+)
+
+func TestWithHTTPVersion(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`"` + r.Proto + `"`))
+	})
+
+	tlsServer := httptest.NewUnstartedServer(handler)
+	tlsServer.EnableHTTP2 = true
+	tlsServer.StartTLS()
+	t.Cleanup(tlsServer.Close)
+
+	cleartextServer := httptest.NewUnstartedServer(handler)
+	cleartextServer.Config.Protocols = new(http.Protocols)
+	cleartextServer.Config.Protocols.SetHTTP1(true)
+	cleartextServer.Config.Protocols.SetUnencryptedHTTP2(true)
+	cleartextServer.Start()
+	t.Cleanup(cleartextServer.Close)
+
+	testCases := []struct {
+		Name     string
+		Server   *httptest.Server
+		Version  httpc.HTTPVersion
+		Expected string
+	}{
+		{Name: "Auto", Server: tlsServer, Version: httpc.HTTPVersionAuto, Expected: "HTTP/2.0"},
+		{Name: "HTTP/1.1", Server: tlsServer, Version: httpc.HTTPVersion1, Expected: "HTTP/1.1"},
+		{Name: "HTTP/2", Server: tlsServer, Version: httpc.HTTPVersion2, Expected: "HTTP/2.0"},
+		{Name: "Auto - cleartext", Server: cleartextServer, Version: httpc.HTTPVersionAuto, Expected: "HTTP/1.1"},
+		{Name: "h2c", Server: cleartextServer, Version: httpc.HTTPVersion2Cleartext, Expected: "HTTP/2.0"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			client := testCase.Server.Client()
+
+			for range 2 {
+				got, err := httpc.Fetch[string](t.Context(), http.MethodGet, testCase.Server.URL,
+					httpc.WithClient(client),
+					httpc.WithHTTPVersion(testCase.Version))
+				if err != nil {
+					t.Fatalf("failed to fetch: %v", err)
+				}
+
+				if got != testCase.Expected {
+					t.Errorf("got protocol %q, want %q", got, testCase.Expected)
+				}
+			}
+		})
+	}
+}
+
+func TestWithHTTPVersion_UnsupportedTransport(t *testing.T) {
+	client := &http.Client{
+		Transport: roundTripperFunc(func(*http.Request) (*http.Response, error) {
+			t.Fatal("transport called")
+			return nil, nil
+		}),
+	}
+
+	_, err := httpc.Fetch[any](t.Context(), http.MethodGet, "https://example.com/",
+		httpc.WithClient(client),
+		httpc.WithHTTPVersion(httpc.HTTPVersion1))
+	if err == nil {
+		t.Fatal("got nil error")
+	}
+}
+
+func TestWithIsolatedTransport(t *testing.T) {
+	client, baseURL := testEndpoint(t)
+
+	fetch := func(opts ...httpc.FetchOption) (reused bool) {
+		t.Helper()
+
+		ctx := httptrace.WithClientTrace(t.Context(), &httptrace.ClientTrace{
+			GotConn: func(info httptrace.GotConnInfo) {
+				reused = info.Reused
+			},
+		})
+
+		opts = append([]httpc.FetchOption{httpc.WithClient(client), httpc.WithBaseURL(baseURL)}, opts...)
+
+		if _, err := httpc.Fetch[infoResponse](ctx, http.MethodGet, "/", opts...); err != nil {
+			t.Fatalf("failed to fetch: %v", err)
+		}
+
+		return reused
+	}
+
+	isolated1 := httpc.WithIsolatedTransport(httpc.ConnectionLimits{MaxIdleConnsPerHost: 1})
+	isolated2 := httpc.WithIsolatedTransport(httpc.ConnectionLimits{})
+
+	if fetch() {
+		t.Error("first request on shared transport reused connection")
+	}
+
+	if fetch(isolated1) {
+		t.Error("first request on isolated transport reused connection")
+	}
+
+	if !fetch(isolated1) {
+		t.Error("second request on isolated transport did not reuse connection")
+	}
+
+	if fetch(isolated2) {
+		t.Error("first request on second isolated transport reused connection")
+	}
+
+	if !fetch() {
+		t.Error("second request on shared transport did not reuse connection")
+	}
+}
+
+func TestWithIsolatedTransport_ReleasesTransport(t *testing.T) {
+	isolated := httpc.WithIsolatedTransport(httpc.ConnectionLimits{})
+
+	collected := make(chan struct{})
+
+	func() {
+		transport := &http.Transport{}
+		runtime.AddCleanup(transport, func(ch chan struct{}) { close(ch) }, collected)
+
+		// The request fails, but the transport is still isolated.
+		_, _ = httpc.Fetch[any](t.Context(), http.MethodGet, "http://127.0.0.1:0/",
+			httpc.WithClient(&http.Client{Transport: transport}),
+			isolated)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for {
+		runtime.GC()
+
+		select {
+		case <-collected:
+			runtime.KeepAlive(isolated)
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("transport was not garbage collected")
+		}
+	}
+}